@@ -7,6 +7,7 @@ require (
 	github.com/tkrop/go-config v0.0.22
 	github.com/tkrop/go-testing v0.0.45
 	go.uber.org/mock v0.6.0
+	golang.org/x/sys v0.46.0
 )
 
 require (
@@ -14,6 +15,5 @@ require (
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/sirupsen/logrus v1.9.4 // indirect
-	golang.org/x/sys v0.46.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,364 @@
+// Package bench provides parsing and statistical comparison of `go test
+// -bench` results against a baseline with regression gating by thresholds.
+//
+// The results of the same benchmark, e.g. of multiple runs using `-count`,
+// are collected as samples. The comparison uses the median of the samples and
+// the [Mann-Whitney U test] to decide whether a delta is significant, if
+// there are enough samples, i.e. at least [MinSamples] on each side.
+//
+// [Mann-Whitney U test]: https://en.wikipedia.org/wiki/Mann-Whitney_U_test
+package bench
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// UnitTime provides the unit of the time per operation.
+	UnitTime = "ns/op"
+	// UnitBytes provides the unit of the allocated bytes per operation.
+	UnitBytes = "B/op"
+	// UnitAllocs provides the unit of the allocations per operation.
+	UnitAllocs = "allocs/op"
+
+	// DefaultThresholds provides the default regression thresholds.
+	DefaultThresholds = "ns/op=10% B/op=10% allocs/op=10%"
+
+	// MinSamples provides the minimum number of samples on each side to test
+	// the significance of a delta.
+	MinSamples = 4
+	// Alpha provides the significance level of the delta.
+	Alpha = 0.05
+)
+
+// regexProcs matches the `GOMAXPROCS` suffix of a benchmark name.
+var regexProcs = regexp.MustCompile(`-[0-9]+$`)
+
+// ErrInvalidThreshold represents an invalid regression threshold.
+var ErrInvalidThreshold = errors.New("invalid threshold")
+
+// NewErrInvalidThreshold creates an error for the given invalid regression
+// threshold entry caused by the given error.
+func NewErrInvalidThreshold(entry string, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w [entry=%s]: %w", ErrInvalidThreshold, entry, err)
+	}
+	return fmt.Errorf("%w [entry=%s]", ErrInvalidThreshold, entry)
+}
+
+// Benchmark contains the samples of a benchmark.
+type Benchmark struct {
+	// Package provides the package of the benchmark.
+	Package string
+	// Name provides the name of the benchmark without `GOMAXPROCS` suffix.
+	Name string
+	// Units provides the units of the benchmark in order of appearance.
+	Units []string
+	// Values provides the samples of the benchmark per unit.
+	Values map[string][]float64
+}
+
+// Results contains the benchmarks in order of appearance.
+type Results []*Benchmark
+
+// Find returns the benchmark with given package and name, or nil, if the
+// benchmark is not contained.
+func (r Results) Find(pkg, name string) *Benchmark {
+	for _, bench := range r {
+		if bench.Package == pkg && bench.Name == name {
+			return bench
+		}
+	}
+	return nil
+}
+
+// Parse parses the benchmark results of the `go test -bench` output read
+// from the given reader. Lines not containing benchmark results are ignored.
+func Parse(reader io.Reader) (Results, error) {
+	results, pkg := Results{}, ""
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "pkg:"); ok {
+			pkg = strings.TrimSpace(value)
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 || len(fields)%2 != 0 ||
+			!strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		} else if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+
+		name := regexProcs.ReplaceAllString(fields[0], "")
+		bench := results.Find(pkg, name)
+		if bench == nil {
+			bench = &Benchmark{
+				Package: pkg, Name: name,
+				Units: []string{}, Values: map[string][]float64{},
+			}
+			results = append(results, bench)
+		}
+		for index := 2; index < len(fields); index += 2 {
+			value, err := strconv.ParseFloat(fields[index], 64)
+			if err != nil {
+				continue
+			}
+			unit := fields[index+1]
+			if _, ok := bench.Values[unit]; !ok {
+				bench.Units = append(bench.Units, unit)
+			}
+			bench.Values[unit] = append(bench.Values[unit], value)
+		}
+	}
+	//nolint:wrapcheck // wrapped by caller.
+	return results, scanner.Err()
+}
+
+// threshold contains a regression threshold of a unit for benchmarks
+// matching the regex.
+type threshold struct {
+	// regex provides the regex matching the benchmark names.
+	regex *regexp.Regexp
+	// unit provides the unit of the threshold.
+	unit string
+	// limit provides the maximum accepted relative increase.
+	limit float64
+}
+
+// Thresholds contains the regression thresholds of benchmarks.
+type Thresholds []*threshold
+
+// ParseThresholds parses the regression thresholds from the given whitespace
+// separated entries of the form `[<regex>:]<unit>=<percent>[%]`, e.g.
+// `ns/op=10%` or `BenchmarkParse:allocs/op=0`. Later entries take precedence
+// over earlier entries.
+func ParseThresholds(spec string) (Thresholds, error) {
+	thresholds := Thresholds{}
+	for _, entry := range strings.Fields(spec) {
+		index := strings.LastIndex(entry, "=")
+		if index < 0 {
+			return nil, NewErrInvalidThreshold(entry, nil)
+		}
+		limit, err := strconv.ParseFloat(
+			strings.TrimSuffix(entry[index+1:], "%"), 64)
+		if err != nil {
+			return nil, NewErrInvalidThreshold(entry, err)
+		} else if limit < 0 {
+			return nil, NewErrInvalidThreshold(entry, nil)
+		}
+
+		expr, unit := "", entry[:index]
+		if index := strings.LastIndex(unit, ":"); index >= 0 {
+			expr, unit = unit[:index], unit[index+1:]
+		}
+		if unit == "" {
+			return nil, NewErrInvalidThreshold(entry, nil)
+		}
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, NewErrInvalidThreshold(entry, err)
+		}
+		thresholds = append(thresholds, &threshold{
+			regex: regex, unit: unit, limit: limit / 100,
+		})
+	}
+	return thresholds, nil
+}
+
+// Limit returns the maximum accepted relative increase of the given unit for
+// the benchmark with given name, and whether a threshold is defined.
+func (t Thresholds) Limit(name, unit string) (float64, bool) {
+	for _, threshold := range slices.Backward(t) {
+		if threshold.unit == unit && threshold.regex.MatchString(name) {
+			return threshold.limit, true
+		}
+	}
+	return 0, false
+}
+
+// Delta contains the comparison of a benchmark unit with the baseline.
+type Delta struct {
+	// Package provides the package of the benchmark.
+	Package string
+	// Name provides the name of the benchmark.
+	Name string
+	// Unit provides the compared unit.
+	Unit string
+	// Base provides the median of the baseline samples.
+	Base float64
+	// Head provides the median of the current samples.
+	Head float64
+	// Change provides the relative change of the median.
+	Change float64
+	// P provides the p-value of the delta, or NaN, if the number of samples
+	// is not sufficient to test the significance.
+	P float64
+	// Samples provides the number of baseline and current samples.
+	Samples [2]int
+	// Limit provides the maximum accepted relative increase, if defined.
+	Limit *float64
+	// Regression indicates whether the delta is a regression.
+	Regression bool
+}
+
+// Compare compares the given current benchmark results with the given
+// baseline results using the given regression thresholds. Only benchmarks
+// and units contained in both results are compared. A delta is a regression,
+// if the change exceeds the threshold and is significant, or if there are not
+// enough samples to test the significance.
+func Compare(base, head Results, thresholds Thresholds) []*Delta {
+	deltas := []*Delta{}
+	for _, bench := range head {
+		other := base.Find(bench.Package, bench.Name)
+		if other == nil {
+			continue
+		}
+		for _, unit := range bench.Units {
+			if _, ok := other.Values[unit]; !ok {
+				continue
+			}
+			deltas = append(deltas, compare(bench.Package, bench.Name, unit,
+				other.Values[unit], bench.Values[unit], thresholds))
+		}
+	}
+	return deltas
+}
+
+// compare compares the given current samples with the given baseline samples
+// of the unit of the benchmark with given package and name.
+func compare(
+	pkg, name, unit string, base, head []float64, thresholds Thresholds,
+) *Delta {
+	delta := &Delta{
+		Package: pkg, Name: name, Unit: unit,
+		Base: median(base), Head: median(head), P: math.NaN(),
+		Samples: [2]int{len(base), len(head)},
+	}
+	switch {
+	case delta.Base != 0:
+		delta.Change = delta.Head/delta.Base - 1
+	case delta.Head != 0:
+		delta.Change = math.Inf(1)
+	}
+	if len(base) >= MinSamples && len(head) >= MinSamples {
+		delta.P = mannWhitney(base, head)
+	}
+
+	if limit, ok := thresholds.Limit(name, unit); ok {
+		delta.Limit = &limit
+		delta.Regression = delta.Change > limit &&
+			(math.IsNaN(delta.P) || delta.P < Alpha)
+	}
+	return delta
+}
+
+// String returns the delta as log message.
+func (d *Delta) String() string {
+	builder := &strings.Builder{}
+	if d.Regression {
+		builder.WriteString("regressed ")
+	} else {
+		builder.WriteString("ok ")
+	}
+	builder.WriteString(d.Name + " " + d.Unit + " [")
+	if d.Package != "" {
+		builder.WriteString("pkg=" + d.Package + ", ")
+	}
+	fmt.Fprintf(builder, "base=%s, head=%s, delta=%+.2f%%, p=%s, n=%d+%d",
+		format(d.Base), format(d.Head), d.Change*100, formatP(d.P),
+		d.Samples[0], d.Samples[1])
+	if d.Limit != nil {
+		builder.WriteString(", threshold=" + format(*d.Limit*100) + "%")
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+// format formats the given value using the minimal representation.
+func format(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatP formats the given p-value, or `n/a`, if the p-value is not a
+// number.
+func formatP(value float64) string {
+	if math.IsNaN(value) {
+		return "n/a"
+	}
+	return strconv.FormatFloat(value, 'f', 3, 64)
+}
+
+// median returns the median of the given non-empty values.
+func median(values []float64) float64 {
+	sorted := slices.Sorted(slices.Values(values))
+	if len(sorted)%2 == 1 {
+		return sorted[len(sorted)/2]
+	}
+	return (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+}
+
+// mannWhitney returns the two-sided p-value of the Mann-Whitney U test of the
+// given samples using the normal approximation with tie and continuity
+// correction.
+func mannWhitney(xs, ys []float64) float64 {
+	type sample struct {
+		value float64
+		first bool
+	}
+	samples := make([]sample, 0, len(xs)+len(ys))
+	for _, value := range xs {
+		samples = append(samples, sample{value: value, first: true})
+	}
+	for _, value := range ys {
+		samples = append(samples, sample{value: value})
+	}
+	slices.SortFunc(samples, func(a, b sample) int {
+		switch {
+		case a.value < b.value:
+			return -1
+		case a.value > b.value:
+			return 1
+		}
+		return 0
+	})
+
+	// Rank samples using the average rank for ties.
+	rank, ties := 0.0, 0.0
+	for start := 0; start < len(samples); {
+		end := start
+		for end < len(samples) && samples[end].value == samples[start].value {
+			end++
+		}
+		count := float64(end - start)
+		ties += count*count*count - count
+		for _, sample := range samples[start:end] {
+			if sample.first {
+				rank += float64(start+end+1) / 2
+			}
+		}
+		start = end
+	}
+
+	n1, n2 := float64(len(xs)), float64(len(ys))
+	n := n1 + n2
+	u := rank - n1*(n1+1)/2
+	u = math.Min(u, n1*n2-u)
+	mu := n1 * n2 / 2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+	z := math.Max(0, (mu-u-0.5)/sigma)
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
@@ -0,0 +1,344 @@
+package bench_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/bench"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// pkgParse contains the package of the parse benchmarks.
+	pkgParse = "github.com/org/repo/parse"
+	// pkgWrite contains the package of the write benchmarks.
+	pkgWrite = "github.com/org/repo/write"
+)
+
+// ReadResults reads the benchmark results from the given fixture file.
+func ReadResults(t test.Test, name string) bench.Results {
+	file, err := os.Open("fixtures/" + name)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	results, err := bench.Parse(file)
+	assert.NoError(t, err)
+	return results
+}
+
+// errReader is a reader failing with an error.
+type errReader struct{}
+
+// Read fails always with an error.
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+type ParseParams struct {
+	input         string
+	expectResults bench.Results
+	expectError   error
+}
+
+var parseTestCases = map[string]ParseParams{
+	"empty": {
+		expectResults: bench.Results{},
+	},
+	"benchmarks": {
+		input: "goos: linux\npkg: " + pkgParse + "\n" +
+			"BenchmarkParse-8 1000 12.5 ns/op 8 B/op 1 allocs/op\n" +
+			"BenchmarkParse-8 1000 13.5 ns/op 8 B/op 1 allocs/op 3 MB/s\n" +
+			"BenchmarkSize/size=10 1000 25 ns/op\n" +
+			"Benchmark 1000 ns/op\nBenchmarkNoIterations x 12 ns/op\n" +
+			"PASS\nok  \t" + pkgParse + "\t1.234s\n",
+		expectResults: bench.Results{{
+			Package: pkgParse, Name: "BenchmarkParse",
+			Units: []string{"ns/op", "B/op", "allocs/op", "MB/s"},
+			Values: map[string][]float64{
+				"ns/op": {12.5, 13.5}, "B/op": {8, 8},
+				"allocs/op": {1, 1}, "MB/s": {3},
+			},
+		}, {
+			Package: pkgParse, Name: "BenchmarkSize/size=10",
+			Units:  []string{"ns/op"},
+			Values: map[string][]float64{"ns/op": {25}},
+		}},
+	},
+	"read failed": {
+		expectError: errors.New("read failed"),
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, parseTestCases).
+		Run(func(t test.Test, param ParseParams) {
+			// Given
+			var reader io.Reader = strings.NewReader(param.input)
+			if param.expectError != nil {
+				reader = errReader{}
+			}
+
+			// When
+			results, err := bench.Parse(reader)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectError == nil {
+				assert.Equal(t, param.expectResults, results)
+			}
+		})
+}
+
+func TestResultsFind(t *testing.T) {
+	// Given
+	results := ReadResults(t, "base.bench")
+
+	// When
+	found := results.Find(pkgWrite, "BenchmarkParse")
+	missing := results.Find(pkgWrite, "BenchmarkFormat/size=10")
+
+	// Then
+	assert.Equal(t, &bench.Benchmark{
+		Package: pkgWrite, Name: "BenchmarkParse",
+		Units: []string{"ns/op", "B/op", "allocs/op"},
+		Values: map[string][]float64{
+			"ns/op": {100}, "B/op": {0}, "allocs/op": {0},
+		},
+	}, found)
+	assert.Nil(t, missing)
+}
+
+type ThresholdsParams struct {
+	spec        string
+	name        string
+	unit        string
+	expectLimit float64
+	expectOK    bool
+	expectError error
+}
+
+var thresholdsTestCases = map[string]ThresholdsParams{
+	"default time": {
+		spec: bench.DefaultThresholds,
+		name: "BenchmarkParse", unit: bench.UnitTime,
+		expectLimit: 0.1, expectOK: true,
+	},
+	"default allocs": {
+		spec: bench.DefaultThresholds,
+		name: "BenchmarkParse", unit: bench.UnitAllocs,
+		expectLimit: 0.1, expectOK: true,
+	},
+	"undefined unit": {
+		spec: bench.DefaultThresholds,
+		name: "BenchmarkParse", unit: "MB/s",
+	},
+	"benchmark override": {
+		spec: bench.DefaultThresholds + " BenchmarkParse$:ns/op=25% " +
+			"BenchmarkFormat/size=10:B/op=0",
+		name: "BenchmarkParse", unit: bench.UnitTime,
+		expectLimit: 0.25, expectOK: true,
+	},
+	"benchmark override with equal sign": {
+		spec: bench.DefaultThresholds + " BenchmarkParse$:ns/op=25% " +
+			"BenchmarkFormat/size=10:B/op=0",
+		name: "BenchmarkFormat/size=10", unit: bench.UnitBytes,
+		expectLimit: 0, expectOK: true,
+	},
+	"benchmark override not matching": {
+		spec: bench.DefaultThresholds + " BenchmarkParse$:ns/op=25%",
+		name: "BenchmarkParseAll", unit: bench.UnitTime,
+		expectLimit: 0.1, expectOK: true,
+	},
+
+	"missing limit": {
+		spec:        "ns/op",
+		expectError: bench.NewErrInvalidThreshold("ns/op", nil),
+	},
+	"invalid limit": {
+		spec: "ns/op=ten%",
+		expectError: bench.NewErrInvalidThreshold("ns/op=ten%",
+			&strconv.NumError{
+				Func: "ParseFloat", Num: "ten", Err: strconv.ErrSyntax,
+			}),
+	},
+	"negative limit": {
+		spec:        "ns/op=-5%",
+		expectError: bench.NewErrInvalidThreshold("ns/op=-5%", nil),
+	},
+	"missing unit": {
+		spec:        "BenchmarkParse:=5%",
+		expectError: bench.NewErrInvalidThreshold("BenchmarkParse:=5%", nil),
+	},
+	"invalid regex": {
+		spec: "Benchmark(:ns/op=5%",
+		expectError: bench.NewErrInvalidThreshold("Benchmark(:ns/op=5%",
+			errors.New("error parsing regexp: missing closing ): `Benchmark(`")),
+	},
+}
+
+func TestThresholds(t *testing.T) {
+	test.Map(t, thresholdsTestCases).
+		Run(func(t test.Test, param ThresholdsParams) {
+			// When
+			thresholds, err := bench.ParseThresholds(param.spec)
+
+			// Then
+			if param.expectError != nil {
+				assert.Nil(t, thresholds)
+				assert.Equal(t, param.expectError.Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			limit, ok := thresholds.Limit(param.name, param.unit)
+			assert.Equal(t, param.expectLimit, limit)
+			assert.Equal(t, param.expectOK, ok)
+		})
+}
+
+type CompareParams struct {
+	thresholds   string
+	expectDeltas []string
+}
+
+var compareTestCases = map[string]CompareParams{
+	"default thresholds": {
+		thresholds: bench.DefaultThresholds,
+		expectDeltas: []string{
+			"regressed BenchmarkParse ns/op [pkg=" + pkgParse +
+				", base=1015, head=1215, delta=+19.70%, p=0.030, n=4+4" +
+				", threshold=10%]",
+			"ok BenchmarkParse B/op [pkg=" + pkgParse +
+				", base=128, head=128, delta=+0.00%, p=1.000, n=4+4" +
+				", threshold=10%]",
+			"regressed BenchmarkParse allocs/op [pkg=" + pkgParse +
+				", base=2, head=3, delta=+50.00%, p=0.013, n=4+4" +
+				", threshold=10%]",
+			"ok BenchmarkFormat/size=10 ns/op [pkg=" + pkgParse +
+				", base=2000, head=2100, delta=+5.00%, p=n/a, n=1+1" +
+				", threshold=10%]",
+			"regressed BenchmarkFormat/size=10 B/op [pkg=" + pkgParse +
+				", base=64, head=96, delta=+50.00%, p=n/a, n=1+1" +
+				", threshold=10%]",
+			"ok BenchmarkFormat/size=10 allocs/op [pkg=" + pkgParse +
+				", base=1, head=1, delta=+0.00%, p=n/a, n=1+1" +
+				", threshold=10%]",
+			"ok BenchmarkParse ns/op [pkg=" + pkgWrite +
+				", base=100, head=100, delta=+0.00%, p=n/a, n=1+1" +
+				", threshold=10%]",
+			"regressed BenchmarkParse B/op [pkg=" + pkgWrite +
+				", base=0, head=16, delta=+Inf%, p=n/a, n=1+1" +
+				", threshold=10%]",
+			"regressed BenchmarkParse allocs/op [pkg=" + pkgWrite +
+				", base=0, head=1, delta=+Inf%, p=n/a, n=1+1" +
+				", threshold=10%]",
+		},
+	},
+	"custom thresholds": {
+		thresholds: "ns/op=20 BenchmarkFormat:B/op=50.5%",
+		expectDeltas: []string{
+			"ok BenchmarkParse ns/op [pkg=" + pkgParse +
+				", base=1015, head=1215, delta=+19.70%, p=0.030, n=4+4" +
+				", threshold=20%]",
+			"ok BenchmarkParse B/op [pkg=" + pkgParse +
+				", base=128, head=128, delta=+0.00%, p=1.000, n=4+4]",
+			"ok BenchmarkParse allocs/op [pkg=" + pkgParse +
+				", base=2, head=3, delta=+50.00%, p=0.013, n=4+4]",
+			"ok BenchmarkFormat/size=10 ns/op [pkg=" + pkgParse +
+				", base=2000, head=2100, delta=+5.00%, p=n/a, n=1+1" +
+				", threshold=20%]",
+			"ok BenchmarkFormat/size=10 B/op [pkg=" + pkgParse +
+				", base=64, head=96, delta=+50.00%, p=n/a, n=1+1" +
+				", threshold=50.5%]",
+			"ok BenchmarkFormat/size=10 allocs/op [pkg=" + pkgParse +
+				", base=1, head=1, delta=+0.00%, p=n/a, n=1+1]",
+			"ok BenchmarkParse ns/op [pkg=" + pkgWrite +
+				", base=100, head=100, delta=+0.00%, p=n/a, n=1+1" +
+				", threshold=20%]",
+			"ok BenchmarkParse B/op [pkg=" + pkgWrite +
+				", base=0, head=16, delta=+Inf%, p=n/a, n=1+1]",
+			"ok BenchmarkParse allocs/op [pkg=" + pkgWrite +
+				", base=0, head=1, delta=+Inf%, p=n/a, n=1+1]",
+		},
+	},
+}
+
+func TestCompare(t *testing.T) {
+	test.Map(t, compareTestCases).
+		Run(func(t test.Test, param CompareParams) {
+			// Given
+			base := ReadResults(t, "base.bench")
+			head := ReadResults(t, "head.bench")
+			thresholds, err := bench.ParseThresholds(param.thresholds)
+			assert.NoError(t, err)
+
+			// When
+			deltas := bench.Compare(base, head, thresholds)
+
+			// Then
+			result := []string{}
+			for _, delta := range deltas {
+				result = append(result, delta.String())
+			}
+			assert.Equal(t, param.expectDeltas, result)
+		})
+}
+
+type SignificanceParams struct {
+	base     []float64
+	head     []float64
+	expectP  string
+	expectOK bool
+}
+
+var significanceTestCases = map[string]SignificanceParams{
+	"not significant": {
+		base:     []float64{100, 130, 110, 120},
+		head:     []float64{105, 140, 125, 150},
+		expectP:  "p=0.312",
+		expectOK: true,
+	},
+	"reversed significant": {
+		base:     []float64{150, 151, 152, 153, 154},
+		head:     []float64{100, 101, 102, 103, 104},
+		expectP:  "p=0.012",
+		expectOK: true,
+	},
+	"significant": {
+		base:     []float64{100, 101, 102, 103, 104},
+		head:     []float64{150, 151, 152, 153, 154},
+		expectP:  "p=0.012",
+		expectOK: false,
+	},
+}
+
+func TestSignificance(t *testing.T) {
+	test.Map(t, significanceTestCases).
+		Run(func(t test.Test, param SignificanceParams) {
+			// Given
+			thresholds, err := bench.ParseThresholds("ns/op=10%")
+			assert.NoError(t, err)
+			base := bench.Results{{
+				Name: "BenchmarkParse", Units: []string{"ns/op"},
+				Values: map[string][]float64{"ns/op": param.base},
+			}}
+			head := bench.Results{{
+				Name: "BenchmarkParse", Units: []string{"ns/op"},
+				Values: map[string][]float64{"ns/op": param.head},
+			}}
+
+			// When
+			deltas := bench.Compare(base, head, thresholds)
+
+			// Then
+			assert.Len(t, deltas, 1)
+			assert.Contains(t, deltas[0].String(), param.expectP)
+			assert.Equal(t, param.expectOK, !deltas[0].Regression)
+			assert.NotContains(t, deltas[0].String(), "pkg=")
+		})
+}
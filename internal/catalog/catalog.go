@@ -0,0 +1,160 @@
+// Package catalog provides a parser for the target annotations of makefiles,
+// i.e. the `##` group and the `#@` target comments, that are rendered by the
+// `show-help` target.
+package catalog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Target represents an annotated target or target family of a makefile.
+type Target struct {
+	// Name provides the name of the target, or the name of the target family,
+	// e.g. `run-*` or `git-create-<type>`.
+	Name string `json:"name"`
+	// Group provides the group of the target as announced by `##`.
+	Group string `json:"group,omitempty"`
+	// Args provides the argument synopsis of the target, if any.
+	Args string `json:"args,omitempty"`
+	// Description provides the description of the target.
+	Description string `json:"description,omitempty"`
+	// Pattern provides the make pattern matching the targets of a target
+	// family, e.g. `run-%`, or empty for a plain target.
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// Catalog represents the annotated targets of makefiles in order of their
+// appearance.
+type Catalog []*Target
+
+// ErrParse represents a catalog parse failure.
+var ErrParse = errors.New("parse failed")
+
+// NewErrParse wraps the error of a failed catalog parse operation.
+func NewErrParse(err error) error {
+	return fmt.Errorf("%w: %w", ErrParse, err)
+}
+
+var (
+	// regexRule matches a plain rule providing the target name.
+	regexRule = regexp.MustCompile(`^([a-zA-Z_0-9?-]+)::?`)
+	// regexPatternRule matches a static pattern rule providing the pattern.
+	regexPatternRule = regexp.MustCompile(`^[^#\s].*::? *([^\s:]*%[^\s:]*):`)
+	// regexPlaceholder matches the placeholders of target family names.
+	regexPlaceholder = regexp.MustCompile(`\*|<[^>]*>`)
+)
+
+// Parse parses the target annotations of the makefiles provided by the given
+// reader. A `## <group>: <description>` comment announces the group of the
+// following targets. A `#@ [<args> #] <description>` comment annotates the
+// next rule, while a `#@ <family>: [<args> #] <description>` comment directly
+// describes a target family, e.g. `run-*` or `git-create-<type>`.
+func Parse(reader io.Reader) (Catalog, error) {
+	catalog := Catalog{}
+	var group string
+	var pending *Target
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if name, _, ok := strings.Cut(line[3:], ":"); ok {
+				group = strings.TrimSpace(name)
+			}
+
+		case strings.HasPrefix(line, "#@ "):
+			text := strings.TrimSpace(line[3:])
+			if name, rest, ok := strings.Cut(text, ": "); ok &&
+				!strings.ContainsAny(name, " #") {
+				catalog = append(catalog, newTarget(name, group, rest))
+				pending = nil
+			} else {
+				pending = newTarget("", group, text)
+			}
+
+		case pending == nil || line == "" || line[0] == '#' ||
+			line[0] == '\t':
+
+		default:
+			if match := regexRule.FindStringSubmatch(line); match != nil {
+				pending.Name = match[1]
+			} else if match := regexPatternRule.FindStringSubmatch(line); match != nil {
+				pending.Pattern = match[1]
+				pending.Name = strings.ReplaceAll(match[1], "%", "*")
+				if pending.Args == pending.Name {
+					pending.Args = ""
+				}
+			} else {
+				continue
+			}
+			catalog = append(catalog, pending)
+			pending = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewErrParse(err)
+	}
+	return catalog, nil
+}
+
+// newTarget creates a new target with given name and group from the given
+// annotation text consisting of the optional arguments separated by ` # `
+// from the description. If the name denotes a target family, the pattern is
+// derived from the name.
+func newTarget(name, group, text string) *Target {
+	target := &Target{Name: name, Group: group, Description: text}
+	if args, desc, ok := strings.Cut(text, " # "); ok {
+		target.Args = strings.TrimSpace(args)
+		target.Description = strings.TrimSpace(desc)
+	}
+	if regexPlaceholder.MatchString(name) {
+		target.Pattern = regexPlaceholder.ReplaceAllString(name, "%")
+	}
+	return target
+}
+
+// Lookup returns the target with given name, or the first target family
+// with a pattern matching the given name. If no target is found, nil is
+// returned.
+func (c Catalog) Lookup(name string) *Target {
+	for _, target := range c {
+		if target.Pattern == "" && target.Name == name {
+			return target
+		}
+	}
+	for _, target := range c {
+		if target.Pattern != "" && match(target.Pattern, name) {
+			return target
+		}
+	}
+	return nil
+}
+
+// Resolve returns a catalog with a target for each of the given target names
+// providing the metadata of the matching annotated target or target family.
+func (c Catalog) Resolve(names ...string) Catalog {
+	catalog := make(Catalog, 0, len(names))
+	for _, name := range names {
+		target := &Target{Name: name}
+		if found := c.Lookup(name); found != nil {
+			*target = *found
+			target.Name = name
+		}
+		catalog = append(catalog, target)
+	}
+	return catalog
+}
+
+// match returns whether the given make pattern with a single `%` wildcard
+// matches the given name.
+func match(pattern, name string) bool {
+	prefix, suffix, _ := strings.Cut(pattern, "%")
+	return len(name) >= len(prefix)+len(suffix) &&
+		strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix)
+}
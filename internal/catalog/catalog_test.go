@@ -0,0 +1,228 @@
+package catalog_test
+
+import (
+	"embed"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/catalog"
+	"github.com/tkrop/go-testing/test"
+)
+
+//go:embed fixtures/*
+var fixtures embed.FS
+
+// ReadFile reads the given fixture file and returns its content as string.
+func ReadFile(name string) string {
+	content, err := fixtures.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(content)
+}
+
+// catalogFixture contains the catalog of the `Makefile.test` fixture.
+var catalogFixture = catalog.Catalog{{
+	Name: "all", Group: "Standard",
+	Description: "executes the default targets.",
+}, {
+	Name: "test-unit", Group: "Standard", Args: "[<pkg>|<test>]",
+	Description: "execute only unit tests.",
+}, {
+	Name: "build-*", Group: "Build", Pattern: "build-%",
+	Description: "build executables for a specific platform.",
+}, {
+	Name: "build-cmd-*", Group: "Build", Pattern: "build-cmd-%",
+	Description: "build the matched command.",
+}, {
+	Name: "commit", Group: "Build", Args: "<message>",
+	Description: "creates a commit.",
+}, {
+	Name: "commit-<type>", Group: "Build", Args: "<message>",
+	Pattern: "commit-%", Description: "creates a commit using given commit type.",
+}}
+
+type ParseParams struct {
+	reader        io.Reader
+	expectCatalog catalog.Catalog
+	expectError   error
+}
+
+var parseTestCases = map[string]ParseParams{
+	"empty": {
+		reader:        strings.NewReader(""),
+		expectCatalog: catalog.Catalog{},
+	},
+	"fixture": {
+		reader:        strings.NewReader(ReadFile("fixtures/Makefile.test")),
+		expectCatalog: catalogFixture,
+	},
+	"annotation without rule": {
+		reader: strings.NewReader("#@ lost annotation.\n" +
+			"VALUE := value\n"),
+		expectCatalog: catalog.Catalog{},
+	},
+	"annotation replaced": {
+		reader: strings.NewReader("#@ lost annotation.\n" +
+			"#@ kept annotation.\ntarget:\n"),
+		expectCatalog: catalog.Catalog{{
+			Name: "target", Description: "kept annotation.",
+		}},
+	},
+	"read failure": {
+		reader:      iotest.ErrReader(assert.AnError),
+		expectError: catalog.NewErrParse(assert.AnError),
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, parseTestCases).
+		Run(func(t test.Test, param ParseParams) {
+			// When
+			catalog, err := catalog.Parse(param.reader)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectCatalog, catalog)
+		})
+}
+
+type LookupParams struct {
+	name         string
+	expectTarget *catalog.Target
+}
+
+var lookupTestCases = map[string]LookupParams{
+	"target": {
+		name:         "commit",
+		expectTarget: catalogFixture[4],
+	},
+	"target family": {
+		name:         "build-linux",
+		expectTarget: catalogFixture[2],
+	},
+	"target family first match": {
+		name:         "build-cmd-any",
+		expectTarget: catalogFixture[2],
+	},
+	"target family placeholder": {
+		name:         "commit-feat",
+		expectTarget: catalogFixture[5],
+	},
+	"target unknown": {
+		name: "clean",
+	},
+}
+
+func TestLookup(t *testing.T) {
+	catalog, err := catalog.Parse(
+		strings.NewReader(ReadFile("fixtures/Makefile.test")))
+	assert.NoError(t, err)
+
+	test.Map(t, lookupTestCases).
+		Run(func(t test.Test, param LookupParams) {
+			// When
+			target := catalog.Lookup(param.name)
+
+			// Then
+			assert.Equal(t, param.expectTarget, target)
+		})
+}
+
+type ResolveParams struct {
+	names         []string
+	expectCatalog catalog.Catalog
+}
+
+var resolveTestCases = map[string]ResolveParams{
+	"empty": {
+		expectCatalog: catalog.Catalog{},
+	},
+	"targets": {
+		names: []string{"all", "build-linux", "clean"},
+		expectCatalog: catalog.Catalog{{
+			Name: "all", Group: "Standard",
+			Description: "executes the default targets.",
+		}, {
+			Name: "build-linux", Group: "Build", Pattern: "build-%",
+			Description: "build executables for a specific platform.",
+		}, {
+			Name: "clean",
+		}},
+	},
+}
+
+func TestResolve(t *testing.T) {
+	catalog, err := catalog.Parse(
+		strings.NewReader(ReadFile("fixtures/Makefile.test")))
+	assert.NoError(t, err)
+
+	test.Map(t, resolveTestCases).
+		Run(func(t test.Test, param ResolveParams) {
+			// When
+			resolved := catalog.Resolve(param.names...)
+
+			// Then
+			assert.Equal(t, param.expectCatalog, resolved)
+		})
+}
+
+type ConfigParams struct {
+	name         string
+	expectTarget *catalog.Target
+}
+
+var configTestCases = map[string]ConfigParams{
+	"run family": {
+		name: "run-service",
+		expectTarget: &catalog.Target{
+			Name: "run-*", Group: "Run", Pattern: "run-%",
+			Description: "start the matched command using the native binary.",
+		},
+	},
+	"update family": {
+		name: "update-revive",
+		expectTarget: &catalog.Target{
+			Name: "update-*", Group: "Update", Pattern: "update-%",
+			Description: "update the matched software command or service.",
+		},
+	},
+	"git-create family": {
+		name: "git-create-fix",
+		expectTarget: &catalog.Target{
+			Name: "git-create-<type>", Group: "Git-Support",
+			Pattern: "git-create-%", Description: "creates a branch with " +
+				"the current change set using given commit type.",
+		},
+	},
+	"git-create": {
+		name: "git-create",
+		expectTarget: &catalog.Target{
+			Name: "git-create", Group: "Git-Support",
+			Args: "<branch> <message>", Description: "creates a branch " +
+				"with the current change set using next issue.",
+		},
+	},
+}
+
+func TestConfig(t *testing.T) {
+	file, err := os.Open("../../config/Makefile.base")
+	assert.NoError(t, err)
+	defer file.Close()
+	catalog, err := catalog.Parse(file)
+	assert.NoError(t, err)
+
+	test.Map(t, configTestCases).
+		Run(func(t test.Test, param ConfigParams) {
+			// When
+			target := catalog.Lookup(param.name)
+
+			// Then
+			assert.Equal(t, param.expectTarget, target)
+		})
+}
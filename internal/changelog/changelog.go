@@ -0,0 +1,183 @@
+// Package changelog provides the creation of release changelogs from the
+// conventional commit changes of a revision range, that are grouped by commit
+// type and rendered as Markdown section of a `CHANGELOG.md` file.
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/verify"
+)
+
+const (
+	// Unreleased provides the version of changes not yet released.
+	Unreleased = "Unreleased"
+	// Title provides the title of a new changelog file.
+	Title = "# Changelog"
+	// hashLength provides the length of the abbreviated commit hashes.
+	hashLength = 7
+)
+
+// typeTitles contains the group titles of the known commit types.
+var typeTitles = map[string]string{
+	"feat":      "Features",
+	"deprecate": "Deprecations",
+	"remove":    "Removals",
+	"docs":      "Documentation",
+	"fix":       "Bug fixes",
+	"style":     "Styling",
+	"refactor":  "Refactorings",
+	"perf":      "Performance",
+	"test":      "Tests",
+	"build":     "Build",
+	"ci":        "Continuous integration",
+	"chore":     "Chores",
+	"":          "Other changes",
+}
+
+// ErrSectionExists represents an already existing changelog section.
+var ErrSectionExists = errors.New("section exists")
+
+// NewErrSectionExists creates an error for the already existing changelog
+// section of the given version.
+func NewErrSectionExists(version string) error {
+	return fmt.Errorf("%w [version=%s]", ErrSectionExists, version)
+}
+
+// Group represents the changes of a commit type.
+type Group struct {
+	// Type provides the commit type of the group, or an empty string for the
+	// changes not following the commit types.
+	Type string `json:"type"`
+	// Title provides the title of the group.
+	Title string `json:"title"`
+	// Changes provides the changes of the group.
+	Changes []*verify.Change `json:"changes"`
+}
+
+// Changelog represents the changes of a release.
+type Changelog struct {
+	// Version provides the version of the release.
+	Version string `json:"version"`
+	// Range provides the revision range of the release.
+	Range string `json:"range"`
+	// Breaking provides the breaking changes of the release.
+	Breaking []*verify.Change `json:"breaking"`
+	// Groups provides the changes grouped by commit type.
+	Groups []*Group `json:"groups"`
+	// Issues provides the unique issue references of the release.
+	Issues []string `json:"issues"`
+}
+
+// New creates a new changelog for the given version and revision range from
+// the given changes. The changes are grouped in order of the given commit
+// types, followed by the changes not following the commit types.
+func New(
+	version, rng string, types []string, changes ...*verify.Change,
+) *Changelog {
+	changelog := &Changelog{
+		Version: version, Range: rng,
+		Breaking: []*verify.Change{}, Groups: []*Group{}, Issues: []string{},
+	}
+
+	for _, typ := range append(slices.Clone(types), "") {
+		group := &Group{Type: typ, Title: title(typ)}
+		for _, change := range changes {
+			if change.Type == typ || (typ == "" &&
+				!slices.Contains(types, change.Type)) {
+				group.Changes = append(group.Changes, change)
+			}
+		}
+		if len(group.Changes) != 0 {
+			changelog.Groups = append(changelog.Groups, group)
+		}
+	}
+
+	for _, change := range changes {
+		if change.Breaking != "" {
+			changelog.Breaking = append(changelog.Breaking, change)
+		}
+		for _, issue := range change.Issues {
+			if !slices.Contains(changelog.Issues, issue) {
+				changelog.Issues = append(changelog.Issues, issue)
+			}
+		}
+	}
+	return changelog
+}
+
+// Markdown returns the changelog as Markdown section headed by the version,
+// that lists the breaking changes followed by the groups of changes.
+func (c *Changelog) Markdown() string {
+	builder := &strings.Builder{}
+	builder.WriteString("## " + c.Version + "\n")
+	if len(c.Breaking) != 0 {
+		builder.WriteString("\n### Breaking changes\n\n")
+		for _, change := range c.Breaking {
+			builder.WriteString(entry(change, change.Breaking))
+		}
+	}
+	for _, group := range c.Groups {
+		builder.WriteString("\n### " + group.Title + "\n\n")
+		for _, change := range group.Changes {
+			builder.WriteString(entry(change, change.Subject))
+		}
+	}
+	return builder.String()
+}
+
+// Prepend prepends the changelog section to the given changelog file content
+// before the first existing section, or appends it to the title and preamble
+// of the file, if no section exists. If the content is empty, a new changelog
+// file content with default title is created. An error is returned, if the
+// content already contains a section for the version.
+func (c *Changelog) Prepend(content string) (string, error) {
+	heading := "## " + c.Version
+	for line := range strings.Lines(content) {
+		if strings.TrimSpace(line) == heading {
+			return "", NewErrSectionExists(c.Version)
+		}
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return Title + "\n\n" + c.Markdown(), nil
+	} else if strings.HasPrefix(content, "## ") {
+		return c.Markdown() + "\n" + content, nil
+	} else if index := strings.Index(content, "\n## "); index >= 0 {
+		return content[:index+1] + c.Markdown() + "\n" +
+			content[index+1:], nil
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + c.Markdown(), nil
+}
+
+// ShortHash returns the abbreviated commit hash of the given commit hash.
+func ShortHash(hash string) string {
+	return hash[:min(hashLength, len(hash))]
+}
+
+// title returns the group title of the given commit type.
+func title(typ string) string {
+	if title, ok := typeTitles[typ]; ok {
+		return title
+	}
+	return typ
+}
+
+// entry returns the Markdown list entry of the given change using the given
+// text, that is prefixed by the scope and followed by the issue references
+// and the abbreviated commit hash.
+func entry(change *verify.Change, text string) string {
+	if change.Scope != "" {
+		text = "**" + change.Scope + ":** " + text
+	}
+	if len(change.Issues) != 0 {
+		text += " (" + strings.Join(change.Issues, ", ") + ")"
+	}
+	if change.Commit != "" {
+		text += " (" + ShortHash(change.Commit) + ")"
+	}
+	return "* " + text + "\n"
+}
@@ -0,0 +1,191 @@
+package changelog_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/changelog"
+	"github.com/tkrop/go-make/internal/verify"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// types contains the commit types of the tests.
+	types = []string{"feat", "fix", "custom"}
+
+	// changeFeat contains a breaking feature change with scope.
+	changeFeat = &verify.Change{
+		Commit: "abc123def456", Type: "feat", Scope: "make",
+		Subject: "add feature", Issues: []string{"#1", "#2"},
+		Breaking: "option removed",
+	}
+	// changeFix contains a fix change.
+	changeFix = &verify.Change{
+		Commit: "def456", Type: "fix", Subject: "repair feature",
+		Issues: []string{"#2"},
+	}
+	// changeCustom contains a change of a custom commit type.
+	changeCustom = &verify.Change{
+		Type: "custom", Subject: "custom change",
+	}
+	// changeOther contains a change not following the commit types.
+	changeOther = &verify.Change{
+		Commit: "123abc", Type: "chore", Subject: "update deps",
+	}
+
+	// markdown contains the changelog section of all changes.
+	markdown = "## v1.0.0\n\n" +
+		"### Breaking changes\n\n" +
+		"* **make:** option removed (#1, #2) (abc123d)\n\n" +
+		"### Features\n\n" +
+		"* **make:** add feature (#1, #2) (abc123d)\n\n" +
+		"### Bug fixes\n\n" +
+		"* repair feature (#2) (def456)\n\n" +
+		"### custom\n\n" +
+		"* custom change\n\n" +
+		"### Other changes\n\n" +
+		"* update deps (123abc)\n"
+)
+
+type NewParams struct {
+	changes         []*verify.Change
+	expectChangelog *changelog.Changelog
+}
+
+var newTestCases = map[string]NewParams{
+	"empty": {
+		expectChangelog: &changelog.Changelog{
+			Version: "v1.0.0", Range: "v0.9.0..HEAD",
+			Breaking: []*verify.Change{},
+			Groups:   []*changelog.Group{},
+			Issues:   []string{},
+		},
+	},
+	"all changes": {
+		changes: []*verify.Change{
+			changeOther, changeFix, changeCustom, changeFeat,
+		},
+		expectChangelog: &changelog.Changelog{
+			Version: "v1.0.0", Range: "v0.9.0..HEAD",
+			Breaking: []*verify.Change{changeFeat},
+			Groups: []*changelog.Group{{
+				Type: "feat", Title: "Features",
+				Changes: []*verify.Change{changeFeat},
+			}, {
+				Type: "fix", Title: "Bug fixes",
+				Changes: []*verify.Change{changeFix},
+			}, {
+				Type: "custom", Title: "custom",
+				Changes: []*verify.Change{changeCustom},
+			}, {
+				Type: "", Title: "Other changes",
+				Changes: []*verify.Change{changeOther},
+			}},
+			Issues: []string{"#2", "#1"},
+		},
+	},
+}
+
+func TestNew(t *testing.T) {
+	test.Map(t, newTestCases).
+		Run(func(t test.Test, param NewParams) {
+			// When
+			log := changelog.New("v1.0.0", "v0.9.0..HEAD",
+				types, param.changes...)
+
+			// Then
+			assert.Equal(t, param.expectChangelog, log)
+		})
+}
+
+type MarkdownParams struct {
+	changes        []*verify.Change
+	expectMarkdown string
+}
+
+var markdownTestCases = map[string]MarkdownParams{
+	"empty": {
+		expectMarkdown: "## v1.0.0\n",
+	},
+	"all changes": {
+		changes: []*verify.Change{
+			changeFeat, changeFix, changeCustom, changeOther,
+		},
+		expectMarkdown: markdown,
+	},
+}
+
+func TestMarkdown(t *testing.T) {
+	test.Map(t, markdownTestCases).
+		Run(func(t test.Test, param MarkdownParams) {
+			// Given
+			log := changelog.New("v1.0.0", "HEAD", types, param.changes...)
+
+			// When
+			markdown := log.Markdown()
+
+			// Then
+			assert.Equal(t, param.expectMarkdown, markdown)
+		})
+}
+
+type PrependParams struct {
+	content       string
+	expectContent string
+	expectError   error
+}
+
+var prependTestCases = map[string]PrependParams{
+	"empty": {
+		content: "",
+		expectContent: "# Changelog\n\n## v1.0.0\n\n### Bug fixes\n\n" +
+			"* repair feature (#2) (def456)\n",
+	},
+	"title only": {
+		content: "# Release notes\n",
+		expectContent: "# Release notes\n\n## v1.0.0\n\n### Bug fixes\n\n" +
+			"* repair feature (#2) (def456)\n",
+	},
+	"title with preamble": {
+		content: "# Changelog\n\nAll notable changes.\n",
+		expectContent: "# Changelog\n\nAll notable changes.\n\n" +
+			"## v1.0.0\n\n### Bug fixes\n\n* repair feature (#2) (def456)\n",
+	},
+	"title with sections": {
+		content: "# Changelog\n\nAll notable changes.\n\n" +
+			"## v0.9.0\n\n* old change\n",
+		expectContent: "# Changelog\n\nAll notable changes.\n\n" +
+			"## v1.0.0\n\n### Bug fixes\n\n* repair feature (#2) (def456)\n\n" +
+			"## v0.9.0\n\n* old change\n",
+	},
+	"sections only": {
+		content: "## v0.9.0\n\n* old change\n",
+		expectContent: "## v1.0.0\n\n### Bug fixes\n\n" +
+			"* repair feature (#2) (def456)\n\n## v0.9.0\n\n* old change\n",
+	},
+	"no title": {
+		content: "Release notes.",
+		expectContent: "Release notes.\n\n## v1.0.0\n\n### Bug fixes\n\n" +
+			"* repair feature (#2) (def456)\n",
+	},
+	"section exists": {
+		content:     "# Changelog\n\n## v1.0.0\n\n* old change\n",
+		expectError: changelog.NewErrSectionExists("v1.0.0"),
+	},
+}
+
+func TestPrepend(t *testing.T) {
+	test.Map(t, prependTestCases).
+		Run(func(t test.Test, param PrependParams) {
+			// Given
+			log := changelog.New("v1.0.0", "HEAD", types, changeFix)
+
+			// When
+			content, err := log.Prepend(param.content)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectContent, content)
+		})
+}
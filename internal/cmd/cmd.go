@@ -9,7 +9,11 @@ import (
 	"os"
 	"os/exec"
 	"reflect"
+	"sync"
 	"syscall"
+	"time"
+
+	"github.com/tkrop/go-make/internal/sys"
 )
 
 // Mode represents the execution mode for commands.
@@ -22,8 +26,19 @@ const (
 	Detached Mode = 0x01
 	// Background mode - process runs in the background.
 	Background Mode = 0x02
+	// Forward mode - process receives signals forwarded via the executor.
+	Forward Mode = 0x04
 )
 
+// WaitDelay provides the default grace period given to a cancelled process
+// running in forward mode to clean up after being terminated, before it is
+// killed.
+const WaitDelay = 5 * time.Second
+
+// cancelPoll provides the interval to check whether a terminated process
+// group has finished during the grace period.
+const cancelPoll = 10 * time.Millisecond
+
 // Cmd represents a command to be executed.
 type Cmd struct {
 	// Mode contains the execution mode.
@@ -240,32 +255,75 @@ type Executor interface {
 	Exec(ctx context.Context, cmd *Cmd) error
 	// New creates a new command with the given arguments.
 	New(args ...string) *Cmd
+	// Signal forwards the given signal to all running commands executed in
+	// forward mode and returns whether any command received the signal.
+	Signal(signal os.Signal) bool
 }
 
 // CmdExecutor provides a default command CmdExecutor using `os/exec`
 // supporting optional tracing.
 type CmdExecutor struct {
 	devnull string
+	delay   time.Duration
 	start   func(mode Mode, cmd *exec.Cmd) error
 	finish  func(mode Mode, cmd *exec.Cmd) error
+
+	// mutex protects the running processes.
+	mutex sync.Mutex
+	// running contains the running processes executed in forward mode.
+	running map[*exec.Cmd]struct{}
 }
 
 // NewExecutor creates a new default command process.
 func NewExecutor() *CmdExecutor {
-	return &CmdExecutor{
-		devnull: os.DevNull,
-		start: func(mode Mode, cmd *exec.Cmd) error {
-			if mode&Background == Background {
-				cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
-			}
-			return cmd.Start()
-		},
-		finish: func(mode Mode, cmd *exec.Cmd) error {
-			if mode&Background != Background {
-				return cmd.Wait()
+	e := &CmdExecutor{devnull: os.DevNull, delay: WaitDelay}
+	e.start = func(mode Mode, cmd *exec.Cmd) error {
+		if mode&Background == Background {
+			cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		} else if mode&Forward == Forward {
+			e.cancel(cmd)
+		}
+		return cmd.Start()
+	}
+	e.finish = func(mode Mode, cmd *exec.Cmd) error {
+		if mode&Background != Background {
+			return cmd.Wait()
+		}
+		return cmd.Process.Release()
+	}
+	return e
+}
+
+// cancel sets up the graceful cancellation of the given process running in
+// forward mode. On cancellation, the process is terminated first and only
+// killed, if it did not finish within the grace period. Without a foreground
+// terminal the process is started in its own process group, so that signals
+// are forwarded to all children and the whole group is terminated and killed.
+// Else it stays in the foreground process group to allow interactive input
+// without being stopped by the terminal.
+func (e *CmdExecutor) cancel(cmd *exec.Cmd) {
+	cmd.WaitDelay = e.delay
+	if sys.IsForeground(cmd.Stdin) {
+		cmd.Cancel = func() error {
+			return cmd.Process.Signal(syscall.SIGTERM)
+		}
+		return
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// The process group id is not reused as long as any member of the
+		// group is running, i.e. also children outliving the process.
+		pgid := -cmd.Process.Pid
+		err := syscall.Kill(pgid, syscall.SIGTERM)
+		for deadline := time.Now().Add(e.delay); time.Now().Before(deadline); {
+			if syscall.Kill(pgid, 0) != nil {
+				return err
 			}
-			return cmd.Process.Release()
-		},
+			time.Sleep(cancelPoll)
+		}
+		_ = syscall.Kill(pgid, syscall.SIGKILL)
+		return err
 	}
 }
 
@@ -295,12 +353,70 @@ func (e *CmdExecutor) Exec(ctx context.Context, cmd *Cmd) error {
 
 	if err := e.start(cmd.Mode, cc); err != nil {
 		return cmd.Error("starting process", err)
-	} else if err := e.finish(cmd.Mode, cc); err != nil {
+	}
+
+	if cmd.IsMode(Forward) && !cmd.IsMode(Background) {
+		e.register(cc)
+		defer e.unregister(cc)
+	}
+
+	if err := e.finish(cmd.Mode, cc); err != nil {
 		return cmd.Error("releasing process", err)
 	}
 	return nil
 }
 
+// register registers the given running process for signal forwarding.
+func (e *CmdExecutor) register(cc *exec.Cmd) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.running == nil {
+		e.running = map[*exec.Cmd]struct{}{}
+	}
+	e.running[cc] = struct{}{}
+}
+
+// unregister removes the given finished process from signal forwarding.
+func (e *CmdExecutor) unregister(cc *exec.Cmd) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.running, cc)
+}
+
+// Signal forwards the given signal to all running commands executed in
+// forward mode and returns whether any command received the signal. Commands
+// running in their own process group receive the signal for the whole group.
+// Commands sharing the foreground process group of the terminal already
+// received the signals generated by the terminal, i.e. `SIGINT`, `SIGQUIT`,
+// and `SIGHUP`, that are therefore not forwarded a second time, while any
+// other signal, e.g. `SIGKILL`, is only sent to the command process itself,
+// since the foreground process group also contains the calling process.
+func (e *CmdExecutor) Signal(signal os.Signal) bool {
+	if e == nil {
+		return false
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	signaled := false
+	for cc := range e.running {
+		sig, ok := signal.(syscall.Signal)
+		switch {
+		case !ok:
+			signaled = cc.Process.Signal(signal) == nil || signaled
+		case cc.SysProcAttr != nil && cc.SysProcAttr.Setpgid:
+			signaled = syscall.Kill(-cc.Process.Pid, sig) == nil || signaled
+		case sig == syscall.SIGINT || sig == syscall.SIGQUIT ||
+			sig == syscall.SIGHUP:
+			signaled = cc.Process.Signal(syscall.Signal(0)) == nil || signaled
+		default:
+			signaled = cc.Process.Signal(signal) == nil || signaled
+		}
+	}
+	return signaled
+}
+
 // New creates a new command with the given arguments.
 func (e *CmdExecutor) New(args ...string) *Cmd {
 	return New(args...).WithExecutor(e)
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -93,6 +94,12 @@ var execTestCases = map[string]ExecParams{
 	"attached background sleep": {
 		cmd: cmd.New("sleep", "30").WithMode(cmd.Background),
 	},
+	"attached forward sleep": {
+		cmd: cmd.New("sleep", "0.01").WithMode(cmd.Forward),
+	},
+	"attached forward background sleep": {
+		cmd: cmd.New("sleep", "30").WithMode(cmd.Forward | cmd.Background),
+	},
 	"attached command error": {
 		cmd: cmd.New("_non-existing-command_"),
 		expectError: cmd.New("_non-existing-command_").
@@ -211,6 +218,116 @@ func TestNilExec(t *testing.T) {
 		})
 }
 
+type SignalParams struct {
+	cmd          *cmd.Cmd
+	signal       os.Signal
+	expectSignal bool
+	expectError  bool
+}
+
+var signalTestCases = map[string]SignalParams{
+	"no command": {
+		signal: syscall.SIGTERM,
+	},
+	"attached sleep": {
+		cmd:    cmd.New("sleep", "0.2"),
+		signal: syscall.SIGTERM,
+	},
+	"forward sleep term": {
+		cmd:          cmd.New("sleep", "30").WithMode(cmd.Forward),
+		signal:       syscall.SIGTERM,
+		expectSignal: true,
+		expectError:  true,
+	},
+	"forward bash sleep kill": {
+		cmd: cmd.New("bash", "-c", "sleep 30; echo done").
+			WithMode(cmd.Forward),
+		signal:       os.Kill,
+		expectSignal: true,
+		expectError:  true,
+	},
+}
+
+func TestSignal(t *testing.T) {
+	test.Map(t, signalTestCases).
+		Run(func(t test.Test, param SignalParams) {
+			// Given
+			exec := cmd.NewExecutor()
+			done := make(chan error, 1)
+			if param.cmd != nil {
+				go func() {
+					done <- exec.Exec(ctx, param.cmd.Copy().
+						WithStdin(strings.NewReader("")))
+				}()
+				time.Sleep(50 * time.Millisecond)
+			} else {
+				close(done)
+			}
+
+			// When
+			signaled := exec.Signal(param.signal)
+
+			// Then
+			assert.Equal(t, param.expectSignal, signaled)
+			select {
+			case err := <-done:
+				assert.Equal(t, param.expectError, err != nil)
+			case <-time.After(time.Second):
+				assert.Fail(t, "timeout waiting for command")
+			}
+			assert.False(t, exec.Signal(param.signal))
+		})
+}
+
+type CancelParams struct {
+	cmd          *cmd.Cmd
+	delay        time.Duration
+	expectStdout string
+}
+
+var cancelTestCases = map[string]CancelParams{
+	"forward cancel terminates group": {
+		cmd: cmd.New("bash", "-c", "trap 'echo terminated; exit 0' TERM; "+
+			"sleep 30 & wait").WithMode(cmd.Forward),
+		delay:        5 * time.Second,
+		expectStdout: "terminated\n",
+	},
+	"forward cancel kills group after delay": {
+		cmd: cmd.New("bash", "-c", "trap '' TERM; sleep 30 & wait").
+			WithMode(cmd.Forward),
+		delay: 100 * time.Millisecond,
+	},
+}
+
+func TestCancel(t *testing.T) {
+	test.Map(t, cancelTestCases).
+		Run(func(t test.Test, param CancelParams) {
+			// Given
+			exec := cmd.NewExecutor()
+			reflect.NewAccessor(exec).Set("delay", param.delay)
+			stdout := &strings.Builder{}
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- exec.Exec(ctx, param.cmd.Copy().WithIO(
+					strings.NewReader(""), stdout, &strings.Builder{}))
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// When
+			cancel()
+
+			// Then
+			select {
+			case err := <-done:
+				assert.Error(t, err)
+			case <-time.After(2 * time.Second):
+				assert.Fail(t, "timeout waiting for command")
+			}
+			assert.Equal(t, param.expectStdout, stdout.String())
+		})
+}
+
 type CmdErrorParams struct {
 	message       string
 	cmd           *cmd.Cmd
@@ -0,0 +1,343 @@
+// Package cover provides parsing of go cover profiles, computing of the total,
+// per-package, and per-file statement coverage, checking of the coverage
+// against minimum thresholds, and exporting of the coverage as LCOV and
+// Cobertura XML report.
+package cover
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ModeSet provides the cover mode recording whether a block was run.
+	ModeSet = "set"
+	// NameTotal provides the name of the total coverage.
+	NameTotal = "total"
+)
+
+// regexBlock matches a block of a cover profile.
+var regexBlock = regexp.MustCompile(
+	`^(.+):([0-9]+)\.([0-9]+),([0-9]+)\.([0-9]+) ([0-9]+) ([0-9]+)$`)
+
+var (
+	// ErrInvalidProfile represents an invalid cover profile.
+	ErrInvalidProfile = errors.New("invalid profile")
+	// ErrInvalidThreshold represents an invalid coverage threshold.
+	ErrInvalidThreshold = errors.New("invalid threshold")
+)
+
+// NewErrInvalidProfile creates an error for the given invalid line with
+// given number of a cover profile.
+func NewErrInvalidProfile(number int, line string) error {
+	return fmt.Errorf("%w [line=%d, text=%s]", ErrInvalidProfile, number, line)
+}
+
+// NewErrInvalidThreshold creates an error for the given invalid coverage
+// threshold entry caused by the given error.
+func NewErrInvalidThreshold(entry string, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w [entry=%s]: %w", ErrInvalidThreshold, entry, err)
+	}
+	return fmt.Errorf("%w [entry=%s]", ErrInvalidThreshold, entry)
+}
+
+// Block contains a covered code block of a source file.
+type Block struct {
+	// StartLine provides the start line of the block.
+	StartLine int
+	// StartCol provides the start column of the block.
+	StartCol int
+	// EndLine provides the end line of the block.
+	EndLine int
+	// EndCol provides the end column of the block.
+	EndCol int
+	// Stmts provides the number of statements of the block.
+	Stmts int
+	// Count provides the number of times the block was run.
+	Count int
+}
+
+// File contains the covered code blocks of a source file.
+type File struct {
+	// Name provides the import path of the source file.
+	Name string
+	// Blocks provides the code blocks of the source file ordered by
+	// position.
+	Blocks []*Block
+}
+
+// Package returns the import path of the package of the source file.
+func (f *File) Package() string {
+	return path.Dir(f.Name)
+}
+
+// Lines returns the hit counts of the lines of the source file covered by a
+// code block, i.e. the maximum count of all code blocks of a line.
+func (f *File) Lines() map[int]int {
+	lines := map[int]int{}
+	for _, block := range f.Blocks {
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			if count, ok := lines[line]; !ok || block.Count > count {
+				lines[line] = block.Count
+			}
+		}
+	}
+	return lines
+}
+
+// Profile contains a parsed cover profile.
+type Profile struct {
+	// Mode provides the cover mode, i.e. `set`, `count`, or `atomic`.
+	Mode string
+	// Files provides the source files of the profile ordered by name.
+	Files []*File
+}
+
+// Parse parses the cover profile read from the given reader. Duplicate code
+// blocks, e.g. of multiple test binaries using `-coverpkg`, are merged.
+func Parse(reader io.Reader) (*Profile, error) {
+	profile := &Profile{Files: []*File{}}
+	files := map[string]*File{}
+	blocks := map[string]*Block{}
+
+	scanner, number := bufio.NewScanner(reader), 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if mode, ok := strings.CutPrefix(line, "mode:"); ok {
+			profile.Mode = strings.TrimSpace(mode)
+			continue
+		} else if line == "" {
+			continue
+		}
+
+		match := regexBlock.FindStringSubmatch(line)
+		if match == nil {
+			return nil, NewErrInvalidProfile(number, line)
+		}
+		values := make([]int, 0, len(match)-2)
+		for _, digits := range match[2:] {
+			value, _ := strconv.Atoi(digits)
+			values = append(values, value)
+		}
+
+		key := match[1] + ":" + strings.Join(match[2:6], ",")
+		if block, ok := blocks[key]; ok {
+			if profile.Mode == ModeSet {
+				block.Count = max(block.Count, values[5])
+			} else {
+				block.Count += values[5]
+			}
+			continue
+		}
+
+		file, ok := files[match[1]]
+		if !ok {
+			file = &File{Name: match[1], Blocks: []*Block{}}
+			files[match[1]] = file
+			profile.Files = append(profile.Files, file)
+		}
+		block := &Block{
+			StartLine: values[0], StartCol: values[1],
+			EndLine: values[2], EndCol: values[3],
+			Stmts: values[4], Count: values[5],
+		}
+		blocks[key] = block
+		file.Blocks = append(file.Blocks, block)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by caller.
+	}
+
+	slices.SortFunc(profile.Files, func(a, b *File) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for _, file := range profile.Files {
+		slices.SortFunc(file.Blocks, func(a, b *Block) int {
+			return cmp.Or(cmp.Compare(a.StartLine, b.StartLine),
+				cmp.Compare(a.StartCol, b.StartCol))
+		})
+	}
+	return profile, nil
+}
+
+// Coverage contains the statement coverage of a source file, a package, or
+// the total coverage.
+type Coverage struct {
+	// Name provides the name of the covered unit.
+	Name string
+	// Covered provides the number of covered statements.
+	Covered int
+	// Total provides the total number of statements.
+	Total int
+}
+
+// add adds the statements of the given code block to the coverage.
+func (c *Coverage) add(block *Block) {
+	c.Total += block.Stmts
+	if block.Count > 0 {
+		c.Covered += block.Stmts
+	}
+}
+
+// Percent returns the statement coverage in percent. Without statements the
+// coverage is complete.
+func (c *Coverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Covered) * 100 / float64(c.Total)
+}
+
+// FileCoverage returns the statement coverage of the source files ordered by
+// name.
+func (p *Profile) FileCoverage() []*Coverage {
+	coverages := make([]*Coverage, 0, len(p.Files))
+	for _, file := range p.Files {
+		coverage := &Coverage{Name: file.Name}
+		for _, block := range file.Blocks {
+			coverage.add(block)
+		}
+		coverages = append(coverages, coverage)
+	}
+	return coverages
+}
+
+// PackageCoverage returns the statement coverage of the packages ordered by
+// name.
+func (p *Profile) PackageCoverage() []*Coverage {
+	coverages := []*Coverage{}
+	for _, file := range p.Files {
+		index := slices.IndexFunc(coverages, func(coverage *Coverage) bool {
+			return coverage.Name == file.Package()
+		})
+		if index < 0 {
+			index = len(coverages)
+			coverages = append(coverages, &Coverage{Name: file.Package()})
+		}
+		for _, block := range file.Blocks {
+			coverages[index].add(block)
+		}
+	}
+	slices.SortFunc(coverages, func(a, b *Coverage) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return coverages
+}
+
+// TotalCoverage returns the total statement coverage.
+func (p *Profile) TotalCoverage() *Coverage {
+	coverage := &Coverage{Name: NameTotal}
+	for _, file := range p.Files {
+		for _, block := range file.Blocks {
+			coverage.add(block)
+		}
+	}
+	return coverage
+}
+
+// threshold contains a minimum coverage for packages matching the regex.
+type threshold struct {
+	// regex provides the regex matching the package import paths.
+	regex *regexp.Regexp
+	// minimum provides the minimum coverage in percent.
+	minimum float64
+}
+
+// Thresholds contains the minimum coverage of the total coverage and the
+// packages.
+type Thresholds struct {
+	// minimum provides the default minimum coverage in percent.
+	minimum float64
+	// packages provides the minimum coverage overrides of packages.
+	packages []*threshold
+}
+
+// ParseThresholds parses the coverage thresholds from the given default
+// minimum coverage, e.g. `80`, and the given whitespace separated package
+// overrides of the form `<regex>=<percent>[%]`, e.g. `internal/make=90%`.
+// Later package overrides take precedence over earlier overrides.
+func ParseThresholds(minimum, packages string) (*Thresholds, error) {
+	thresholds := &Thresholds{packages: []*threshold{}}
+	if minimum = strings.TrimSpace(minimum); minimum != "" {
+		value, err := parsePercent(minimum)
+		if err != nil {
+			return nil, NewErrInvalidThreshold(minimum, err)
+		}
+		thresholds.minimum = value
+	}
+
+	for _, entry := range strings.Fields(packages) {
+		index := strings.LastIndex(entry, "=")
+		if index <= 0 {
+			return nil, NewErrInvalidThreshold(entry, nil)
+		}
+		value, err := parsePercent(entry[index+1:])
+		if err != nil {
+			return nil, NewErrInvalidThreshold(entry, err)
+		}
+		regex, err := regexp.Compile(entry[:index])
+		if err != nil {
+			return nil, NewErrInvalidThreshold(entry, err)
+		}
+		thresholds.packages = append(thresholds.packages,
+			&threshold{regex: regex, minimum: value})
+	}
+	return thresholds, nil
+}
+
+// parsePercent parses the given percent value with optional `%` suffix
+// ensuring that the value is in the range of 0 to 100.
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, err //nolint:wrapcheck // wrapped by caller.
+	} else if percent < 0 || percent > 100 {
+		return 0, strconv.ErrRange
+	}
+	return percent, nil
+}
+
+// Minimum returns the minimum coverage in percent of the package with given
+// import path, or the default minimum coverage for the total coverage.
+func (t *Thresholds) Minimum(pkg string) float64 {
+	if pkg != NameTotal {
+		for _, threshold := range slices.Backward(t.packages) {
+			if threshold.regex.MatchString(pkg) {
+				return threshold.minimum
+			}
+		}
+	}
+	return t.minimum
+}
+
+// Offender contains a coverage below its minimum coverage.
+type Offender struct {
+	*Coverage
+	// Minimum provides the minimum coverage in percent.
+	Minimum float64
+}
+
+// Check checks the package and total coverage of the given profile against
+// the given thresholds and returns the offenders below their minimum.
+func Check(profile *Profile, thresholds *Thresholds) []*Offender {
+	offenders := []*Offender{}
+	coverages := append(profile.PackageCoverage(), profile.TotalCoverage())
+	for _, coverage := range coverages {
+		minimum := thresholds.Minimum(coverage.Name)
+		if coverage.Percent() < minimum {
+			offenders = append(offenders,
+				&Offender{Coverage: coverage, Minimum: minimum})
+		}
+	}
+	return offenders
+}
@@ -0,0 +1,299 @@
+package cover_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/cover"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// pkgOther contains the package outside of the module.
+	pkgOther = "example.com/other"
+	// pkgMain contains the main package of the module.
+	pkgMain = "example.com/project"
+	// pkgSub contains the sub package of the module.
+	pkgSub = "example.com/project/pkg"
+)
+
+// ReadProfile reads the cover profile from the given fixture file.
+func ReadProfile(t test.Test, name string) *cover.Profile {
+	file, err := os.Open("fixtures/" + name)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	profile, err := cover.Parse(file)
+	assert.NoError(t, err)
+	return profile
+}
+
+// errReader is a reader failing with an error.
+type errReader struct{}
+
+// Read fails always with an error.
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+type ParseParams struct {
+	input         string
+	reader        io.Reader
+	expectProfile *cover.Profile
+	expectError   error
+}
+
+var parseTestCases = map[string]ParseParams{
+	"empty": {
+		expectProfile: &cover.Profile{Files: []*cover.File{}},
+	},
+	"set mode": {
+		input: "mode: set\n" +
+			pkgSub + "/pkg.go:7.20,8.10 1 1\n" +
+			pkgSub + "/pkg.go:3.20,5.2 2 0\n\n" +
+			pkgSub + "/pkg.go:7.20,8.10 1 1\n",
+		expectProfile: &cover.Profile{
+			Mode: cover.ModeSet,
+			Files: []*cover.File{{
+				Name: pkgSub + "/pkg.go",
+				Blocks: []*cover.Block{{
+					StartLine: 3, StartCol: 20, EndLine: 5, EndCol: 2,
+					Stmts: 2, Count: 0,
+				}, {
+					StartLine: 7, StartCol: 20, EndLine: 8, EndCol: 10,
+					Stmts: 1, Count: 1,
+				}},
+			}},
+		},
+	},
+	"count mode": {
+		input: "mode: count\n" +
+			pkgSub + "/pkg.go:7.20,8.10 1 2\n" +
+			pkgSub + "/pkg.go:7.20,7.30 1 0\n" +
+			pkgSub + "/pkg.go:7.20,8.10 1 3\n",
+		expectProfile: &cover.Profile{
+			Mode: "count",
+			Files: []*cover.File{{
+				Name: pkgSub + "/pkg.go",
+				Blocks: []*cover.Block{{
+					StartLine: 7, StartCol: 20, EndLine: 8, EndCol: 10,
+					Stmts: 1, Count: 5,
+				}, {
+					StartLine: 7, StartCol: 20, EndLine: 7, EndCol: 30,
+					Stmts: 1, Count: 0,
+				}},
+			}},
+		},
+	},
+	"invalid block": {
+		input: "mode: set\n" + pkgSub + "/pkg.go:7.20,8.10 1\n",
+		expectError: cover.NewErrInvalidProfile(2,
+			pkgSub+"/pkg.go:7.20,8.10 1"),
+	},
+	"read failed": {
+		reader:      errReader{},
+		expectError: errors.New("read failed"),
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, parseTestCases).
+		Run(func(t test.Test, param ParseParams) {
+			// Given
+			reader := param.reader
+			if reader == nil {
+				reader = strings.NewReader(param.input)
+			}
+
+			// When
+			profile, err := cover.Parse(reader)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectProfile, profile)
+		})
+}
+
+func TestFileLines(t *testing.T) {
+	// Given
+	profile := ReadProfile(t, "test.cover")
+
+	// When
+	lines := profile.Files[2].Lines()
+
+	// Then
+	assert.Equal(t, pkgSub, profile.Files[2].Package())
+	assert.Equal(t, map[int]int{
+		3: 1, 4: 1, 5: 1, 7: 3, 8: 3, 9: 2, 10: 2,
+	}, lines)
+}
+
+func TestCoverage(t *testing.T) {
+	// Given
+	profile := ReadProfile(t, "test.cover")
+
+	// When
+	files := profile.FileCoverage()
+	packages := profile.PackageCoverage()
+	total := profile.TotalCoverage()
+
+	// Then
+	assert.Equal(t, []*cover.Coverage{
+		{Name: pkgOther + "/lib.go", Covered: 1, Total: 1},
+		{Name: pkgMain + "/main.go", Covered: 0, Total: 2},
+		{Name: pkgSub + "/pkg.go", Covered: 4, Total: 4},
+		{Name: pkgSub + "/util.go", Covered: 0, Total: 3},
+	}, files)
+	assert.Equal(t, []*cover.Coverage{
+		{Name: pkgOther, Covered: 1, Total: 1},
+		{Name: pkgMain, Covered: 0, Total: 2},
+		{Name: pkgSub, Covered: 4, Total: 7},
+	}, packages)
+	assert.Equal(t, &cover.Coverage{
+		Name: cover.NameTotal, Covered: 5, Total: 10,
+	}, total)
+	assert.InDelta(t, 50.0, total.Percent(), 0.001)
+	assert.InDelta(t, 100.0, (&cover.Coverage{}).Percent(), 0.001)
+}
+
+type ThresholdsParams struct {
+	minimum       string
+	packages      string
+	pkg           string
+	expectMinimum float64
+	expectError   error
+}
+
+var thresholdsTestCases = map[string]ThresholdsParams{
+	"no thresholds": {
+		pkg: pkgSub,
+	},
+	"default minimum": {
+		minimum: "80", pkg: pkgSub,
+		expectMinimum: 80,
+	},
+	"default minimum percent": {
+		minimum: " 75.5% ", pkg: cover.NameTotal,
+		expectMinimum: 75.5,
+	},
+	"package override": {
+		minimum: "80", packages: "project/pkg$=90%",
+		pkg: pkgSub, expectMinimum: 90,
+	},
+	"package override not matching": {
+		minimum: "80", packages: "project/pkg$=90%",
+		pkg: pkgMain, expectMinimum: 80,
+	},
+	"package override precedence": {
+		minimum: "80", packages: "project/pkg$=90% example.com/=60",
+		pkg: pkgSub, expectMinimum: 60,
+	},
+	"package override total": {
+		minimum: "80", packages: ".*=60",
+		pkg: cover.NameTotal, expectMinimum: 80,
+	},
+
+	"invalid minimum": {
+		minimum: "high",
+		expectError: cover.NewErrInvalidThreshold("high",
+			&strconv.NumError{
+				Func: "ParseFloat", Num: "high", Err: strconv.ErrSyntax,
+			}),
+	},
+	"minimum out of range": {
+		minimum: "101%",
+		expectError: cover.NewErrInvalidThreshold("101%",
+			strconv.ErrRange),
+	},
+	"missing package": {
+		packages:    "=90",
+		expectError: cover.NewErrInvalidThreshold("=90", nil),
+	},
+	"missing minimum": {
+		packages:    "project/pkg",
+		expectError: cover.NewErrInvalidThreshold("project/pkg", nil),
+	},
+	"package minimum out of range": {
+		packages: "project/pkg=-1",
+		expectError: cover.NewErrInvalidThreshold("project/pkg=-1",
+			strconv.ErrRange),
+	},
+	"invalid regex": {
+		packages: "project(=90",
+		expectError: cover.NewErrInvalidThreshold("project(=90",
+			errors.New("error parsing regexp: missing closing ): `project(`")),
+	},
+}
+
+func TestThresholds(t *testing.T) {
+	test.Map(t, thresholdsTestCases).
+		Run(func(t test.Test, param ThresholdsParams) {
+			// When
+			thresholds, err := cover.ParseThresholds(
+				param.minimum, param.packages)
+
+			// Then
+			if param.expectError != nil {
+				assert.Nil(t, thresholds)
+				assert.Equal(t, param.expectError.Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, param.expectMinimum,
+				thresholds.Minimum(param.pkg), 0.001)
+		})
+}
+
+type CheckParams struct {
+	minimum         string
+	packages        string
+	expectOffenders []*cover.Offender
+}
+
+var checkTestCases = map[string]CheckParams{
+	"no thresholds": {
+		expectOffenders: []*cover.Offender{},
+	},
+	"all passed": {
+		minimum: "50", packages: "project$=0 project/pkg$=50",
+		expectOffenders: []*cover.Offender{},
+	},
+	"offenders": {
+		minimum: "60", packages: "other=100",
+		expectOffenders: []*cover.Offender{{
+			Coverage: &cover.Coverage{Name: pkgMain, Covered: 0, Total: 2},
+			Minimum:  60,
+		}, {
+			Coverage: &cover.Coverage{Name: pkgSub, Covered: 4, Total: 7},
+			Minimum:  60,
+		}, {
+			Coverage: &cover.Coverage{
+				Name: cover.NameTotal, Covered: 5, Total: 10,
+			},
+			Minimum: 60,
+		}},
+	},
+}
+
+func TestCheck(t *testing.T) {
+	test.Map(t, checkTestCases).
+		Run(func(t test.Test, param CheckParams) {
+			// Given
+			profile := ReadProfile(t, "test.cover")
+			thresholds, err := cover.ParseThresholds(
+				param.minimum, param.packages)
+			assert.NoError(t, err)
+
+			// When
+			offenders := cover.Check(profile, thresholds)
+
+			// Then
+			assert.Equal(t, param.expectOffenders, offenders)
+		})
+}
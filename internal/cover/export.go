@@ -0,0 +1,170 @@
+package cover
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+const (
+	// DocTypeCobertura provides the document type of Cobertura XML reports.
+	DocTypeCobertura = `<!DOCTYPE coverage SYSTEM ` +
+		`"http://cobertura.sourceforge.net/xml/coverage-04.dtd">`
+)
+
+// source returns the given import path of a source file relative to the
+// given module path, or the import path, if it is not part of the module.
+func source(name, module string) string {
+	if rel, ok := strings.CutPrefix(name, module+"/"); ok && module != "" {
+		return rel
+	}
+	return name
+}
+
+// WriteLCOV writes the line coverage of the profile as LCOV report to the
+// given writer using source file paths relative to the given module path.
+func (p *Profile) WriteLCOV(writer io.Writer, module string) error {
+	builder := &strings.Builder{}
+	for _, file := range p.Files {
+		lines := file.Lines()
+		hit := 0
+		fmt.Fprintf(builder, "TN:\nSF:%s\n", source(file.Name, module))
+		for _, line := range slices.Sorted(maps.Keys(lines)) {
+			fmt.Fprintf(builder, "DA:%d,%d\n", line, lines[line])
+			if lines[line] > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(builder, "LF:%d\nLH:%d\nend_of_record\n", len(lines), hit)
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return err //nolint:wrapcheck // wrapped by caller.
+}
+
+// cobertura contains the root element of a Cobertura XML report.
+type cobertura struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      float64            `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []*coberturaPkg    `xml:"packages>package"`
+	lines           coberturaLineCount `xml:"-"`
+}
+
+// coberturaPkg contains a package element of a Cobertura XML report.
+type coberturaPkg struct {
+	Name       string             `xml:"name,attr"`
+	LineRate   float64            `xml:"line-rate,attr"`
+	BranchRate float64            `xml:"branch-rate,attr"`
+	Complexity float64            `xml:"complexity,attr"`
+	Classes    []*coberturaClass  `xml:"classes>class"`
+	lines      coberturaLineCount `xml:"-"`
+}
+
+// coberturaClass contains a class element, i.e. a source file, of a
+// Cobertura XML report.
+type coberturaClass struct {
+	Name       string           `xml:"name,attr"`
+	Filename   string           `xml:"filename,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Methods    struct{}         `xml:"methods"`
+	Lines      []*coberturaLine `xml:"lines>line"`
+}
+
+// coberturaLine contains a line element of a Cobertura XML report.
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// coberturaLineCount contains the number of covered and valid lines.
+type coberturaLineCount struct {
+	covered int
+	valid   int
+}
+
+// add adds the given number of covered and valid lines.
+func (c *coberturaLineCount) add(covered, valid int) {
+	c.covered += covered
+	c.valid += valid
+}
+
+// rate returns the line coverage rate.
+func (c *coberturaLineCount) rate() float64 {
+	if c.valid == 0 {
+		return 1
+	}
+	return float64(c.covered) / float64(c.valid)
+}
+
+// WriteCobertura writes the line coverage of the profile as Cobertura XML
+// report to the given writer using source file paths relative to the given
+// module path, the given source directory, and the given timestamp.
+func (p *Profile) WriteCobertura(
+	writer io.Writer, module, dir string, timestamp int64,
+) error {
+	report := &cobertura{
+		Timestamp: timestamp, Sources: []string{dir},
+		Packages: []*coberturaPkg{},
+	}
+	for _, file := range p.Files {
+		index := slices.IndexFunc(report.Packages,
+			func(pkg *coberturaPkg) bool {
+				return pkg.Name == file.Package()
+			})
+		if index < 0 {
+			index = len(report.Packages)
+			report.Packages = append(report.Packages, &coberturaPkg{
+				Name: file.Package(), Classes: []*coberturaClass{},
+			})
+		}
+		pkg := report.Packages[index]
+
+		lines := file.Lines()
+		class := &coberturaClass{
+			Name: path.Base(file.Name), Filename: source(file.Name, module),
+			Lines: make([]*coberturaLine, 0, len(lines)),
+		}
+		count := coberturaLineCount{valid: len(lines)}
+		for _, line := range slices.Sorted(maps.Keys(lines)) {
+			class.Lines = append(class.Lines,
+				&coberturaLine{Number: line, Hits: lines[line]})
+			if lines[line] > 0 {
+				count.covered++
+			}
+		}
+		class.LineRate = count.rate()
+		pkg.Classes = append(pkg.Classes, class)
+		pkg.lines.add(count.covered, count.valid)
+		report.lines.add(count.covered, count.valid)
+	}
+
+	for _, pkg := range report.Packages {
+		pkg.LineRate = pkg.lines.rate()
+	}
+	report.LineRate = report.lines.rate()
+	report.LinesCovered = report.lines.covered
+	report.LinesValid = report.lines.valid
+
+	builder := &strings.Builder{}
+	builder.WriteString(xml.Header + DocTypeCobertura + "\n")
+	encoder := xml.NewEncoder(builder)
+	encoder.Indent("", "  ")
+	_ = encoder.Encode(report)
+	builder.WriteString("\n")
+	_, err := io.WriteString(writer, builder.String())
+	return err //nolint:wrapcheck // wrapped by caller.
+}
@@ -0,0 +1,98 @@
+package cover_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/cover"
+	"github.com/tkrop/go-testing/test"
+)
+
+// errWriter is a writer failing with an error.
+type errWriter struct{}
+
+// Write fails always with an error.
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+type ExportParams struct {
+	export       func(*cover.Profile, io.Writer) error
+	writer       io.Writer
+	expectOutput string
+	expectError  error
+}
+
+// writeLCOV writes the profile as LCOV report.
+func writeLCOV(profile *cover.Profile, writer io.Writer) error {
+	return profile.WriteLCOV(writer, pkgMain)
+}
+
+// writeCobertura writes the profile as Cobertura XML report.
+func writeCobertura(profile *cover.Profile, writer io.Writer) error {
+	return profile.WriteCobertura(writer, pkgMain, "/src/project", 1700000000)
+}
+
+var exportTestCases = map[string]ExportParams{
+	"lcov": {
+		export:       writeLCOV,
+		expectOutput: "test.lcov",
+	},
+	"lcov write failed": {
+		export:      writeLCOV,
+		writer:      errWriter{},
+		expectError: errors.New("write failed"),
+	},
+	"cobertura": {
+		export:       writeCobertura,
+		expectOutput: "test.xml",
+	},
+	"cobertura write failed": {
+		export:      writeCobertura,
+		writer:      errWriter{},
+		expectError: errors.New("write failed"),
+	},
+}
+
+func TestExport(t *testing.T) {
+	test.Map(t, exportTestCases).
+		Run(func(t test.Test, param ExportParams) {
+			// Given
+			profile := ReadProfile(t, "test.cover")
+			builder := &strings.Builder{}
+			writer := param.writer
+			if writer == nil {
+				writer = builder
+			}
+
+			// When
+			err := param.export(profile, writer)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectOutput != "" {
+				output, err := os.ReadFile("fixtures/" + param.expectOutput)
+				assert.NoError(t, err)
+				assert.Equal(t, string(output), builder.String())
+			}
+		})
+}
+
+func TestExportEmpty(t *testing.T) {
+	// Given
+	profile := &cover.Profile{Files: []*cover.File{}}
+	builder := &strings.Builder{}
+
+	// When
+	err := profile.WriteCobertura(builder, "", ".", 0)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Contains(t, builder.String(),
+		`<coverage line-rate="1" branch-rate="0" lines-covered="0"`)
+}
@@ -0,0 +1,186 @@
+// Package flaky provides detection of flaky tests by re-running the failed
+// tests of a `go test -json` run and classifying them as flaky, if they pass
+// at least once, or as failing, if they fail consistently. The results are
+// recorded in a persistent JSON lines history.
+package flaky
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tkrop/go-make/internal/junit"
+)
+
+const (
+	// StatusFlaky provides the status of a test passing on re-run.
+	StatusFlaky = "flaky"
+	// StatusFailing provides the status of a test failing consistently.
+	StatusFailing = "failing"
+)
+
+// Failure contains the failed top-level tests of a package.
+type Failure struct {
+	// Package provides the import path of the package.
+	Package string
+	// Tests provides the names of the failed top-level tests.
+	Tests []string
+	// Broken indicates whether the package failed without failing test, e.g.
+	// due to a build failure, so that it cannot be re-run selectively.
+	Broken bool
+}
+
+// Regex returns the regex matching exactly the failed tests of the package.
+func (f *Failure) Regex() string {
+	names := make([]string, 0, len(f.Tests))
+	for _, name := range f.Tests {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	return "^(" + strings.Join(names, "|") + ")$"
+}
+
+// Failures returns the failures of the packages of the given report in order
+// of appearance. Failed subtests are re-run via their top-level test.
+func Failures(report *junit.Report) []*Failure {
+	failures := []*Failure{}
+	for _, suite := range report.Suites {
+		failure := &Failure{Package: suite.Name, Tests: []string{}}
+		for _, test := range suite.Cases {
+			if test.Action != junit.ActionPass &&
+				test.Action != junit.ActionSkip &&
+				!strings.Contains(test.Name, "/") {
+				failure.Tests = append(failure.Tests, test.Name)
+			}
+		}
+		if suite.Action == junit.ActionFail && len(failure.Tests) == 0 {
+			failure.Broken = true
+		}
+		if failure.Broken || len(failure.Tests) != 0 {
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// Record contains the classification of a re-run test.
+type Record struct {
+	// Package provides the import path of the package of the test.
+	Package string `json:"package"`
+	// Test provides the name of the test.
+	Test string `json:"test"`
+	// Start provides the start time of the re-runs.
+	Start time.Time `json:"start"`
+	// Runs provides the number of re-runs of the test.
+	Runs int `json:"runs"`
+	// Failures provides the number of failed re-runs of the test.
+	Failures int `json:"failures"`
+	// Status provides the classification of the test.
+	Status string `json:"status"`
+}
+
+// String returns the record as log message.
+func (r *Record) String() string {
+	return fmt.Sprintf("%s %s [pkg=%s, runs=%d, failures=%d]",
+		r.Status, r.Test, r.Package, r.Runs, r.Failures)
+}
+
+// Classify reads the `go test -json` events of the re-runs of the failed
+// tests of the given failure from the given reader and classifies the tests.
+// A test is flaky, if at least one re-run passed, and failing otherwise.
+func Classify(
+	reader io.Reader, failure *Failure, start time.Time,
+) ([]*Record, error) {
+	records := make([]*Record, 0, len(failure.Tests))
+	index := map[string]*Record{}
+	for _, name := range failure.Tests {
+		record := &Record{
+			Package: failure.Package, Test: name, Start: start,
+		}
+		records = append(records, record)
+		index[name] = record
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event := &junit.Event{}
+		if json.Unmarshal(scanner.Bytes(), event) != nil ||
+			event.Package != failure.Package {
+			continue
+		} else if record, ok := index[event.Test]; ok {
+			switch event.Action {
+			case junit.ActionPass:
+				record.Runs++
+			case junit.ActionFail:
+				record.Runs++
+				record.Failures++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by caller.
+	}
+
+	for _, record := range records {
+		if record.Failures < record.Runs {
+			record.Status = StatusFlaky
+		} else {
+			record.Status = StatusFailing
+		}
+	}
+	return records, nil
+}
+
+// ErrHistory represents a flaky test history failure.
+var ErrHistory = errors.New("flaky history failed")
+
+// NewErrHistory wraps the error of a failed flaky test history operation.
+func NewErrHistory(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrHistory, file, err)
+}
+
+// History provides access to the flaky test history stored as JSON lines in
+// a file.
+type History struct {
+	// File provides the path to the flaky test history file.
+	File string
+}
+
+// New creates a new flaky test history using the given file.
+func New(file string) *History {
+	return &History{File: file}
+}
+
+// Append appends the given records to the flaky test history file creating
+// the file and its parent directories as needed.
+func (h *History) Append(records ...*Record) error {
+	builder := &strings.Builder{}
+	encoder := json.NewEncoder(builder)
+	for _, record := range records {
+		_ = encoder.Encode(record)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.File), 0o750); err != nil {
+		return NewErrHistory(h.File, err)
+	}
+
+	// #nosec G304 -- file is the flaky test history in the cache directory.
+	file, err := os.OpenFile(h.File,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return NewErrHistory(h.File, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(builder.String()); err != nil {
+		return NewErrHistory(h.File, err)
+	}
+	return nil
+}
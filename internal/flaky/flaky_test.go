@@ -0,0 +1,245 @@
+package flaky_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/flaky"
+	"github.com/tkrop/go-make/internal/junit"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// pkgFlaky contains the package of the flaky tests.
+	pkgFlaky = "example.com/flaky"
+	// pkgBroken contains the package failing to build.
+	pkgBroken = "example.com/broken"
+	// pkgPass contains the package of the passing tests.
+	pkgPass = "example.com/pass"
+)
+
+// start contains the start time of the re-runs.
+var start = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// Event returns the `go test -json` event of the given test of the given
+// package with the given action.
+func Event(pkg, test, action string) string {
+	if test == "" {
+		return `{"Action":"` + action + `","Package":"` + pkg + `"}` + "\n"
+	}
+	return `{"Action":"` + action + `","Package":"` + pkg +
+		`","Test":"` + test + `"}` + "\n"
+}
+
+// errReader is a reader failing with an error.
+type errReader struct{}
+
+// Read fails always with an error.
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestFailures(t *testing.T) {
+	// Given
+	report := junit.NewReport()
+	assert.NoError(t, junit.Convert(strings.NewReader(""+
+		Event(pkgFlaky, "TestPass", "pass")+
+		Event(pkgFlaky, "TestFail/sub", "fail")+
+		Event(pkgFlaky, "TestFail", "fail")+
+		Event(pkgFlaky, "TestSkip", "skip")+
+		Event(pkgFlaky, "TestIncomplete", "run")+
+		Event(pkgFlaky, "", "fail")+
+		Event(pkgBroken, "", "fail")+
+		Event(pkgPass, "TestPass", "pass")+
+		Event(pkgPass, "", "pass"),
+	), io.Discard, report))
+
+	// When
+	failures := flaky.Failures(report)
+
+	// Then
+	assert.Equal(t, []*flaky.Failure{{
+		Package: pkgFlaky, Tests: []string{"TestFail", "TestIncomplete"},
+	}, {
+		Package: pkgBroken, Tests: []string{}, Broken: true,
+	}}, failures)
+	assert.Equal(t, "^(TestFail|TestIncomplete)$", failures[0].Regex())
+}
+
+type ClassifyParams struct {
+	input         string
+	reader        io.Reader
+	expectRecords []*flaky.Record
+	expectError   error
+}
+
+var classifyTestCases = map[string]ClassifyParams{
+	"flaky and failing": {
+		input: "go: downloading\n" +
+			Event(pkgFlaky, "TestFlaky", "run") +
+			Event(pkgFlaky, "TestFlaky", "fail") +
+			Event(pkgFlaky, "TestFlaky", "pass") +
+			Event(pkgFlaky, "TestFlaky", "pass") +
+			Event(pkgFlaky, "TestFailing", "fail") +
+			Event(pkgFlaky, "TestFailing", "fail") +
+			Event(pkgFlaky, "TestFailing", "fail") +
+			Event(pkgFlaky, "TestOther", "pass") +
+			Event(pkgPass, "TestFailing", "pass") +
+			Event(pkgFlaky, "", "fail"),
+		expectRecords: []*flaky.Record{{
+			Package: pkgFlaky, Test: "TestFlaky", Start: start,
+			Runs: 3, Failures: 1, Status: flaky.StatusFlaky,
+		}, {
+			Package: pkgFlaky, Test: "TestFailing", Start: start,
+			Runs: 3, Failures: 3, Status: flaky.StatusFailing,
+		}},
+	},
+	"no runs": {
+		expectRecords: []*flaky.Record{{
+			Package: pkgFlaky, Test: "TestFlaky", Start: start,
+			Status: flaky.StatusFailing,
+		}, {
+			Package: pkgFlaky, Test: "TestFailing", Start: start,
+			Status: flaky.StatusFailing,
+		}},
+	},
+	"read failed": {
+		reader:      errReader{},
+		expectError: errors.New("read failed"),
+	},
+}
+
+func TestClassify(t *testing.T) {
+	test.Map(t, classifyTestCases).
+		Run(func(t test.Test, param ClassifyParams) {
+			// Given
+			reader := param.reader
+			if reader == nil {
+				reader = strings.NewReader(param.input)
+			}
+			failure := &flaky.Failure{
+				Package: pkgFlaky, Tests: []string{"TestFlaky", "TestFailing"},
+			}
+
+			// When
+			records, err := flaky.Classify(reader, failure, start)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectRecords, records)
+		})
+}
+
+func TestRecordString(t *testing.T) {
+	// Given
+	record := &flaky.Record{
+		Package: pkgFlaky, Test: "TestFlaky", Start: start,
+		Runs: 3, Failures: 1, Status: flaky.StatusFlaky,
+	}
+
+	// When
+	message := record.String()
+
+	// Then
+	assert.Equal(t, "flaky TestFlaky [pkg="+pkgFlaky+
+		", runs=3, failures=1]", message)
+}
+
+// recordFlaky contains a flaky test record.
+var recordFlaky = &flaky.Record{
+	Package: pkgFlaky, Test: "TestFlaky", Start: start,
+	Runs: 3, Failures: 1, Status: flaky.StatusFlaky,
+}
+
+// lineFlaky contains the JSON line of the flaky test record.
+const lineFlaky = `{"package":"example.com/flaky","test":"TestFlaky",` +
+	`"start":"2026-01-02T03:04:05Z","runs":3,"failures":1,` +
+	`"status":"flaky"}` + "\n"
+
+type AppendParams struct {
+	file          string
+	setup         func(test.Test, string)
+	expectContent string
+	expectError   func(string) error
+}
+
+var appendTestCases = map[string]AppendParams{
+	"new file": {
+		file:          "cache/flaky.json",
+		expectContent: lineFlaky + lineFlaky,
+	},
+	"existing file": {
+		file: "flaky.json",
+		setup: func(t test.Test, file string) {
+			assert.NoError(t, os.WriteFile(file, []byte(lineFlaky), 0o600))
+		},
+		expectContent: lineFlaky + lineFlaky + lineFlaky,
+	},
+	"mkdir failed": {
+		file: "cache/flaky.json",
+		setup: func(t test.Test, file string) {
+			assert.NoError(t, os.WriteFile(
+				filepath.Dir(file), []byte{}, 0o600))
+		},
+		expectError: func(file string) error {
+			return flaky.NewErrHistory(file, &fs.PathError{
+				Op: "mkdir", Path: filepath.Dir(file), Err: syscall.ENOTDIR,
+			})
+		},
+	},
+	"open failed": {
+		file: "flaky.json",
+		setup: func(t test.Test, file string) {
+			assert.NoError(t, os.Mkdir(file, 0o750))
+		},
+		expectError: func(file string) error {
+			return flaky.NewErrHistory(file, &fs.PathError{
+				Op: "open", Path: file, Err: syscall.EISDIR,
+			})
+		},
+	},
+	"write failed": {
+		file: "/dev/full",
+		expectError: func(file string) error {
+			return flaky.NewErrHistory(file, &fs.PathError{
+				Op: "write", Path: file, Err: syscall.ENOSPC,
+			})
+		},
+	},
+}
+
+func TestHistoryAppend(t *testing.T) {
+	test.Map(t, appendTestCases).
+		Run(func(t test.Test, param AppendParams) {
+			// Given
+			file := param.file
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(t.TempDir(), file)
+			}
+			if param.setup != nil {
+				param.setup(t, file)
+			}
+
+			// When
+			err := flaky.New(file).Append(recordFlaky, recordFlaky)
+
+			// Then
+			if param.expectError != nil {
+				assert.Equal(t, param.expectError(file), err)
+				return
+			}
+			assert.NoError(t, err)
+			data, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectContent, string(data))
+		})
+}
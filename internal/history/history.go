@@ -0,0 +1,157 @@
+// Package history provides a persistent run history of go-make invocations.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Record represents a single go-make invocation in the run history.
+type Record struct {
+	// Args provides the command line arguments of the invocation.
+	Args []string `json:"args"`
+	// Targets provides the make targets of the invocation, i.e. the arguments
+	// without options and variable assignments.
+	Targets []string `json:"targets,omitempty"`
+	// Dir provides the working directory of the invocation.
+	Dir string `json:"dir"`
+	// Config provides the resolved go-make config version.
+	Config string `json:"config"`
+	// Commit provides the commit hash of `HEAD` the invocation ran on.
+	Commit string `json:"commit,omitempty"`
+	// Start provides the start time of the invocation.
+	Start time.Time `json:"start"`
+	// Duration provides the duration of the invocation.
+	Duration time.Duration `json:"duration"`
+	// Exit provides the exit code of the invocation.
+	Exit int `json:"exit"`
+	// Aborted indicates whether the invocation was aborted.
+	Aborted bool `json:"aborted,omitempty"`
+}
+
+// Failed returns whether the invocation failed or was aborted.
+func (r *Record) Failed() bool {
+	return r.Exit != 0 || r.Aborted
+}
+
+// Format writes a single line representation of the record with the given
+// history index to the given writer.
+func (r *Record) Format(writer io.Writer, index int) {
+	status := fmt.Sprintf("exit=%d", r.Exit)
+	if r.Aborted {
+		status = "aborted"
+	}
+	fmt.Fprintf(writer, "%4d  %s  %8s  %-7s  %s  %s\n", index,
+		r.Start.Local().Format(time.DateTime),
+		r.Duration.Round(time.Millisecond), status, r.Config,
+		strings.Join(r.Args, " "))
+}
+
+// Filter provides the criteria to select records from the run history.
+type Filter struct {
+	// Grep provides a text that must be contained in the arguments.
+	Grep string
+	// Failed selects only failed or aborted invocations.
+	Failed bool
+	// Aborted selects only aborted invocations.
+	Aborted bool
+	// Since selects only invocations started after the given time.
+	Since time.Time
+}
+
+// Match returns whether the given record matches the filter criteria.
+func (f *Filter) Match(record *Record) bool {
+	switch {
+	case f.Failed && !record.Failed():
+		return false
+	case f.Aborted && !record.Aborted:
+		return false
+	case !f.Since.IsZero() && record.Start.Before(f.Since):
+		return false
+	case f.Grep != "" &&
+		!strings.Contains(strings.Join(record.Args, " "), f.Grep):
+		return false
+	}
+	return true
+}
+
+// ErrHistory represents a run history failure.
+var ErrHistory = errors.New("history failed")
+
+// NewErrHistory wraps the error of a failed run history operation.
+func NewErrHistory(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrHistory, file, err)
+}
+
+// History provides access to the run history stored as JSON lines in a file.
+type History struct {
+	// File provides the path to the run history file.
+	File string
+}
+
+// New creates a new run history using the given file.
+func New(file string) *History {
+	return &History{File: file}
+}
+
+// Append appends the given record to the run history file creating the file
+// and its parent directories as needed.
+func (h *History) Append(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return NewErrHistory(h.File, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.File), 0o750); err != nil {
+		return NewErrHistory(h.File, err)
+	}
+
+	// #nosec G304 -- file is the history file in the cache directory.
+	file, err := os.OpenFile(h.File,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return NewErrHistory(h.File, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return NewErrHistory(h.File, err)
+	}
+	return nil
+}
+
+// Read reads all records from the run history file in order of their
+// recording. A missing file is treated as empty history, while malformed
+// lines are skipped to stay resilient against interrupted writes.
+func (h *History) Read() ([]*Record, error) {
+	// #nosec G304 -- file is the history file in the cache directory.
+	file, err := os.Open(h.File)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*Record{}, nil
+	} else if err != nil {
+		return nil, NewErrHistory(h.File, err)
+	}
+	defer file.Close()
+
+	records := []*Record{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err == nil {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewErrHistory(h.File, err)
+	}
+	return records, nil
+}
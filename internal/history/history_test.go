@@ -0,0 +1,243 @@
+package history_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/history"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// timeBase contains an arbitrary base time for testing.
+	timeBase = time.Date(2024, 1, 10, 16, 22, 54, 0, time.Local)
+
+	// recordOkay contains an arbitrary successful record.
+	recordOkay = &history.Record{
+		Args:     []string{"go-make", "test"},
+		Targets:  []string{"test"},
+		Dir:      "/root/go-make",
+		Config:   "v0.0.25",
+		Start:    timeBase,
+		Duration: 1500 * time.Millisecond,
+	}
+	// recordFailed contains an arbitrary failed record.
+	recordFailed = &history.Record{
+		Args:     []string{"go-make", "lint"},
+		Targets:  []string{"lint"},
+		Dir:      "/root/go-make",
+		Config:   "v0.0.25",
+		Start:    timeBase.Add(time.Hour),
+		Duration: 12 * time.Second,
+		Exit:     3,
+	}
+	// recordAborted contains an arbitrary aborted record.
+	recordAborted = &history.Record{
+		Args:     []string{"go-make", "test-all"},
+		Targets:  []string{"test-all"},
+		Dir:      "/root/go-make",
+		Config:   "custom",
+		Start:    timeBase.Add(2 * time.Hour),
+		Duration: 250 * time.Millisecond,
+		Aborted:  true,
+	}
+)
+
+type FormatParams struct {
+	record       *history.Record
+	index        int
+	expectString string
+}
+
+var formatTestCases = map[string]FormatParams{
+	"record okay": {
+		record: recordOkay,
+		index:  1,
+		expectString: "   1  2024-01-10 16:22:54      1.5s  exit=0   " +
+			"v0.0.25  go-make test\n",
+	},
+	"record failed": {
+		record: recordFailed,
+		index:  2,
+		expectString: "   2  2024-01-10 17:22:54       12s  exit=3   " +
+			"v0.0.25  go-make lint\n",
+	},
+	"record aborted": {
+		record: recordAborted,
+		index:  3,
+		expectString: "   3  2024-01-10 18:22:54     250ms  aborted  " +
+			"custom  go-make test-all\n",
+	},
+}
+
+func TestFormat(t *testing.T) {
+	test.Map(t, formatTestCases).
+		Run(func(t test.Test, param FormatParams) {
+			// Given
+			writer := &strings.Builder{}
+
+			// When
+			param.record.Format(writer, param.index)
+
+			// Then
+			assert.Equal(t, param.expectString, writer.String())
+		})
+}
+
+type MatchParams struct {
+	filter      history.Filter
+	record      *history.Record
+	expectMatch bool
+}
+
+var matchTestCases = map[string]MatchParams{
+	"empty filter": {
+		record:      recordOkay,
+		expectMatch: true,
+	},
+	"failed filter okay": {
+		filter: history.Filter{Failed: true},
+		record: recordOkay,
+	},
+	"failed filter failed": {
+		filter:      history.Filter{Failed: true},
+		record:      recordFailed,
+		expectMatch: true,
+	},
+	"failed filter aborted": {
+		filter:      history.Filter{Failed: true},
+		record:      recordAborted,
+		expectMatch: true,
+	},
+	"aborted filter failed": {
+		filter: history.Filter{Aborted: true},
+		record: recordFailed,
+	},
+	"aborted filter aborted": {
+		filter:      history.Filter{Aborted: true},
+		record:      recordAborted,
+		expectMatch: true,
+	},
+	"since filter before": {
+		filter: history.Filter{Since: timeBase.Add(time.Minute)},
+		record: recordOkay,
+	},
+	"since filter after": {
+		filter:      history.Filter{Since: timeBase.Add(time.Minute)},
+		record:      recordFailed,
+		expectMatch: true,
+	},
+	"grep filter mismatch": {
+		filter: history.Filter{Grep: "lint"},
+		record: recordOkay,
+	},
+	"grep filter match": {
+		filter:      history.Filter{Grep: "make lint"},
+		record:      recordFailed,
+		expectMatch: true,
+	},
+}
+
+func TestMatch(t *testing.T) {
+	test.Map(t, matchTestCases).
+		Run(func(t test.Test, param MatchParams) {
+			// When
+			match := param.filter.Match(param.record)
+
+			// Then
+			assert.Equal(t, param.expectMatch, match)
+		})
+}
+
+type ReadParams struct {
+	content       *string
+	records       []*history.Record
+	expectRecords []*history.Record
+}
+
+var readTestCases = map[string]ReadParams{
+	"missing file": {
+		expectRecords: []*history.Record{},
+	},
+	"appended records": {
+		records: []*history.Record{
+			recordOkay, recordFailed, recordAborted,
+		},
+		expectRecords: []*history.Record{
+			recordOkay, recordFailed, recordAborted,
+		},
+	},
+	"malformed records": {
+		content: ptr("{\"args\":[\"go-make\",\n\n"),
+		records: []*history.Record{recordOkay},
+		expectRecords: []*history.Record{
+			recordOkay,
+		},
+	},
+}
+
+func TestRead(t *testing.T) {
+	test.Map(t, readTestCases).
+		Run(func(t test.Test, param ReadParams) {
+			// Given
+			file := filepath.Join(t.TempDir(), "cache", "history.json")
+			store := history.New(file)
+			if param.content != nil {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0o750))
+				assert.NoError(t, os.WriteFile(file,
+					[]byte(*param.content), 0o600))
+			}
+			for _, record := range param.records {
+				assert.NoError(t, store.Append(record))
+			}
+
+			// When
+			records, err := store.Read()
+
+			// Then
+			assert.NoError(t, err)
+			assert.Len(t, records, len(param.expectRecords))
+			for index, record := range param.expectRecords {
+				assert.Equal(t, record.Args, records[index].Args)
+				assert.Equal(t, record.Exit, records[index].Exit)
+				assert.Equal(t, record.Aborted, records[index].Aborted)
+				assert.Equal(t, record.Duration, records[index].Duration)
+				assert.True(t, record.Start.Equal(records[index].Start))
+			}
+		})
+}
+
+func TestAppendFailed(t *testing.T) {
+	// Given
+	file := filepath.Join(t.TempDir(), "blocked")
+	assert.NoError(t, os.WriteFile(file, []byte{}, 0o600))
+	store := history.New(filepath.Join(file, "history.json"))
+
+	// When
+	err := store.Append(recordOkay)
+
+	// Then
+	assert.ErrorIs(t, err, history.ErrHistory)
+}
+
+func TestReadFailed(t *testing.T) {
+	// Given
+	store := history.New(t.TempDir())
+
+	// When
+	records, err := store.Read()
+
+	// Then
+	assert.ErrorIs(t, err, history.ErrHistory)
+	assert.Nil(t, records)
+}
+
+// ptr returns a pointer to the given value.
+func ptr[T any](value T) *T {
+	return &value
+}
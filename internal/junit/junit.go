@@ -0,0 +1,400 @@
+// Package junit provides conversion of `go test -json` events into JUnit XML
+// test reports while forwarding the human-readable test output.
+//
+// Tests are reported as test cases of the test suite of their package with
+// their durations. Skipped tests are reported as skipped, failed tests as
+// failures, and panicked or incomplete tests as errors. Packages that failed
+// to build or failed without a failing test, e.g. due to a panic in `init` or
+// `TestMain`, are reported as error test case of the package.
+package junit
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// ActionOutput provides the action of an output event.
+	ActionOutput = "output"
+	// ActionPass provides the action of a passed test or package.
+	ActionPass = "pass"
+	// ActionFail provides the action of a failed test or package.
+	ActionFail = "fail"
+	// ActionSkip provides the action of a skipped test or package.
+	ActionSkip = "skip"
+	// ActionBuildOutput provides the action of a build output event.
+	ActionBuildOutput = "build-output"
+
+	// NameBuildFailed provides the test case name of a failed package build.
+	NameBuildFailed = "[build failed]"
+	// NamePackageFailed provides the test case name of a failed package
+	// without failing test.
+	NamePackageFailed = "[package failed]"
+)
+
+// Event contains a `go test -json` event.
+type Event struct {
+	// Time provides the time of the event.
+	Time time.Time
+	// Action provides the action of the event.
+	Action string
+	// Package provides the package of the event.
+	Package string
+	// Test provides the test of the event, if any.
+	Test string
+	// Elapsed provides the duration of a test or package in seconds.
+	Elapsed float64
+	// Output provides the output of an output event.
+	Output string
+	// ImportPath provides the import path of a build event.
+	ImportPath string
+	// FailedBuild provides the import path of the failed package build.
+	FailedBuild string
+}
+
+// Case contains the result of a test.
+type Case struct {
+	// Name provides the name of the test.
+	Name string
+	// Action provides the final action of the test, or empty, if the test
+	// did not complete.
+	Action string
+	// Elapsed provides the duration of the test in seconds.
+	Elapsed float64
+	// Output provides the output of the test.
+	Output strings.Builder
+	// Panic indicates whether the test panicked.
+	Panic bool
+}
+
+// Suite contains the results of the tests of a package.
+type Suite struct {
+	// Name provides the import path of the package.
+	Name string
+	// Time provides the start time of the package tests.
+	Time time.Time
+	// Action provides the final action of the package.
+	Action string
+	// Elapsed provides the duration of the package tests in seconds.
+	Elapsed float64
+	// Output provides the package output not belonging to a test.
+	Output strings.Builder
+	// FailedBuild provides the import path of the failed package build.
+	FailedBuild string
+	// Cases provides the test results in order of appearance.
+	Cases []*Case
+}
+
+// Totals contains the total number of tests, failures, errors, and skipped
+// tests of a report.
+type Totals struct {
+	// Tests provides the number of tests.
+	Tests int
+	// Failures provides the number of failed tests.
+	Failures int
+	// Errors provides the number of tests with errors.
+	Errors int
+	// Skipped provides the number of skipped tests.
+	Skipped int
+}
+
+// Report contains the test suites of the `go test -json` events.
+type Report struct {
+	// Suites provides the test suites in order of appearance.
+	Suites []*Suite
+	// builds provides the build output per import path.
+	builds map[string]*strings.Builder
+}
+
+// NewReport creates a new empty report.
+func NewReport() *Report {
+	return &Report{
+		Suites: []*Suite{},
+		builds: map[string]*strings.Builder{},
+	}
+}
+
+// suite returns the test suite of the package with given name, creating a
+// new test suite, if necessary.
+func (r *Report) suite(name string) *Suite {
+	for _, suite := range r.Suites {
+		if suite.Name == name {
+			return suite
+		}
+	}
+	suite := &Suite{Name: name, Cases: []*Case{}}
+	r.Suites = append(r.Suites, suite)
+	return suite
+}
+
+// test returns the test case with given name of the test suite, creating a
+// new test case, if necessary.
+func (s *Suite) test(name string) *Case {
+	for _, test := range s.Cases {
+		if test.Name == name {
+			return test
+		}
+	}
+	test := &Case{Name: name}
+	s.Cases = append(s.Cases, test)
+	return test
+}
+
+// Add adds the given event to the report.
+func (r *Report) Add(event *Event) {
+	if event.Action == ActionBuildOutput {
+		if _, ok := r.builds[event.ImportPath]; !ok {
+			r.builds[event.ImportPath] = &strings.Builder{}
+		}
+		r.builds[event.ImportPath].WriteString(event.Output)
+		return
+	} else if event.Package == "" {
+		return
+	}
+
+	suite := r.suite(event.Package)
+	if suite.Time.IsZero() {
+		suite.Time = event.Time
+	}
+	if event.Test == "" {
+		switch event.Action {
+		case ActionOutput:
+			suite.Output.WriteString(event.Output)
+		case ActionPass, ActionFail, ActionSkip:
+			suite.Action, suite.Elapsed = event.Action, event.Elapsed
+			suite.FailedBuild = event.FailedBuild
+		}
+		return
+	}
+
+	test := suite.test(event.Test)
+	switch event.Action {
+	case ActionOutput:
+		if !frame(event.Output) {
+			test.Output.WriteString(event.Output)
+		}
+		if strings.HasPrefix(event.Output, "panic: ") {
+			test.Panic = true
+		}
+	case ActionPass, ActionFail, ActionSkip:
+		test.Action, test.Elapsed = event.Action, event.Elapsed
+	}
+}
+
+// frame returns whether the given output is a framing line of a test, i.e.
+// a `=== RUN`, `=== PAUSE`, `=== CONT`, or `=== NAME` line.
+func frame(output string) bool {
+	return strings.HasPrefix(output, "=== ")
+}
+
+// Convert reads the `go test -json` events from the given reader, adds them
+// to the given report, and writes the human-readable test output to the
+// given writer. Lines not containing an event are forwarded unchanged.
+func Convert(reader io.Reader, writer io.Writer, report *Report) error {
+	buffer := bufio.NewReader(reader)
+	for {
+		line, err := buffer.ReadString('\n')
+		if line != "" {
+			if err := convert(line, writer, report); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err //nolint:wrapcheck // wrapped by caller.
+		}
+	}
+}
+
+// convert converts the given line of the `go test -json` output.
+func convert(line string, writer io.Writer, report *Report) error {
+	event := &Event{}
+	if !strings.HasPrefix(line, "{") ||
+		json.Unmarshal([]byte(line), event) != nil {
+		_, err := io.WriteString(writer, line)
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+
+	report.Add(event)
+	switch event.Action {
+	case ActionOutput, ActionBuildOutput:
+		if !frame(event.Output) {
+			_, err := io.WriteString(writer, event.Output)
+			return err //nolint:wrapcheck // wrapped by caller.
+		}
+	}
+	return nil
+}
+
+// testsuites contains the root element of a JUnit XML report.
+type testsuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []*testsuite `xml:"testsuite"`
+}
+
+// testsuite contains a test suite element of a JUnit XML report.
+type testsuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Cases     []*testcase `xml:"testcase"`
+}
+
+// testcase contains a test case element of a JUnit XML report.
+type testcase struct {
+	Name      string  `xml:"name,attr"`
+	Classname string  `xml:"classname,attr"`
+	Time      string  `xml:"time,attr"`
+	Skipped   *result `xml:"skipped,omitempty"`
+	Failure   *result `xml:"failure,omitempty"`
+	Error     *result `xml:"error,omitempty"`
+}
+
+// result contains a skipped, failure, or error element of a test case.
+type result struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// count adds the given test case to the totals.
+func (t *Totals) count(test *testcase) {
+	t.Tests++
+	switch {
+	case test.Skipped != nil:
+		t.Skipped++
+	case test.Failure != nil:
+		t.Failures++
+	case test.Error != nil:
+		t.Errors++
+	}
+}
+
+// duration formats the given duration in seconds.
+func duration(elapsed float64) string {
+	return fmt.Sprintf("%.3f", elapsed)
+}
+
+// element creates the test case element of the test.
+func (c *Case) element(classname string) *testcase {
+	test := &testcase{
+		Name: c.Name, Classname: classname, Time: duration(c.Elapsed),
+	}
+	output := c.Output.String()
+	switch {
+	case c.Action == ActionPass:
+	case c.Action == ActionSkip:
+		test.Skipped = &result{Message: "skipped", Text: output}
+	case c.Action == ActionFail && !c.Panic:
+		test.Failure = &result{Message: "failed", Text: output}
+	case c.Panic:
+		test.Error = &result{Message: "panic", Type: "panic", Text: output}
+	default:
+		test.Error = &result{Message: "incomplete", Text: output}
+	}
+	return test
+}
+
+// element creates the test suite element of the suite using the given build
+// outputs and adds its test cases to the given totals.
+func (s *Suite) element(
+	builds map[string]*strings.Builder, totals *Totals,
+) *testsuite {
+	suite := &testsuite{
+		Name: s.Name, Time: duration(s.Elapsed), Cases: []*testcase{},
+	}
+	if !s.Time.IsZero() {
+		suite.Timestamp = s.Time.UTC().Format(time.RFC3339)
+	}
+
+	counts := &Totals{}
+	for _, test := range s.Cases {
+		element := test.element(s.Name)
+		counts.count(element)
+		suite.Cases = append(suite.Cases, element)
+	}
+
+	if s.Action == ActionFail && s.FailedBuild != "" {
+		output := s.Output.String()
+		if build, ok := builds[s.FailedBuild]; ok {
+			output = build.String()
+		}
+		test := &testcase{
+			Name: NameBuildFailed, Classname: s.Name, Time: duration(0),
+			Error: &result{Message: "build failed", Text: output},
+		}
+		counts.count(test)
+		suite.Cases = append(suite.Cases, test)
+	} else if s.Action == ActionFail &&
+		counts.Failures == 0 && counts.Errors == 0 {
+		test := &testcase{
+			Name: NamePackageFailed, Classname: s.Name,
+			Time:  duration(s.Elapsed),
+			Error: &result{Message: "package failed", Text: s.Output.String()},
+		}
+		counts.count(test)
+		suite.Cases = append(suite.Cases, test)
+	}
+
+	suite.Tests, suite.Failures = counts.Tests, counts.Failures
+	suite.Errors, suite.Skipped = counts.Errors, counts.Skipped
+	totals.Tests += counts.Tests
+	totals.Failures += counts.Failures
+	totals.Errors += counts.Errors
+	totals.Skipped += counts.Skipped
+	return suite
+}
+
+// junit creates the JUnit XML report element and the totals of the report.
+// Test suites without test cases, e.g. of packages without test files, are
+// omitted.
+func (r *Report) junit() (*testsuites, *Totals) {
+	report := &testsuites{Suites: []*testsuite{}}
+	totals, elapsed := &Totals{}, 0.0
+	for _, suite := range r.Suites {
+		if element := suite.element(r.builds, totals); len(element.Cases) != 0 {
+			report.Suites = append(report.Suites, element)
+		}
+		elapsed += suite.Elapsed
+	}
+	report.Tests, report.Failures = totals.Tests, totals.Failures
+	report.Errors, report.Skipped = totals.Errors, totals.Skipped
+	report.Time = duration(elapsed)
+	return report, totals
+}
+
+// Totals returns the total number of tests, failures, errors, and skipped
+// tests of the report.
+func (r *Report) Totals() *Totals {
+	_, totals := r.junit()
+	return totals
+}
+
+// Write writes the report as JUnit XML report to the given writer.
+func (r *Report) Write(writer io.Writer) error {
+	report, _ := r.junit()
+	builder := &strings.Builder{}
+	builder.WriteString(xml.Header)
+	encoder := xml.NewEncoder(builder)
+	encoder.Indent("", "  ")
+	_ = encoder.Encode(report)
+	builder.WriteString("\n")
+	_, err := io.WriteString(writer, builder.String())
+	return err //nolint:wrapcheck // wrapped by caller.
+}
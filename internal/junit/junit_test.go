@@ -0,0 +1,173 @@
+package junit_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/junit"
+	"github.com/tkrop/go-testing/test"
+)
+
+// ReadFixture reads the content of the given fixture file.
+func ReadFixture(t test.Test, name string) string {
+	data, err := os.ReadFile("fixtures/" + name)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+// errReader is a reader failing with an error.
+type errReader struct{}
+
+// Read fails always with an error.
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+// errWriter is a writer failing with an error.
+type errWriter struct{}
+
+// Write fails always with an error.
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+type ConvertParams struct {
+	input        string
+	reader       io.Reader
+	writer       io.Writer
+	expectOutput string
+	expectReport string
+	expectTotals *junit.Totals
+	expectError  error
+}
+
+var convertTestCases = map[string]ConvertParams{
+	"test events": {
+		input:        "test.json",
+		expectOutput: "test.out",
+		expectReport: "test.xml",
+		expectTotals: &junit.Totals{
+			Tests: 9, Failures: 2, Errors: 4, Skipped: 1,
+		},
+	},
+	"read failed": {
+		reader:       errReader{},
+		expectTotals: &junit.Totals{},
+		expectError:  errors.New("read failed"),
+	},
+	"write line failed": {
+		reader:       strings.NewReader("go: downloading\n"),
+		writer:       errWriter{},
+		expectTotals: &junit.Totals{},
+		expectError:  errors.New("write failed"),
+	},
+	"write output failed": {
+		reader: strings.NewReader(`{"Action":"output",` +
+			`"Package":"example.com/pkg","Output":"PASS\n"}` + "\n"),
+		writer:       errWriter{},
+		expectTotals: &junit.Totals{},
+		expectError:  errors.New("write failed"),
+	},
+}
+
+func TestConvert(t *testing.T) {
+	test.Map(t, convertTestCases).
+		Run(func(t test.Test, param ConvertParams) {
+			// Given
+			reader := param.reader
+			if param.input != "" {
+				reader = strings.NewReader(ReadFixture(t, param.input))
+			}
+			builder := &strings.Builder{}
+			writer := param.writer
+			if writer == nil {
+				writer = builder
+			}
+			report := junit.NewReport()
+
+			// When
+			err := junit.Convert(reader, writer, report)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectTotals, report.Totals())
+			if param.expectOutput != "" {
+				assert.Equal(t, ReadFixture(t, param.expectOutput),
+					builder.String())
+			}
+			if param.expectReport != "" {
+				output := &strings.Builder{}
+				assert.NoError(t, report.Write(output))
+				assert.Equal(t, ReadFixture(t, param.expectReport),
+					output.String())
+			}
+		})
+}
+
+type WriteParams struct {
+	events       []*junit.Event
+	writer       io.Writer
+	expectReport string
+	expectError  error
+}
+
+var writeTestCases = map[string]WriteParams{
+	"empty": {
+		expectReport: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<testsuites tests="0" failures="0" errors="0" skipped="0"` +
+			` time="0.000"></testsuites>` + "\n",
+	},
+	"build failed without build output": {
+		events: []*junit.Event{{
+			Action: junit.ActionOutput, Package: "example.com/pkg",
+			Output: "FAIL\texample.com/pkg [setup failed]\n",
+		}, {
+			Action: junit.ActionFail, Package: "example.com/pkg",
+			FailedBuild: "example.com/pkg",
+		}},
+		expectReport: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<testsuites tests="1" failures="0" errors="1" skipped="0"` +
+			` time="0.000">` + "\n" +
+			`  <testsuite name="example.com/pkg" tests="1" failures="0"` +
+			` errors="1" skipped="0" time="0.000">` + "\n" +
+			`    <testcase name="[build failed]"` +
+			` classname="example.com/pkg" time="0.000">` + "\n" +
+			`      <error message="build failed"><![CDATA[FAIL` + "\t" +
+			`example.com/pkg [setup failed]` + "\n" + `]]></error>` + "\n" +
+			`    </testcase>` + "\n" +
+			`  </testsuite>` + "\n" +
+			`</testsuites>` + "\n",
+	},
+	"write failed": {
+		writer:      errWriter{},
+		expectError: errors.New("write failed"),
+	},
+}
+
+func TestWrite(t *testing.T) {
+	test.Map(t, writeTestCases).
+		Run(func(t test.Test, param WriteParams) {
+			// Given
+			report := junit.NewReport()
+			for _, event := range param.events {
+				report.Add(event)
+			}
+			builder := &strings.Builder{}
+			writer := param.writer
+			if writer == nil {
+				writer = builder
+			}
+
+			// When
+			err := report.Write(writer)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectReport, builder.String())
+		})
+}
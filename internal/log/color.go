@@ -0,0 +1,63 @@
+package log
+
+import (
+	"io"
+
+	"github.com/tkrop/go-make/internal/sys"
+)
+
+// Color codes used for colored logging matching the `COLOR-*` scheme of the
+// `Makefile.base`.
+const (
+	// ColorInfo provides the color code for info messages.
+	ColorInfo = "1;96"
+	// ColorDebug provides the color code for debug messages.
+	ColorDebug = "1;94"
+	// ColorError provides the color code for error messages.
+	ColorError = "1;91"
+	// ColorWarning provides the color code for warning messages.
+	ColorWarning = "1;93"
+	// ColorSuccess provides the color code for success messages.
+	ColorSuccess = "1;92"
+)
+
+// Available color mode constants exported to the `Makefile.base`.
+const (
+	// ColorAlways enables colored output.
+	ColorAlways = "always"
+	// ColorNever disables colored output.
+	ColorNever = "never"
+)
+
+// UseColor evaluates whether colored output should be used for the given
+// writer using the given environment lookup function. Colors are disabled if
+// `NO_COLOR` is set or `TERM` is `dumb`, and enabled if `FORCE_COLOR` is set
+// or the writer is a terminal.
+func UseColor(writer io.Writer, getenv func(key string) string) bool {
+	switch {
+	case getenv("NO_COLOR") != "":
+		return false
+	case getenv("TERM") == "dumb":
+		return false
+	case getenv("FORCE_COLOR") != "":
+		return true
+	}
+	return sys.IsTerminal(writer)
+}
+
+// ColorMode returns the color mode constant matching the given color flag.
+func ColorMode(color bool) string {
+	if color {
+		return ColorAlways
+	}
+	return ColorNever
+}
+
+// colorize wraps the given prefix in the given color code, if colors are
+// enabled.
+func (l *defaultLogger) colorize(color, prefix string) string {
+	if l.color {
+		return "\033[" + color + "m" + prefix + "\033[0m"
+	}
+	return prefix
+}
@@ -0,0 +1,176 @@
+package log_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/log"
+	"github.com/tkrop/go-testing/test"
+)
+
+type UseColorParams struct {
+	writer      io.Writer
+	env         map[string]string
+	expectColor bool
+}
+
+var useColorTestCases = map[string]UseColorParams{
+	"builder without env": {
+		writer:      &strings.Builder{},
+		expectColor: false,
+	},
+	"builder with force color": {
+		writer:      &strings.Builder{},
+		env:         map[string]string{"FORCE_COLOR": "1"},
+		expectColor: true,
+	},
+	"builder with no color": {
+		writer:      &strings.Builder{},
+		env:         map[string]string{"NO_COLOR": "1"},
+		expectColor: false,
+	},
+	"builder with no and force color": {
+		writer: &strings.Builder{},
+		env: map[string]string{
+			"NO_COLOR": "1", "FORCE_COLOR": "1",
+		},
+		expectColor: false,
+	},
+	"builder with dumb terminal": {
+		writer: &strings.Builder{},
+		env: map[string]string{
+			"TERM": "dumb", "FORCE_COLOR": "1",
+		},
+		expectColor: false,
+	},
+	"builder with empty no color": {
+		writer: &strings.Builder{},
+		env: map[string]string{
+			"NO_COLOR": "", "FORCE_COLOR": "true",
+		},
+		expectColor: true,
+	},
+	"nil file": {
+		writer:      (*os.File)(nil),
+		expectColor: false,
+	},
+}
+
+func TestUseColor(t *testing.T) {
+	test.Map(t, useColorTestCases).
+		Run(func(t test.Test, param UseColorParams) {
+			// Given
+			getenv := func(key string) string {
+				return param.env[key]
+			}
+
+			// When
+			color := log.UseColor(param.writer, getenv)
+
+			// Then
+			assert.Equal(t, param.expectColor, color)
+		})
+}
+
+type ColorModeParams struct {
+	color      bool
+	expectMode string
+}
+
+var colorModeTestCases = map[string]ColorModeParams{
+	"color always": {
+		color:      true,
+		expectMode: log.ColorAlways,
+	},
+	"color never": {
+		color:      false,
+		expectMode: log.ColorNever,
+	},
+}
+
+func TestColorMode(t *testing.T) {
+	test.Map(t, colorModeTestCases).
+		Run(func(t test.Test, param ColorModeParams) {
+			// When
+			mode := log.ColorMode(param.color)
+
+			// Then
+			assert.Equal(t, param.expectMode, mode)
+		})
+}
+
+type ColorLoggerParams struct {
+	call         func(logger log.Logger, writer io.Writer)
+	expectString string
+}
+
+var colorLoggerTestCases = map[string]ColorLoggerParams{
+	"info": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Info(writer, infoDirty, false)
+		},
+		expectString: "\033[1;96minfo:\033[0m " + infoDirty.String() + "\n",
+	},
+	"info raw": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Info(writer, infoDirty, true)
+		},
+		expectString: infoDirty.String() + "\n",
+	},
+	"exec": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Exec(writer, "dir", "arg")
+		},
+		expectString: "\033[1;94mexec:\033[0m arg [dir]\n",
+	},
+	"call": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Call(writer, "arg")
+		},
+		expectString: "\033[1;94mcall:\033[0m arg\n",
+	},
+	"config": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Config(writer, "custom", "config")
+		},
+		expectString: "\033[1;94mconfig:\033[0m custom [config]\n",
+	},
+	"warning": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Warning(writer, "message")
+		},
+		expectString: "\033[1;93mwarning:\033[0m message\n",
+	},
+	"error": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Error(writer, "message", assert.AnError)
+		},
+		expectString: fmt.Sprintf(
+			"\033[1;91merror:\033[0m message: %v\n", assert.AnError),
+	},
+	"message": {
+		call: func(logger log.Logger, writer io.Writer) {
+			logger.Message(writer, "message")
+		},
+		expectString: "message\n",
+	},
+}
+
+func TestColorLogger(t *testing.T) {
+	test.Map(t, colorLoggerTestCases).
+		Run(func(t test.Test, param ColorLoggerParams) {
+			// Given
+			writer := &strings.Builder{}
+
+			// When
+			param.call(colorLogger, writer)
+
+			// Then
+			assert.Equal(t, param.expectString, writer.String())
+		})
+}
@@ -7,74 +7,190 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/tkrop/go-config/info"
 )
 
-// Logger provides a common interface for logging.
+// Level defines the verbosity level of the go-make wrapper logging.
+type Level int
+
+// Available log level constants.
+const (
+	// LevelQuiet suppresses all wrapper logging but errors.
+	LevelQuiet Level = -1
+	// LevelDefault logs only errors and warnings (default).
+	LevelDefault Level = 0
+	// LevelVerbose additionally logs calls, build info, command executions,
+	// and the config resolution.
+	LevelVerbose Level = 1
+	// LevelDebug additionally logs the timings of all execution steps.
+	LevelDebug Level = 2
+)
+
+// levels maps the names of the log levels to the log levels.
+var levels = map[string]Level{
+	"quiet":   LevelQuiet,
+	"default": LevelDefault,
+	"verbose": LevelVerbose,
+	"debug":   LevelDebug,
+}
+
+// ParseLevel returns the log level with given name and whether the name is a
+// valid log level name.
+func ParseLevel(name string) (Level, bool) {
+	level, ok := levels[name]
+	return level, ok
+}
+
+// Logger provides a common interface for logging. The logger filters the log
+// messages by its log level, while messages and raw build information are
+// always written, since they provide the command output.
 type Logger interface {
-	// Logs the build information of the command or module to the given writer.
+	// Level returns the current log level of the logger.
+	Level() Level
+	// SetLevel sets the log level of the logger.
+	SetLevel(level Level)
+	// Logs the build information of the command or module to the given writer
+	// (verbose), or the raw build information as command output.
 	Info(writer io.Writer, info *info.Info, raw bool)
-	// Exec logs the internal command execution for debugging to the given writer.
+	// Exec logs the internal command execution for debugging to the given
+	// writer (verbose).
 	Exec(writer io.Writer, dir string, args ...string)
-	// Logs the call of the command to the given writer.
+	// Logs the call of the command to the given writer (verbose).
 	Call(writer io.Writer, args ...string)
-	// Logs the given error message and error to the given writer.
+	// Config logs the resolved go-make config version and directory to the
+	// given writer (verbose).
+	Config(writer io.Writer, version, dir string)
+	// Timing logs the duration of the named execution step to the given writer
+	// (debug).
+	Timing(writer io.Writer, name string, duration time.Duration)
+	// Warning logs the given warning message to the given writer (not quiet).
+	Warning(writer io.Writer, message string)
+	// Logs the given error message and error to the given writer (always).
 	Error(writer io.Writer, message string, err error)
 	// Logs the given message to the given writer.
 	Message(writer io.Writer, message string)
 }
 
 // defaultLogger provides a default logger using `fmt` and `json` package.
-type defaultLogger struct{}
+type defaultLogger struct {
+	// level contains the current log level.
+	level Level
+	// color defines whether the log prefixes are colored.
+	color bool
+}
 
-// NewLogger creates a new default logger.
-func NewLogger() Logger {
-	return &defaultLogger{}
+// NewLogger creates a new default logger that optionally colors the log
+// prefixes using the color scheme of the `Makefile.base`.
+func NewLogger(color bool) Logger {
+	return &defaultLogger{level: LevelDefault, color: color}
+}
+
+// Level returns the current log level of the logger.
+func (l *defaultLogger) Level() Level {
+	return l.level
+}
+
+// SetLevel sets the log level of the logger.
+func (l *defaultLogger) SetLevel(level Level) {
+	l.level = level
+}
+
+// enabled returns whether messages of the given log level are logged.
+func (l *defaultLogger) enabled(level Level) bool {
+	return l.level >= level
 }
 
 // Info logs the build information of the command or module to the given
 // writer.
-func (*defaultLogger) Info(writer io.Writer, info *info.Info, raw bool) {
+func (l *defaultLogger) Info(writer io.Writer, info *info.Info, raw bool) {
 	if !raw {
-		fmt.Fprintf(writer, "info: %s\n", info)
+		if !l.enabled(LevelVerbose) {
+			return
+		}
+		fmt.Fprintf(writer, "%s %s\n", l.colorize(ColorInfo, "info:"), info)
 	} else {
 		fmt.Fprintf(writer, "%s\n", info)
 	}
 }
 
 // Exec logs the internal command execution for debugging to the given writer.
-func (*defaultLogger) Exec(writer io.Writer, dir string, args ...string) {
+func (l *defaultLogger) Exec(writer io.Writer, dir string, args ...string) {
+	if !l.enabled(LevelVerbose) {
+		return
+	}
+
+	prefix := l.colorize(ColorDebug, "exec:")
 	if len(args) != 0 {
-		fmt.Fprintf(writer, "exec: %s [%s]\n", strings.Join(args, " "), dir)
+		fmt.Fprintf(writer, "%s %s [%s]\n", prefix, strings.Join(args, " "), dir)
 	} else {
-		fmt.Fprintf(writer, "exec: [%s]\n", dir)
+		fmt.Fprintf(writer, "%s [%s]\n", prefix, dir)
 	}
 }
 
 // Call logs the call of the command to the given writer.
-func (*defaultLogger) Call(writer io.Writer, args ...string) {
+func (l *defaultLogger) Call(writer io.Writer, args ...string) {
+	if !l.enabled(LevelVerbose) {
+		return
+	}
+
+	prefix := l.colorize(ColorDebug, "call:")
 	if len(args) != 0 {
-		fmt.Fprintf(writer, "call: %s\n", strings.Join(args, " "))
+		fmt.Fprintf(writer, "%s %s\n", prefix, strings.Join(args, " "))
 	} else {
-		fmt.Fprintf(writer, "call: %s\n", "<no-args>")
+		fmt.Fprintf(writer, "%s %s\n", prefix, "<no-args>")
+	}
+}
+
+// Config logs the resolved go-make config version and directory to the given
+// writer.
+func (l *defaultLogger) Config(writer io.Writer, version, dir string) {
+	if !l.enabled(LevelVerbose) {
+		return
 	}
+
+	fmt.Fprintf(writer, "%s %s [%s]\n",
+		l.colorize(ColorDebug, "config:"), version, dir)
+}
+
+// Timing logs the duration of the named execution step to the given writer.
+func (l *defaultLogger) Timing(
+	writer io.Writer, name string, duration time.Duration,
+) {
+	if !l.enabled(LevelDebug) {
+		return
+	}
+
+	fmt.Fprintf(writer, "%s %s [%s]\n",
+		l.colorize(ColorDebug, "timing:"), name, duration)
 }
 
 // Error logs the given error message and error to the given writer.
-func (*defaultLogger) Error(writer io.Writer, message string, err error) {
+func (l *defaultLogger) Error(writer io.Writer, message string, err error) {
+	prefix := l.colorize(ColorError, "error:")
 	switch {
 	case err != nil && message != "":
-		fmt.Fprintf(writer, "error: %s: %v\n", message, err)
+		fmt.Fprintf(writer, "%s %s: %v\n", prefix, message, err)
 	case message != "":
-		fmt.Fprintf(writer, "error: %s\n", message)
+		fmt.Fprintf(writer, "%s %s\n", prefix, message)
 	case err != nil:
-		fmt.Fprintf(writer, "error: %v\n", err)
+		fmt.Fprintf(writer, "%s %v\n", prefix, err)
 	default:
-		fmt.Fprintf(writer, "error: %s\n", "<no-error>")
+		fmt.Fprintf(writer, "%s %s\n", prefix, "<no-error>")
 	}
 }
 
+// Warning logs the given warning message to the given writer.
+func (l *defaultLogger) Warning(writer io.Writer, message string) {
+	if !l.enabled(LevelDefault) {
+		return
+	}
+
+	fmt.Fprintf(writer, "%s %s\n",
+		l.colorize(ColorWarning, "warning:"), message)
+}
+
 // Message logs the given message to the given writer.
 func (*defaultLogger) Message(writer io.Writer, message string) {
 	if len(message) == 0 || message[len(message)-1] != '\n' {
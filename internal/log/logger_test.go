@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -13,12 +14,22 @@ import (
 )
 
 var (
-	// log is the singleton logger for testing.
-	logger = log.NewLogger()
+	// log is the singleton logger for testing logging all messages.
+	logger = NewLogger(false, log.LevelDebug)
+	// colorLogger is the singleton color logger for testing logging all
+	// messages.
+	colorLogger = NewLogger(true, log.LevelDebug)
 	// infoDirty is an arbitrary dirty info for testing.
 	infoDirty = info.New("", "", "", "", "", "true")
 )
 
+// NewLogger creates a new default logger with given color mode and log level.
+func NewLogger(color bool, level log.Level) log.Logger {
+	logger := log.NewLogger(color)
+	logger.SetLevel(level)
+	return logger
+}
+
 type InfoParams struct {
 	info         *info.Info
 	raw          bool
@@ -133,6 +144,197 @@ func TestCall(t *testing.T) {
 		})
 }
 
+type LevelParams struct {
+	level       log.Level
+	expectLevel log.Level
+}
+
+var levelTestCases = map[string]LevelParams{
+	"quiet level": {
+		level:       log.LevelQuiet,
+		expectLevel: log.LevelQuiet,
+	},
+	"default level": {
+		level:       log.LevelDefault,
+		expectLevel: log.LevelDefault,
+	},
+	"verbose level": {
+		level:       log.LevelVerbose,
+		expectLevel: log.LevelVerbose,
+	},
+	"debug level": {
+		level:       log.LevelDebug,
+		expectLevel: log.LevelDebug,
+	},
+}
+
+func TestLevel(t *testing.T) {
+	test.Map(t, levelTestCases).
+		Run(func(t test.Test, param LevelParams) {
+			// Given
+			logger := log.NewLogger(false)
+			assert.Equal(t, log.LevelDefault, logger.Level())
+
+			// When
+			logger.SetLevel(param.level)
+
+			// Then
+			assert.Equal(t, param.expectLevel, logger.Level())
+		})
+}
+
+type FilterParams struct {
+	level        log.Level
+	expectString string
+}
+
+var filterTestCases = map[string]FilterParams{
+	"quiet level": {
+		level:        log.LevelQuiet,
+		expectString: infoDirty.String() + "\nerror: error\nmessage\n",
+	},
+	"default level": {
+		level: log.LevelDefault,
+		expectString: infoDirty.String() + "\n" +
+			"warning: warning\nerror: error\nmessage\n",
+	},
+	"verbose level": {
+		level: log.LevelVerbose,
+		expectString: "info: " + infoDirty.String() + "\n" +
+			infoDirty.String() + "\nexec: arg [dir]\ncall: arg\n" +
+			"config: custom [dir]\n" +
+			"warning: warning\nerror: error\nmessage\n",
+	},
+	"debug level": {
+		level: log.LevelDebug,
+		expectString: "info: " + infoDirty.String() + "\n" +
+			infoDirty.String() + "\nexec: arg [dir]\ncall: arg\n" +
+			"config: custom [dir]\ntiming: make [1s]\n" +
+			"warning: warning\nerror: error\nmessage\n",
+	},
+}
+
+func TestFilter(t *testing.T) {
+	test.Map(t, filterTestCases).
+		Run(func(t test.Test, param FilterParams) {
+			// Given
+			writer := &strings.Builder{}
+			logger := NewLogger(false, param.level)
+
+			// When
+			logger.Info(writer, infoDirty, false)
+			logger.Info(writer, infoDirty, true)
+			logger.Exec(writer, "dir", "arg")
+			logger.Call(writer, "arg")
+			logger.Config(writer, "custom", "dir")
+			logger.Timing(writer, "make", time.Second)
+			logger.Warning(writer, "warning")
+			logger.Error(writer, "error", nil)
+			logger.Message(writer, "message")
+
+			// Then
+			assert.Equal(t, param.expectString, writer.String())
+		})
+}
+
+type ConfigParams struct {
+	version      string
+	dir          string
+	expectString string
+}
+
+var configTestCases = map[string]ConfigParams{
+	"empty config": {
+		expectString: "config:  []\n",
+	},
+	"custom config": {
+		version:      "custom",
+		dir:          "config",
+		expectString: "config: custom [config]\n",
+	},
+	"version config": {
+		version:      "v0.0.1",
+		dir:          "/go/pkg/mod/github.com/tkrop/go-make@v0.0.1/config",
+		expectString: "config: v0.0.1 " +
+			"[/go/pkg/mod/github.com/tkrop/go-make@v0.0.1/config]\n",
+	},
+}
+
+func TestConfig(t *testing.T) {
+	test.Map(t, configTestCases).
+		Run(func(t test.Test, param ConfigParams) {
+			// Given
+			writer := &strings.Builder{}
+
+			// When
+			logger.Config(writer, param.version, param.dir)
+
+			// Then
+			assert.Equal(t, param.expectString, writer.String())
+		})
+}
+
+type TimingParams struct {
+	name         string
+	duration     time.Duration
+	expectString string
+}
+
+var timingTestCases = map[string]TimingParams{
+	"zero duration": {
+		name:         "setup",
+		expectString: "timing: setup [0s]\n",
+	},
+	"millis duration": {
+		name:         "make",
+		duration:     1500 * time.Millisecond,
+		expectString: "timing: make [1.5s]\n",
+	},
+}
+
+func TestTiming(t *testing.T) {
+	test.Map(t, timingTestCases).
+		Run(func(t test.Test, param TimingParams) {
+			// Given
+			writer := &strings.Builder{}
+
+			// When
+			logger.Timing(writer, param.name, param.duration)
+
+			// Then
+			assert.Equal(t, param.expectString, writer.String())
+		})
+}
+
+type WarningParams struct {
+	message      string
+	expectString string
+}
+
+var warningTestCases = map[string]WarningParams{
+	"empty message": {
+		expectString: "warning: \n",
+	},
+	"non-empty message": {
+		message:      "message",
+		expectString: "warning: message\n",
+	},
+}
+
+func TestWarning(t *testing.T) {
+	test.Map(t, warningTestCases).
+		Run(func(t test.Test, param WarningParams) {
+			// Given
+			writer := &strings.Builder{}
+
+			// When
+			logger.Warning(writer, param.message)
+
+			// Then
+			assert.Equal(t, param.expectString, writer.String())
+		})
+}
+
 type ErrorParams struct {
 	message      string
 	error        error
@@ -0,0 +1,231 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/bench"
+)
+
+const (
+	// CmdTestBenchCompare provides the name of the native test-bench-compare
+	// command.
+	CmdTestBenchCompare = "test-bench-compare"
+	// EnvTestBench provides the name of the makefile variable containing the
+	// benchmark results file.
+	EnvTestBench = "TEST_BENCH"
+	// EnvTestBaseline provides the name of the makefile variable containing
+	// the directory of the benchmark baselines.
+	EnvTestBaseline = "TEST_BASELINE"
+	// EnvTestThresholds provides the name of the makefile variable containing
+	// the benchmark regression thresholds.
+	EnvTestThresholds = "TEST_THRESHOLDS"
+	// DefaultTestBench provides the default benchmark results file.
+	DefaultTestBench = "build/test.bench"
+	// DefaultTestBaseline provides the default directory of the benchmark
+	// baselines.
+	DefaultTestBaseline = "build/baseline"
+)
+
+var (
+	// ErrNoBranch represents an error for a missing baseline branch.
+	ErrNoBranch = errors.New("no branch")
+	// ErrBenchRegression represents a benchmark regression.
+	ErrBenchRegression = errors.New("benchmark regression")
+)
+
+// NewErrBenchRegression creates a benchmark regression error for the given
+// number of regressions compared to the baseline of the given branch.
+func NewErrBenchRegression(count int, branch string) error {
+	return fmt.Errorf("%w [count=%d, baseline=%s]",
+		ErrBenchRegression, count, branch)
+}
+
+// benchArgs contains the parsed arguments of the test-bench-compare command.
+type benchArgs struct {
+	// file provides the benchmark results file, if any.
+	file string
+	// branch provides the branch of the baseline, if any.
+	branch string
+	// save indicates whether to save the results as baseline.
+	save bool
+}
+
+// parseBenchCompare parses the arguments of the test-bench-compare command.
+func parseBenchCompare(args ...string) (*benchArgs, error) {
+	params := &benchArgs{}
+	for _, arg := range args {
+		switch {
+		case arg == "--save":
+			params.save = true
+		case strings.HasPrefix(arg, "--branch=") && arg != "--branch=":
+			params.branch = arg[len("--branch="):]
+		case params.file == "" && !strings.HasPrefix(arg, "-"):
+			params.file = arg
+		default:
+			return nil, NewErrInvalidArg(CmdTestBenchCompare, arg, nil)
+		}
+	}
+	return params, nil
+}
+
+// testBenchCompare runs the native test-bench-compare command with given
+// arguments. It compares the benchmark results of `test-bench` with the
+// baseline of the current branch, or of the default branch, if the current
+// branch has no baseline, and fails if a benchmark regresses beyond the
+// `TEST_THRESHOLDS`. With `--save` the results are stored as baseline of the
+// branch in the `TEST_BASELINE` directory instead.
+func (gm *GoMake) testBenchCompare(args ...string) (int, error) {
+	params, err := parseBenchCompare(args...)
+	if err != nil {
+		gm.error("parse test-bench-compare", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	values, err := gm.variables(ctx, map[string]string{
+		EnvTestBench:      DefaultTestBench,
+		EnvTestBaseline:   DefaultTestBaseline,
+		EnvTestThresholds: bench.DefaultThresholds,
+	})
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+	thresholds, err := bench.ParseThresholds(values[EnvTestThresholds])
+	if err != nil {
+		gm.error("parse thresholds", err)
+		return ExitCommandFailure, err
+	}
+
+	if params.file == "" {
+		params.file = values[EnvTestBench]
+	}
+	data, results, err := gm.readBench(params.file)
+	if err != nil {
+		gm.error("read benchmarks", err)
+		return ExitCommandFailure, err
+	}
+
+	branches, err := gm.benchBranches(ctx, params.branch)
+	if err != nil {
+		gm.error("read branch", err)
+		return ExitCommandFailure, err
+	}
+	dir := values[EnvTestBaseline]
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gm.WorkDir, dir)
+	}
+
+	if params.save {
+		file := benchBaseline(dir, branches[0])
+		if err := saveBaseline(file, data); err != nil {
+			gm.error("save baseline", err)
+			return ExitCommandFailure, err
+		}
+		gm.Logger.Message(gm.Stdout, fmt.Sprintf(
+			"saved baseline [branch=%s, file=%s]", branches[0], file))
+		return ExitSuccess, nil
+	}
+	return gm.compareBaseline(dir, branches, results, thresholds)
+}
+
+// compareBaseline compares the given benchmark results with the baseline of
+// the first of the given branches having a baseline in the given directory
+// using the given thresholds and reports the deltas.
+func (gm *GoMake) compareBaseline(
+	dir string, branches []string,
+	results bench.Results, thresholds bench.Thresholds,
+) (int, error) {
+	for _, branch := range branches {
+		_, base, err := gm.readBench(benchBaseline(dir, branch))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			gm.error("read baseline", err)
+			return ExitCommandFailure, err
+		}
+
+		regressions := 0
+		for _, delta := range bench.Compare(base, results, thresholds) {
+			gm.Logger.Message(gm.Stdout, delta.String())
+			if delta.Regression {
+				regressions++
+			}
+		}
+		if regressions != 0 {
+			err := NewErrBenchRegression(regressions, branch)
+			gm.error(CmdTestBenchCompare, err)
+			return ExitCommandFailure, err
+		}
+		return ExitSuccess, nil
+	}
+
+	gm.Logger.Warning(gm.Stderr, fmt.Sprintf(
+		"no baseline [dir=%s, branches=%s]", dir, strings.Join(branches, " ")))
+	return ExitSuccess, nil
+}
+
+// benchBranches returns the branches of the baselines in order of precedence,
+// i.e. the given branch, or the current branch and the default branch.
+func (gm *GoMake) benchBranches(
+	ctx context.Context, branch string,
+) ([]string, error) {
+	if branch != "" {
+		return []string{branch}, nil
+	}
+
+	branches := []string{}
+	if branch, err := gm.gitOutput(ctx,
+		CmdGitBranch(gm.WorkDir, gm.Env...)); err == nil && branch != "" {
+		branches = append(branches, branch)
+	}
+	if main, err := gm.gitMainBranch(ctx); err == nil &&
+		!slices.Contains(branches, main) {
+		branches = append(branches, main)
+	}
+	if len(branches) == 0 {
+		return nil, ErrNoBranch
+	}
+	return branches, nil
+}
+
+// readBench reads the given benchmark results file relative to the working
+// directory returning the raw data and the parsed results.
+func (gm *GoMake) readBench(file string) ([]byte, bench.Results, error) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(gm.WorkDir, file)
+	}
+	// #nosec G304 -- file is a benchmark results file of the project.
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err //nolint:wrapcheck // wrapped by caller.
+	}
+	results, err := bench.Parse(bytes.NewReader(data))
+	return data, results, err
+}
+
+// benchBaseline returns the baseline file of the given branch in the given
+// baseline directory.
+func benchBaseline(dir, branch string) string {
+	return filepath.Join(dir, strings.ReplaceAll(branch, "/", "-")+".bench")
+}
+
+// saveBaseline saves the given benchmark results data to the given baseline
+// file creating the baseline directory, if necessary.
+func saveBaseline(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	//nolint:wrapcheck // wrapped by caller.
+	return os.WriteFile(file, data, 0o644)
+}
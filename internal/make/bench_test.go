@@ -0,0 +1,365 @@
+package make_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/bench"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// benchHead contains the current benchmark results.
+	benchHead = "pkg: example.com/bench\n" +
+		"BenchmarkRun-8 100 105 ns/op 16 B/op 1 allocs/op\nPASS\n"
+	// benchBase contains baseline benchmark results without regression.
+	benchBase = "pkg: example.com/bench\n" +
+		"BenchmarkRun-4 100 100 ns/op 16 B/op 1 allocs/op\nPASS\n"
+	// benchSlow contains baseline benchmark results with regression.
+	benchSlow = "pkg: example.com/bench\n" +
+		"BenchmarkRun-4 100 50 ns/op 16 B/op 1 allocs/op\nPASS\n"
+)
+
+var (
+	// envBench contains the environment of the benchmark comparison.
+	envBench = []string{
+		EnvTestBench + "=" + DefaultTestBench,
+		EnvTestBaseline + "=" + DefaultTestBaseline,
+		EnvTestThresholds + "=" + bench.DefaultThresholds,
+	}
+
+	// logBenchOK contains the log of the benchmark deltas without regression.
+	logBenchOK = mock.Chain(
+		LogMessage("stdout", "ok BenchmarkRun ns/op [pkg=example.com/bench, "+
+			"base=100, head=105, delta=+5.00%, p=n/a, n=1+1, threshold=10%]"),
+		LogMessage("stdout", "ok BenchmarkRun B/op [pkg=example.com/bench, "+
+			"base=16, head=16, delta=+0.00%, p=n/a, n=1+1, threshold=10%]"),
+		LogMessage("stdout", "ok BenchmarkRun allocs/op [pkg=example.com/bench, "+
+			"base=1, head=1, delta=+0.00%, p=n/a, n=1+1, threshold=10%]"),
+	)
+)
+
+// DirBench returns the project directory of the benchmark comparison test
+// case with given name.
+func DirBench(name string) string {
+	return filepath.Join(dirTargets, "bench", name)
+}
+
+// FileBaseline returns the baseline file of the given branch of the
+// benchmark comparison test case with given name.
+func FileBaseline(name, branch string) string {
+	return filepath.Join(DirBench(name), DefaultTestBaseline, branch+".bench")
+}
+
+// BenchSetup sets up the git commands of the benchmark comparison test case
+// with given name using the given environment, providing the given current
+// branch on the default branch `main`.
+func BenchSetup(name, branch string, env ...string) func(*mock.Mocks) any {
+	dir := DirBench(name)
+	return mock.Chain(
+		Exec(CmdGitTop(dirWork, env...), "nil", "builder", "stderr",
+			dir, "", nil),
+		Exec(CmdGitBranch(dir, env...),
+			"nil", "builder", "stderr", branch+"\n", "", nil),
+		Exec(CmdGitRemote(dir, env...), "nil", "builder", "stderr",
+			"* remote origin\n  HEAD branch: main\n", "", nil),
+	)
+}
+
+type TestBenchCompareParams struct {
+	mockSetup   mock.SetupFunc
+	dir         string
+	env         []string
+	args        []string
+	files       map[string]string
+	expectFiles map[string]string
+	expectError error
+	expectExit  int
+}
+
+var testBenchCompareTestCases = map[string]TestBenchCompareParams{
+	"compare branch baseline": {
+		mockSetup: mock.Chain(
+			BenchSetup("branch", "feature/bench", envBench...),
+			logBenchOK,
+		),
+		dir:  "branch",
+		env:  envBench,
+		args: []string{"go-make", "--test-bench-compare"},
+		files: map[string]string{
+			DefaultTestBench: benchHead,
+			DefaultTestBaseline + "/feature-bench.bench": benchBase,
+			DefaultTestBaseline + "/main.bench":          benchSlow,
+		},
+	},
+	"compare default baseline": {
+		mockSetup: mock.Chain(
+			BenchSetup("default", "feature/bench", envBench...),
+			LogMessage("stdout", "regressed BenchmarkRun ns/op "+
+				"[pkg=example.com/bench, base=50, head=105, delta=+110.00%, "+
+				"p=n/a, n=1+1, threshold=10%]"),
+			LogMessage("stdout", "ok BenchmarkRun B/op "+
+				"[pkg=example.com/bench, base=16, head=16, delta=+0.00%, "+
+				"p=n/a, n=1+1, threshold=10%]"),
+			LogMessage("stdout", "ok BenchmarkRun allocs/op "+
+				"[pkg=example.com/bench, base=1, head=1, delta=+0.00%, "+
+				"p=n/a, n=1+1, threshold=10%]"),
+			LogError("stderr", CmdTestBenchCompare,
+				NewErrBenchRegression(1, "main")),
+		),
+		dir:  "default",
+		env:  envBench,
+		args: []string{"go-make", "--test-bench-compare"},
+		files: map[string]string{
+			DefaultTestBench:                    benchHead,
+			DefaultTestBaseline + "/main.bench": benchSlow,
+		},
+		expectError: NewErrBenchRegression(1, "main"),
+		expectExit:  ExitCommandFailure,
+	},
+	"compare explicit branch and file": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envBench...), "nil", "builder",
+				"stderr", DirBench("explicit"), "", nil),
+			logBenchOK,
+		),
+		dir: "explicit",
+		env: envBench,
+		args: []string{
+			"go-make", "--test-bench-compare", "--branch=release/v1",
+			"custom.bench",
+		},
+		files: map[string]string{
+			"custom.bench": benchHead,
+			DefaultTestBaseline + "/release-v1.bench": benchBase,
+			DefaultTestBaseline + "/main.bench":       benchSlow,
+		},
+	},
+	"compare without baseline": {
+		mockSetup: mock.Chain(
+			BenchSetup("missing", "main", envBench...),
+			LogWarning("stderr", "no baseline [dir="+filepath.Join(
+				DirBench("missing"), DefaultTestBaseline)+", branches=main]"),
+		),
+		dir:   "missing",
+		env:   envBench,
+		args:  []string{"go-make", "--test-bench-compare"},
+		files: map[string]string{DefaultTestBench: benchHead},
+	},
+	"compare with config": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirBench("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirBench("config")),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, DirBench("config")),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvTestThresholds+" := ns/op=200%\n", "", nil),
+			Exec(CmdGitBranch(DirBench("config")),
+				"nil", "builder", "stderr", "", "", nil),
+			Exec(CmdGitRemote(DirBench("config")), "nil", "builder",
+				"stderr", "* remote origin\n  HEAD branch: main\n", "", nil),
+			LogMessage("stdout", "ok BenchmarkRun ns/op "+
+				"[pkg=example.com/bench, base=50, head=105, delta=+110.00%, "+
+				"p=n/a, n=1+1, threshold=200%]"),
+			LogMessage("stdout", "ok BenchmarkRun B/op "+
+				"[pkg=example.com/bench, base=16, head=16, delta=+0.00%, "+
+				"p=n/a, n=1+1]"),
+			LogMessage("stdout", "ok BenchmarkRun allocs/op "+
+				"[pkg=example.com/bench, base=1, head=1, delta=+0.00%, "+
+				"p=n/a, n=1+1]"),
+		),
+		dir:  "config",
+		args: []string{"go-make", "--test-bench-compare"},
+		files: map[string]string{
+			DefaultTestBench:                    benchHead,
+			DefaultTestBaseline + "/main.bench": benchSlow,
+		},
+	},
+	"save baseline": {
+		mockSetup: mock.Chain(
+			BenchSetup("save", "feature/bench", envBench...),
+			LogMessage("stdout", "saved baseline [branch=feature/bench, "+
+				"file="+FileBaseline("save", "feature-bench")+"]"),
+		),
+		dir:   "save",
+		env:   envBench,
+		args:  []string{"go-make", "--test-bench-compare", "--save"},
+		files: map[string]string{DefaultTestBench: benchHead},
+		expectFiles: map[string]string{
+			DefaultTestBaseline + "/feature-bench.bench": benchHead,
+		},
+	},
+
+	"save baseline failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envBench...), "nil", "builder",
+				"stderr", DirBench("save-failed"), "", nil),
+			LogError("stderr", "save baseline", &fs.PathError{
+				Op: "mkdir", Path: filepath.Join(DirBench("save-failed"),
+					DefaultTestBaseline), Err: syscall.ENOTDIR,
+			}),
+		),
+		dir: "save-failed",
+		env: envBench,
+		args: []string{
+			"go-make", "--test-bench-compare", "--save", "--branch=main",
+		},
+		files: map[string]string{
+			DefaultTestBench:    benchHead,
+			DefaultTestBaseline: "",
+		},
+		expectError: &fs.PathError{
+			Op: "mkdir", Path: filepath.Join(DirBench("save-failed"),
+				DefaultTestBaseline), Err: syscall.ENOTDIR,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"read baseline failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envBench...), "nil", "builder",
+				"stderr", DirBench("baseline-failed"), "", nil),
+			LogError("stderr", "read baseline", &fs.PathError{
+				Op: "read", Path: FileBaseline("baseline-failed", "main"),
+				Err: syscall.EISDIR,
+			}),
+		),
+		dir:  "baseline-failed",
+		env:  envBench,
+		args: []string{"go-make", "--test-bench-compare", "--branch=main"},
+		files: map[string]string{
+			DefaultTestBench:                         benchHead,
+			DefaultTestBaseline + "/main.bench/file": "",
+		},
+		expectError: &fs.PathError{
+			Op: "read", Path: FileBaseline("baseline-failed", "main"),
+			Err: syscall.EISDIR,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"read benchmarks failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envBench...), "nil", "builder",
+				"stderr", DirBench("bench-failed"), "", nil),
+			LogError("stderr", "read benchmarks", &fs.PathError{
+				Op: "open", Path: filepath.Join(DirBench("bench-failed"),
+					DefaultTestBench), Err: syscall.ENOENT,
+			}),
+		),
+		dir:  "bench-failed",
+		env:  envBench,
+		args: []string{"go-make", "--test-bench-compare"},
+		expectError: &fs.PathError{
+			Op: "open", Path: filepath.Join(DirBench("bench-failed"),
+				DefaultTestBench), Err: syscall.ENOENT,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"read branch failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envBench...), "nil", "builder",
+				"stderr", DirBench("branch-failed"), "", nil),
+			Exec(CmdGitBranch(DirBench("branch-failed"), envBench...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			Exec(CmdGitRemote(DirBench("branch-failed"), envBench...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogError("stderr", "read branch", ErrNoBranch),
+		),
+		dir:         "branch-failed",
+		env:         envBench,
+		args:        []string{"go-make", "--test-bench-compare"},
+		files:       map[string]string{DefaultTestBench: benchHead},
+		expectError: ErrNoBranch,
+		expectExit:  ExitCommandFailure,
+	},
+	"invalid thresholds": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTestBench+"=build/test.bench",
+				EnvTestBaseline+"=build/baseline",
+				EnvTestThresholds+"=ns/op"), "nil", "builder", "stderr",
+				DirBench("thresholds"), "", nil),
+			LogError("stderr", "parse thresholds",
+				bench.NewErrInvalidThreshold("ns/op", nil)),
+		),
+		dir: "thresholds",
+		env: []string{
+			EnvTestBench + "=build/test.bench",
+			EnvTestBaseline + "=build/baseline",
+			EnvTestThresholds + "=ns/op",
+		},
+		args:        []string{"go-make", "--test-bench-compare"},
+		expectError: bench.NewErrInvalidThreshold("ns/op", nil),
+		expectExit:  ExitCommandFailure,
+	},
+	"config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirBench("config-failed"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirBench("config-failed")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirBench("config-failed")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirBench("config-failed")), assert.AnError))),
+		),
+		dir:  "config-failed",
+		args: []string{"go-make", "--test-bench-compare"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirBench("config-failed")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"invalid argument": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse test-bench-compare",
+				NewErrInvalidArg(CmdTestBenchCompare, "--branch=", nil)),
+		),
+		args: []string{"go-make", "--test-bench-compare", "--branch="},
+		expectError: NewErrInvalidArg(CmdTestBenchCompare,
+			"--branch=", nil),
+		expectExit: ExitCommandFailure,
+	},
+}
+
+func TestTestBenchCompare(t *testing.T) {
+	test.Map(t, testBenchCompareTestCases).
+		Run(func(t test.Test, param TestBenchCompareParams) {
+			// Given
+			dir := DirBench(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				WriteFile(path, 0o600, content)
+			}
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			for name, content := range param.expectFiles {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				assert.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+		})
+}
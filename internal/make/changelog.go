@@ -0,0 +1,225 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/changelog"
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/verify"
+)
+
+const (
+	// CmdVersionChangelog provides the name of the native version-changelog
+	// command.
+	CmdVersionChangelog = "version-changelog"
+	// FileChangelog provides the default name of the project changelog file.
+	FileChangelog = "CHANGELOG.md"
+)
+
+// CmdGitDescribe creates the argument array of a `git describe` command to
+// get the last release tag matching `v*` reachable from `HEAD`.
+func CmdGitDescribe(dir string, env ...string) *cmd.Cmd {
+	return cmd.New("git", "describe", "--tags", "--abbrev=0", "--match=v*").
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// changelogArgs contains the parsed arguments of the version-changelog
+// command.
+type changelogArgs struct {
+	// rng provides the revision range of the changelog.
+	rng string
+	// version provides the version of the changelog section.
+	version string
+	// file provides the changelog file to prepend the section to, if any.
+	file string
+	// json indicates whether to render the changelog as JSON.
+	json bool
+}
+
+// parseChangelog parses the arguments of the version-changelog command.
+func parseChangelog(args ...string) (*changelogArgs, error) {
+	params := &changelogArgs{}
+	for _, arg := range args {
+		switch {
+		case arg == "--json" && params.file == "":
+			params.json = true
+		case arg == "--prepend" && !params.json:
+			params.file = FileChangelog
+		case strings.HasPrefix(arg, "--prepend=") && !params.json &&
+			arg != "--prepend=":
+			params.file = arg[len("--prepend="):]
+		case strings.HasPrefix(arg, "--version=") && arg != "--version=":
+			params.version = arg[len("--version="):]
+		case params.rng == "" && !strings.HasPrefix(arg, "-"):
+			params.rng = arg
+		default:
+			return nil, NewErrInvalidArg(CmdVersionChangelog, arg, nil)
+		}
+	}
+	return params, nil
+}
+
+// versionChangelog runs the native version-changelog command with given
+// arguments. It creates the changelog of the conventional commits between the
+// last `v*` release tag and `HEAD`, or of the given revision range, grouped by
+// the commit types of the project. The changelog is rendered as Markdown or
+// JSON, or prepended as new section to the project `CHANGELOG.md` file.
+func (gm *GoMake) versionChangelog(args ...string) (int, error) {
+	params, err := parseChangelog(args...)
+	if err != nil {
+		gm.error("parse version-changelog", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	if params.rng == "" {
+		params.rng = gm.changelogRange(ctx)
+	}
+	if params.version == "" {
+		if params.version, err = gm.changelogVersion(); err != nil {
+			gm.error("read version", err)
+			return ExitCommandFailure, err
+		}
+	}
+
+	commits, err := gm.gitCommits(ctx, params.rng)
+	if err != nil {
+		gm.error("read commits", err)
+		return ExitCommandFailure, err
+	}
+
+	verifier, exit, err := gm.changeVerifier(ctx)
+	if err != nil {
+		return exit, err
+	}
+
+	changes := make([]*verify.Change, 0, len(commits))
+	for _, commit := range commits {
+		changes = append(changes, verifier.Change(commit))
+	}
+	return gm.changelogOutput(params, changelog.New(
+		params.version, params.rng, verifier.Types(), changes...))
+}
+
+// changelogOutput renders the given changelog as Markdown or JSON, or
+// prepends it to the changelog file as requested by the given arguments.
+func (gm *GoMake) changelogOutput(
+	params *changelogArgs, log *changelog.Changelog,
+) (int, error) {
+	switch {
+	case params.json:
+		encoder := json.NewEncoder(gm.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(log)
+	case params.file != "":
+		file := params.file
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(gm.WorkDir, file)
+		}
+		if err := prependChangelog(file, log); err != nil {
+			gm.error("write changelog", err)
+			return ExitCommandFailure, err
+		}
+		gm.Logger.Message(gm.Stdout, fmt.Sprintf(
+			"prepended changelog [file=%s, version=%s]",
+			params.file, log.Version))
+	default:
+		_, _ = io.WriteString(gm.Stdout, log.Markdown())
+	}
+	return ExitSuccess, nil
+}
+
+// changelogRange returns the revision range from the last `v*` release tag
+// to `HEAD`, or `HEAD`, if no release tag exists.
+func (gm *GoMake) changelogRange(ctx context.Context) string {
+	output := &strings.Builder{}
+	if err := gm.exec(ctx, CmdGitDescribe(gm.WorkDir, gm.Env...).
+		WithIO(nil, output, io.Discard)); err == nil {
+		if tag := strings.TrimSpace(output.String()); tag != "" {
+			return tag + "..HEAD"
+		}
+	}
+	return "HEAD"
+}
+
+// gitCommits returns the commits of the given revision range excluding
+// merges as provided by the git log.
+func (gm *GoMake) gitCommits(
+	ctx context.Context, rng string,
+) ([]*verify.Commit, error) {
+	log := &strings.Builder{}
+	if err := gm.exec(ctx, CmdGitLog([]string{rng}, gm.WorkDir, gm.Env...).
+		WithIO(nil, log, gm.Stderr)); err != nil {
+		return nil, err
+	}
+	return verify.ParseLog(rng, strings.NewReader(log.String()))
+}
+
+// changeVerifier returns the verifier to parse the conventional commits of
+// the project using the project commit rules, where the commit types default
+// to the `COMMIT_CONVENTION` makefile variable.
+func (gm *GoMake) changeVerifier(
+	ctx context.Context,
+) (*verify.Verifier, int, error) {
+	rules, err := gm.verifyRules()
+	if err != nil {
+		gm.error("read rules", err)
+		return nil, ExitCommandFailure, err
+	}
+	if len(rules.Types) == 0 {
+		types, err := gm.variable(ctx,
+			EnvCommitConvention, verify.DefaultTypes)
+		if err != nil {
+			gm.error("ensure config", err)
+			return nil, ExitConfigFailure, err
+		}
+		rules.Types = strings.Fields(types)
+	}
+	verifier, err := verify.NewVerifier(rules, "")
+	if err != nil {
+		gm.error("verify rules", err)
+		return nil, ExitCommandFailure, err
+	}
+	return verifier, ExitSuccess, nil
+}
+
+// changelogVersion returns the version of the changelog section as provided
+// by the project `VERSION` file, or `Unreleased`, if the file does not exist.
+func (gm *GoMake) changelogVersion() (string, error) {
+	file := filepath.Join(gm.WorkDir, FileVersion)
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		return changelog.Unreleased, nil
+	}
+	version, _, err := gm.readVersion()
+	if err != nil {
+		return "", err
+	}
+	return "v" + version.String(), nil
+}
+
+// prependChangelog prepends the given changelog as new section to the given
+// changelog file, that is created, if it does not exist.
+func prependChangelog(file string, log *changelog.Changelog) error {
+	// #nosec G304 -- file is provided by the project.
+	data, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	content, err := log.Prepend(string(data))
+	if err != nil {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	//nolint:wrapcheck // wrapped by caller.
+	return os.WriteFile(file, []byte(content), 0o644)
+}
@@ -0,0 +1,408 @@
+package make_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/changelog"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/semver"
+	"github.com/tkrop/go-make/internal/verify"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// envChangelog contains the environment providing the commit convention.
+	envChangelog = []string{
+		EnvCommitConvention + "=" + verify.DefaultTypes,
+	}
+
+	// logChangelog contains a raw git log with conventional commits.
+	logChangelog = "commit abc123def456\ntree 123abc\n\n" +
+		"    feat(make)!: add changelog (#1)\n\n" +
+		"    BREAKING CHANGE: changelog required\n\n" +
+		"    Signed-off-by: " + authorVerify + "\n\n" +
+		"commit def456abc123\ntree 456def\n\n" +
+		"    fix: repair changelog (#2)\n\n" +
+		"commit 123abc\ntree abc123\n\n" +
+		"    update readme\n"
+
+	// mdChangelog contains the Markdown changelog of the raw git log without
+	// version heading.
+	mdChangelog = "\n### Breaking changes\n\n" +
+		"* **make:** changelog required (#1) (abc123d)\n\n" +
+		"### Features\n\n" +
+		"* **make:** add changelog (#1) (abc123d)\n\n" +
+		"### Bug fixes\n\n" +
+		"* repair changelog (#2) (def456a)\n\n" +
+		"### Other changes\n\n" +
+		"* update readme (123abc)\n"
+
+	// jsonChangelog contains the JSON changelog of the raw git log.
+	jsonChangelog = `{
+  "version": "v1.1.0",
+  "range": "v1.0.0..v1.1.0",
+  "breaking": [
+    {
+      "commit": "abc123def456",
+      "type": "feat",
+      "scope": "make",
+      "subject": "add changelog",
+      "issues": [
+        "#1"
+      ],
+      "breaking": "changelog required"
+    }
+  ],
+  "groups": [
+    {
+      "type": "feat",
+      "title": "Features",
+      "changes": [
+        {
+          "commit": "abc123def456",
+          "type": "feat",
+          "scope": "make",
+          "subject": "add changelog",
+          "issues": [
+            "#1"
+          ],
+          "breaking": "changelog required"
+        }
+      ]
+    },
+    {
+      "type": "fix",
+      "title": "Bug fixes",
+      "changes": [
+        {
+          "commit": "def456abc123",
+          "type": "fix",
+          "subject": "repair changelog",
+          "issues": [
+            "#2"
+          ]
+        }
+      ]
+    },
+    {
+      "type": "",
+      "title": "Other changes",
+      "changes": [
+        {
+          "commit": "123abc",
+          "subject": "update readme"
+        }
+      ]
+    }
+  ],
+  "issues": [
+    "#1",
+    "#2"
+  ]
+}
+`
+)
+
+// DirChangelog returns the project directory of the changelog test case with
+// given name.
+func DirChangelog(name string) string {
+	return filepath.Join(dirTargets, "changelog", name)
+}
+
+// ChangelogSetup sets up the working directory of the changelog test case
+// with given name using the given environment.
+func ChangelogSetup(name string, env ...string) mock.SetupFunc {
+	return Exec(CmdGitTop(dirWork, env...),
+		"nil", "builder", "stderr", DirChangelog(name), "", nil)
+}
+
+type VersionChangelogParams struct {
+	mockSetup    mock.SetupFunc
+	dir          string
+	env          []string
+	args         []string
+	files        map[string]string
+	expectFiles  map[string]string
+	expectStdout string
+	expectError  error
+	expectExit   int
+}
+
+var versionChangelogTestCases = map[string]VersionChangelogParams{
+	"markdown since last tag": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("markdown", envChangelog...),
+			Exec(CmdGitDescribe(DirChangelog("markdown"), envChangelog...),
+				"nil", "builder", "discard", "v1.0.0\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.0.0..HEAD"},
+				DirChangelog("markdown"), envChangelog...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+		),
+		dir:          "markdown",
+		env:          envChangelog,
+		args:         []string{"go-make", "--version-changelog"},
+		expectStdout: "## " + changelog.Unreleased + "\n" + mdChangelog,
+	},
+	"markdown without tag": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("untagged", envChangelog...),
+			Exec(CmdGitDescribe(DirChangelog("untagged"), envChangelog...),
+				"nil", "builder", "discard", "", "", assert.AnError),
+			Exec(CmdGitLog([]string{"HEAD"},
+				DirChangelog("untagged"), envChangelog...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+		),
+		dir:          "untagged",
+		env:          envChangelog,
+		args:         []string{"go-make", "--version-changelog"},
+		files:        map[string]string{"VERSION": "1.1.0\n"},
+		expectStdout: "## v1.1.0\n" + mdChangelog,
+	},
+	"json with range and version": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("json", envChangelog...),
+			Exec(CmdGitLog([]string{"v1.0.0..v1.1.0"},
+				DirChangelog("json"), envChangelog...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+		),
+		dir: "json",
+		env: envChangelog,
+		args: []string{
+			"go-make", "--version-changelog", "--json",
+			"--version=v1.1.0", "v1.0.0..v1.1.0",
+		},
+		expectStdout: jsonChangelog,
+	},
+	"prepend changelog": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("prepend", envChangelog...),
+			Exec(CmdGitLog([]string{"v1.0.0..HEAD"},
+				DirChangelog("prepend"), envChangelog...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+			LogMessage("stdout", "prepended changelog "+
+				"[file=CHANGELOG.md, version=v1.1.0]"),
+		),
+		dir: "prepend",
+		env: envChangelog,
+		args: []string{
+			"go-make", "--version-changelog", "--prepend", "v1.0.0..HEAD",
+		},
+		files: map[string]string{
+			"VERSION":      "1.1.0\n",
+			"CHANGELOG.md": "# Changelog\n\n## v1.0.0\n\n* initial\n",
+		},
+		expectFiles: map[string]string{
+			"CHANGELOG.md": "# Changelog\n\n## v1.1.0\n" + mdChangelog +
+				"\n## v1.0.0\n\n* initial\n",
+		},
+	},
+	"prepend new changelog": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("create", envChangelog...),
+			Exec(CmdGitLog([]string{"HEAD"},
+				DirChangelog("create"), envChangelog...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+			LogMessage("stdout", "prepended changelog "+
+				"[file=NOTES.md, version=v1.1.0]"),
+		),
+		dir: "create",
+		env: envChangelog,
+		args: []string{
+			"go-make", "--version-changelog", "--prepend=NOTES.md",
+			"--version=v1.1.0", "HEAD",
+		},
+		expectFiles: map[string]string{
+			"NOTES.md": "# Changelog\n\n## v1.1.0\n" + mdChangelog,
+		},
+	},
+	"prepend section exists": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("exists", envChangelog...),
+			Exec(CmdGitLog([]string{"HEAD"},
+				DirChangelog("exists"), envChangelog...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+			LogError("stderr", "write changelog",
+				changelog.NewErrSectionExists("v1.0.0")),
+		),
+		dir: "exists",
+		env: envChangelog,
+		args: []string{
+			"go-make", "--version-changelog", "--prepend", "HEAD",
+		},
+		files: map[string]string{
+			"VERSION":      "1.0.0\n",
+			"CHANGELOG.md": "# Changelog\n\n## v1.0.0\n\n* initial\n",
+		},
+		expectFiles: map[string]string{
+			"CHANGELOG.md": "# Changelog\n\n## v1.0.0\n\n* initial\n",
+		},
+		expectError: changelog.NewErrSectionExists("v1.0.0"),
+		expectExit:  ExitCommandFailure,
+	},
+
+	"types from config": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("config"),
+			Exec(CmdGitLog([]string{"HEAD"}, DirChangelog("config")),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirChangelog("config")),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, DirChangelog("config")),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvCommitConvention+" := fix chore\n", "", nil),
+		),
+		dir: "config",
+		args: []string{
+			"go-make", "--version-changelog", "--version=v1.1.0", "HEAD",
+		},
+		expectStdout: "## v1.1.0\n\n### Breaking changes\n\n" +
+			"* **make:** changelog required (#1) (abc123d)\n\n" +
+			"### Bug fixes\n\n" +
+			"* repair changelog (#2) (def456a)\n\n" +
+			"### Other changes\n\n" +
+			"* **make:** add changelog (#1) (abc123d)\n" +
+			"* update readme (123abc)\n",
+	},
+	"types config failed": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("config"),
+			Exec(CmdGitLog([]string{"HEAD"}, DirChangelog("config")),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirChangelog("config")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirChangelog("config")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirChangelog("config")), assert.AnError))),
+		),
+		dir: "config",
+		args: []string{
+			"go-make", "--version-changelog", "--version=v1.1.0", "HEAD",
+		},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirChangelog("config")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"rules unknown": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("rules", EnvRules("rules-unknown.json")...),
+			Exec(CmdGitLog([]string{"HEAD"}, DirChangelog("rules"),
+				EnvRules("rules-unknown.json")...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+			LogError("stderr", "read rules", ErrRulesUnknown()),
+		),
+		dir: "rules",
+		env: EnvRules("rules-unknown.json"),
+		args: []string{
+			"go-make", "--version-changelog", "--version=v1.1.0", "HEAD",
+		},
+		expectError: ErrRulesUnknown(),
+		expectExit:  ExitCommandFailure,
+	},
+	"rules invalid": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("rules", append(EnvRules("rules-value.json"),
+				envChangelog...)...),
+			Exec(CmdGitLog([]string{"HEAD"}, DirChangelog("rules"),
+				append(EnvRules("rules-value.json"), envChangelog...)...),
+				"nil", "builder", "stderr", logChangelog, "", nil),
+			LogError("stderr", "verify rules",
+				verify.NewErrRuleValue("signed-off-by", "sometimes")),
+		),
+		dir: "rules",
+		env: append(EnvRules("rules-value.json"), envChangelog...),
+		args: []string{
+			"go-make", "--version-changelog", "--version=v1.1.0", "HEAD",
+		},
+		expectError: verify.NewErrRuleValue("signed-off-by", "sometimes"),
+		expectExit:  ExitCommandFailure,
+	},
+
+	"log failed": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("log", envChangelog...),
+			Exec(CmdGitLog([]string{"HEAD"},
+				DirChangelog("log"), envChangelog...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogError("stderr", "read commits", NewErrCallFailed(
+				CmdGitLog([]string{"HEAD"}, DirChangelog("log")),
+				assert.AnError)),
+		),
+		dir: "log",
+		env: envChangelog,
+		args: []string{
+			"go-make", "--version-changelog", "--version=v1.1.0", "HEAD",
+		},
+		expectError: NewErrCallFailed(CmdGitLog([]string{"HEAD"},
+			DirChangelog("log")), assert.AnError),
+		expectExit: ExitCommandFailure,
+	},
+	"version invalid": {
+		mockSetup: mock.Chain(
+			ChangelogSetup("version", envChangelog...),
+			LogError("stderr", "read version",
+				semver.NewErrInvalid("invalid")),
+		),
+		dir:         "version",
+		env:         envChangelog,
+		args:        []string{"go-make", "--version-changelog", "HEAD"},
+		files:       map[string]string{"VERSION": "invalid\n"},
+		expectError: semver.NewErrInvalid("invalid"),
+		expectExit:  ExitCommandFailure,
+	},
+	"args invalid": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-changelog",
+				NewErrInvalidArg(CmdVersionChangelog, "--prepend", nil)),
+		),
+		dir:  "invalid",
+		env:  envChangelog,
+		args: []string{"go-make", "--version-changelog", "--json", "--prepend"},
+		expectError: NewErrInvalidArg(CmdVersionChangelog,
+			"--prepend", nil),
+		expectExit: ExitCommandFailure,
+	},
+}
+
+func TestVersionChangelog(t *testing.T) {
+	test.Map(t, versionChangelogTestCases).
+		Run(func(t test.Test, param VersionChangelogParams) {
+			// Given
+			dir := DirChangelog(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				WriteFile(filepath.Join(dir, name), 0o600, content)
+			}
+			gm, mocks := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+			stdout := mocks.GetArg("stdout").(*strings.Builder)
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			assert.Equal(t, "stdout"+param.expectStdout, stdout.String())
+			for name, content := range param.expectFiles {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				assert.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+		})
+}
@@ -0,0 +1,97 @@
+package make
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvGoMakePID provides the name of the environment variable exporting
+	// the process id of go-make to the make targets.
+	EnvGoMakePID = "GOMAKE_PID"
+	// EnvGoMakeControl provides the name of the environment variable
+	// exporting the control file of go-make to the make targets.
+	EnvGoMakeControl = "GOMAKE_CONTROL"
+)
+
+// ErrStopped represent a stop requested by a make target.
+var ErrStopped = errors.New("stopped")
+
+// NewErrStopped creates a stop error for the given stop request.
+func NewErrStopped(stop *StopRequest) error {
+	return fmt.Errorf("%w [exit=%d]: %s", ErrStopped, stop.Exit, stop.Reason)
+}
+
+// StopRequest represents a request of a make target to stop go-make cleanly
+// with an exit code and a reason.
+type StopRequest struct {
+	// Exit provides the requested exit code.
+	Exit int
+	// Reason provides the reason of the stop request.
+	Reason string
+}
+
+// fileControl returns the path of the control file used by make targets to
+// request a clean stop. It uses the explicit control file, or the default
+// file in the per-project cache directory including the process id.
+func (gm *GoMake) fileControl() string {
+	file := gm.ControlFile
+	if file == "" {
+		file = filepath.Join(gm.cacheDir(),
+			"control."+strconv.Itoa(os.Getpid()))
+	}
+	return filepath.Clean(file)
+}
+
+// setupControl resolves the control file and ensures that its directory
+// exists, so that make targets can write their stop requests. It returns a
+// function to remove the control file after make has finished.
+func (gm *GoMake) setupControl() func() {
+	gm.ControlFile = gm.fileControl()
+	if err := os.MkdirAll(filepath.Dir(gm.ControlFile), 0o750); err != nil {
+		gm.error("setup control", err)
+	}
+	return func() { _ = os.Remove(gm.ControlFile) }
+}
+
+// readControl reads and removes the stop request from the control file. The
+// file contains a single line with the exit code followed by the reason. If
+// the file does not exist, it returns nil indicating a plain abort. If the
+// exit code is invalid, the target failure exit code is used with the whole
+// line as reason.
+func (gm *GoMake) readControl() *StopRequest {
+	if gm.ControlFile == "" {
+		return nil
+	}
+
+	// #nosec G304 -- file is the control file in the cache directory.
+	content, err := os.ReadFile(gm.ControlFile)
+	if err != nil {
+		return nil
+	}
+	_ = os.Remove(gm.ControlFile)
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(content)), "\n")
+	code, reason, _ := strings.Cut(line, " ")
+	exit, err := strconv.Atoi(code)
+	if err != nil || exit < 0 {
+		return &StopRequest{Exit: ExitTargetFailure, Reason: line}
+	}
+	return &StopRequest{Exit: exit, Reason: strings.TrimSpace(reason)}
+}
+
+// stopped finishes a run stopped by a make target logging the reason of the
+// given stop request and returning the requested exit code.
+func (gm *GoMake) stopped(stop *StopRequest) (int, error) {
+	if stop.Reason != "" {
+		gm.Logger.Warning(gm.Stderr, "stopped: "+stop.Reason)
+	}
+	if stop.Exit != ExitSuccess {
+		return stop.Exit, NewErrStopped(stop)
+	}
+	return ExitSuccess, nil
+}
@@ -0,0 +1,168 @@
+package make_test
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// ExecStop creates a make target call expectation that requests a stop with
+// given control content via the control file and an abort signal, similar to
+// the `stop` macro of the `Makefile.base`. The abort signal is handled
+// directly by the go-make instance provided as `gm` argument, since a process
+// wide signal would interfere with other parallel tests. If `signal` is false,
+// make finishes with given error before the abort signal is handled.
+func ExecStop(control string, signal bool, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockExecutor).EXPECT().
+			Exec(gomock.AssignableToTypeOf(ctx), gomock.Any()).
+			DoAndReturn(mocks.Call(cmd.Executor.Exec,
+				func(args ...any) []any {
+					gm := mocks.GetArg("gm").(*GoMake)
+					assert.Contains(mocks.Ctrl.T, args[1].(*cmd.Cmd).Env,
+						EnvGoMakeControl+"="+gm.ControlFile)
+					if control != "" {
+						WriteFile(gm.ControlFile, 0o600, control)
+					}
+					if signal {
+						gm.HandleSignal(func() {}, syscall.SIGABRT, 1)
+					}
+					return []any{err}
+				}))
+	}
+}
+
+type MakeStopParams struct {
+	mockSetup   mock.SetupFunc
+	args        []string
+	expectError error
+	expectExit  int
+}
+
+var makeStopTestCases = map[string]MakeStopParams{
+	"abort without control": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			ExecStop("", true, assert.AnError),
+			ExecCommit(dirRoot),
+		),
+		args:       []string{"go-make", "target"},
+		expectExit: ExitSuccess,
+	},
+	"stop with success": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			ExecStop("0 done", true, assert.AnError),
+			LogWarning("stderr", "stopped: done"),
+			ExecCommit(dirRoot),
+		),
+		args:       []string{"go-make", "target"},
+		expectExit: ExitSuccess,
+	},
+	"stop with failure": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			ExecStop("5 missing release notes\n", true, assert.AnError),
+			LogWarning("stderr", "stopped: missing release notes"),
+			ExecCommit(dirRoot),
+		),
+		args: []string{"go-make", "target"},
+		expectError: NewErrStopped(&StopRequest{
+			Exit: 5, Reason: "missing release notes",
+		}),
+		expectExit: 5,
+	},
+	"stop with failure quiet": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			ExecStop("5 missing release notes", true, assert.AnError),
+			LogWarning("stderr", "stopped: missing release notes"),
+			ExecCommit(dirRoot),
+		),
+		args: []string{"go-make", "--log-level=quiet", "target"},
+		expectError: NewErrStopped(&StopRequest{
+			Exit: 5, Reason: "missing release notes",
+		}),
+		expectExit: 5,
+	},
+	"stop with invalid exit": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			ExecStop("x broken", true, assert.AnError),
+			LogWarning("stderr", "stopped: x broken"),
+			ExecCommit(dirRoot),
+		),
+		args: []string{"go-make", "target"},
+		expectError: NewErrStopped(&StopRequest{
+			Exit: ExitTargetFailure, Reason: "x broken",
+		}),
+		expectExit: ExitTargetFailure,
+	},
+	"stop before signal with success": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			ExecStop("0 done", false, nil),
+			LogWarning("stderr", "stopped: done"),
+			ExecCommit(dirRoot),
+		),
+		args:       []string{"go-make", "target"},
+		expectExit: ExitSuccess,
+	},
+	"stop before signal with failure": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			ExecStop("5 missing release notes", false, assert.AnError),
+			LogWarning("stderr", "stopped: missing release notes"),
+			ExecCommit(dirRoot),
+		),
+		args: []string{"go-make", "target"},
+		expectError: NewErrStopped(&StopRequest{
+			Exit: 5, Reason: "missing release notes",
+		}),
+		expectExit: 5,
+	},
+}
+
+func TestMakeStop(t *testing.T) {
+	test.Map(t, makeStopTestCases).
+		Run(func(t test.Test, param MakeStopParams) {
+			// Given
+			gm, mocks := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+			})
+			gm.ControlFile = filepath.Join(t.TempDir(), "control")
+			mocks.SetArg("gm", gm)
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			assert.True(t, gm.Aborted.Load())
+			assert.NoFileExists(t, gm.ControlFile)
+		})
+}
@@ -0,0 +1,271 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tkrop/go-make/internal/cover"
+)
+
+const (
+	// CmdTestCoverCheck provides the name of the native test-cover-check
+	// command.
+	CmdTestCoverCheck = "test-cover-check"
+	// CmdTestCoverExport provides the name of the native test-cover-export
+	// command.
+	CmdTestCoverExport = "test-cover-export"
+	// EnvTestCover provides the name of the makefile variable containing the
+	// cover profile file.
+	EnvTestCover = "TEST_COVER"
+	// EnvCoverMin provides the name of the makefile variable containing the
+	// default minimum coverage.
+	EnvCoverMin = "COVER_MIN"
+	// EnvCoverPackages provides the name of the makefile variable containing
+	// the minimum coverage overrides of packages.
+	EnvCoverPackages = "COVER_PACKAGES"
+	// DefaultTestCover provides the default cover profile file.
+	DefaultTestCover = "build/test.cover"
+	// DefaultCoverMin provides the default minimum coverage.
+	DefaultCoverMin = "0"
+	// DefaultCoverCobertura provides the default Cobertura XML report file.
+	DefaultCoverCobertura = "build/test.cover.xml"
+	// DefaultCoverLCOV provides the default LCOV report file.
+	DefaultCoverLCOV = "build/test.lcov"
+)
+
+// ErrCoverage represents an insufficient test coverage.
+var ErrCoverage = errors.New("insufficient coverage")
+
+// NewErrCoverage creates an insufficient coverage error for the given number
+// of offenders.
+func NewErrCoverage(count int) error {
+	return fmt.Errorf("%w [count=%d]", ErrCoverage, count)
+}
+
+// coverArgs contains the parsed arguments of the test-cover commands.
+type coverArgs struct {
+	// file provides the cover profile file, if any.
+	file string
+	// files indicates whether to report the coverage of source files.
+	files bool
+	// cobertura provides the Cobertura XML report file, if any.
+	cobertura string
+	// lcov provides the LCOV report file, if any.
+	lcov string
+}
+
+// parseCoverCheck parses the arguments of the test-cover-check command.
+func parseCoverCheck(args ...string) (*coverArgs, error) {
+	params := &coverArgs{}
+	for _, arg := range args {
+		switch {
+		case arg == "--files":
+			params.files = true
+		case params.file == "" && !strings.HasPrefix(arg, "-"):
+			params.file = arg
+		default:
+			return nil, NewErrInvalidArg(CmdTestCoverCheck, arg, nil)
+		}
+	}
+	return params, nil
+}
+
+// parseCoverExport parses the arguments of the test-cover-export command.
+func parseCoverExport(args ...string) (*coverArgs, error) {
+	params := &coverArgs{}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--cobertura=") && arg != "--cobertura=":
+			params.cobertura = arg[len("--cobertura="):]
+		case strings.HasPrefix(arg, "--lcov=") && arg != "--lcov=":
+			params.lcov = arg[len("--lcov="):]
+		case params.file == "" && !strings.HasPrefix(arg, "-"):
+			params.file = arg
+		default:
+			return nil, NewErrInvalidArg(CmdTestCoverExport, arg, nil)
+		}
+	}
+	return params, nil
+}
+
+// testCoverCheck runs the native test-cover-check command with given
+// arguments. It reports the package and total coverage of the cover profile
+// of the last test run, and with `--files` the coverage of the source files.
+// It fails with a table of offenders, if a package or the total coverage is
+// below the minimum defined by `COVER_MIN` and `COVER_PACKAGES`.
+func (gm *GoMake) testCoverCheck(args ...string) (int, error) {
+	params, err := parseCoverCheck(args...)
+	if err != nil {
+		gm.error("parse test-cover-check", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	values, err := gm.variables(ctx, map[string]string{
+		EnvTestCover:     DefaultTestCover,
+		EnvCoverMin:      DefaultCoverMin,
+		EnvCoverPackages: "",
+	})
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+	thresholds, err := cover.ParseThresholds(
+		values[EnvCoverMin], values[EnvCoverPackages])
+	if err != nil {
+		gm.error("parse thresholds", err)
+		return ExitCommandFailure, err
+	}
+
+	if params.file == "" {
+		params.file = values[EnvTestCover]
+	}
+	profile, err := gm.readCover(params.file)
+	if err != nil {
+		gm.error("read coverage", err)
+		return ExitCommandFailure, err
+	}
+
+	coverages := profile.PackageCoverage()
+	if params.files {
+		coverages = append(coverages, profile.FileCoverage()...)
+	}
+	rows := [][]string{{"NAME", "STATEMENTS", "COVERAGE"}}
+	for _, coverage := range append(coverages, profile.TotalCoverage()) {
+		rows = append(rows, []string{coverage.Name,
+			fmt.Sprintf("%d/%d", coverage.Covered, coverage.Total),
+			fmt.Sprintf("%.1f%%", coverage.Percent())})
+	}
+	gm.Logger.Message(gm.Stdout, coverTable(rows))
+
+	offenders := cover.Check(profile, thresholds)
+	if len(offenders) != 0 {
+		rows := [][]string{{"OFFENDER", "COVERAGE", "MINIMUM"}}
+		for _, offender := range offenders {
+			rows = append(rows, []string{offender.Name,
+				fmt.Sprintf("%.1f%%", offender.Percent()),
+				fmt.Sprintf("%.1f%%", offender.Minimum)})
+		}
+		gm.Logger.Message(gm.Stdout, coverTable(rows))
+		err := NewErrCoverage(len(offenders))
+		gm.error(CmdTestCoverCheck, err)
+		return ExitCommandFailure, err
+	}
+	return ExitSuccess, nil
+}
+
+// testCoverExport runs the native test-cover-export command with given
+// arguments. It exports the cover profile of the last test run as Cobertura
+// XML and LCOV report using source file paths relative to the module.
+func (gm *GoMake) testCoverExport(args ...string) (int, error) {
+	params, err := parseCoverExport(args...)
+	if err != nil {
+		gm.error("parse test-cover-export", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	file, err := gm.variable(ctx, EnvTestCover, DefaultTestCover)
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+
+	if params.file == "" {
+		params.file = file
+	}
+	profile, err := gm.readCover(params.file)
+	if err != nil {
+		gm.error("read coverage", err)
+		return ExitCommandFailure, err
+	}
+
+	module, _, err := readGoMod(filepath.Join(gm.WorkDir, "go.mod"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		gm.error("read go.mod", err)
+		return ExitCommandFailure, err
+	}
+
+	cobertura := gm.coverFile(params.cobertura, DefaultCoverCobertura)
+	if err := writeReport(cobertura, func(writer io.Writer) error {
+		return profile.WriteCobertura(writer,
+			module, gm.WorkDir, time.Now().Unix())
+	}); err != nil {
+		gm.error("write cobertura", err)
+		return ExitCommandFailure, err
+	}
+	lcov := gm.coverFile(params.lcov, DefaultCoverLCOV)
+	if err := writeReport(lcov, func(writer io.Writer) error {
+		return profile.WriteLCOV(writer, module)
+	}); err != nil {
+		gm.error("write lcov", err)
+		return ExitCommandFailure, err
+	}
+
+	gm.Logger.Message(gm.Stdout, fmt.Sprintf(
+		"exported coverage [cobertura=%s, lcov=%s]", cobertura, lcov))
+	return ExitSuccess, nil
+}
+
+// readCover reads the given cover profile file relative to the working
+// directory.
+func (gm *GoMake) readCover(file string) (*cover.Profile, error) {
+	// #nosec G304 -- file is a cover profile of the project.
+	reader, err := os.Open(gm.coverFile(file, ""))
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by caller.
+	}
+	defer reader.Close()
+	return cover.Parse(reader)
+}
+
+// coverFile returns the given file, or the given default file, if the file is
+// empty, relative to the working directory.
+func (gm *GoMake) coverFile(file, deflt string) string {
+	if file == "" {
+		file = deflt
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(gm.WorkDir, file)
+	}
+	return file
+}
+
+// writeReport writes a report to the given file using the given write
+// function creating the report directory, if necessary.
+func writeReport(file string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	// #nosec G304 -- file is a report of the project.
+	writer, err := os.Create(file)
+	if err != nil {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	defer writer.Close()
+	return write(writer)
+}
+
+// coverTable formats the given rows as table with aligned columns.
+func coverTable(rows [][]string) string {
+	builder := &strings.Builder{}
+	writer := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	_ = writer.Flush()
+	return builder.String()
+}
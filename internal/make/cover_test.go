@@ -0,0 +1,390 @@
+package make_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/cover"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// coverProfile contains the cover profile of the coverage tests.
+	coverProfile = "mode: set\n" +
+		"example.com/cover/pkg/pkg.go:3.20,5.2 2 1\n" +
+		"example.com/cover/main.go:5.13,7.2 2 0\n"
+	// coverLCOV contains the LCOV report of the cover profile.
+	coverLCOV = "TN:\nSF:main.go\nDA:5,0\nDA:6,0\nDA:7,0\n" +
+		"LF:3\nLH:0\nend_of_record\n" +
+		"TN:\nSF:pkg/pkg.go\nDA:3,1\nDA:4,1\nDA:5,1\n" +
+		"LF:3\nLH:3\nend_of_record\n"
+	// coverTable contains the package coverage table of the cover profile.
+	coverTable = "" +
+		"NAME                   STATEMENTS  COVERAGE\n" +
+		"example.com/cover      0/2         0.0%\n" +
+		"example.com/cover/pkg  2/2         100.0%\n" +
+		"total                  2/4         50.0%\n"
+)
+
+var (
+	// envCover contains the environment of the coverage check.
+	envCover = []string{
+		EnvTestCover + "=" + DefaultTestCover,
+		EnvCoverMin + "=0",
+		EnvCoverPackages + "=cover/pkg$=100",
+	}
+	// envCoverMin contains the environment of the coverage check with
+	// insufficient coverage.
+	envCoverMin = []string{
+		EnvTestCover + "=" + DefaultTestCover,
+		EnvCoverMin + "=60",
+		EnvCoverPackages + "=cover$=0",
+	}
+)
+
+// DirCover returns the project directory of the coverage test case with
+// given name.
+func DirCover(name string) string {
+	return filepath.Join(dirTargets, "cover", name)
+}
+
+// CoverSetup sets up the git command of the coverage test case with given
+// name using the given environment.
+func CoverSetup(name string, env ...string) func(*mock.Mocks) any {
+	return Exec(CmdGitTop(dirWork, env...), "nil", "builder", "stderr",
+		DirCover(name), "", nil)
+}
+
+// CoverConfigFailed sets up the failing config of the coverage test case
+// with given name.
+func CoverConfigFailed(name string) func(*mock.Mocks) any {
+	dir := DirCover(name)
+	return mock.Chain(
+		Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dir, "", nil),
+		Exec(CmdTestDir(goMakeInfoBase, dir),
+			"nil", "stderr", "stderr", "", "", assert.AnError),
+		Exec(CmdGoInstall(infoBase.Path, infoBase.Version, dir),
+			"nil", "stderr", "stderr", "", "", assert.AnError),
+		LogError("stderr", "ensure config", ErrCoverConfig(name)),
+	)
+}
+
+// ErrCoverConfig returns the config error of the coverage test case with
+// given name.
+func ErrCoverConfig(name string) error {
+	return NewErrNotFound(infoBase.Path, infoBase.Version,
+		NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+			DirCover(name)), assert.AnError))
+}
+
+type TestCoverParams struct {
+	mockSetup    mock.SetupFunc
+	dir          string
+	env          []string
+	args         []string
+	files        map[string]string
+	expectFiles  map[string]string
+	expectExists []string
+	expectError  error
+	expectExit   int
+}
+
+var testCoverTestCases = map[string]TestCoverParams{
+	"check succeeded": {
+		mockSetup: mock.Chain(
+			CoverSetup("check", envCover...),
+			LogMessage("stdout", coverTable),
+		),
+		dir:   "check",
+		env:   envCover,
+		args:  []string{"go-make", "--test-cover-check"},
+		files: map[string]string{DefaultTestCover: coverProfile},
+	},
+	"check with files": {
+		mockSetup: mock.Chain(
+			CoverSetup("files", envCover...),
+			LogMessage("stdout", ""+
+				"NAME                          STATEMENTS  COVERAGE\n"+
+				"example.com/cover             0/2         0.0%\n"+
+				"example.com/cover/pkg         2/2         100.0%\n"+
+				"example.com/cover/main.go     0/2         0.0%\n"+
+				"example.com/cover/pkg/pkg.go  2/2         100.0%\n"+
+				"total                         2/4         50.0%\n"),
+		),
+		dir: "files",
+		env: envCover,
+		args: []string{
+			"go-make", "--test-cover-check", "--files", "custom.cover",
+		},
+		files: map[string]string{"custom.cover": coverProfile},
+	},
+	"check offenders": {
+		mockSetup: mock.Chain(
+			CoverSetup("offenders", envCoverMin...),
+			LogMessage("stdout", coverTable),
+			LogMessage("stdout", ""+
+				"OFFENDER  COVERAGE  MINIMUM\n"+
+				"total     50.0%     60.0%\n"),
+			LogError("stderr", CmdTestCoverCheck, NewErrCoverage(1)),
+		),
+		dir:         "offenders",
+		env:         envCoverMin,
+		args:        []string{"go-make", "--test-cover-check"},
+		files:       map[string]string{DefaultTestCover: coverProfile},
+		expectError: NewErrCoverage(1),
+		expectExit:  ExitCommandFailure,
+	},
+	"check with config": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirCover("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirCover("config")),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, DirCover("config")),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvCoverMin+" := 40\n", "", nil),
+			LogMessage("stdout", coverTable),
+			LogMessage("stdout", ""+
+				"OFFENDER           COVERAGE  MINIMUM\n"+
+				"example.com/cover  0.0%      40.0%\n"),
+			LogError("stderr", CmdTestCoverCheck, NewErrCoverage(1)),
+		),
+		dir:         "config",
+		args:        []string{"go-make", "--test-cover-check"},
+		files:       map[string]string{DefaultTestCover: coverProfile},
+		expectError: NewErrCoverage(1),
+		expectExit:  ExitCommandFailure,
+	},
+	"check invalid thresholds": {
+		mockSetup: mock.Chain(
+			CoverSetup("thresholds", EnvTestCover+"="+DefaultTestCover,
+				EnvCoverMin+"=120", EnvCoverPackages+"=cover=0"),
+			LogError("stderr", "parse thresholds",
+				cover.NewErrInvalidThreshold("120", strconv.ErrRange)),
+		),
+		dir: "thresholds",
+		env: []string{
+			EnvTestCover + "=" + DefaultTestCover,
+			EnvCoverMin + "=120", EnvCoverPackages + "=cover=0",
+		},
+		args: []string{"go-make", "--test-cover-check"},
+		expectError: cover.NewErrInvalidThreshold("120",
+			strconv.ErrRange),
+		expectExit: ExitCommandFailure,
+	},
+	"check read coverage failed": {
+		mockSetup: mock.Chain(
+			CoverSetup("check-missing", envCover...),
+			LogError("stderr", "read coverage", &fs.PathError{
+				Op: "open", Path: filepath.Join(DirCover("check-missing"),
+					DefaultTestCover), Err: syscall.ENOENT,
+			}),
+		),
+		dir:  "check-missing",
+		env:  envCover,
+		args: []string{"go-make", "--test-cover-check"},
+		expectError: &fs.PathError{
+			Op: "open", Path: filepath.Join(DirCover("check-missing"),
+				DefaultTestCover), Err: syscall.ENOENT,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"check config failed": {
+		mockSetup:   CoverConfigFailed("check-config"),
+		dir:         "check-config",
+		args:        []string{"go-make", "--test-cover-check"},
+		expectError: ErrCoverConfig("check-config"),
+		expectExit:  ExitConfigFailure,
+	},
+	"check invalid argument": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse test-cover-check",
+				NewErrInvalidArg(CmdTestCoverCheck, "--all", nil)),
+		),
+		args: []string{"go-make", "--test-cover-check", "--all"},
+		expectError: NewErrInvalidArg(CmdTestCoverCheck,
+			"--all", nil),
+		expectExit: ExitCommandFailure,
+	},
+
+	"export succeeded": {
+		mockSetup: mock.Chain(
+			CoverSetup("export", envCover...),
+			LogMessage("stdout", "exported coverage [cobertura="+
+				filepath.Join(DirCover("export"), DefaultCoverCobertura)+
+				", lcov="+filepath.Join(DirCover("export"),
+				DefaultCoverLCOV)+"]"),
+		),
+		dir:  "export",
+		env:  envCover,
+		args: []string{"go-make", "--test-cover-export"},
+		files: map[string]string{
+			"go.mod":         "module example.com/cover\n",
+			DefaultTestCover: coverProfile,
+		},
+		expectFiles:  map[string]string{DefaultCoverLCOV: coverLCOV},
+		expectExists: []string{DefaultCoverCobertura},
+	},
+	"export custom files": {
+		mockSetup: mock.Chain(
+			CoverSetup("custom", envCover...),
+			LogMessage("stdout", "exported coverage [cobertura="+
+				filepath.Join(DirCover("custom"), "out/cover.xml")+
+				", lcov="+filepath.Join(DirCover("custom"), "out/lcov.info")+
+				"]"),
+		),
+		dir: "custom",
+		env: envCover,
+		args: []string{
+			"go-make", "--test-cover-export", "--cobertura=out/cover.xml",
+			"--lcov=out/lcov.info", "custom.cover",
+		},
+		files: map[string]string{"custom.cover": coverProfile},
+		expectFiles: map[string]string{
+			"out/lcov.info": "TN:\nSF:example.com/cover/main.go\n" +
+				"DA:5,0\nDA:6,0\nDA:7,0\nLF:3\nLH:0\nend_of_record\n" +
+				"TN:\nSF:example.com/cover/pkg/pkg.go\n" +
+				"DA:3,1\nDA:4,1\nDA:5,1\nLF:3\nLH:3\nend_of_record\n",
+		},
+		expectExists: []string{"out/cover.xml"},
+	},
+	"export read go.mod failed": {
+		mockSetup: mock.Chain(
+			CoverSetup("gomod", envCover...),
+			LogError("stderr", "read go.mod", &fs.PathError{
+				Op: "read", Path: filepath.Join(DirCover("gomod"), "go.mod"),
+				Err: syscall.EISDIR,
+			}),
+		),
+		dir:  "gomod",
+		env:  envCover,
+		args: []string{"go-make", "--test-cover-export"},
+		files: map[string]string{
+			"go.mod/file":    "",
+			DefaultTestCover: coverProfile,
+		},
+		expectError: &fs.PathError{
+			Op: "read", Path: filepath.Join(DirCover("gomod"), "go.mod"),
+			Err: syscall.EISDIR,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"export write cobertura failed": {
+		mockSetup: mock.Chain(
+			CoverSetup("cobertura", envCover...),
+			LogError("stderr", "write cobertura", &fs.PathError{
+				Op: "mkdir", Path: filepath.Join(DirCover("cobertura"), "out"),
+				Err: syscall.ENOTDIR,
+			}),
+		),
+		dir: "cobertura",
+		env: envCover,
+		args: []string{
+			"go-make", "--test-cover-export", "--cobertura=out/cover.xml",
+		},
+		files: map[string]string{
+			"out":            "",
+			DefaultTestCover: coverProfile,
+		},
+		expectError: &fs.PathError{
+			Op: "mkdir", Path: filepath.Join(DirCover("cobertura"), "out"),
+			Err: syscall.ENOTDIR,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"export write lcov failed": {
+		mockSetup: mock.Chain(
+			CoverSetup("lcov", envCover...),
+			LogError("stderr", "write lcov", &fs.PathError{
+				Op: "open", Path: filepath.Join(DirCover("lcov"), "out"),
+				Err: syscall.EISDIR,
+			}),
+		),
+		dir:  "lcov",
+		env:  envCover,
+		args: []string{"go-make", "--test-cover-export", "--lcov=out"},
+		files: map[string]string{
+			"out/file":       "",
+			DefaultTestCover: coverProfile,
+		},
+		expectError: &fs.PathError{
+			Op: "open", Path: filepath.Join(DirCover("lcov"), "out"),
+			Err: syscall.EISDIR,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"export read coverage failed": {
+		mockSetup: mock.Chain(
+			CoverSetup("export-invalid", envCover...),
+			LogError("stderr", "read coverage",
+				cover.NewErrInvalidProfile(1, "invalid")),
+		),
+		dir:         "export-invalid",
+		env:         envCover,
+		args:        []string{"go-make", "--test-cover-export"},
+		files:       map[string]string{DefaultTestCover: "invalid\n"},
+		expectError: cover.NewErrInvalidProfile(1, "invalid"),
+		expectExit:  ExitCommandFailure,
+	},
+	"export config failed": {
+		mockSetup:   CoverConfigFailed("export-config"),
+		dir:         "export-config",
+		args:        []string{"go-make", "--test-cover-export"},
+		expectError: ErrCoverConfig("export-config"),
+		expectExit:  ExitConfigFailure,
+	},
+	"export invalid argument": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse test-cover-export",
+				NewErrInvalidArg(CmdTestCoverExport, "--lcov=", nil)),
+		),
+		args: []string{"go-make", "--test-cover-export", "--lcov="},
+		expectError: NewErrInvalidArg(CmdTestCoverExport,
+			"--lcov=", nil),
+		expectExit: ExitCommandFailure,
+	},
+}
+
+func TestTestCover(t *testing.T) {
+	test.Map(t, testCoverTestCases).
+		Run(func(t test.Test, param TestCoverParams) {
+			// Given
+			dir := DirCover(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				WriteFile(path, 0o600, content)
+			}
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			for name, content := range param.expectFiles {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				assert.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+			for _, name := range param.expectExists {
+				assert.FileExists(t, filepath.Join(dir, name))
+			}
+		})
+}
@@ -0,0 +1,195 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/flaky"
+	"github.com/tkrop/go-make/internal/junit"
+)
+
+const (
+	// CmdTestFlakyRerun provides the name of the native test-flaky-rerun
+	// command.
+	CmdTestFlakyRerun = "test-flaky-rerun"
+	// EnvTestFlakyCount provides the name of the makefile variable containing
+	// the number of re-runs of failed tests.
+	EnvTestFlakyCount = "TEST_FLAKY_COUNT"
+	// EnvTestFlakyAllow provides the name of the makefile variable containing
+	// whether flaky tests are allowed to pass the build.
+	EnvTestFlakyAllow = "TEST_FLAKY_ALLOW"
+	// EnvFileFlaky provides the name of the flaky test history file
+	// environment variable.
+	EnvFileFlaky = "FILE_FLAKY"
+	// DefaultTestFlakyCount provides the default number of re-runs of failed
+	// tests.
+	DefaultTestFlakyCount = "3"
+	// DefaultTestFlakyAllow provides the default whether flaky tests are
+	// allowed to pass the build.
+	DefaultTestFlakyAllow = "false"
+)
+
+// ErrTestFlaky represents a test run with flaky tests.
+var ErrTestFlaky = errors.New("flaky tests")
+
+// NewErrTestFlaky creates a flaky test run error for the given number of
+// flaky tests.
+func NewErrTestFlaky(count int) error {
+	return fmt.Errorf("%w [count=%d]", ErrTestFlaky, count)
+}
+
+// ErrTestResults represents a test run without package results.
+var ErrTestResults = errors.New("missing test results")
+
+// NewErrTestResults creates a missing test results error for a test run that
+// did not report the result of any package, e.g. since `go test` failed
+// before running the tests.
+func NewErrTestResults() error {
+	return fmt.Errorf("%w [packages=0]", ErrTestResults)
+}
+
+// CmdGoTestRerun creates the argument array of a `go test -json` command
+// re-running the tests matching the given regex of the given package the
+// given number of times using the given additional test arguments.
+func CmdGoTestRerun(
+	pkg, regex string, count int, args []string, dir string, env ...string,
+) *cmd.Cmd {
+	return cmd.New(append(append([]string{"go", "test", "-json"}, args...),
+		"-count="+strconv.Itoa(count), "-run="+regex, pkg)...).
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// parseTestFlaky parses the flaky test settings, i.e. the number of re-runs
+// and whether flaky tests are allowed.
+func parseTestFlaky(values map[string]string) (int, bool, error) {
+	count, err := strconv.Atoi(values[EnvTestFlakyCount])
+	if err != nil || count <= 0 {
+		return 0, false, NewErrInvalidArg(CmdTestFlakyRerun,
+			EnvTestFlakyCount+"="+values[EnvTestFlakyCount], err)
+	}
+	allow, err := strconv.ParseBool(values[EnvTestFlakyAllow])
+	if err != nil {
+		return 0, false, NewErrInvalidArg(CmdTestFlakyRerun,
+			EnvTestFlakyAllow+"="+values[EnvTestFlakyAllow], err)
+	}
+	return count, allow, nil
+}
+
+// testFlakyRerun runs the native test-flaky-rerun command with given `go
+// test` arguments. It reads the `go test -json` events from standard input
+// forwarding the human-readable test output, re-runs the failed top-level
+// tests `TEST_FLAKY_COUNT` times per package, and classifies them as flaky or
+// failing recording the results in the flaky test history. It fails on
+// failing tests and broken packages, and on flaky tests, unless
+// `TEST_FLAKY_ALLOW` is set.
+func (gm *GoMake) testFlakyRerun(args ...string) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	values, err := gm.variables(ctx, map[string]string{
+		EnvTestFlakyCount: DefaultTestFlakyCount,
+		EnvTestFlakyAllow: DefaultTestFlakyAllow,
+	})
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+	count, allow, err := parseTestFlaky(values)
+	if err != nil {
+		gm.error("parse test-flaky-rerun", err)
+		return ExitCommandFailure, err
+	}
+
+	report := junit.NewReport()
+	if err := junit.Convert(gm.Stdin, gm.Stdout, report); err != nil {
+		gm.error("convert test output", err)
+		return ExitCommandFailure, err
+	} else if !slices.ContainsFunc(report.Suites, func(suite *junit.Suite) bool {
+		return suite.Action != ""
+	}) {
+		err := NewErrTestResults()
+		gm.error(CmdTestFlakyRerun, err)
+		return ExitCommandFailure, err
+	}
+
+	start, records, broken := time.Now(), []*flaky.Record{}, 0
+	for _, failure := range flaky.Failures(report) {
+		if failure.Broken {
+			gm.Logger.Message(gm.Stdout,
+				fmt.Sprintf("broken [pkg=%s]", failure.Package))
+			broken++
+			continue
+		}
+
+		// The re-run fails on any failing test that is classified below.
+		output := &strings.Builder{}
+		_ = gm.exec(ctx, CmdGoTestRerun(failure.Package, failure.Regex(),
+			count, args, gm.WorkDir, gm.Env...).WithIO(nil, output, gm.Stderr))
+		results, err := flaky.Classify(
+			strings.NewReader(output.String()), failure, start)
+		if err != nil {
+			gm.error("classify tests", err)
+			return ExitCommandFailure, err
+		}
+		for _, record := range results {
+			gm.Logger.Message(gm.Stdout, record.String())
+		}
+		records = append(records, results...)
+	}
+
+	if len(records) != 0 {
+		if err := flaky.New(gm.fileFlaky()).Append(records...); err != nil {
+			gm.error("write flaky history", err)
+		}
+	}
+	return gm.flakyResult(records, broken, allow)
+}
+
+// flakyResult returns the result of the test run with the given re-run test
+// records and number of broken packages.
+func (gm *GoMake) flakyResult(
+	records []*flaky.Record, broken int, allow bool,
+) (int, error) {
+	flakes, failing := 0, 0
+	for _, record := range records {
+		if record.Status == flaky.StatusFlaky {
+			flakes++
+		} else {
+			failing++
+		}
+	}
+
+	switch {
+	case failing != 0 || broken != 0:
+		err := NewErrTestFailed(failing, broken)
+		gm.error(CmdTestFlakyRerun, err)
+		return ExitCommandFailure, err
+	case flakes != 0 && !allow:
+		err := NewErrTestFlaky(flakes)
+		gm.error(CmdTestFlakyRerun, err)
+		return ExitCommandFailure, err
+	case flakes != 0:
+		gm.Logger.Warning(gm.Stderr,
+			fmt.Sprintf("flaky tests allowed [count=%d]", flakes))
+	}
+	return ExitSuccess, nil
+}
+
+// fileFlaky returns the path of the flaky test history file. It uses the
+// `FILE_FLAKY` environment variable, or the default file in the per-project
+// cache directory.
+func (gm *GoMake) fileFlaky() string {
+	file := gm.GetEnvDefault(EnvFileFlaky, "")
+	if file == "" {
+		file = filepath.Join(gm.cacheDir(), "flaky.json")
+	}
+	return filepath.Clean(file)
+}
@@ -0,0 +1,339 @@
+package make_test
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/flaky"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// flakyRun contains the events of a test run with a failed test and a
+	// broken package.
+	flakyRun = `{"Action":"run","Package":"example.com/flaky",` +
+		`"Test":"TestFlaky"}
+{"Action":"output","Package":"example.com/flaky","Test":"TestFlaky",` +
+		`"Output":"--- FAIL: TestFlaky (0.01s)\n"}
+{"Action":"fail","Package":"example.com/flaky","Test":"TestFlaky"}
+{"Action":"fail","Package":"example.com/flaky"}
+`
+	// flakyBroken contains the events of a broken package.
+	flakyBroken = `{"Action":"fail","Package":"example.com/broken"}
+`
+	// flakyPass contains the events of a passed test run.
+	flakyPass = `{"Action":"pass","Package":"example.com/flaky",` +
+		`"Test":"TestFlaky"}
+{"Action":"pass","Package":"example.com/flaky"}
+`
+	// flakyRerunFlaky contains the events of a flaky re-run.
+	flakyRerunFlaky = `{"Action":"fail","Package":"example.com/flaky",` +
+		`"Test":"TestFlaky"}
+{"Action":"pass","Package":"example.com/flaky","Test":"TestFlaky"}
+{"Action":"pass","Package":"example.com/flaky","Test":"TestFlaky"}
+`
+	// flakyRerunFailing contains the events of a failing re-run.
+	flakyRerunFailing = `{"Action":"fail","Package":"example.com/flaky",` +
+		`"Test":"TestFlaky"}
+{"Action":"fail","Package":"example.com/flaky","Test":"TestFlaky"}
+{"Action":"fail","Package":"example.com/flaky","Test":"TestFlaky"}
+`
+)
+
+// DirFlaky returns the project directory of the flaky test case with given
+// name.
+func DirFlaky(name string) string {
+	return filepath.Join(dirTargets, "flaky", name)
+}
+
+// EnvFlaky returns the environment of the flaky test case with given name
+// using the given flaky test settings.
+func EnvFlaky(name, count, allow string) []string {
+	return []string{
+		EnvTestFlakyCount + "=" + count,
+		EnvTestFlakyAllow + "=" + allow,
+		EnvFileFlaky + "=" + filepath.Join(DirFlaky(name), "flaky.json"),
+	}
+}
+
+// RecordFlaky returns the flaky test record message with given status and
+// number of failures.
+func RecordFlaky(status string, failures int) string {
+	return (&flaky.Record{
+		Package: "example.com/flaky", Test: "TestFlaky",
+		Runs: 3, Failures: failures, Status: status,
+	}).String()
+}
+
+type TestFlakyParams struct {
+	mockSetup    mock.SetupFunc
+	dir          string
+	env          []string
+	args         []string
+	stdin        io.Reader
+	files        map[string]string
+	expectStdout string
+	expectFlaky  []string
+	expectError  error
+	expectExit   int
+}
+
+var testFlakyTestCases = map[string]TestFlakyParams{
+	"tests passed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("passed", "3", "false")...),
+				"nil", "builder", "stderr", DirFlaky("passed"), "", nil),
+		),
+		dir:   "passed",
+		env:   EnvFlaky("passed", "3", "false"),
+		args:  []string{"go-make", "--test-flaky-rerun"},
+		stdin: strings.NewReader(flakyPass),
+	},
+	"flaky allowed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("allowed", "3", "true")...),
+				"nil", "builder", "stderr", DirFlaky("allowed"), "", nil),
+			Exec(CmdGoTestRerun("example.com/flaky", "^(TestFlaky)$", 3,
+				[]string{"-race"}, DirFlaky("allowed"),
+				EnvFlaky("allowed", "3", "true")...),
+				"nil", "builder", "stderr", flakyRerunFlaky, "",
+				assert.AnError),
+			LogMessage("stdout", RecordFlaky(flaky.StatusFlaky, 1)),
+			LogWarning("stderr", "flaky tests allowed [count=1]"),
+		),
+		dir:          "allowed",
+		env:          EnvFlaky("allowed", "3", "true"),
+		args:         []string{"go-make", "--test-flaky-rerun", "-race"},
+		stdin:        strings.NewReader(flakyRun),
+		expectStdout: "--- FAIL: TestFlaky (0.01s)\n",
+		expectFlaky:  []string{`"status":"flaky"`},
+	},
+	"flaky failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("flaky", "2", "false")...),
+				"nil", "builder", "stderr", DirFlaky("flaky"), "", nil),
+			Exec(CmdGoTestRerun("example.com/flaky", "^(TestFlaky)$", 2,
+				nil, DirFlaky("flaky"), EnvFlaky("flaky", "2", "false")...),
+				"nil", "builder", "stderr", flakyRerunFlaky, "",
+				assert.AnError),
+			LogMessage("stdout", RecordFlaky(flaky.StatusFlaky, 1)),
+			LogError("stderr", CmdTestFlakyRerun, NewErrTestFlaky(1)),
+		),
+		dir:          "flaky",
+		env:          EnvFlaky("flaky", "2", "false"),
+		args:         []string{"go-make", "--test-flaky-rerun"},
+		stdin:        strings.NewReader(flakyRun),
+		expectStdout: "--- FAIL: TestFlaky (0.01s)\n",
+		expectFlaky:  []string{`"status":"flaky"`},
+		expectError:  NewErrTestFlaky(1),
+		expectExit:   ExitCommandFailure,
+	},
+	"failing and broken": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("failing", "3", "true")...),
+				"nil", "builder", "stderr", DirFlaky("failing"), "", nil),
+			Exec(CmdGoTestRerun("example.com/flaky", "^(TestFlaky)$", 3,
+				nil, DirFlaky("failing"), EnvFlaky("failing", "3", "true")...),
+				"nil", "builder", "stderr", flakyRerunFailing, "",
+				assert.AnError),
+			LogMessage("stdout", RecordFlaky(flaky.StatusFailing, 3)),
+			LogMessage("stdout", "broken [pkg=example.com/broken]"),
+			LogError("stderr", CmdTestFlakyRerun, NewErrTestFailed(1, 1)),
+		),
+		dir:          "failing",
+		env:          EnvFlaky("failing", "3", "true"),
+		args:         []string{"go-make", "--test-flaky-rerun"},
+		stdin:        strings.NewReader(flakyRun + flakyBroken),
+		expectStdout: "--- FAIL: TestFlaky (0.01s)\n",
+		expectFlaky:  []string{`"status":"failing"`},
+		expectError:  NewErrTestFailed(1, 1),
+		expectExit:   ExitCommandFailure,
+	},
+	"history failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("history", "3", "true")...),
+				"nil", "builder", "stderr", DirFlaky("history"), "", nil),
+			Exec(CmdGoTestRerun("example.com/flaky", "^(TestFlaky)$", 3,
+				nil, DirFlaky("history"), EnvFlaky("history", "3", "true")...),
+				"nil", "builder", "stderr", flakyRerunFlaky, "", nil),
+			LogMessage("stdout", RecordFlaky(flaky.StatusFlaky, 1)),
+			LogError("stderr", "write flaky history", flaky.NewErrHistory(
+				filepath.Join(DirFlaky("history"), "flaky.json"),
+				&fs.PathError{
+					Op: "open", Err: syscall.EISDIR, Path: filepath.Join(
+						DirFlaky("history"), "flaky.json"),
+				})),
+			LogWarning("stderr", "flaky tests allowed [count=1]"),
+		),
+		dir:          "history",
+		env:          EnvFlaky("history", "3", "true"),
+		args:         []string{"go-make", "--test-flaky-rerun"},
+		stdin:        strings.NewReader(flakyRun),
+		files:        map[string]string{"flaky.json/file": ""},
+		expectStdout: "--- FAIL: TestFlaky (0.01s)\n",
+	},
+	"classify failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("classify", "3", "true")...),
+				"nil", "builder", "stderr", DirFlaky("classify"), "", nil),
+			Exec(CmdGoTestRerun("example.com/flaky", "^(TestFlaky)$", 3,
+				nil, DirFlaky("classify"), EnvFlaky("classify", "3", "true")...),
+				"nil", "builder", "stderr", strings.Repeat("x", 1024*1024+1),
+				"", nil),
+			LogError("stderr", "classify tests", bufio.ErrTooLong),
+		),
+		dir:          "classify",
+		env:          EnvFlaky("classify", "3", "true"),
+		args:         []string{"go-make", "--test-flaky-rerun"},
+		stdin:        strings.NewReader(flakyRun),
+		expectStdout: "--- FAIL: TestFlaky (0.01s)\n",
+		expectError:  bufio.ErrTooLong,
+		expectExit:   ExitCommandFailure,
+	},
+	"invalid count": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("count", "0", "true")...),
+				"nil", "builder", "stderr", DirFlaky("count"), "", nil),
+			LogError("stderr", "parse test-flaky-rerun",
+				NewErrInvalidArg(CmdTestFlakyRerun,
+					EnvTestFlakyCount+"=0", nil)),
+		),
+		dir:  "count",
+		env:  EnvFlaky("count", "0", "true"),
+		args: []string{"go-make", "--test-flaky-rerun"},
+		expectError: NewErrInvalidArg(CmdTestFlakyRerun,
+			EnvTestFlakyCount+"=0", nil),
+		expectExit: ExitCommandFailure,
+	},
+	"invalid allow": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("allow", "3", "maybe")...),
+				"nil", "builder", "stderr", DirFlaky("allow"), "", nil),
+			LogErrorAny("stderr", "parse test-flaky-rerun"),
+		),
+		dir:  "allow",
+		env:  EnvFlaky("allow", "3", "maybe"),
+		args: []string{"go-make", "--test-flaky-rerun"},
+		expectError: NewErrInvalidArg(CmdTestFlakyRerun,
+			EnvTestFlakyAllow+"=maybe", &strconv.NumError{
+				Func: "ParseBool", Num: "maybe", Err: strconv.ErrSyntax,
+			}),
+		expectExit: ExitCommandFailure,
+	},
+	"convert failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("convert", "3", "true")...),
+				"nil", "builder", "stderr", DirFlaky("convert"), "", nil),
+			LogError("stderr", "convert test output", assert.AnError),
+		),
+		dir:         "convert",
+		env:         EnvFlaky("convert", "3", "true"),
+		args:        []string{"go-make", "--test-flaky-rerun"},
+		stdin:       iotest.ErrReader(assert.AnError),
+		expectError: assert.AnError,
+		expectExit:  ExitCommandFailure,
+	},
+	"empty input": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("empty", "3", "false")...),
+				"nil", "builder", "stderr", DirFlaky("empty"), "", nil),
+			LogError("stderr", CmdTestFlakyRerun, NewErrTestResults()),
+		),
+		dir:         "empty",
+		env:         EnvFlaky("empty", "3", "false"),
+		args:        []string{"go-make", "--test-flaky-rerun"},
+		stdin:       strings.NewReader(""),
+		expectError: NewErrTestResults(),
+		expectExit:  ExitCommandFailure,
+	},
+	"non-json input": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvFlaky("text", "3", "false")...),
+				"nil", "builder", "stderr", DirFlaky("text"), "", nil),
+			LogError("stderr", CmdTestFlakyRerun, NewErrTestResults()),
+		),
+		dir:          "text",
+		env:          EnvFlaky("text", "3", "false"),
+		args:         []string{"go-make", "--test-flaky-rerun"},
+		stdin:        strings.NewReader("flag provided but not defined: -x\n"),
+		expectStdout: "flag provided but not defined: -x\n",
+		expectError:  NewErrTestResults(),
+		expectExit:   ExitCommandFailure,
+	},
+	"config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirFlaky("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirFlaky("config")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirFlaky("config")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirFlaky("config")), assert.AnError))),
+		),
+		dir:  "config",
+		args: []string{"go-make", "--test-flaky-rerun"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirFlaky("config")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+}
+
+func TestTestFlaky(t *testing.T) {
+	test.Map(t, testFlakyTestCases).
+		Run(func(t test.Test, param TestFlakyParams) {
+			// Given
+			dir := DirFlaky(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				WriteFile(path, 0o600, content)
+			}
+			gm, mocks := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+			if param.stdin != nil {
+				gm.Stdin = param.stdin
+			}
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			assert.Equal(t, "stdout"+param.expectStdout,
+				mocks.GetArg("stdout").(*strings.Builder).String())
+			if param.expectFlaky != nil {
+				data, err := os.ReadFile(filepath.Join(dir, "flaky.json"))
+				assert.NoError(t, err)
+				for _, content := range param.expectFlaky {
+					assert.Contains(t, string(data), content)
+				}
+			} else if param.files == nil {
+				_, err := os.Stat(filepath.Join(dir, "flaky.json"))
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+			}
+		})
+}
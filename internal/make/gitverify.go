@@ -0,0 +1,329 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/verify"
+)
+
+const (
+	// CmdGitVerify provides the name of the native git-verify command.
+	CmdGitVerify = "git-verify"
+	// EnvCommitConvention provides the name of the makefile variable
+	// containing the allowed conventional commit types.
+	EnvCommitConvention = "COMMIT_CONVENTION"
+	// EnvGitAuthor provides the name of the makefile variable containing the
+	// git author expected in the `Signed-off-by` trailer.
+	EnvGitAuthor = "GITAUTHOR"
+	// EnvFileGitVerify provides the name of the commit rules file environment
+	// variable.
+	EnvFileGitVerify = "FILE_GIT_VERIFY"
+	// FileGitVerify provides the default name of the project commit rules
+	// file.
+	FileGitVerify = ".git-verify.json"
+)
+
+// Available git-verify modes.
+const (
+	// VerifyMessage verifies a single commit message file.
+	VerifyMessage = "message"
+	// VerifyLog verifies a git log file in raw format.
+	VerifyLog = "log"
+	// VerifyBranch verifies the commits of the current branch.
+	VerifyBranch = "branch"
+	// VerifyPull verifies the commits of the current branch not contained
+	// in the remote target branch.
+	VerifyPull = "pull"
+)
+
+// ErrVerify represent a commit convention verification failure.
+var ErrVerify = errors.New("verify failed")
+
+// NewErrVerify creates a verification failure for the given mode reporting
+// the given number of errors.
+func NewErrVerify(mode string, errors int) error {
+	return fmt.Errorf("%w [mode=%s, errors=%d]", ErrVerify, mode, errors)
+}
+
+// CmdGitBranch creates the argument array of a `git branch --show-current`
+// command to get the current branch.
+func CmdGitBranch(dir string, env ...string) *cmd.Cmd {
+	return cmd.New("git", "branch", "--show-current").
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdGitRemote creates the argument array of a `git remote show origin`
+// command to get the default branch of the remote repository.
+func CmdGitRemote(dir string, env ...string) *cmd.Cmd {
+	return cmd.New("git", "remote", "show", "origin").
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdGitFetch creates the argument array of a `git fetch origin <branch>`
+// command to update the given remote branch.
+func CmdGitFetch(branch, dir string, env ...string) *cmd.Cmd {
+	return cmd.New("git", "fetch", "origin", branch, "--verbose").
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdGitLog creates the argument array of a `git log --format=raw` command
+// listing the commits of the given revisions excluding merges.
+func CmdGitLog(revs []string, dir string, env ...string) *cmd.Cmd {
+	return cmd.New(append([]string{
+		"git", "log", "--no-merges", "--format=raw",
+	}, revs...)...).WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdGitConfig creates the argument array of a `git config --get <key>`
+// command to get the value of the given git config key.
+func CmdGitConfig(key, dir string, env ...string) *cmd.Cmd {
+	return cmd.New("git", "config", "--get", key).
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// verifyArgs contains the parsed arguments of the git-verify command.
+type verifyArgs struct {
+	// mode provides the verification mode.
+	mode string
+	// arg provides the file of the message and log mode, or the target
+	// branch of the pull mode.
+	arg string
+	// json indicates whether to report the diagnostics as JSON.
+	json bool
+}
+
+// verifyReport represents the JSON report of the git-verify command.
+type verifyReport struct {
+	// Mode provides the verification mode.
+	Mode string `json:"mode"`
+	// Errors provides the number of errors.
+	Errors int `json:"errors"`
+	// Diagnostics provides the diagnostics of the errors.
+	Diagnostics []*verify.Diagnostic `json:"diagnostics"`
+}
+
+// parseVerify parses the arguments of the git-verify command.
+func parseVerify(args ...string) (*verifyArgs, error) {
+	params := &verifyArgs{}
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			params.json = true
+		case params.mode == "" && (arg == VerifyMessage ||
+			arg == VerifyLog || arg == VerifyBranch || arg == VerifyPull):
+			params.mode = arg
+		case params.mode != "" && params.mode != VerifyBranch &&
+			params.arg == "" && !strings.HasPrefix(arg, "-"):
+			params.arg = arg
+		default:
+			return nil, NewErrInvalidArg(CmdGitVerify, arg, nil)
+		}
+	}
+
+	switch params.mode {
+	case "":
+		params.mode = VerifyPull
+	case VerifyMessage, VerifyLog:
+		if params.arg == "" {
+			return nil, NewErrInvalidArg(CmdGitVerify, params.mode, nil)
+		}
+	}
+	return params, nil
+}
+
+// gitVerify runs the native git-verify command with given arguments. It
+// verifies that the commit message file, the git log file, the commits of
+// the current branch, or the commits of the current branch not yet pulled
+// into the target branch follow the commit rules of the project, by default
+// the commit types of `COMMIT_CONVENTION`, the issue references, and the
+// `Signed-off-by` trailer, that must name the git author in message mode.
+func (gm *GoMake) gitVerify(args ...string) (int, error) {
+	params, err := parseVerify(args...)
+	if err != nil {
+		gm.error("parse git-verify", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	commits, err := gm.verifyCommits(ctx, params)
+	if err != nil {
+		gm.error("read commits", err)
+		return ExitCommandFailure, err
+	}
+
+	rules, err := gm.verifyRules()
+	if err != nil {
+		gm.error("read rules", err)
+		return ExitCommandFailure, err
+	}
+	if len(rules.Types) == 0 {
+		types, err := gm.variable(ctx,
+			EnvCommitConvention, verify.DefaultTypes)
+		if err != nil {
+			gm.error("ensure config", err)
+			return ExitConfigFailure, err
+		}
+		rules.Types = strings.Fields(types)
+	}
+	author := ""
+	if params.mode == VerifyMessage {
+		author = gm.verifyAuthor(ctx)
+	}
+
+	verifier, err := verify.NewVerifier(rules, author)
+	if err != nil {
+		gm.error("verify rules", err)
+		return ExitCommandFailure, err
+	}
+	diagnostics := verifier.Verify(commits...)
+	if params.json {
+		encoder := json.NewEncoder(gm.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(&verifyReport{
+			Mode: params.mode, Errors: len(diagnostics),
+			Diagnostics: diagnostics,
+		})
+	} else {
+		for _, diagnostic := range diagnostics {
+			gm.error("", diagnostic)
+		}
+	}
+
+	if len(diagnostics) != 0 {
+		err := NewErrVerify(params.mode, len(diagnostics))
+		gm.error(CmdGitVerify, err)
+		return ExitCommandFailure, err
+	}
+	return ExitSuccess, nil
+}
+
+// verifyCommits reads the commits to verify for the given git-verify
+// arguments from the message or log file, or from the git log of the current
+// branch.
+func (gm *GoMake) verifyCommits(
+	ctx context.Context, params *verifyArgs,
+) ([]*verify.Commit, error) {
+	switch params.mode {
+	case VerifyMessage, VerifyLog:
+		// #nosec G304 -- file is provided by the user to verify.
+		file, err := os.Open(params.arg)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // wrapped by caller.
+		}
+		defer file.Close()
+
+		if params.mode == VerifyLog {
+			return verify.ParseLog(params.arg, file)
+		}
+		commit, err := verify.ParseMessage(params.arg, file)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // wrapped by caller.
+		}
+		return []*verify.Commit{commit}, nil
+	}
+
+	branch, err := gm.gitOutput(ctx, CmdGitBranch(gm.WorkDir, gm.Env...))
+	if err != nil {
+		return nil, err
+	}
+	revs := []string{branch}
+	if params.mode == VerifyPull {
+		target := params.arg
+		if target == "" || target == "all" {
+			if target, err = gm.gitMainBranch(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if err := gm.exec(ctx, CmdGitFetch(target, gm.WorkDir, gm.Env...).
+			WithIO(nil, gm.Stderr, gm.Stderr)); err != nil {
+			return nil, err
+		}
+		revs = append(revs, "^origin/"+target)
+	}
+
+	log := &strings.Builder{}
+	if err := gm.exec(ctx, CmdGitLog(revs, gm.WorkDir, gm.Env...).
+		WithIO(nil, log, gm.Stderr)); err != nil {
+		return nil, err
+	}
+	return verify.ParseLog(params.mode, strings.NewReader(log.String()))
+}
+
+// verifyRules reads the commit rules from the file provided by the
+// `FILE_GIT_VERIFY` environment variable or from the default project file,
+// if it exists. Rules missing in the file are taken from the default rules,
+// except for the commit types that are left empty.
+func (gm *GoMake) verifyRules() (*verify.Rules, error) {
+	rules := verify.DefaultRules("")
+	file := gm.GetEnvDefault(EnvFileGitVerify,
+		filepath.Join(gm.WorkDir, FileGitVerify))
+
+	// #nosec G304 -- file is provided by the project.
+	reader, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, nil
+	} else if err != nil {
+		return nil, verify.NewErrRules(file, err)
+	}
+	defer reader.Close()
+
+	return verify.ReadRules(file, reader, rules)
+}
+
+// verifyAuthor returns the git author expected in the `Signed-off-by`
+// trailer as provided by the `GITAUTHOR` environment variable, or by the git
+// config of the user name and email. If the user name or email is not
+// configured, it returns an empty author to skip the author check.
+func (gm *GoMake) verifyAuthor(ctx context.Context) string {
+	if author := gm.GetEnvDefault(EnvGitAuthor, ""); author != "" {
+		return author
+	}
+
+	name, err := gm.gitOutput(ctx,
+		CmdGitConfig("user.name", gm.WorkDir, gm.Env...))
+	if err != nil {
+		return ""
+	}
+	email, err := gm.gitOutput(ctx,
+		CmdGitConfig("user.email", gm.WorkDir, gm.Env...))
+	if err != nil {
+		return ""
+	}
+	return name + " <" + email + ">"
+}
+
+// gitMainBranch returns the default branch of the remote repository.
+func (gm *GoMake) gitMainBranch(ctx context.Context) (string, error) {
+	output, err := gm.gitOutput(ctx, CmdGitRemote(gm.WorkDir, gm.Env...))
+	if err != nil {
+		return "", err
+	}
+	for line := range strings.Lines(output) {
+		if branch, ok := strings.CutPrefix(
+			strings.TrimSpace(line), "HEAD branch:"); ok {
+			return strings.TrimSpace(branch), nil
+		}
+	}
+	return "", NewErrCallFailed(CmdGitRemote(gm.WorkDir), io.ErrUnexpectedEOF)
+}
+
+// gitOutput executes the given git command and returns its trimmed output.
+func (gm *GoMake) gitOutput(ctx context.Context, cmd *cmd.Cmd) (string, error) {
+	output := &strings.Builder{}
+	if err := gm.exec(ctx, cmd.WithIO(nil, output, gm.Stderr)); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output.String()), nil
+}
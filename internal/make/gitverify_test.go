@@ -0,0 +1,591 @@
+package make_test
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/verify"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// authorVerify contains the author of the verify fixtures.
+	authorVerify = "John Doe <john.doe@zalando.de>"
+
+	// envVerify contains the environment providing the commit convention
+	// and the author for verifying the fixtures.
+	envVerify = []string{
+		EnvCommitConvention + "=" + verify.DefaultTypes,
+		EnvGitAuthor + "=" + authorVerify,
+	}
+
+	// logVerifyOkay contains a raw git log following the commit conventions.
+	logVerifyOkay = "commit abc123\ntree 123abc\n" +
+		"author John Doe <john.doe@zalando.de> 1704900000 +0100\n\n" +
+		"    feat: add verifier (#1)\n\n" +
+		"    Signed-off-by: " + authorVerify + "\n"
+	// logVerifyFailed contains a raw git log violating the commit
+	// conventions.
+	logVerifyFailed = "commit def456\ntree 456def\n\n" +
+		"    add verifier\n\n" +
+		"    Signed-off-by: " + authorVerify + "\n"
+
+	// jsonVerifyFailed contains the JSON report of the failed raw git log.
+	jsonVerifyFailed = `{
+  "mode": "pull",
+  "errors": 2,
+  "diagnostics": [
+    {
+      "name": "pull",
+      "commit": "def456",
+      "line": 4,
+      "column": 5,
+      "rule": "type-missing",
+      "message": "commit type missing",
+      "context": "title=add verifier"
+    },
+    {
+      "name": "pull",
+      "commit": "def456",
+      "line": 4,
+      "column": 17,
+      "rule": "issue-missing",
+      "message": "issue missing",
+      "context": "title=add verifier"
+    }
+  ]
+}
+`
+)
+
+// EnvRules returns the environment providing the author and the commit rules
+// fixture with given name.
+func EnvRules(name string) []string {
+	return []string{
+		EnvGitAuthor + "=" + authorVerify, EnvFileGitVerify + "=" +
+			filepath.Join(dirFixtures, "git-verify", name),
+	}
+}
+
+// ErrRulesUnknown returns the error of reading the commit rules fixture with
+// unknown rules.
+func ErrRulesUnknown() error {
+	decoder := json.NewDecoder(strings.NewReader(`{"unknown": true}`))
+	decoder.DisallowUnknownFields()
+	return verify.NewErrRules(filepath.Join(dirFixtures, "git-verify",
+		"rules-unknown.json"), decoder.Decode(&verify.Rules{}))
+}
+
+// FileVerify returns the path of the verify fixture with given name.
+func FileVerify(name string) string {
+	return filepath.Join(dirRoot, "internal", "verify", "fixtures", name)
+}
+
+// DiagnosticsVerifyFailed returns the diagnostics of the failed raw git log
+// for the input with given name.
+func DiagnosticsVerifyFailed(name string) []*verify.Diagnostic {
+	return []*verify.Diagnostic{{
+		Name: name, Commit: "def456", Line: 4, Column: 5,
+		Rule: verify.RuleTypeMissing, Message: "commit type missing",
+		Context: "title=add verifier",
+	}, {
+		Name: name, Commit: "def456", Line: 4, Column: 17,
+		Rule: verify.RuleIssueMissing, Message: "issue missing",
+		Context: "title=add verifier",
+	}}
+}
+
+// DiagnosticsVerifyLog returns the diagnostics of the raw git log file with
+// given name using the default commit types.
+func DiagnosticsVerifyLog(name string) []*verify.Diagnostic {
+	file, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	commits, err := verify.ParseLog(name, file)
+	if err != nil {
+		panic(err)
+	}
+	verifier, err := verify.NewVerifier(
+		verify.DefaultRules(verify.DefaultTypes), "")
+	if err != nil {
+		panic(err)
+	}
+	return verifier.Verify(commits...)
+}
+
+// LogDiagnostics logs the given diagnostics as errors.
+func LogDiagnostics(diagnostics ...*verify.Diagnostic) mock.SetupFunc {
+	setups := []func(*mock.Mocks) any{}
+	for _, diagnostic := range diagnostics {
+		setups = append(setups, LogError("stderr", "", diagnostic))
+	}
+	return mock.Chain(setups...)
+}
+
+type GitVerifyParams struct {
+	mockSetup    mock.SetupFunc
+	env          []string
+	args         []string
+	expectStdout string
+	expectError  error
+	expectExit   int
+}
+
+var gitVerifyTestCases = map[string]GitVerifyParams{
+	"message okay": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		env: envVerify,
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+	},
+	"message okay json": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		env: envVerify,
+		args: []string{
+			"go-make", "--git-verify", "--json",
+			"message", FileVerify("msg-okay.in"),
+		},
+		expectStdout: "{\n  \"mode\": \"message\",\n  \"errors\": 0,\n" +
+			"  \"diagnostics\": []\n}\n",
+	},
+	"message failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogDiagnostics(&verify.Diagnostic{
+				Name: FileVerify("msg-failed.in"), Line: 1, Column: 1,
+				Rule: verify.RuleTypeMissing, Message: "commit type missing",
+				Context: "title=feat{wrong}: all is somehow wrong (org#1)",
+			}, &verify.Diagnostic{
+				Name: FileVerify("msg-failed.in"), Line: 1, Column: 42,
+				Rule: verify.RuleIssueMissing, Message: "issue missing",
+				Context: "title=feat{wrong}: all is somehow wrong (org#1)",
+			}, &verify.Diagnostic{
+				Name: FileVerify("msg-failed.in"), Line: 3, Column: 16,
+				Rule:    verify.RuleSignedAuthor,
+				Message: "signed-off-by not the author",
+				Context: "sign=Signed-off-by: Alice Doe <alice.doe@zalando.de>, " +
+					"author=" + authorVerify,
+			}),
+			LogError("stderr", CmdGitVerify,
+				NewErrVerify(VerifyMessage, 3)),
+		),
+		env: envVerify,
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-failed.in"),
+		},
+		expectError: NewErrVerify(VerifyMessage, 3),
+		expectExit:  ExitCommandFailure,
+	},
+	"message author from git config": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify[0]),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitConfig("user.name", dirRoot, envVerify[0]),
+				"nil", "builder", "stderr", "John Doe\n", "", nil),
+			Exec(CmdGitConfig("user.email", dirRoot, envVerify[0]),
+				"nil", "builder", "stderr", "john.doe@zalando.de\n", "", nil),
+		),
+		env: envVerify[:1],
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+	},
+	"message author without git user name": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify[0]),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitConfig("user.name", dirRoot, envVerify[0]),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogDiagnostics(&verify.Diagnostic{
+				Name: FileVerify("msg-failed.in"), Line: 1, Column: 1,
+				Rule: verify.RuleTypeMissing, Message: "commit type missing",
+				Context: "title=feat{wrong}: all is somehow wrong (org#1)",
+			}, &verify.Diagnostic{
+				Name: FileVerify("msg-failed.in"), Line: 1, Column: 42,
+				Rule: verify.RuleIssueMissing, Message: "issue missing",
+				Context: "title=feat{wrong}: all is somehow wrong (org#1)",
+			}),
+			LogError("stderr", CmdGitVerify,
+				NewErrVerify(VerifyMessage, 2)),
+		),
+		env: envVerify[:1],
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-failed.in"),
+		},
+		expectError: NewErrVerify(VerifyMessage, 2),
+		expectExit:  ExitCommandFailure,
+	},
+	"message types from config": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify[1]),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, envVerify[1]),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, envVerify[1]),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvCommitConvention+" := fix chore\n", "", nil),
+			LogDiagnostics(&verify.Diagnostic{
+				Name: FileVerify("msg-okay.in"), Line: 1, Column: 1,
+				Rule: verify.RuleTypeInvalid, Message: "commit type invalid",
+				Context: "title=feat[make]!: validate commit message " +
+					"(#0,org/repo#1)",
+			}),
+			LogError("stderr", CmdGitVerify,
+				NewErrVerify(VerifyMessage, 1)),
+		),
+		env: envVerify[1:],
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+		expectError: NewErrVerify(VerifyMessage, 1),
+		expectExit:  ExitCommandFailure,
+	},
+	"message types default": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify[1]),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, envVerify[1]),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, envVerify[1]),
+				"nil", "builder", "discard", "", "", nil),
+		),
+		env: envVerify[1:],
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+	},
+	"message types config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify[1]),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, envVerify[1]),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version, dirRoot,
+				envVerify[1]), "nil", "stderr", "stderr", "", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version, dirRoot),
+					assert.AnError))),
+		),
+		env: envVerify[1:],
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				dirRoot), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"message rules from file": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvRules("rules-jira.json")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogDiagnostics(&verify.Diagnostic{
+				Name: FileVerify("msg-okay.in"), Line: 1, Column: 53,
+				Rule: verify.RuleIssueMissing, Message: "issue missing",
+				Context: "title=feat[make]!: validate commit message " +
+					"(#0,org/repo#1)",
+			}, &verify.Diagnostic{
+				Name: FileVerify("msg-okay.in"), Line: 6, Column: 1,
+				Rule:    verify.RuleSignedForbidden,
+				Message: "signed-off-by forbidden",
+				Context: "sign=Signed-off-by: " + authorVerify,
+			}),
+			LogError("stderr", CmdGitVerify,
+				NewErrVerify(VerifyMessage, 2)),
+		),
+		env: EnvRules("rules-jira.json"),
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+		expectError: NewErrVerify(VerifyMessage, 2),
+		expectExit:  ExitCommandFailure,
+	},
+	"message rules unknown": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvRules("rules-unknown.json")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogError("stderr", "read rules", ErrRulesUnknown()),
+		),
+		env: EnvRules("rules-unknown.json"),
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+		expectError: ErrRulesUnknown(),
+		expectExit:  ExitCommandFailure,
+	},
+	"message rules invalid": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, append(EnvRules("rules-value.json"),
+				envVerify[0])...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogError("stderr", "verify rules",
+				verify.NewErrRuleValue("signed-off-by", "sometimes")),
+		),
+		env: append(EnvRules("rules-value.json"), envVerify[0]),
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-okay.in"),
+		},
+		expectError: verify.NewErrRuleValue("signed-off-by", "sometimes"),
+		expectExit:  ExitCommandFailure,
+	},
+	"message missing file": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogError("stderr", "read commits", &fs.PathError{
+				Op: "open", Path: FileVerify("msg-missing.in"),
+				Err: syscall.ENOENT,
+			}),
+		),
+		env: envVerify,
+		args: []string{
+			"go-make", "--git-verify", "message", FileVerify("msg-missing.in"),
+		},
+		expectError: &fs.PathError{
+			Op: "open", Path: FileVerify("msg-missing.in"),
+			Err: syscall.ENOENT,
+		},
+		expectExit: ExitCommandFailure,
+	},
+
+	"log failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogDiagnostics(DiagnosticsVerifyLog(FileVerify("log-all.in"))...),
+			LogError("stderr", CmdGitVerify, NewErrVerify(VerifyLog, 7)),
+		),
+		env: envVerify,
+		args: []string{
+			"go-make", "--git-verify", "log", FileVerify("log-all.in"),
+		},
+		expectError: NewErrVerify(VerifyLog, 7),
+		expectExit:  ExitCommandFailure,
+	},
+
+	"branch okay": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitLog([]string{"feature"}, dirRoot, envVerify...),
+				"nil", "builder", "stderr", logVerifyOkay, "", nil),
+		),
+		env:  envVerify,
+		args: []string{"go-make", "--git-verify", "branch"},
+	},
+	"branch failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogError("stderr", "read commits", NewErrCallFailed(
+				CmdGitBranch(dirRoot), assert.AnError)),
+		),
+		env:         envVerify,
+		args:        []string{"go-make", "--git-verify", "branch"},
+		expectError: NewErrCallFailed(CmdGitBranch(dirRoot), assert.AnError),
+		expectExit:  ExitCommandFailure,
+	},
+	"branch log failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitLog([]string{"feature"}, dirRoot, envVerify...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogError("stderr", "read commits", NewErrCallFailed(
+				CmdGitLog([]string{"feature"}, dirRoot), assert.AnError)),
+		),
+		env:  envVerify,
+		args: []string{"go-make", "--git-verify", "branch"},
+		expectError: NewErrCallFailed(
+			CmdGitLog([]string{"feature"}, dirRoot), assert.AnError),
+		expectExit: ExitCommandFailure,
+	},
+
+	"pull default failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitRemote(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "* remote origin\n"+
+					"  HEAD branch: main\n", "", nil),
+			Exec(CmdGitFetch("main", dirRoot, envVerify...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdGitLog([]string{"feature", "^origin/main"},
+				dirRoot, envVerify...), "nil", "builder", "stderr",
+				logVerifyFailed, "", nil),
+			LogDiagnostics(DiagnosticsVerifyFailed(VerifyPull)...),
+			LogError("stderr", CmdGitVerify, NewErrVerify(VerifyPull, 2)),
+		),
+		env:         envVerify,
+		args:        []string{"go-make", "--git-verify"},
+		expectError: NewErrVerify(VerifyPull, 2),
+		expectExit:  ExitCommandFailure,
+	},
+	"pull all json failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitRemote(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "  HEAD branch: main\n", "", nil),
+			Exec(CmdGitFetch("main", dirRoot, envVerify...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdGitLog([]string{"feature", "^origin/main"},
+				dirRoot, envVerify...), "nil", "builder", "stderr",
+				logVerifyFailed, "", nil),
+			LogError("stderr", CmdGitVerify, NewErrVerify(VerifyPull, 2)),
+		),
+		env:          envVerify,
+		args:         []string{"go-make", "--git-verify", "pull", "all", "--json"},
+		expectStdout: jsonVerifyFailed,
+		expectError:  NewErrVerify(VerifyPull, 2),
+		expectExit:   ExitCommandFailure,
+	},
+	"pull branch okay": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitFetch("develop", dirRoot, envVerify...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdGitLog([]string{"feature", "^origin/develop"},
+				dirRoot, envVerify...), "nil", "builder", "stderr",
+				logVerifyOkay, "", nil),
+		),
+		env:  envVerify,
+		args: []string{"go-make", "--git-verify", "pull", "develop"},
+	},
+	"pull remote failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitRemote(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogError("stderr", "read commits", NewErrCallFailed(
+				CmdGitRemote(dirRoot), assert.AnError)),
+		),
+		env:         envVerify,
+		args:        []string{"go-make", "--git-verify", "pull"},
+		expectError: NewErrCallFailed(CmdGitRemote(dirRoot), assert.AnError),
+		expectExit:  ExitCommandFailure,
+	},
+	"pull remote without head": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitRemote(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "* remote origin\n", "", nil),
+			LogError("stderr", "read commits", NewErrCallFailed(
+				CmdGitRemote(dirRoot), io.ErrUnexpectedEOF)),
+		),
+		env:  envVerify,
+		args: []string{"go-make", "--git-verify", "pull"},
+		expectError: NewErrCallFailed(
+			CmdGitRemote(dirRoot), io.ErrUnexpectedEOF),
+		expectExit: ExitCommandFailure,
+	},
+	"pull fetch failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envVerify...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitBranch(dirRoot, envVerify...),
+				"nil", "builder", "stderr", "feature\n", "", nil),
+			Exec(CmdGitFetch("develop", dirRoot, envVerify...),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			LogError("stderr", "read commits", NewErrCallFailed(
+				CmdGitFetch("develop", dirRoot), assert.AnError)),
+		),
+		env:  envVerify,
+		args: []string{"go-make", "--git-verify", "pull", "develop"},
+		expectError: NewErrCallFailed(
+			CmdGitFetch("develop", dirRoot), assert.AnError),
+		expectExit: ExitCommandFailure,
+	},
+
+	"invalid mode": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse git-verify",
+				NewErrInvalidArg(CmdGitVerify, "unknown", nil)),
+		),
+		args:        []string{"go-make", "--git-verify", "unknown"},
+		expectError: NewErrInvalidArg(CmdGitVerify, "unknown", nil),
+		expectExit:  ExitCommandFailure,
+	},
+	"invalid branch arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse git-verify",
+				NewErrInvalidArg(CmdGitVerify, "main", nil)),
+		),
+		args:        []string{"go-make", "--git-verify", "branch", "main"},
+		expectError: NewErrInvalidArg(CmdGitVerify, "main", nil),
+		expectExit:  ExitCommandFailure,
+	},
+	"invalid message without file": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse git-verify",
+				NewErrInvalidArg(CmdGitVerify, VerifyMessage, nil)),
+		),
+		args:        []string{"go-make", "--git-verify", "message"},
+		expectError: NewErrInvalidArg(CmdGitVerify, VerifyMessage, nil),
+		expectExit:  ExitCommandFailure,
+	},
+}
+
+func TestGitVerify(t *testing.T) {
+	test.Map(t, gitVerifyTestCases).
+		Run(func(t test.Test, param GitVerifyParams) {
+			// Given
+			gm, mocks := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+			stdout := mocks.GetArg("stdout").(*strings.Builder)
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			assert.Equal(t, "stdout"+param.expectStdout, stdout.String())
+			_, statErr := os.Stat(gm.HistoryFile)
+			assert.ErrorIs(t, statErr, fs.ErrNotExist)
+		})
+}
@@ -0,0 +1,250 @@
+package make
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tkrop/go-make/internal/history"
+)
+
+const (
+	// CmdHistory provides the name of the native history command.
+	CmdHistory = "history"
+	// CmdHistoryRerun provides the name of the history re-run sub-command.
+	CmdHistoryRerun = "rerun"
+	// EnvFileHistory provides the name of the history file environment
+	// variable.
+	EnvFileHistory = "FILE_HISTORY"
+	// DefaultHistoryLimit provides the default number of listed records.
+	DefaultHistoryLimit = 20
+)
+
+// ErrInvalidArg represent an invalid command line argument error.
+var ErrInvalidArg = errors.New("invalid argument")
+
+// NewErrInvalidArg creates an invalid argument error for the given argument
+// of the given command.
+func NewErrInvalidArg(cmd, arg string, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w [cmd=%s, arg=%s]: %w", ErrInvalidArg, cmd, arg, err)
+	}
+	return fmt.Errorf("%w [cmd=%s, arg=%s]", ErrInvalidArg, cmd, arg)
+}
+
+// ErrNoRecord represent a missing history record error.
+var ErrNoRecord = errors.New("no history record")
+
+// NewErrNoRecord creates a missing history record error for the given index.
+func NewErrNoRecord(index int) error {
+	return fmt.Errorf("%w [index=%d]", ErrNoRecord, index)
+}
+
+// historyArgs contains the parsed arguments of the history command.
+type historyArgs struct {
+	// filter provides the record filter.
+	filter history.Filter
+	// limit provides the maximum number of listed records (0 = all).
+	limit int
+	// json indicates whether to list the records as JSON lines.
+	json bool
+	// rerun indicates whether to re-run a record.
+	rerun bool
+	// index provides the index of the record to re-run (0 = last).
+	index int
+}
+
+// historyRecord represents a listed history record with its index.
+type historyRecord struct {
+	// Index provides the index of the record in the run history.
+	Index int `json:"index"`
+	*history.Record
+}
+
+// commandArgs returns the arguments following the given native command, if
+// the command is requested via its reserved `--<command>` option before the
+// first target of the given arguments. This ensures that native commands
+// never shadow make targets of the same name.
+func commandArgs(cmd string, args ...string) ([]string, bool) {
+	for index, arg := range args {
+		if arg == "--"+cmd {
+			return args[index+1:], true
+		} else if !strings.HasPrefix(arg, "-") {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// fileHistory returns the path of the run history file. It uses the explicit
+// history file, the `FILE_HISTORY` environment variable, or the default file
+// in the per-project cache directory.
+func (gm *GoMake) fileHistory() string {
+	file := gm.HistoryFile
+	if file == "" {
+		file = gm.GetEnvDefault(EnvFileHistory, "")
+	}
+	if file == "" {
+		file = filepath.Join(gm.cacheDir(), "history.json")
+	}
+	return filepath.Clean(file)
+}
+
+// recordHistory appends the record of the finished invocation with given
+// arguments, make targets, start time, and exit code to the run history. The
+// record also contains the commit hash of `HEAD`, if the working directory is
+// a git repository. The working tree state is not checked to keep the
+// recording cheap for large repositories.
+func (gm *GoMake) recordHistory(
+	args, targets []string, start time.Time, exit int,
+) {
+	record := &history.Record{
+		Args:     args,
+		Targets:  targetNames(targets),
+		Dir:      gm.WorkDir,
+		Config:   gm.ConfigVersion,
+		Start:    start,
+		Duration: time.Since(start),
+		Exit:     exit,
+		Aborted:  gm.Aborted.Load(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	output := &strings.Builder{}
+	if err := gm.exec(ctx, CmdGitCommit(gm.WorkDir, gm.Env...).
+		WithIO(nil, output, io.Discard)); err == nil {
+		record.Commit = strings.TrimSpace(output.String())
+	}
+	if err := history.New(gm.fileHistory()).Append(record); err != nil {
+		gm.error("write history", err)
+	}
+}
+
+// targetNames returns the make targets of the given make arguments, i.e. the
+// arguments without options, their values, and variable assignments.
+func targetNames(args []string) []string {
+	var names []string
+	for index, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") ||
+			(index > 0 && slices.Contains(makeValueOptions, args[index-1])) {
+			continue
+		}
+		names = append(names, arg)
+	}
+	return names
+}
+
+// parseHistory parses the arguments of the history command.
+func parseHistory(args ...string) (*historyArgs, error) {
+	params := &historyArgs{limit: DefaultHistoryLimit}
+	for _, arg := range args {
+		switch {
+		case arg == CmdHistoryRerun:
+			params.rerun = true
+		case arg == "--failed":
+			params.filter.Failed = true
+		case arg == "--aborted":
+			params.filter.Aborted = true
+		case arg == "--json":
+			params.json = true
+		case strings.HasPrefix(arg, "--grep="):
+			params.filter.Grep = arg[len("--grep="):]
+		case strings.HasPrefix(arg, "--since="):
+			since, err := time.ParseDuration(arg[len("--since="):])
+			if err != nil {
+				return nil, NewErrInvalidArg(CmdHistory, arg, err)
+			}
+			params.filter.Since = time.Now().Add(-since)
+		case strings.HasPrefix(arg, "--limit="):
+			limit, err := strconv.Atoi(arg[len("--limit="):])
+			if err != nil || limit < 0 {
+				return nil, NewErrInvalidArg(CmdHistory, arg, err)
+			}
+			params.limit = limit
+		case params.rerun && params.index == 0:
+			index, err := strconv.Atoi(arg)
+			if err != nil || index <= 0 {
+				return nil, NewErrInvalidArg(CmdHistory, arg, err)
+			}
+			params.index = index
+		default:
+			return nil, NewErrInvalidArg(CmdHistory, arg, nil)
+		}
+	}
+	return params, nil
+}
+
+// history runs the native history command with given arguments. It lists
+// the filtered records of the run history of the current project, or re-runs
+// the record with given index, or the last matching record, if requested.
+func (gm *GoMake) history(args ...string) (int, error) {
+	params, err := parseHistory(args...)
+	if err != nil {
+		gm.error("parse history", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	records, err := history.New(gm.fileHistory()).Read()
+	if err != nil {
+		gm.error("read history", err)
+		return ExitCommandFailure, err
+	}
+
+	selected := []historyRecord{}
+	for index, record := range records {
+		if params.filter.Match(record) {
+			selected = append(selected, historyRecord{index + 1, record})
+		}
+	}
+
+	if params.rerun {
+		return gm.historyRerun(params.index, records, selected)
+	}
+
+	if params.limit > 0 && len(selected) > params.limit {
+		selected = selected[len(selected)-params.limit:]
+	}
+	for _, record := range selected {
+		if params.json {
+			data, _ := json.Marshal(record)
+			fmt.Fprintf(gm.Stdout, "%s\n", data)
+		} else {
+			record.Format(gm.Stdout, record.Index)
+		}
+	}
+	return ExitSuccess, nil
+}
+
+// historyRerun re-runs the record with given index from the given records, or
+// the last selected record, if no index is given.
+func (gm *GoMake) historyRerun(
+	index int, records []*history.Record, selected []historyRecord,
+) (int, error) {
+	var record *history.Record
+	switch {
+	case index > 0 && index <= len(records):
+		record = records[index-1]
+	case index == 0 && len(selected) > 0:
+		record = selected[len(selected)-1].Record
+	default:
+		err := NewErrNoRecord(index)
+		gm.error("rerun history", err)
+		return ExitCommandFailure, err
+	}
+
+	gm.Logger.Call(gm.Stderr, record.Args...)
+	gm.WorkDir = record.Dir
+	return gm.runTargets(false, record.Args...)
+}
@@ -0,0 +1,340 @@
+package make_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/history"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// timeHistory contains an arbitrary base time for history testing.
+	timeHistory = time.Date(2024, 1, 10, 16, 22, 54, 0, time.Local)
+
+	// recordsHistory contains an arbitrary run history for testing.
+	recordsHistory = []*history.Record{{
+		Args:     []string{"go-make", "test"},
+		Dir:      dirRoot,
+		Config:   "v0.0.25",
+		Start:    timeHistory,
+		Duration: 1500 * time.Millisecond,
+	}, {
+		Args:     []string{"go-make", "target"},
+		Dir:      dirRoot,
+		Config:   "v0.0.25",
+		Start:    timeHistory.Add(time.Hour),
+		Duration: 12 * time.Second,
+		Exit:     3,
+	}, {
+		Args:     []string{"go-make", "lint"},
+		Dir:      dirRoot,
+		Config:   "custom",
+		Start:    timeHistory.Add(2 * time.Hour),
+		Duration: 250 * time.Millisecond,
+	}}
+
+	// outHistory contains the formatted lines of the run history.
+	outHistory = []string{
+		"   1  2024-01-10 16:22:54      1.5s  exit=0   v0.0.25  go-make test\n",
+		"   2  2024-01-10 17:22:54       12s  exit=3   v0.0.25  go-make target\n",
+		"   3  2024-01-10 18:22:54     250ms  exit=0   custom  go-make lint\n",
+	}
+)
+
+// ParseIntError returns the error of parsing the given value as integer.
+func ParseIntError(value string) error {
+	_, err := strconv.Atoi(value)
+	return err
+}
+
+// ExecCommit sets up the git rev-parse command providing the commit recorded
+// in the run history for a run in the given directory using the given
+// environment.
+func ExecCommit(dir string, env ...string) mock.SetupFunc {
+	return Exec(CmdGitCommit(dir, env...),
+		"nil", "builder", "discard", "", "", nil)
+}
+
+type MakeHistoryParams struct {
+	mockSetup      mock.SetupFunc
+	args           []string
+	expectStdout   string
+	expectError    error
+	expectExit     int
+	expectRecorded int
+}
+
+var makeHistoryTestCases = map[string]MakeHistoryParams{
+	"history list": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		args:         []string{"go-make", "--history"},
+		expectStdout: strings.Join(outHistory, ""),
+	},
+	"history list limit": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		args:         []string{"go-make", "--history", "--limit=2"},
+		expectStdout: strings.Join(outHistory[1:], ""),
+	},
+	"history list failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		args:         []string{"go-make", "--history", "--failed"},
+		expectStdout: outHistory[1],
+	},
+	"history list aborted": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		args: []string{"go-make", "--history", "--aborted"},
+	},
+	"history list since": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		args: []string{"go-make", "--history", "--since=1h"},
+	},
+	"history list grep json": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+		),
+		args: []string{"go-make", "--history", "--grep=lint", "--json"},
+		expectStdout: fmt.Sprintf(`{"index":3,"args":["go-make","lint"],`+
+			`"dir":"%s","config":"custom","start":"%s",`+
+			`"duration":250000000,"exit":0}`+"\n", dirRoot,
+			timeHistory.Add(2*time.Hour).Format(time.RFC3339Nano)),
+	},
+
+	"history invalid arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse history", NewErrInvalidArg(
+				CmdHistory, "--unknown", nil)),
+		),
+		args: []string{"go-make", "--history", "--unknown"},
+		expectError: NewErrInvalidArg(
+			CmdHistory, "--unknown", nil),
+		expectExit: ExitCommandFailure,
+	},
+	"history invalid limit": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse history", NewErrInvalidArg(
+				CmdHistory, "--limit=x", ParseIntError("x"))),
+		),
+		args: []string{"go-make", "--history", "--limit=x"},
+		expectError: NewErrInvalidArg(
+			CmdHistory, "--limit=x", ParseIntError("x")),
+		expectExit: ExitCommandFailure,
+	},
+	"history invalid since": {
+		mockSetup: mock.Chain(
+			LogErrorAny("stderr", "parse history"),
+		),
+		args: []string{"go-make", "--history", "--since=x"},
+		expectError: NewErrInvalidArg(CmdHistory, "--since=x",
+			func() error { _, err := time.ParseDuration("x"); return err }()),
+		expectExit: ExitCommandFailure,
+	},
+	"history invalid rerun index": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse history", NewErrInvalidArg(
+				CmdHistory, "0", nil)),
+		),
+		args:        []string{"go-make", "--history", "rerun", "0"},
+		expectError: NewErrInvalidArg(CmdHistory, "0", nil),
+		expectExit:  ExitCommandFailure,
+	},
+
+	"history rerun missing": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			LogError("stderr", "rerun history", NewErrNoRecord(5)),
+		),
+		args:        []string{"go-make", "--history", "rerun", "5"},
+		expectError: NewErrNoRecord(5),
+		expectExit:  ExitCommandFailure,
+	},
+	"history rerun aborted missing": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			LogError("stderr", "rerun history", NewErrNoRecord(0)),
+		),
+		args:        []string{"go-make", "--history", "rerun", "--aborted"},
+		expectError: NewErrNoRecord(0),
+		expectExit:  ExitCommandFailure,
+	},
+	"history rerun index": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			LogCall("stderr", []string{"go-make", "target"}),
+			Exec(CmdGitTop(dirRoot), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"target"}, dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward), "stdin", "stdout", "stderr", "", "", nil),
+		),
+		args: []string{"go-make", "--history", "rerun", "2"},
+	},
+	"history rerun last failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			LogCall("stderr", []string{"go-make", "target"}),
+			Exec(CmdGitTop(dirRoot), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"target"}, dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward), "stdin", "stdout", "stderr", "", "", nil),
+		),
+		args: []string{"go-make", "--history", "rerun", "--failed"},
+	},
+	"history rerun quiet": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdGitTop(dirRoot), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"target"}, dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward), "stdin", "stdout", "stderr", "", "", nil),
+		),
+		args: []string{"go-make", "--log-level=quiet", "--history", "rerun", "2"},
+	},
+	"history after target": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"target", "history"},
+				dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
+		),
+		args:           []string{"go-make", "target", "history"},
+		expectRecorded: 1,
+	},
+	"history as target": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"history"},
+				dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
+		),
+		args:           []string{"go-make", "history"},
+		expectRecorded: 1,
+	},
+}
+
+func TestMakeHistory(t *testing.T) {
+	test.Map(t, makeHistoryTestCases).
+		Run(func(t test.Test, param MakeHistoryParams) {
+			// Given
+			gm, mocks := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+			})
+			store := history.New(gm.HistoryFile)
+			for _, record := range recordsHistory {
+				assert.NoError(t, store.Append(record))
+			}
+			stdout := mocks.GetArg("stdout").(*strings.Builder)
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			assert.Equal(t, "stdout"+param.expectStdout, stdout.String())
+			records, err := store.Read()
+			assert.NoError(t, err)
+			assert.Len(t, records, len(recordsHistory)+param.expectRecorded)
+		})
+}
+
+type MakeRecordHistoryParams struct {
+	args          []string
+	commit        string
+	err           error
+	expectTargets []string
+	expectCommit  string
+}
+
+var makeRecordHistoryTestCases = map[string]MakeRecordHistoryParams{
+	"record commit": {
+		args:          []string{"go-make", "target"},
+		commit:        "5114f85\n",
+		expectTargets: []string{"target"},
+		expectCommit:  "5114f85",
+	},
+	"record options and variables": {
+		args: []string{
+			"go-make", "--jobs", "4", "-k", "GOFLAGS=-v", "test", "lint",
+		},
+		commit:        "5114f85\n",
+		expectTargets: []string{"test", "lint"},
+		expectCommit:  "5114f85",
+	},
+	"record without targets": {
+		args:         []string{"go-make", "-k"},
+		commit:       "5114f85\n",
+		expectCommit: "5114f85",
+	},
+	"record without git": {
+		args:          []string{"go-make", "target"},
+		err:           assert.AnError,
+		expectTargets: []string{"target"},
+	},
+}
+
+func TestMakeRecordHistory(t *testing.T) {
+	test.Map(t, makeRecordHistoryTestCases).
+		Run(func(t test.Test, param MakeRecordHistoryParams) {
+			// Given
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: mock.Chain(
+					Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+						dirRoot, "", nil),
+					Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+						"nil", "stderr", "stderr", "", "", nil),
+					Exec(CmdMakeTargets(makeInfoBase, param.args[1:],
+						dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+						"stdin", "stdout", "stderr", "", "", nil),
+					Exec(CmdGitCommit(dirRoot), "nil", "builder", "discard",
+						param.commit, "", param.err),
+				),
+				info: infoBase,
+			})
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, ExitSuccess, exit)
+			records, err := history.New(gm.HistoryFile).Read()
+			assert.NoError(t, err)
+			if assert.Len(t, records, 1) {
+				assert.Equal(t, param.args, records[0].Args)
+				assert.Equal(t, param.expectTargets, records[0].Targets)
+				assert.Equal(t, dirRoot, records[0].Dir)
+				assert.Equal(t, infoBase.Version, records[0].Config)
+				assert.Equal(t, param.expectCommit, records[0].Commit)
+				assert.Equal(t, ExitSuccess, records[0].Exit)
+				assert.False(t, records[0].Aborted)
+			}
+		})
+}
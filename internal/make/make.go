@@ -7,22 +7,30 @@ import (
 	"fmt"
 	"go/build"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/tkrop/go-config/info"
 	"github.com/tkrop/go-make/internal/cmd"
 	"github.com/tkrop/go-make/internal/log"
 	"github.com/tkrop/go-make/internal/sys"
+	"github.com/tkrop/go-make/internal/trace"
 )
 
 const (
 	// EnvGoMakeConfig provides the name of the go-make config environment
 	// variable.
 	EnvGoMakeConfig = "GOMAKE_CONFIG"
+	// EnvGoMakeColor provides the name of the go-make color environment
+	// variable exported to make to switch colored output on and off.
+	EnvGoMakeColor = "GOMAKE_COLOR"
 	// EnvGoPath provides the name of the genera go path environment variable.
 	EnvGoPath = "GOPATH"
 	// Makefile provides the name of the base makefile to be executed by
@@ -34,9 +42,6 @@ const (
 	// GoMakeOutputSync provides the common output sync options for the
 	// go-make command.
 	GoMakeOutputSync = "none line target recurse"
-	// GoMakeTargetsDir provides the base name of the file to cache the
-	// go-make targets.
-	GoMakeTargetsDir = "${TMPDIR:-/tmp}/go-make-${USER:-$(whoami)}${PWD}"
 	// CompleteFilterFunc provides the common filter function to filter
 	// go-make targets before applying completion.
 	CompleteFilterFunc = "_go-make-filter() {\n" +
@@ -68,10 +73,7 @@ const (
 	// go-make targets for completion.
 	CompleteShowTargetsFunc = "_go-make-show-targets() {\n" +
 		"    local CMD=\"${1}\"; local WORD=\"${2}\";\n" +
-		"    local FILE=\"" + GoMakeTargetsDir + "/targets.${CMD}\";\n" +
-		"    if [ -f \"${FILE}\" ]; then cat \"${FILE}\";\n" +
-		"        (go-make show-targets-${CMD} >/dev/null &);\n" +
-		"    else go-make show-targets-${CMD}; fi 2>/dev/null |\n" +
+		"    go-make show-targets-${CMD} 2>/dev/null |\n" +
 		"        _go-make-filter \"${WORD}\";\n" +
 		"};\n"
 	// CompleteCPUCountFunc provides the common function to get the number of
@@ -138,6 +140,11 @@ const (
 	ExitConfigFailure int = 2
 	// ExitTargetFailure indicates that executing targets failed.
 	ExitTargetFailure int = 3
+	// ExitCommandFailure is the exit code for failures of native commands.
+	ExitCommandFailure int = 4
+
+	// TraceTimeout provides the timeout for exporting the trace spans.
+	TraceTimeout = 5 * time.Second
 )
 
 var (
@@ -185,6 +192,21 @@ func CmdMakeTargets(
 	}, targets...)...).WithEnv(env...).WithWorkDir(dir)
 }
 
+// CmdMakeDatabase creates the argument array of a `make --print-data-base`
+// command in question mode using the given makefile name with the given
+// working directory and environment variables.
+func CmdMakeDatabase(file, dir string, env ...string) *cmd.Cmd {
+	return cmd.New("make", "--question", "--no-builtin-rules",
+		"--no-builtin-variables", "--print-data-base", "--makefile="+file).
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdMakeHelp creates the argument array of a `make --help` command with the
+// given working directory and environment variables.
+func CmdMakeHelp(dir string, env ...string) *cmd.Cmd {
+	return cmd.New("make", "--help").WithEnv(env...).WithWorkDir(dir)
+}
+
 // CmdGitTop creates the argument array of a `git rev-parse` command to
 // get the root path of the current git repository.
 func CmdGitTop(dir string, env ...string) *cmd.Cmd {
@@ -217,6 +239,14 @@ func NewErrNotFound(dir, version string, err error) error {
 		ErrNotFound, dir, version, err)
 }
 
+// ErrTraceFailed represent a trace file write failure.
+var ErrTraceFailed = errors.New("trace failed")
+
+// NewErrTraceFailed wraps the error of a failed trace file write.
+func NewErrTraceFailed(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrTraceFailed, file, err)
+}
+
 // ErrCallFailed represent a version not found error.
 var ErrCallFailed = errors.New("call failed")
 
@@ -253,11 +283,31 @@ type GoMake struct {
 	ConfigDir string
 	// The path to the go-make config Makefile.
 	Makefile string
-	// Trace provides the flags to trace calls.
+	// Trace provides the flag to pass the trace option to make.
 	Trace bool
+	// Color provides the flag to enable colored output.
+	Color bool
+	// TraceFile provides the file to write the Chrome trace events to.
+	TraceFile string
+	// TraceOTLP provides the OTLP/HTTP endpoint to export the trace spans to.
+	TraceOTLP string
+	// Format provides the output format of the native show commands.
+	Format string
+	// Interactive provides the flag to pick the target interactively.
+	Interactive bool
+	// Picked provides the target and arguments picked interactively, if any.
+	Picked []string
+	// Tracer provides the tracer recording the spans of the run, if enabled.
+	Tracer *trace.Tracer
+	// HistoryFile provides the run history file overriding the default.
+	HistoryFile string
+	// ControlFile provides the control file overriding the default.
+	ControlFile string
 
 	// Aborted indicates whether go-make was Aborted.
 	Aborted atomic.Bool
+	// Stopped provides the stop request of a make target, if any.
+	Stopped atomic.Pointer[StopRequest]
 }
 
 // NewGoMake returns a new default `go-make` service context with given
@@ -267,23 +317,54 @@ func NewGoMake( //revive:disable-line:argument-limit // kiss.
 	stdin io.Reader, stdout, stderr io.Writer,
 	info *info.Info, config, wd string, env ...string,
 ) *GoMake {
-	return (&GoMake{
+	gm := &GoMake{
 		Info:     info,
 		Executor: cmd.NewExecutor(),
-		Logger:   log.NewLogger(),
 		Stdin:    stdin,
 		Stdout:   stdout,
 		Stderr:   stderr,
 		Config:   config,
 		WorkDir:  wd,
 		Env:      env,
+	}
+	gm.Color = gm.setupColor()
+	gm.Logger = log.NewLogger(gm.Color)
+
+	return gm
+}
+
+// setupColor determines whether colored output should be used. An explicit
+// `GOMAKE_COLOR` setting, e.g. inherited from a parent go-make, takes
+// precedence over the `NO_COLOR`, `FORCE_COLOR`, and `TERM` detection on the
+// standard error writer.
+func (gm *GoMake) setupColor() bool {
+	switch gm.GetEnvDefault(EnvGoMakeColor, "") {
+	case log.ColorAlways:
+		return true
+	case log.ColorNever:
+		return false
+	}
+	return log.UseColor(gm.Stderr, func(key string) string {
+		return gm.GetEnvDefault(key, "")
 	})
 }
 
+// makeEnv returns the environment variables for the make command extended by
+// the go-make variables exported to the `Makefile.base`.
+func (gm *GoMake) makeEnv() []string {
+	return append(slices.Clone(gm.Env),
+		EnvGoMakeColor+"="+log.ColorMode(gm.Color),
+		EnvGoMakePID+"="+strconv.Itoa(os.Getpid()),
+		EnvGoMakeControl+"="+gm.ControlFile)
+}
+
 // setupWorkDir ensures that the working directory is setup to the root of the
 // current git repository since this is where the go-make targets should be
 // executed.
 func (gm *GoMake) setupWorkDir(ctx context.Context) {
+	defer gm.timing("setup-workdir", time.Now())
+	defer gm.Tracer.Start("setup-workdir").End()
+
 	buffer := &strings.Builder{}
 	if err := gm.exec(ctx, CmdGitTop(gm.WorkDir, gm.Env...).
 		WithIO(nil, buffer, gm.Stderr)); err == nil {
@@ -296,6 +377,9 @@ func (gm *GoMake) setupWorkDir(ctx context.Context) {
 // context of the executed go-make command. The setup ensures that the expected
 // go-make config is installed and the correct Makefile is referenced.
 func (gm *GoMake) setupConfig(ctx context.Context) error {
+	defer gm.timing("setup-config", time.Now())
+	defer gm.Tracer.Start("setup-config").End()
+
 	if gm.Config == "" {
 		return gm.ensureConfig(ctx, gm.Info.Version,
 			GoMakePath(gm.Info.Path, gm.Info.Version))
@@ -315,8 +399,12 @@ func (gm *GoMake) setupConfig(ctx context.Context) error {
 func (gm *GoMake) ensureConfig(
 	ctx context.Context, version, dir string,
 ) error {
+	defer gm.Tracer.Start("ensure-config",
+		"version", version, "dir", dir).End()
+
 	gm.ConfigVersion, gm.ConfigDir = version, dir
 	gm.Makefile = filepath.Join(dir, Makefile)
+	gm.Logger.Config(gm.Stderr, version, dir)
 	if version == "custom" {
 		return nil
 	}
@@ -336,27 +424,103 @@ func (gm *GoMake) ensureConfig(
 // Executes given command using given context calling the command executor and
 // taking care to wrap the resulting error.
 func (gm *GoMake) exec(ctx context.Context, cmd *cmd.Cmd) error {
-	if gm.Trace {
-		gm.Logger.Exec(cmd.Stderr, cmd.Dir, cmd.Args...)
-	}
+	defer gm.Tracer.Start("exec",
+		"args", strings.Join(cmd.Args, " "), "dir", cmd.Dir).End()
 
+	gm.Logger.Exec(cmd.Stderr, cmd.Dir, cmd.Args...)
 	if err := gm.Executor.Exec(ctx, cmd); err != nil {
 		return NewErrCallFailed(cmd, errors.Unwrap(err))
 	}
 	return nil
 }
 
+// timing logs the duration of the named execution step since the given start
+// time.
+func (gm *GoMake) timing(name string, start time.Time) {
+	gm.Logger.Timing(gm.Stderr, name, time.Since(start))
+}
+
+// error logs the given error message and error.
+func (gm *GoMake) error(message string, err error) {
+	gm.Logger.Error(gm.Stderr, message, err)
+}
+
+// setupLevel sets up the log level of the logger from the log level option
+// provided by the command line arguments, and logs the call and the build
+// information.
+func (gm *GoMake) setupLevel(args ...string) {
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "--log-level=") {
+			name := arg[len("--log-level="):]
+			if level, ok := log.ParseLevel(name); ok {
+				gm.Logger.SetLevel(level)
+			} else {
+				gm.Logger.Warning(gm.Stderr, fmt.Sprintf(
+					"invalid log level [%s]", name))
+			}
+		}
+	}
+
+	gm.Logger.Call(gm.Stderr, args...)
+	gm.Logger.Info(gm.Stderr, gm.Info, false)
+}
+
 // Make runs the go-make command with given arguments and return the exit code
 // and error.
 func (gm *GoMake) Make(args ...string) (int, error) {
+	gm.setupLevel(args...)
+	if args, ok := commandArgs(CmdHistory, args[1:]...); ok {
+		return gm.history(args...)
+	}
+	if args, ok := commandArgs(CmdGitVerify, args[1:]...); ok {
+		return gm.gitVerify(args...)
+	}
+	if args, ok := commandArgs(CmdVersionBump, args[1:]...); ok {
+		return gm.versionBump(args...)
+	}
+	if args, ok := commandArgs(CmdVersionChangelog, args[1:]...); ok {
+		return gm.versionChangelog(args...)
+	}
+	if args, ok := commandArgs(CmdVersionPreflight, args[1:]...); ok {
+		return gm.versionPreflight(args...)
+	}
+	if args, ok := commandArgs(CmdTestArgs, args[1:]...); ok {
+		return gm.testArgs(args...)
+	}
+	if args, ok := commandArgs(CmdTestBenchCompare, args[1:]...); ok {
+		return gm.testBenchCompare(args...)
+	}
+	if args, ok := commandArgs(CmdTestCoverCheck, args[1:]...); ok {
+		return gm.testCoverCheck(args...)
+	}
+	if args, ok := commandArgs(CmdTestCoverExport, args[1:]...); ok {
+		return gm.testCoverExport(args...)
+	}
+	if args, ok := commandArgs(CmdTestReport, args[1:]...); ok {
+		return gm.testReport(args...)
+	}
+	if args, ok := commandArgs(CmdTestFlakyRerun, args[1:]...); ok {
+		return gm.testFlakyRerun(args...)
+	}
+	if args, ok := commandArgs(CmdGenerateMocks, args[1:]...); ok {
+		return gm.generateMocks(args...)
+	}
+	return gm.runTargets(true, args...)
+}
+
+// runTargets runs the make targets with the go-make options given by the
+// arguments and returns the exit code and error. If requested, the run is
+// recorded in the run history, unless it only shows the targets.
+func (gm *GoMake) runTargets(record bool, args ...string) (int, error) {
 	var mode cmd.Mode
 	var suffix *string
 	var targets []string
 	for _, arg := range args[1:] {
 		switch {
+		case strings.HasPrefix(arg, "--log-level="):
+			// Log level option is already handled by `setupLevel`.
+
 		case arg == "--trace":
-			gm.Logger.Call(gm.Stderr, args...)
-			gm.Logger.Info(gm.Stderr, gm.Info, false)
 			targets = append(targets, arg)
 			gm.Trace = true
 
@@ -375,6 +539,19 @@ func (gm *GoMake) Make(args ...string) (int, error) {
 		case strings.HasPrefix(arg, "--config="):
 			gm.Config = arg[len("--config="):]
 
+		case strings.HasPrefix(arg, "--trace-file="):
+			gm.TraceFile = arg[len("--trace-file="):]
+
+		case strings.HasPrefix(arg, "--trace-otlp="):
+			gm.TraceOTLP = arg[len("--trace-otlp="):]
+
+		case strings.HasPrefix(arg, "--format="):
+			// Consumed by `outputFormat` for native output commands only.
+			targets = append(targets, arg)
+
+		case arg == "--pick":
+			gm.Interactive = true
+
 		// case arg == "--async":
 		// 	mode |= cmd.Detached | cmd.Background
 		// case arg == "--detached":
@@ -399,50 +576,176 @@ func (gm *GoMake) Make(args ...string) (int, error) {
 		}
 	}
 
-	return gm.makeTargets(mode, suffix, targets)
+	targets = gm.outputFormat(suffix, targets)
+	if gm.TraceFile != "" || gm.TraceOTLP != "" {
+		gm.Tracer = trace.NewTracer("go-make")
+	}
+	start := time.Now()
+	span := gm.Tracer.Start("go-make", "args", strings.Join(args, " "))
+	exit, err := gm.makeTargets(mode, suffix, targets)
+	if record && suffix == nil {
+		args, targets = gm.pickedArgs(args, targets)
+		gm.recordHistory(args, targets, start, exit)
+	}
+	span.SetAttr("config", gm.ConfigVersion)
+	span.SetAttr("exit", strconv.Itoa(exit))
+	span.End()
+	gm.writeTrace()
+
+	return exit, err
+}
+
+// outputFormat consumes the output format option from the given targets, if
+// the remaining target is a native output command of go-make, i.e. one of the
+// show targets commands or the help. Else the targets are returned unchanged
+// to pass the option to make.
+func (gm *GoMake) outputFormat(suffix *string, targets []string) []string {
+	index := slices.IndexFunc(targets, func(target string) bool {
+		return strings.HasPrefix(target, "--format=")
+	})
+	if index < 0 {
+		return targets
+	}
+
+	rest := slices.Delete(slices.Clone(targets), index, index+1)
+	if len(rest) == 1 && (suffix != nil ||
+		rest[0] == "show-help" || rest[0] == "help") {
+		gm.Format = targets[index][len("--format="):]
+		return rest
+	}
+	return targets
+}
+
+// writeTrace writes the recorded spans to the trace file and exports them to
+// the OTLP/HTTP endpoint, if configured. Failures are logged but do not change
+// the exit code of the go-make run. A relative trace file is resolved against
+// the working directory, i.e. usually the root of the git repository.
+func (gm *GoMake) writeTrace() {
+	if gm.TraceFile != "" {
+		file := gm.TraceFile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(gm.WorkDir, file)
+		}
+		if err := gm.writeTraceFile(file); err != nil {
+			gm.error("write trace", NewErrTraceFailed(file, err))
+		}
+	}
+
+	if gm.TraceOTLP != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), TraceTimeout)
+		defer cancel()
+		if err := gm.Tracer.Export(
+			ctx, http.DefaultClient, gm.TraceOTLP); err != nil {
+			gm.error("export trace", err)
+		}
+	}
+}
+
+// writeTraceFile writes the recorded spans in the Chrome trace-event format
+// to the given file creating the parent directories as needed.
+func (gm *GoMake) writeTraceFile(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return err
+	}
+
+	// #nosec G304 -- file is provided by the user to write the trace.
+	writer, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := gm.Tracer.WriteChrome(writer); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
 }
 
 // makeTargets executes the provided make targets with given command mode and
-// targets suffix. If the targets suffix indicates that the targets should be
-// shown, it displays them and returns immediately. Otherwise, it calls the
-// targets and returns the exit code and error.
+// targets suffix. If the targets suffix indicates that only the targets should
+// be shown, or the help should be shown as JSON, it shows them natively and
+// returns immediately. In interactive mode it lets the user pick the target
+// first. Otherwise, it calls the targets and returns the exit code and error.
 func (gm *GoMake) makeTargets(
 	mode cmd.Mode, suffix *string, targets []string,
 ) (int, error) {
-	if gm.showTargets(suffix) {
-		mode = cmd.Detached | cmd.Background
+	if gm.Interactive {
+		return gm.pickTargets(mode, targets)
+	}
+	if !gm.Trace && len(targets) == 1 {
+		switch {
+		case suffix != nil:
+			return gm.showTargets(*suffix)
+		case gm.Format == FormatJSON &&
+			(targets[0] == "show-help" || targets[0] == "help"):
+			return gm.showCatalog(*SuffixTargets, true)
+		}
 	}
 
-	ctx := sys.NewSignaler(gm.HandleSignal, sys.Signals...).
-		Signal(context.Background())
+	ctx, stop := gm.signalContext(context.Background())
+	defer stop()
 
 	return gm.callTargets(ctx, mode, targets)
 }
 
-// HandleSignal handles received OS signals during go-make execution.
-func (gm *GoMake) HandleSignal(cancel context.CancelFunc, signal os.Signal) {
-	if signal == syscall.SIGABRT {
-		gm.Aborted.Store(true)
+// signalContext sets up the signal handling for executing make, returning a
+// context cancelled by the signal handler and a function to stop the signal
+// handling. The signal handler stays registered when the context is cancelled
+// until it is stopped after make has finished, so that repeated signals still
+// escalate instead of killing go-make while make is cleaning up.
+func (gm *GoMake) signalContext(
+	ctx context.Context,
+) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	signaler := sys.NewSignaler(func(
+		_ context.CancelFunc, signal os.Signal, count int,
+	) {
+		gm.HandleSignal(cancel, signal, count)
+	}, sys.Signals...)
+	signaler.Signal(context.Background())
+
+	return ctx, func() {
+		signaler.Stop()
+		cancel()
 	}
-	cancel()
 }
 
-// showTargets reads the targets from the go-make targets file and displays
-// them via standard output. If the file does not exist, it returns false
-// indicating that no targets were found.
-func (gm *GoMake) showTargets(suffix *string) bool {
-	if gm.Trace || suffix == nil {
-		return false
-	}
+// HandleSignal handles received OS signals during go-make execution. An abort
+// signal cancels the execution, i.e. terminates make and kills it only after
+// a grace period, taking over the stop request of the make target from the
+// control file, if present. Interrupt, terminate,
+// and hangup signals are forwarded to the running make process to allow it to
+// clean up, while repeated signals, as indicated by the signal count, escalate
+// to terminating and finally killing make. If no make process is running, the
+// execution is cancelled.
+func (gm *GoMake) HandleSignal(
+	cancel context.CancelFunc, signal os.Signal, count int,
+) {
+	switch signal {
+	case syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP:
+		var message string
+		switch count {
+		case 1:
+			message = fmt.Sprintf("forwarded %s to make, "+
+				"repeat to terminate", signal)
+		case 2: //nolint:mnd // second interrupt.
+			signal, message = syscall.SIGTERM,
+				"terminating make, repeat to kill"
+		default:
+			signal, message = syscall.SIGKILL, "killing make"
+		}
 
-	// Read the targets file, iff it exists.
-	file := gm.fileTargets(*suffix)
-	// #nosec G304 -- file is safe to dump.
-	if content, err := os.ReadFile(file); err == nil {
-		gm.Logger.Message(gm.Stdout, string(content))
-		return true
+		if gm.Executor.Signal(signal) {
+			gm.Logger.Warning(gm.Stderr, message)
+			return
+		}
+	case syscall.SIGABRT:
+		if stop := gm.readControl(); stop != nil {
+			gm.Stopped.Store(stop)
+		}
+		gm.Aborted.Store(true)
 	}
-	return false
+	cancel()
 }
 
 // fileTargets returns the path to the go-make targets file based on the
@@ -461,14 +764,20 @@ func (gm *GoMake) fileTargets(suffix string) string {
 	}
 
 	if file == "" {
-		file = filepath.Join(
-			gm.GetEnvDefault("TMPDIR", os.TempDir()),
-			"go-make-"+gm.GetEnvDefault("USER", "unknown"),
-			AbsPath(gm.WorkDir), "targets."+suffix)
+		file = filepath.Join(gm.cacheDir(), "targets."+suffix)
 	}
 	return filepath.Clean(file)
 }
 
+// cacheDir returns the per-project cache directory of go-make based on the
+// temporary directory, the user name, and the absolute working directory.
+func (gm *GoMake) cacheDir() string {
+	return filepath.Join(
+		gm.GetEnvDefault("TMPDIR", os.TempDir()),
+		"go-make-"+gm.GetEnvDefault("USER", "unknown"),
+		AbsPath(gm.WorkDir))
+}
+
 // GetEnvDefault returns the value of the environment variable with given name
 // or the given default value, if the environment variable is not set. The
 // function checks the go-make context environment variables backwards first
@@ -484,23 +793,59 @@ func (gm *GoMake) GetEnvDefault(name, deflt string) string {
 }
 
 // callTargets executes the provided make targets after setting up the working
-// directory and the go-make config. It returns the exit code and error if any
-// step of the setup or the targets execution fails.
+// directory and the go-make config, and checking the targets for typos. It
+// returns the exit code and error if any step of the setup, the targets check,
+// or the targets execution fails.
 func (gm *GoMake) callTargets(
 	ctx context.Context, mode cmd.Mode, targets []string,
 ) (int, error) {
 	gm.setupWorkDir(ctx)
 	if err := gm.setupConfig(ctx); err != nil {
-		gm.Logger.Error(gm.Stderr, "ensure config", err)
+		gm.error("ensure config", err)
 		return ExitConfigFailure, err
-	} else if err := gm.exec(ctx,
-		CmdMakeTargets(gm.Makefile, targets, gm.WorkDir, gm.Env...).
-			WithMode(mode).WithIO(gm.Stdin, gm.Stdout, gm.Stderr)); err != nil {
-		if !gm.Aborted.Load() {
-			gm.Logger.Error(gm.Stderr, "execute make", err)
-			return ExitTargetFailure, err
+	}
+	targets, err := gm.checkTargets(targets)
+	if err != nil {
+		gm.error("check targets", err)
+		return ExitTargetFailure, err
+	}
+	return gm.execTargets(ctx, mode, targets)
+}
+
+// execTargets executes the provided make targets with given command mode
+// using the working directory and go-make config set up before. It returns
+// the exit code and error if the targets execution fails.
+func (gm *GoMake) execTargets(
+	ctx context.Context, mode cmd.Mode, targets []string,
+) (int, error) {
+	if mode&cmd.Background != cmd.Background {
+		mode |= cmd.Forward
+	}
+
+	defer gm.setupControl()()
+	defer gm.timing("make", time.Now())
+	defer gm.Tracer.Start("make").End()
+	err := gm.exec(ctx,
+		CmdMakeTargets(gm.Makefile, targets, gm.WorkDir, gm.makeEnv()...).
+			WithMode(mode).WithIO(gm.Stdin, gm.Stdout, gm.Stderr))
+
+	// Make may finish before the abort signal of a stop request is handled,
+	// so the control file is checked before it is removed.
+	if gm.Stopped.Load() == nil {
+		if stop := gm.readControl(); stop != nil {
+			gm.Stopped.Store(stop)
+			gm.Aborted.Store(true)
 		}
 	}
+
+	if err != nil && !gm.Aborted.Load() {
+		gm.error("execute make", err)
+		return ExitTargetFailure, err
+	}
+
+	if stop := gm.Stopped.Load(); stop != nil {
+		return gm.stopped(stop)
+	}
 	return ExitSuccess, nil
 }
 
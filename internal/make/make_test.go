@@ -3,12 +3,16 @@ package make_test
 import (
 	"context"
 	"embed"
+	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"syscall"
@@ -68,15 +72,10 @@ var (
 	dirWork = "."
 	// dirRoot contains an arbitrary absolute test directory (use current).
 	dirRoot = filepath.Dir(filepath.Dir(AbsPath(dirWork)))
-	// envMakeMock contains the environment variables for the targets files.
-	envMakeMock = []string{
-		"FILE_TARGETS=" + filepath.Join(dirRoot,
-			"internal", "make", "fixtures", "targets", "std.out"),
-		"FILE_TARGETS_MAKE=" + filepath.Join(dirRoot,
-			"internal", "make", "fixtures", "targets", "make-std.out"),
-		"FILE_TARGETS_GOMAKE=" + filepath.Join(dirRoot,
-			"internal", "make", "fixtures", "targets", "go-make-std.out"),
-	}
+	// fileControl contains the default control file of the test process.
+	fileControl = filepath.Join(GetEnvDefault("TMPDIR", os.TempDir()),
+		"go-make-"+GetEnvDefault("USER", "unknown"), dirRoot,
+		"control."+strconv.Itoa(os.Getpid()))
 
 	// infoBase with version and revision.
 	infoBase = info.New(goMakePath, "v0.0.25",
@@ -108,6 +107,10 @@ var (
 	argsShowTargetsCustom = []string{"go-make", "--config=custom", "show-targets"}
 	argsShowTargetsLatest = []string{"go-make", "--config=latest", "show-targets"}
 	argsTraceAnyTarget    = []string{"go-make", "--trace", "target"}
+	argsVerboseVersion    = []string{"go-make", "--log-level=verbose", "--version"}
+	argsVerboseAnyTarget  = []string{"go-make", "--log-level=verbose", "target"}
+	argsDebugAnyTarget    = []string{"go-make", "--log-level=debug", "target"}
+	argsQuietAnyTarget    = []string{"go-make", "--log-level=quiet", "target"}
 )
 
 // MakefilePath returns the path to the Makefile for the given path and version.
@@ -127,6 +130,14 @@ func NewWriter(id string) io.Writer {
 	return builder
 }
 
+// MakeEnv returns the given environment variables extended by the go-make
+// variables exported to the make command.
+func MakeEnv(env ...string) []string {
+	return append(env, EnvGoMakeColor+"="+log.ColorNever,
+		EnvGoMakePID+"="+strconv.Itoa(os.Getpid()),
+		EnvGoMakeControl+"="+fileControl)
+}
+
 // GoMakeSetup sets up a new go-make test with mocks.
 func GoMakeSetup(
 	t test.Test, param MakeParams,
@@ -137,6 +148,7 @@ func GoMakeSetup(
 		SetArg("stdout", NewWriter("stdout")).
 		SetArg("stderr", NewWriter("stderr")).
 		SetArg("builder", &strings.Builder{}).
+		SetArg("discard", io.Discard).
 		Expect(param.mockSetup)
 
 	gm := NewGoMake(
@@ -150,6 +162,24 @@ func GoMakeSetup(
 
 	gm.Executor = mock.Get(mocks, NewMockExecutor)
 	gm.Logger = mock.Get(mocks, NewMockLogger)
+	// Ensure color mode independent of the test environment.
+	gm.Color = false
+	// Ensure run history is not written to the cache directory.
+	gm.HistoryFile = filepath.Join(t.TempDir(), "history.json")
+
+	// Stub the log level to behave like the default logger.
+	level := log.LevelDefault
+	logger := mock.Get(mocks, NewMockLogger)
+	logger.EXPECT().SetLevel(gomock.Any()).AnyTimes().
+		Do(func(value log.Level) { level = value })
+	logger.EXPECT().Level().AnyTimes().
+		DoAndReturn(func() log.Level { return level })
+	// Ignore the verbose and debug logging filtered by the default logger.
+	logger.EXPECT().Call(gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Info(gomock.Any(), gomock.Any(), false).AnyTimes()
+	logger.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Config(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	logger.EXPECT().Timing(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 
 	return gm, mocks
 }
@@ -216,6 +246,22 @@ func LogInfo(writer string, info *info.Info, raw bool) mock.SetupFunc {
 	}
 }
 
+func LogConfig(writer string, version, dir string) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockLogger).EXPECT().
+			Config(mocks.GetArg(writer), version, dir).
+			DoAndReturn(mocks.Do(log.Logger.Config))
+	}
+}
+
+func LogTiming(writer string, name string) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockLogger).EXPECT().
+			Timing(mocks.GetArg(writer), name, gomock.Any()).
+			DoAndReturn(mocks.Do(log.Logger.Timing))
+	}
+}
+
 func LogError(writer string, message string, err error) mock.SetupFunc {
 	return func(mocks *mock.Mocks) any {
 		return mock.Get(mocks, NewMockLogger).EXPECT().
@@ -224,6 +270,14 @@ func LogError(writer string, message string, err error) mock.SetupFunc {
 	}
 }
 
+func LogErrorAny(writer string, message string) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockLogger).EXPECT().
+			Error(mocks.GetArg(writer), message, gomock.Any()).
+			DoAndReturn(mocks.Do(log.Logger.Error))
+	}
+}
+
 func LogMessage(writer string, message string) mock.SetupFunc {
 	return func(mocks *mock.Mocks) any {
 		return mock.Get(mocks, NewMockLogger).EXPECT().
@@ -232,6 +286,21 @@ func LogMessage(writer string, message string) mock.SetupFunc {
 	}
 }
 
+func LogWarning(writer string, message string) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockLogger).EXPECT().
+			Warning(mocks.GetArg(writer), message).
+			DoAndReturn(mocks.Do(log.Logger.Warning))
+	}
+}
+
+func Signal(signal os.Signal, signaled bool) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockExecutor).EXPECT().
+			Signal(signal).Return(signaled)
+	}
+}
+
 type MakeParams struct {
 	mockSetup   mock.SetupFunc
 	info        *info.Info
@@ -265,219 +334,179 @@ var makeMockTestCases = map[string]MakeParams{
 		args: argsZsh,
 	},
 
-	"go-make show targets": {
+	"go-make show targets with param": {
 		mockSetup: mock.Chain(
 			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
 			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
 				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(makeInfoBase, argsShowTargets[1:], dirRoot),
+			Exec(CmdMakeTargets(makeInfoBase, argsShowTargetsParam[1:], dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
 				"stdin", "stdout", "stderr", "", "", nil),
 		),
 		info: infoBase,
-		args: argsShowTargets,
+		args: argsShowTargetsParam,
 	},
-	"go-make show targets with file": {
+
+	// targets with trace.
+	"go-make version traced": {
 		mockSetup: mock.Chain(
-			LogMessage("stdout", ReadFile(fixtures, "fixtures/targets/std.out")),
-			Exec(CmdGitTop(dirWork, envMakeMock...),
-				"nil", "builder", "stderr", dirRoot, "", nil),
-			Exec(CmdTestDir(goMakeInfoBase, dirRoot, envMakeMock...),
-				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(makeInfoBase, argsShowTargets[1:], dirRoot,
-				envMakeMock...).WithMode(cmd.Detached|cmd.Background),
-				"stdin", "stdout", "stderr", "", "", nil),
+			LogInfo("stdout", infoBase, true),
 		),
 		info: infoBase,
-		env:  envMakeMock,
-		args: argsShowTargets,
+		args: argsTraceVersion,
 	},
-	"go-make show targets make with file": {
+	"go-make completion bash traced": {
 		mockSetup: mock.Chain(
-			LogMessage("stdout",
-				ReadFile(fixtures, "fixtures/targets/make-std.out")),
-			Exec(CmdGitTop(dirWork, envMakeMock...),
-				"nil", "builder", "stderr", dirRoot, "", nil),
-			Exec(CmdTestDir(goMakeInfoBase, dirRoot, envMakeMock...),
-				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(makeInfoBase, argsShowTargetsMake[1:], dirRoot,
-				envMakeMock...).WithMode(cmd.Detached|cmd.Background),
-				"stdin", "stdout", "stderr", "", "", nil),
+			LogMessage("stdout", CompleteBash),
 		),
 		info: infoBase,
-		env:  envMakeMock,
-		args: argsShowTargetsMake,
+		args: argsBashTrace,
 	},
-	"go-make show targets go-make with file": {
+	"go-make completion zsh traced": {
 		mockSetup: mock.Chain(
-			LogMessage("stdout",
-				ReadFile(fixtures, "fixtures/targets/go-make-std.out")),
-			Exec(CmdGitTop(dirWork, envMakeMock...),
-				"nil", "builder", "stderr", dirRoot, "", nil),
-			Exec(CmdTestDir(goMakeInfoBase, dirRoot, envMakeMock...),
-				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(makeInfoBase, argsShowTargetsGoMake[1:], dirRoot,
-				envMakeMock...).WithMode(cmd.Detached|cmd.Background),
-				"stdin", "stdout", "stderr", "", "", nil),
+			LogMessage("stdout", CompleteZsh),
 		),
 		info: infoBase,
-		env:  envMakeMock,
-		args: argsShowTargetsGoMake,
+		args: argsZshTrace,
 	},
-	"go-make show targets with param": {
+	"go-make any target traced": {
 		mockSetup: mock.Chain(
 			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
 			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
 				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(makeInfoBase, argsShowTargetsParam[1:], dirRoot),
+			Exec(CmdMakeTargets(makeInfoBase, argsTraceAnyTarget[1:], dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
 				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
 		),
 		info: infoBase,
-		args: argsShowTargetsParam,
-	},
-	"go-make show targets install": {
-		mockSetup: mock.Chain(
-			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
-			Exec(CmdTestDir(goMakeInfoNew, dirRoot),
-				"nil", "stderr", "stderr", "", "", assert.AnError),
-			Exec(CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
-				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(makeInfoNew, argsShowTargets[1:], dirRoot),
-				"stdin", "stdout", "stderr", "", "", nil),
-		),
-		info: infoNew,
-		args: argsShowTargets,
-	},
-	"go-make show targets config custom": {
-		mockSetup: mock.Chain(
-			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
-			Exec(CmdTestDir(AbsPath("custom"), dirRoot),
-				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(filepath.Join(AbsPath("custom"), Makefile),
-				argsShowTargets[1:], dirRoot),
-				"stdin", "stdout", "stderr", "", "", nil),
-		),
-		info: infoBase,
-		args: argsShowTargetsCustom,
-	},
-	"go-make show targets config version latest": {
-		mockSetup: mock.Chain(
-			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
-			Exec(CmdTestDir(AbsPath("latest"), dirRoot),
-				"nil", "stderr", "stderr", "", "", assert.AnError),
-			Exec(CmdTestDir(GoMakePath(infoBase.Path, "latest"), dirRoot),
-				"nil", "stderr", "stderr", "", "", assert.AnError),
-			Exec(CmdGoInstall(infoBase.Path, "latest", dirRoot),
-				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(MakefilePath(infoBase.Path, "latest"),
-				argsShowTargets[1:], dirRoot),
-				"stdin", "stdout", "stderr", "", "", nil),
-		),
-		info: infoBase,
-		args: argsShowTargetsLatest,
-	},
-
-	"go-make show targets install failed": {
-		mockSetup: mock.Chain(
-			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
-			Exec(CmdTestDir(goMakeInfoNew, dirRoot),
-				"nil", "stderr", "stderr", "", "", assert.AnError),
-			Exec(CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
-				"nil", "stderr", "stderr", "", "", assert.AnError),
-			LogError("stderr", "ensure config", NewErrNotFound(
-				infoNew.Path, infoNew.Version, NewErrCallFailed(
-					CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
-					assert.AnError))),
-		),
-		info: infoNew,
-		args: argsShowTargets,
-		expectError: NewErrNotFound(
-			infoNew.Path, infoNew.Version, NewErrCallFailed(
-				CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
-				assert.AnError)),
-		expectExit: ExitConfigFailure,
+		args: argsTraceAnyTarget,
 	},
-	"go-make show targets failed": {
+	"go-make any target traced failed": {
 		mockSetup: mock.Chain(
 			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
 			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
 				"nil", "stderr", "stderr", "", "", nil),
-			Exec(CmdMakeTargets(makeInfoBase, argsShowTargets[1:], dirRoot),
+			Exec(CmdMakeTargets(makeInfoBase, argsTraceAnyTarget[1:], dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
 				"stdin", "stdout", "stderr", "", "", assert.AnError),
 			LogError("stderr", "execute make", NewErrCallFailed(CmdMakeTargets(
-				makeInfoBase, argsShowTargets[1:], dirRoot), assert.AnError)),
+				makeInfoBase, argsTraceAnyTarget[1:], dirRoot), assert.AnError)),
+			ExecCommit(dirRoot),
 		),
 		info: infoBase,
-		args: argsShowTargets,
+		args: argsTraceAnyTarget,
 		expectError: NewErrCallFailed(CmdMakeTargets(makeInfoBase,
-			argsShowTargets[1:], dirRoot), assert.AnError),
+			argsTraceAnyTarget[1:], dirRoot), assert.AnError),
 		expectExit: ExitTargetFailure,
 	},
 
-	// targets without trace.
-	"go-make version traced": {
+	// targets with log levels.
+	"go-make version verbose": {
 		mockSetup: mock.Chain(
-			LogCall("stderr", argsTraceVersion),
+			LogCall("stderr", argsVerboseVersion),
 			LogInfo("stderr", infoBase, false),
 			LogInfo("stdout", infoBase, true),
 		),
 		info: infoBase,
-		args: argsTraceVersion,
+		args: argsVerboseVersion,
 	},
-	"go-make completion bash traced": {
+	"go-make version invalid level": {
 		mockSetup: mock.Chain(
-			LogCall("stderr", argsBashTrace),
-			LogInfo("stderr", infoBase, false),
-			LogMessage("stdout", CompleteBash),
+			LogWarning("stderr", "invalid log level [loud]"),
+			LogInfo("stdout", infoBase, true),
 		),
 		info: infoBase,
-		args: argsBashTrace,
+		args: []string{"go-make", "--log-level=loud", "--version"},
 	},
-	"go-make completion zsh traced": {
+	"go-make any target verbose": {
 		mockSetup: mock.Chain(
-			LogCall("stderr", argsZshTrace),
+			LogCall("stderr", argsVerboseAnyTarget),
 			LogInfo("stderr", infoBase, false),
-			LogMessage("stdout", CompleteZsh),
+			LogExec("stderr", CmdGitTop(dirWork)),
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			LogConfig("stderr", infoBase.Version, goMakeInfoBase),
+			LogExec("stderr", CmdTestDir(goMakeInfoBase, dirRoot)),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			LogExec("stderr", CmdMakeTargets(makeInfoBase,
+				argsVerboseAnyTarget[2:], dirRoot)),
+			Exec(CmdMakeTargets(makeInfoBase, argsVerboseAnyTarget[2:], dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			LogExec("discard", CmdGitCommit(dirRoot)),
+			ExecCommit(dirRoot),
 		),
 		info: infoBase,
-		args: argsZshTrace,
+		args: argsVerboseAnyTarget,
 	},
-	"go-make any target traced": {
+	"go-make any target debug": {
 		mockSetup: mock.Chain(
-			LogCall("stderr", argsTraceAnyTarget),
+			LogCall("stderr", argsDebugAnyTarget),
 			LogInfo("stderr", infoBase, false),
 			LogExec("stderr", CmdGitTop(dirWork)),
 			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			LogTiming("stderr", "setup-workdir"),
+			LogConfig("stderr", infoBase.Version, goMakeInfoBase),
 			LogExec("stderr", CmdTestDir(goMakeInfoBase, dirRoot)),
 			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
 				"nil", "stderr", "stderr", "", "", nil),
+			LogTiming("stderr", "setup-config"),
 			LogExec("stderr", CmdMakeTargets(makeInfoBase,
-				argsTraceAnyTarget[1:], dirRoot)),
-			Exec(CmdMakeTargets(makeInfoBase, argsTraceAnyTarget[1:], dirRoot),
+				argsDebugAnyTarget[2:], dirRoot)),
+			Exec(CmdMakeTargets(makeInfoBase, argsDebugAnyTarget[2:], dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
 				"stdin", "stdout", "stderr", "", "", nil),
+			LogTiming("stderr", "make"),
+			LogExec("discard", CmdGitCommit(dirRoot)),
+			ExecCommit(dirRoot),
 		),
 		info: infoBase,
-		args: argsTraceAnyTarget,
+		args: argsDebugAnyTarget,
 	},
-	"go-make any target traced failed": {
+	"go-make any target verbose failed": {
 		mockSetup: mock.Chain(
-			LogCall("stderr", argsTraceAnyTarget),
+			LogCall("stderr", argsVerboseAnyTarget),
 			LogInfo("stderr", infoBase, false),
 			LogExec("stderr", CmdGitTop(dirWork)),
 			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			LogConfig("stderr", infoBase.Version, goMakeInfoBase),
 			LogExec("stderr", CmdTestDir(goMakeInfoBase, dirRoot)),
 			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
 				"nil", "stderr", "stderr", "", "", nil),
 			LogExec("stderr", CmdMakeTargets(makeInfoBase,
-				argsTraceAnyTarget[1:], dirRoot)),
-			Exec(CmdMakeTargets(makeInfoBase, argsTraceAnyTarget[1:], dirRoot),
+				argsVerboseAnyTarget[2:], dirRoot)),
+			Exec(CmdMakeTargets(makeInfoBase, argsVerboseAnyTarget[2:], dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
 				"stdin", "stdout", "stderr", "", "", assert.AnError),
 			LogError("stderr", "execute make", NewErrCallFailed(CmdMakeTargets(
-				makeInfoBase, argsTraceAnyTarget[1:], dirRoot), assert.AnError)),
+				makeInfoBase, argsVerboseAnyTarget[2:], dirRoot), assert.AnError)),
+			LogExec("discard", CmdGitCommit(dirRoot)),
+			ExecCommit(dirRoot),
 		),
 		info: infoBase,
-		args: argsTraceAnyTarget,
+		args: argsVerboseAnyTarget,
 		expectError: NewErrCallFailed(CmdMakeTargets(makeInfoBase,
-			argsTraceAnyTarget[1:], dirRoot), assert.AnError),
+			argsVerboseAnyTarget[2:], dirRoot), assert.AnError),
+		expectExit: ExitTargetFailure,
+	},
+	"go-make any target quiet failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, argsQuietAnyTarget[2:], dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", assert.AnError),
+			LogError("stderr", "execute make", NewErrCallFailed(
+				CmdMakeTargets(makeInfoBase, argsQuietAnyTarget[2:], dirRoot),
+				assert.AnError)),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: argsQuietAnyTarget,
+		expectError: NewErrCallFailed(CmdMakeTargets(makeInfoBase,
+			argsQuietAnyTarget[2:], dirRoot), assert.AnError),
 		expectExit: ExitTargetFailure,
 	},
 }
@@ -563,12 +592,14 @@ var (
 )
 
 // EnvPrepare copies the environment variables and replaces the variable
-// `${dir}` with the given directory. It also appends empty make flags to the
-// end of the slice to ensure that parent options influence the test results
-// - in particular the '--trace' flag.
+// `${dir}` with the given directory. It disables colored output by default
+// and also appends empty make flags to the end of the slice to ensure that
+// parent options influence the test results - in particular the '--trace'
+// flag.
 func EnvPrepare(env []string, dir string) []string {
-	result := make([]string, 0, len(env)+4)
-	result = append(result, "FILE_TARGETS=${dir}/targets")
+	result := make([]string, 0, len(env)+5)
+	result = append(result, "FILE_TARGETS=${dir}/targets",
+		EnvGoMakeColor+"="+log.ColorNever)
 
 	for _, value := range env {
 		result = append(result, regexTargets.ReplaceAllString(value, dir))
@@ -619,11 +650,11 @@ var makeExecTestCases = map[string]MakeExecParams{
 		args:         []string{"go-make", "--version"},
 		expectStdout: ReadFile(fixtures, "fixtures/version.out"),
 	},
-	"go-make version trace": {
+	"go-make version verbose": {
 		info:         infoBase,
-		args:         []string{"go-make", "--trace", "--version"},
+		args:         []string{"go-make", "--log-level=verbose", "--version"},
 		expectStdout: ReadFile(fixtures, "fixtures/version.out"),
-		expectStderr: ReadFile(fixtures, "fixtures/version-trace.err"),
+		expectStderr: ReadFile(fixtures, "fixtures/version-verbose.err"),
 	},
 
 	"go-make bash": {
@@ -631,9 +662,9 @@ var makeExecTestCases = map[string]MakeExecParams{
 		args:         []string{"go-make", "--completion=bash"},
 		expectStdout: ReadFile(fixtures, "fixtures/completion/bash.out"),
 	},
-	"go-make bash trace": {
+	"go-make bash verbose": {
 		info:         infoBase,
-		args:         []string{"go-make", "--trace", "--completion=bash"},
+		args:         []string{"go-make", "--log-level=verbose", "--completion=bash"},
 		expectStdout: ReadFile(fixtures, "fixtures/completion/bash.out"),
 		expectStderr: ReadFile(fixtures, "fixtures/completion/bash.err"),
 	},
@@ -643,9 +674,9 @@ var makeExecTestCases = map[string]MakeExecParams{
 		args:         []string{"go-make", "--completion=zsh"},
 		expectStdout: ReadFile(fixtures, "fixtures/completion/zsh.out"),
 	},
-	"go-make zsh trace": {
+	"go-make zsh verbose": {
 		info:         infoBase,
-		args:         []string{"go-make", "--trace", "--completion=zsh"},
+		args:         []string{"go-make", "--log-level=verbose", "--completion=zsh"},
 		expectStdout: ReadFile(fixtures, "fixtures/completion/zsh.out"),
 		expectStderr: ReadFile(fixtures, "fixtures/completion/zsh.err"),
 	},
@@ -656,9 +687,15 @@ var makeExecTestCases = map[string]MakeExecParams{
 		args:         []string{"go-make", "show-targets"},
 		expectStdout: ReadFile(fixtures, "fixtures/targets/std.out"),
 	},
-	"go-make show targets trace": {
-		env:          []string{"FILE_TARGETS=${dir}/targets"},
-		args:         []string{"go-make", "--trace", "show-targets"},
+	"go-make show targets build": {
+		info:         infoBase,
+		env:          []string{"FILE_TARGETS=${dir}/targets.new"},
+		args:         []string{"go-make", "show-targets"},
+		expectStdout: ReadFile(fixtures, "fixtures/targets/std.out"),
+	},
+	"go-make show targets verbose trace": {
+		env:          []string{"FILE_TARGETS=${dir}/targets", "GOBIN=${dir}"},
+		args:         []string{"go-make", "--log-level=verbose", "--trace", "show-targets"},
 		expectStdout: ReadFile(fixtures, "fixtures/targets/trace.out"),
 		expectStderr: ReadFile(fixtures, "fixtures/targets/trace.err"),
 	},
@@ -668,9 +705,15 @@ var makeExecTestCases = map[string]MakeExecParams{
 		args:         []string{"go-make", "show-targets-make"},
 		expectStdout: ReadFile(fixtures, "fixtures/targets/make-std.out"),
 	},
-	"go-make show targets make trace": {
-		env:          []string{"FILE_TARGETS_MAKE=${dir}/targets.make"},
-		args:         []string{"go-make", "--trace", "show-targets-make"},
+	"go-make show targets make build": {
+		info:         infoBase,
+		env:          []string{"FILE_TARGETS_MAKE=${dir}/targets.make.new"},
+		args:         []string{"go-make", "show-targets-make"},
+		expectStdout: ReadFile(fixtures, "fixtures/targets/make-std.out"),
+	},
+	"go-make show targets make verbose trace": {
+		env:          []string{"FILE_TARGETS_MAKE=${dir}/targets.make", "GOBIN=${dir}"},
+		args:         []string{"go-make", "--log-level=verbose", "--trace", "show-targets-make"},
 		expectStdout: ReadFile(fixtures, "fixtures/targets/make-trace.out"),
 		expectStderr: ReadFile(fixtures, "fixtures/targets/make-trace.err"),
 	},
@@ -680,9 +723,15 @@ var makeExecTestCases = map[string]MakeExecParams{
 		args:         []string{"go-make", "show-targets-go-make"},
 		expectStdout: ReadFile(fixtures, "fixtures/targets/go-make-std.out"),
 	},
-	"go-make show targets go-make trace": {
-		env:          []string{"FILE_TARGETS_GOMAKE=${dir}/targets.go-make"},
-		args:         []string{"go-make", "--trace", "show-targets-go-make"},
+	"go-make show targets go-make build": {
+		info:         infoBase,
+		env:          []string{"FILE_TARGETS_GOMAKE=${dir}/targets.go-make.new"},
+		args:         []string{"go-make", "show-targets-go-make"},
+		expectStdout: ReadFile(fixtures, "fixtures/targets/go-make-std.out"),
+	},
+	"go-make show targets go-make verbose trace": {
+		env:          []string{"FILE_TARGETS_GOMAKE=${dir}/targets.go-make", "GOBIN=${dir}"},
+		args:         []string{"go-make", "--log-level=verbose", "--trace", "show-targets-go-make"},
 		expectStdout: ReadFile(fixtures, "fixtures/targets/go-make-trace.out"),
 		expectStderr: ReadFile(fixtures, "fixtures/targets/go-make-trace.err"),
 	},
@@ -694,39 +743,6 @@ var makeExecTestCases = map[string]MakeExecParams{
 		expectStdout: "Hello, World!",
 		expectStderr: ReadFile(fixtures, "fixtures/cat.err"),
 	},
-
-	"go-make git-verify log": {
-		info: infoBase,
-		args: []string{
-			"go-make", "git-verify", "log",
-			filepath.Join(dirFixtures, "git-verify", "log-all.in"),
-		},
-		expectStdout: ReadFile(fixtures, "fixtures/git-verify/log-all.out"),
-		expectStderr: ReadFile(fixtures, "fixtures/git-verify/log-all.err"),
-		expectExit:   3,
-	},
-	"go-make git-verify message failed": {
-		info: infoBase,
-		env:  []string{"GITAUTHOR=John Doe <john.doe@zalando.de>"},
-		args: []string{
-			"go-make", "git-verify", "message",
-			filepath.Join(dirFixtures, "git-verify", "msg-failed.in"),
-		},
-		expectStdout: ReadFile(fixtures, "fixtures/git-verify/msg-failed.out"),
-		expectStderr: ReadFile(fixtures, "fixtures/git-verify/msg-failed.err"),
-		expectExit:   3,
-	},
-	"go-make git-verify message okay": {
-		info: infoBase,
-		env:  []string{"GITAUTHOR=John Doe <john.doe@zalando.de>"},
-		args: []string{
-			"go-make", "git-verify", "message",
-			filepath.Join(dirFixtures, "git-verify", "msg-okay.in"),
-		},
-		expectStdout: ReadFile(fixtures, "fixtures/git-verify/msg-okay.out"),
-		expectStderr: ReadFile(fixtures, "fixtures/git-verify/msg-okay.err"),
-		expectExit:   0,
-	},
 }
 
 func TestMakeExec(t *testing.T) {
@@ -777,18 +793,72 @@ func TestMakeExec(t *testing.T) {
 }
 
 type handleSignalParams struct {
-	signal        syscall.Signal
-	expectAborted bool
+	mockSetup       mock.SetupFunc
+	signals         []os.Signal
+	level           log.Level
+	expectAborted   bool
+	expectCancelled bool
 }
 
 var handleSignalTestCases = map[string]handleSignalParams{
 	"signal abrt": {
-		signal:        syscall.SIGABRT,
-		expectAborted: true,
+		signals:         []os.Signal{syscall.SIGABRT},
+		expectAborted:   true,
+		expectCancelled: true,
+	},
+	"signal quit": {
+		signals:         []os.Signal{syscall.SIGQUIT},
+		expectCancelled: true,
+	},
+	"signal term without make": {
+		mockSetup: mock.Chain(
+			Signal(syscall.SIGTERM, false),
+		),
+		signals:         []os.Signal{syscall.SIGTERM},
+		expectCancelled: true,
 	},
-	"signal term": {
-		signal:        syscall.SIGTERM,
-		expectAborted: false,
+	"signal term forwarded": {
+		mockSetup: mock.Chain(
+			Signal(syscall.SIGTERM, true),
+			LogWarning("stderr", "forwarded terminated to make, "+
+				"repeat to terminate"),
+		),
+		signals: []os.Signal{syscall.SIGTERM},
+	},
+	"signal hup forwarded quiet": {
+		mockSetup: mock.Chain(
+			Signal(syscall.SIGHUP, true),
+			LogWarning("stderr", "forwarded hangup to make, "+
+				"repeat to terminate"),
+		),
+		signals: []os.Signal{syscall.SIGHUP},
+		level:   log.LevelQuiet,
+	},
+	"signal int escalated": {
+		mockSetup: mock.Chain(
+			Signal(syscall.SIGINT, true),
+			LogWarning("stderr", "forwarded interrupt to make, "+
+				"repeat to terminate"),
+			Signal(syscall.SIGTERM, true),
+			LogWarning("stderr", "terminating make, repeat to kill"),
+			Signal(syscall.SIGKILL, true),
+			LogWarning("stderr", "killing make"),
+			Signal(syscall.SIGKILL, true),
+			LogWarning("stderr", "killing make"),
+		),
+		signals: []os.Signal{
+			syscall.SIGINT, syscall.SIGINT, syscall.SIGINT, syscall.SIGINT,
+		},
+	},
+	"signal int escalated after make": {
+		mockSetup: mock.Chain(
+			Signal(syscall.SIGINT, true),
+			LogWarning("stderr", "forwarded interrupt to make, "+
+				"repeat to terminate"),
+			Signal(syscall.SIGTERM, false),
+		),
+		signals:         []os.Signal{syscall.SIGINT, syscall.SIGINT},
+		expectCancelled: true,
 	},
 }
 
@@ -796,15 +866,197 @@ func TestHandleSignal(t *testing.T) {
 	test.Map(t, handleSignalTestCases).
 		Run(func(t test.Test, param handleSignalParams) {
 			// Given
-			gm := &GoMake{}
+			mocks := mock.NewMocks(t).
+				SetArg("stderr", NewWriter("stderr")).
+				Expect(param.mockSetup)
+			logger := mock.Get(mocks, NewMockLogger)
+			logger.EXPECT().Level().AnyTimes().Return(param.level)
+			gm := &GoMake{
+				Executor: mock.Get(mocks, NewMockExecutor),
+				Logger:   logger,
+				Stderr:   mocks.GetArg("stderr").(io.Writer),
+			}
 			var cancelled atomic.Bool
 			cancel := func() { cancelled.Store(true) }
 
 			// When
-			gm.HandleSignal(cancel, param.signal)
+			for index, signal := range param.signals {
+				gm.HandleSignal(cancel, signal, index+1)
+			}
 
 			// Then
 			assert.Equal(t, param.expectAborted, gm.Aborted.Load())
-			assert.True(t, cancelled.Load())
+			assert.Equal(t, param.expectCancelled, cancelled.Load())
+		})
+}
+
+type SetupColorParams struct {
+	env         []string
+	expectColor bool
+}
+
+var setupColorTestCases = map[string]SetupColorParams{
+	"go-make color always": {
+		env:         []string{EnvGoMakeColor + "=" + log.ColorAlways},
+		expectColor: true,
+	},
+	"go-make color never": {
+		env:         []string{EnvGoMakeColor + "=" + log.ColorNever},
+		expectColor: false,
+	},
+	"go-make color never forced": {
+		env: []string{
+			"FORCE_COLOR=1", EnvGoMakeColor + "=" + log.ColorNever,
+		},
+		expectColor: false,
+	},
+	"force color": {
+		env:         []string{"NO_COLOR=", "TERM=xterm", "FORCE_COLOR=1"},
+		expectColor: true,
+	},
+	"no color": {
+		env:         []string{"NO_COLOR=1", "FORCE_COLOR=1"},
+		expectColor: false,
+	},
+}
+
+func TestSetupColor(t *testing.T) {
+	test.Map(t, setupColorTestCases).
+		Run(func(t test.Test, param SetupColorParams) {
+			// Given
+			env := append([]string{EnvGoMakeColor + "="}, param.env...)
+
+			// When
+			gm := NewGoMake(nil, nil, &strings.Builder{},
+				infoBase, "", dirWork, env...)
+
+			// Then
+			assert.Equal(t, param.expectColor, gm.Color)
+		})
+}
+
+var (
+	// mockTraceSetup contains the mock setup for a traced any target call.
+	mockTraceSetup = mock.Chain(
+		Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+		Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+			"nil", "stderr", "stderr", "", "", nil),
+		Exec(CmdMakeTargets(makeInfoBase, []string{"target"}, dirRoot,
+			MakeEnv()...).WithMode(cmd.Forward), "stdin", "stdout", "stderr", "", "", nil),
+		ExecCommit(dirRoot),
+	)
+	// spansTraceAnyTarget contains the expected span names of a traced any
+	// target call.
+	spansTraceAnyTarget = []string{
+		"go-make", "setup-workdir", "exec", "setup-config",
+		"ensure-config", "exec", "make", "exec", "exec",
+	}
+)
+
+type MakeTraceParams struct {
+	mockSetup    mock.SetupFunc
+	file         string
+	otlp         string
+	status       int
+	expectFile   []string
+	expectExport []string
+}
+
+var makeTraceTestCases = map[string]MakeTraceParams{
+	"trace file": {
+		mockSetup:  mockTraceSetup,
+		file:       "build/go-make.trace.json",
+		expectFile: spansTraceAnyTarget,
+	},
+	"trace file failed": {
+		mockSetup: mock.Chain(mockTraceSetup,
+			LogErrorAny("stderr", "write trace")),
+		file: "build/\x00/go-make.trace.json",
+	},
+	"trace otlp": {
+		mockSetup:    mockTraceSetup,
+		otlp:         "/",
+		status:       http.StatusOK,
+		expectExport: spansTraceAnyTarget,
+	},
+	"trace otlp failed": {
+		mockSetup: mock.Chain(mockTraceSetup,
+			LogErrorAny("stderr", "export trace")),
+		otlp:         "/",
+		status:       http.StatusInternalServerError,
+		expectExport: spansTraceAnyTarget,
+	},
+	"trace file and otlp": {
+		mockSetup:    mockTraceSetup,
+		file:         "go-make.trace.json",
+		otlp:         "/v1/traces",
+		status:       http.StatusOK,
+		expectFile:   spansTraceAnyTarget,
+		expectExport: spansTraceAnyTarget,
+	},
+}
+
+func TestMakeTrace(t *testing.T) {
+	test.Map(t, makeTraceTestCases).
+		Run(func(t test.Test, param MakeTraceParams) {
+			// Given
+			exported := []string{}
+			server := httptest.NewServer(http.HandlerFunc(
+				func(writer http.ResponseWriter, req *http.Request) {
+					request := struct {
+						ResourceSpans []struct {
+							ScopeSpans []struct {
+								Spans []struct{ Name string }
+							}
+						}
+					}{}
+					assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+					for _, span := range request.ResourceSpans[0].
+						ScopeSpans[0].Spans {
+						exported = append(exported, span.Name)
+					}
+					writer.WriteHeader(param.status)
+				}))
+			defer server.Close()
+
+			dir := t.TempDir()
+			args := []string{"go-make"}
+			if param.file != "" {
+				args = append(args, "--trace-file="+
+					filepath.Join(dir, param.file))
+			}
+			if param.otlp != "" {
+				args = append(args, "--trace-otlp="+server.URL+param.otlp)
+			}
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+			})
+
+			// When
+			exit, err := gm.Make(append(args, "target")...)
+
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, ExitSuccess, exit)
+			if param.expectFile != nil {
+				// #nosec G304 -- test file in temporary directory.
+				data, err := os.ReadFile(filepath.Join(dir, param.file))
+				assert.NoError(t, err)
+				events := struct {
+					TraceEvents []struct{ Name string }
+				}{}
+				assert.NoError(t, json.Unmarshal(data, &events))
+				names := []string{}
+				for _, event := range events.TraceEvents {
+					names = append(names, event.Name)
+				}
+				assert.Equal(t, param.expectFile, names)
+			}
+			if param.expectExport != nil {
+				assert.Equal(t, param.expectExport, exported)
+			} else {
+				assert.Empty(t, exported)
+			}
 		})
 }
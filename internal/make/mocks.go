@@ -0,0 +1,255 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/build"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/mocks"
+)
+
+const (
+	// CmdGenerateMocks provides the name of the native generate-mocks command.
+	CmdGenerateMocks = "generate-mocks"
+	// EnvSources provides the name of the makefile variable containing the
+	// go source files of the project.
+	EnvSources = "SOURCES"
+	// EnvFileMocks provides the name of the mock state file prefix
+	// environment variable.
+	EnvFileMocks = "FILE_MOCKS"
+	// EnvGoBin provides the name of the go binary directory environment
+	// variable.
+	EnvGoBin = "GOBIN"
+)
+
+// ErrGenerateMocks represents a failed mock generation.
+var ErrGenerateMocks = errors.New("mock generation failed")
+
+// NewErrGenerateMocks creates a failed mock generation error for the given
+// number of failures.
+func NewErrGenerateMocks(failed int) error {
+	return fmt.Errorf("%w [failed=%d]", ErrGenerateMocks, failed)
+}
+
+// CmdGoListModules creates the argument array of a `go list -m all` command
+// listing the module versions of the project.
+func CmdGoListModules(dir string, env ...string) *cmd.Cmd {
+	return cmd.New("go", "list", "-m", "all").
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdGoVersionBinary creates the argument array of a `go version -m` command
+// reporting the module version of the given binary.
+func CmdGoVersionBinary(binary, dir string, env ...string) *cmd.Cmd {
+	return cmd.New("go", "version", "-m", binary).
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdMockGenerate creates the argument array of the mock generator command
+// of the given mock.
+func CmdMockGenerate(mock *mocks.Mock, dir string, env ...string) *cmd.Cmd {
+	return cmd.New(mock.Command...).WithEnv(env...).WithWorkDir(dir)
+}
+
+// generateMocksParams contains the parameters of the generate-mocks command.
+type generateMocksParams struct {
+	// workers provides the maximum number of parallel mock generations.
+	workers int
+	// force indicates whether all mocks are regenerated.
+	force bool
+}
+
+// parseGenerateMocks parses the arguments of the generate-mocks command.
+func parseGenerateMocks(args ...string) (*generateMocksParams, error) {
+	params := &generateMocksParams{workers: runtime.NumCPU()}
+	for _, arg := range args {
+		switch {
+		case arg == "--force":
+			params.force = true
+		case strings.HasPrefix(arg, "--workers="):
+			workers, err := strconv.Atoi(arg[len("--workers="):])
+			if err != nil || workers <= 0 {
+				return nil, NewErrInvalidArg(CmdGenerateMocks, arg, err)
+			}
+			params.workers = workers
+		default:
+			return nil, NewErrInvalidArg(CmdGenerateMocks, arg, nil)
+		}
+	}
+	return params, nil
+}
+
+// generateMocks runs the native generate-mocks command with given arguments.
+// It parses the mock directives of the `SOURCES`, plans the generation of the
+// stale mocks by comparing the content hashes of their sources and the
+// versions of their source packages and mock generators with the recorded
+// mock state, and generates the stale mocks in parallel using a bounded
+// number of workers. It reports the regenerated mocks with the reason of the
+// generation.
+func (gm *GoMake) generateMocks(args ...string) (int, error) {
+	params, err := parseGenerateMocks(args...)
+	if err != nil {
+		gm.error("parse generate-mocks", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	sources, err := gm.variable(ctx, EnvSources, "")
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+
+	failed := 0
+	list, err := mocks.Parse(gm.WorkDir, strings.Fields(sources)...)
+	if err != nil {
+		gm.error("parse mocks", err)
+		failed++
+	}
+	if len(list) == 0 && failed == 0 {
+		return ExitSuccess, nil
+	}
+
+	file := gm.fileMocks()
+	state, err := mocks.ReadState(file)
+	if err != nil {
+		gm.error("read mocks state", err)
+		return ExitCommandFailure, err
+	}
+	resolver, err := gm.mockResolver(ctx, list)
+	if err != nil {
+		gm.error("list modules", err)
+		return ExitCommandFailure, err
+	}
+
+	plans := []*mocks.Plan{}
+	for _, mock := range list {
+		plan, err := resolver.Plan(mock, state[mock.Target], params.force)
+		if err != nil {
+			gm.error("plan mock", err)
+			failed++
+		} else if plan.Reason != "" {
+			plans = append(plans, plan)
+		}
+	}
+
+	regenerated := 0
+	outputs, errs := gm.mockGenerate(ctx, plans, params.workers)
+	for index, plan := range plans {
+		if errs[index] != nil {
+			_, _ = gm.Stderr.Write([]byte(outputs[index]))
+			gm.error("generate mock", errs[index])
+			failed++
+			continue
+		}
+		state[plan.Mock.Target] = plan.Entry
+		regenerated++
+		gm.Logger.Message(gm.Stdout, fmt.Sprintf("regenerated %s [reason=%s]",
+			plan.Mock.Target, plan.Reason))
+	}
+
+	if len(plans) != 0 {
+		if err := state.Write(file); err != nil {
+			gm.error("write mocks state", err)
+			return ExitCommandFailure, err
+		}
+	}
+	gm.Logger.Message(gm.Stdout, fmt.Sprintf("mocks [total=%d, "+
+		"regenerated=%d, failed=%d]", len(list), regenerated, failed))
+
+	if failed != 0 {
+		err := NewErrGenerateMocks(failed)
+		gm.error(CmdGenerateMocks, err)
+		return ExitCommandFailure, err
+	}
+	return ExitSuccess, nil
+}
+
+// mockResolver creates the mock resolver for the given mocks providing the
+// versions of the mock generators and, if needed, of the project modules.
+func (gm *GoMake) mockResolver(
+	ctx context.Context, list []*mocks.Mock,
+) (*mocks.Resolver, error) {
+	resolver := &mocks.Resolver{
+		Root:     gm.WorkDir,
+		Modules:  map[string]string{},
+		Versions: map[string]string{},
+	}
+
+	gobin := gm.GetEnvDefault(EnvGoBin, "")
+	if gobin == "" {
+		gobin = filepath.Join(build.Default.GOPATH, "bin")
+	}
+
+	modules := false
+	for _, mock := range list {
+		name := mock.Command[0]
+		if _, ok := resolver.Versions[name]; !ok {
+			// A missing generator results in an unknown version, while the
+			// failure is reported by the generation.
+			output := &strings.Builder{}
+			_ = gm.exec(ctx, CmdGoVersionBinary(filepath.Join(gobin, name),
+				gm.WorkDir, gm.Env...).WithIO(nil, output, io.Discard))
+			resolver.Versions[name] = mocks.ParseVersion(output.String())
+		}
+		modules = modules || (mock.Package != "" &&
+			!build.IsLocalImport(mock.Package))
+	}
+
+	if modules {
+		output := &strings.Builder{}
+		if err := gm.exec(ctx, CmdGoListModules(gm.WorkDir, gm.Env...).
+			WithIO(nil, output, gm.Stderr)); err != nil {
+			return nil, err
+		}
+		resolver.Modules = mocks.ParseModules(output.String())
+	}
+	return resolver, nil
+}
+
+// mockGenerate generates the mocks of the given plans in parallel using the
+// given number of workers. It returns the outputs and errors of the mock
+// generator commands in order of the plans.
+func (gm *GoMake) mockGenerate(
+	ctx context.Context, plans []*mocks.Plan, workers int,
+) ([]string, []error) {
+	outputs := make([]string, len(plans))
+	errs := make([]error, len(plans))
+	limit := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for index, plan := range plans {
+		limit <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-limit }()
+			output := &strings.Builder{}
+			errs[index] = gm.exec(ctx, CmdMockGenerate(plan.Mock,
+				gm.WorkDir, gm.Env...).WithIO(nil, output, output))
+			outputs[index] = output.String()
+		})
+	}
+	wg.Wait()
+	return outputs, errs
+}
+
+// fileMocks returns the path of the mock state file. It uses the
+// `FILE_MOCKS` environment variable as prefix, or the default prefix in the
+// per-project cache directory.
+func (gm *GoMake) fileMocks() string {
+	file := gm.GetEnvDefault(EnvFileMocks, "")
+	if file == "" {
+		file = filepath.Join(gm.cacheDir(), "mocks")
+	}
+	return filepath.Clean(file + ".json")
+}
@@ -0,0 +1,398 @@
+package make_test
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/mocks"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// mocksSource contains a source file with a source mode mock directive.
+	mocksSource = "package a\n\n//go:generate mockgen -package=a " +
+		"-destination=mock_a_test.go -source=a.go Iface\n"
+	// mocksPackage contains a source file with package mode mock directives.
+	mocksPackage = "package b\n\n//go:generate mock " +
+		"-destination=mock_io_test.go io Reader\n" +
+		"//go:generate mockgen -destination=mock_dep_test.go " +
+		"github.com/dep/pkg Iface\n"
+	// mocksVersion contains the output of `go version -m` of mockgen.
+	mocksVersion = "/gobin/mockgen: go1.26.4\n" +
+		"\tpath\tgo.uber.org/mock/mockgen\n" +
+		"\tmod\tgo.uber.org/mock\tv0.6.0\th1:hash=\n"
+	// mocksModules contains the output of `go list -m all`.
+	mocksModules = "example.com/main\ngithub.com/dep/pkg v1.0.0\n"
+)
+
+var (
+	// mockSource contains the resolved source mode mock.
+	mockSource = &mocks.Mock{Command: []string{
+		"mockgen", "-package=a", "-destination=a/mock_a_test.go",
+		"-source=a/a.go", "Iface",
+	}}
+	// mockIO contains the resolved package mode mock of `io`.
+	mockIO = &mocks.Mock{Command: []string{
+		"mock", "-destination=b/mock_io_test.go", "io", "Reader",
+	}}
+	// mockDep contains the resolved package mode mock of a dependency.
+	mockDep = &mocks.Mock{Command: []string{
+		"mockgen", "-destination=b/mock_dep_test.go",
+		"github.com/dep/pkg", "Iface",
+	}}
+)
+
+// DirMocks returns the project directory of the mocks test case with given
+// name.
+func DirMocks(name string) string {
+	return filepath.Join(dirTargets, "mocks", name)
+}
+
+// EnvMocks returns the environment of the mocks test case with given name
+// using the given sources.
+func EnvMocks(name string, sources ...string) []string {
+	return []string{
+		EnvSources + "=" + strings.Join(sources, " "),
+		EnvGoBin + "=/gobin",
+		EnvFileMocks + "=" + filepath.Join(DirMocks(name), "cache", "mocks"),
+	}
+}
+
+// ExecMockVersion returns the mock setup of the version call of the given
+// mock generator.
+func ExecMockVersion(name, binary string, env []string) mock.SetupFunc {
+	return Exec(CmdGoVersionBinary(binary, DirMocks(name), env...),
+		"nil", "builder", "discard", mocksVersion, "", nil)
+}
+
+type GenerateMocksParams struct {
+	mockSetup   mock.SetupFunc
+	dir         string
+	env         []string
+	args        []string
+	files       map[string]string
+	state       bool
+	expectState []string
+	expectError error
+	expectExit  int
+}
+
+var generateMocksTestCases = map[string]GenerateMocksParams{
+	"generate missing": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("missing", "a/a.go")...),
+				"nil", "builder", "stderr", DirMocks("missing"), "", nil),
+			ExecMockVersion("missing", "/gobin/mockgen",
+				EnvMocks("missing", "a/a.go")),
+			Exec(CmdMockGenerate(mockSource, DirMocks("missing"),
+				EnvMocks("missing", "a/a.go")...),
+				"nil", "builder", "builder", "", "", nil),
+			LogMessage("stdout", "regenerated a/mock_a_test.go "+
+				"[reason=missing]"),
+			LogMessage("stdout", "mocks [total=1, regenerated=1, failed=0]"),
+		),
+		dir:   "missing",
+		env:   EnvMocks("missing", "a/a.go"),
+		args:  []string{"go-make", "--generate-mocks"},
+		files: map[string]string{"a/a.go": mocksSource},
+		expectState: []string{
+			`"a/mock_a_test.go"`, `"version": "go.uber.org/mock@v0.6.0"`,
+		},
+	},
+	"generate up-to-date": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("fresh", "a/a.go")...),
+				"nil", "builder", "stderr", DirMocks("fresh"), "", nil),
+			ExecMockVersion("fresh", "/gobin/mockgen",
+				EnvMocks("fresh", "a/a.go")),
+			LogMessage("stdout", "mocks [total=1, regenerated=0, failed=0]"),
+		),
+		dir:  "fresh",
+		env:  EnvMocks("fresh", "a/a.go"),
+		args: []string{"go-make", "--generate-mocks"},
+		files: map[string]string{
+			"a/a.go": mocksSource, "a/mock_a_test.go": "",
+		},
+		state: true,
+	},
+	"generate forced": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("forced", "a/a.go")...),
+				"nil", "builder", "stderr", DirMocks("forced"), "", nil),
+			ExecMockVersion("forced", "/gobin/mockgen",
+				EnvMocks("forced", "a/a.go")),
+			Exec(CmdMockGenerate(mockSource, DirMocks("forced"),
+				EnvMocks("forced", "a/a.go")...),
+				"nil", "builder", "builder", "", "", nil),
+			LogMessage("stdout", "regenerated a/mock_a_test.go "+
+				"[reason=forced]"),
+			LogMessage("stdout", "mocks [total=1, regenerated=1, failed=0]"),
+		),
+		dir:  "forced",
+		env:  EnvMocks("forced", "a/a.go"),
+		args: []string{"go-make", "--generate-mocks", "--force"},
+		files: map[string]string{
+			"a/a.go": mocksSource, "a/mock_a_test.go": "",
+		},
+		state:       true,
+		expectState: []string{`"a/mock_a_test.go"`},
+	},
+	"generate packages parallel": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("packages", "b/b.go")...),
+				"nil", "builder", "stderr", DirMocks("packages"), "", nil),
+			ExecMockVersion("packages", "/gobin/mockgen",
+				EnvMocks("packages", "b/b.go")),
+			ExecMockVersion("packages", "/gobin/mock",
+				EnvMocks("packages", "b/b.go")),
+			Exec(CmdGoListModules(DirMocks("packages"),
+				EnvMocks("packages", "b/b.go")...),
+				"nil", "builder", "stderr", mocksModules, "", nil),
+			mock.Parallel(
+				Exec(CmdMockGenerate(mockDep, DirMocks("packages"),
+					EnvMocks("packages", "b/b.go")...),
+					"nil", "builder", "builder", "", "", nil),
+				Exec(CmdMockGenerate(mockIO, DirMocks("packages"),
+					EnvMocks("packages", "b/b.go")...),
+					"nil", "builder", "builder", "", "", nil),
+			),
+			LogMessage("stdout", "regenerated b/mock_dep_test.go "+
+				"[reason=missing]"),
+			LogMessage("stdout", "regenerated b/mock_io_test.go "+
+				"[reason=missing]"),
+			LogMessage("stdout", "mocks [total=2, regenerated=2, failed=0]"),
+		),
+		dir:   "packages",
+		env:   EnvMocks("packages", "b/b.go"),
+		args:  []string{"go-make", "--generate-mocks", "--workers=2"},
+		files: map[string]string{"b/b.go": mocksPackage},
+		expectState: []string{
+			`"package": "github.com/dep/pkg@v1.0.0"`, `"package": "io"`,
+		},
+	},
+	"generate default gobin": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("gobin", "a/a.go")[0],
+				EnvMocks("gobin", "a/a.go")[2]), "nil", "builder",
+				"stderr", DirMocks("gobin"), "", nil),
+			Exec(CmdGoVersionBinary(filepath.Join(build.Default.GOPATH,
+				"bin", "mockgen"), DirMocks("gobin"),
+				EnvMocks("gobin", "a/a.go")[0],
+				EnvMocks("gobin", "a/a.go")[2]),
+				"nil", "builder", "discard", "", "", assert.AnError),
+			LogMessage("stdout", "mocks [total=1, regenerated=0, failed=0]"),
+		),
+		dir: "gobin",
+		env: []string{
+			EnvMocks("gobin", "a/a.go")[0], EnvMocks("gobin", "a/a.go")[2],
+		},
+		args: []string{"go-make", "--generate-mocks"},
+		files: map[string]string{
+			"a/a.go": mocksSource, "a/mock_a_test.go": "",
+		},
+		state: true,
+	},
+	"generate failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("failed", "a/a.go")...),
+				"nil", "builder", "stderr", DirMocks("failed"), "", nil),
+			ExecMockVersion("failed", "/gobin/mockgen",
+				EnvMocks("failed", "a/a.go")),
+			Exec(CmdMockGenerate(mockSource, DirMocks("failed"),
+				EnvMocks("failed", "a/a.go")...),
+				"nil", "builder", "builder", "", "failure", assert.AnError),
+			LogError("stderr", "generate mock", NewErrCallFailed(
+				CmdMockGenerate(mockSource, DirMocks("failed")),
+				assert.AnError)),
+			LogMessage("stdout", "mocks [total=1, regenerated=0, failed=1]"),
+			LogError("stderr", CmdGenerateMocks, NewErrGenerateMocks(1)),
+		),
+		dir:         "failed",
+		env:         EnvMocks("failed", "a/a.go"),
+		args:        []string{"go-make", "--generate-mocks"},
+		files:       map[string]string{"a/a.go": mocksSource},
+		expectState: []string{"{}"},
+		expectError: NewErrGenerateMocks(1),
+		expectExit:  ExitCommandFailure,
+	},
+	"no mocks": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("none", "c/c.go")...),
+				"nil", "builder", "stderr", DirMocks("none"), "", nil),
+		),
+		dir:   "none",
+		env:   EnvMocks("none", "c/c.go"),
+		args:  []string{"go-make", "--generate-mocks"},
+		files: map[string]string{"c/c.go": "package c\n"},
+	},
+	"parse failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("parse", "c/c.go")...),
+				"nil", "builder", "stderr", DirMocks("parse"), "", nil),
+			LogErrorAny("stderr", "parse mocks"),
+			LogMessage("stdout", "mocks [total=0, regenerated=0, failed=1]"),
+			LogError("stderr", CmdGenerateMocks, NewErrGenerateMocks(1)),
+		),
+		dir:         "parse",
+		env:         EnvMocks("parse", "c/c.go"),
+		args:        []string{"go-make", "--generate-mocks"},
+		files:       map[string]string{"c/c.go": "package"},
+		expectError: NewErrGenerateMocks(1),
+		expectExit:  ExitCommandFailure,
+	},
+	"plan failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("plan", "a/b.go")...),
+				"nil", "builder", "stderr", DirMocks("plan"), "", nil),
+			ExecMockVersion("plan", "/gobin/mockgen",
+				EnvMocks("plan", "a/b.go")),
+			LogErrorAny("stderr", "plan mock"),
+			LogMessage("stdout", "mocks [total=1, regenerated=0, failed=1]"),
+			LogError("stderr", CmdGenerateMocks, NewErrGenerateMocks(1)),
+		),
+		dir:         "plan",
+		env:         EnvMocks("plan", "a/b.go"),
+		args:        []string{"go-make", "--generate-mocks"},
+		files:       map[string]string{"a/b.go": mocksSource},
+		expectError: NewErrGenerateMocks(1),
+		expectExit:  ExitCommandFailure,
+	},
+	"list modules failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("modules", "b/b.go")...),
+				"nil", "builder", "stderr", DirMocks("modules"), "", nil),
+			ExecMockVersion("modules", "/gobin/mockgen",
+				EnvMocks("modules", "b/b.go")),
+			ExecMockVersion("modules", "/gobin/mock",
+				EnvMocks("modules", "b/b.go")),
+			Exec(CmdGoListModules(DirMocks("modules"),
+				EnvMocks("modules", "b/b.go")...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogError("stderr", "list modules", NewErrCallFailed(
+				CmdGoListModules(DirMocks("modules")), assert.AnError)),
+		),
+		dir:   "modules",
+		env:   EnvMocks("modules", "b/b.go"),
+		args:  []string{"go-make", "--generate-mocks"},
+		files: map[string]string{"b/b.go": mocksPackage},
+		expectError: NewErrCallFailed(
+			CmdGoListModules(DirMocks("modules")), assert.AnError),
+		expectExit: ExitCommandFailure,
+	},
+	"read state failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvMocks("state", "a/a.go")...),
+				"nil", "builder", "stderr", DirMocks("state"), "", nil),
+			LogErrorAny("stderr", "read mocks state"),
+		),
+		dir:  "state",
+		env:  EnvMocks("state", "a/a.go"),
+		args: []string{"go-make", "--generate-mocks"},
+		files: map[string]string{
+			"a/a.go": mocksSource, "cache/mocks.json/file": "",
+		},
+		expectError: mocks.NewErrState(
+			filepath.Join(DirMocks("state"), "cache", "mocks.json"),
+			&os.PathError{Op: "read", Path: filepath.Join(
+				DirMocks("state"), "cache", "mocks.json"),
+				Err: syscall.EISDIR}),
+		expectExit: ExitCommandFailure,
+	},
+	"config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirMocks("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirMocks("config")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirMocks("config")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirMocks("config")), assert.AnError))),
+		),
+		dir:  "config",
+		args: []string{"go-make", "--generate-mocks"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirMocks("config")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"invalid argument": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse generate-mocks",
+				NewErrInvalidArg(CmdGenerateMocks, "--workers=0", nil)),
+		),
+		args: []string{"go-make", "--generate-mocks", "--workers=0"},
+		expectError: NewErrInvalidArg(CmdGenerateMocks,
+			"--workers=0", nil),
+		expectExit: ExitCommandFailure,
+	},
+	"unknown argument": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse generate-mocks",
+				NewErrInvalidArg(CmdGenerateMocks, "--all", nil)),
+		),
+		args:        []string{"go-make", "--generate-mocks", "--all"},
+		expectError: NewErrInvalidArg(CmdGenerateMocks, "--all", nil),
+		expectExit:  ExitCommandFailure,
+	},
+}
+
+func TestGenerateMocks(t *testing.T) {
+	test.Map(t, generateMocksTestCases).
+		Run(func(t test.Test, param GenerateMocksParams) {
+			// Given
+			dir := DirMocks(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				WriteFile(path, 0o600, content)
+			}
+			if param.state {
+				list, err := mocks.Parse(dir, "a/a.go")
+				assert.NoError(t, err)
+				version := mocks.ParseVersion(mocksVersion)
+				if param.dir == "gobin" {
+					version = ""
+				}
+				resolver := &mocks.Resolver{
+					Root: dir, Versions: map[string]string{"mockgen": version},
+				}
+				entry, err := resolver.Entry(list[0])
+				assert.NoError(t, err)
+				assert.NoError(t, mocks.State{list[0].Target: entry}.
+					Write(filepath.Join(dir, "cache", "mocks.json")))
+			}
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			for _, content := range param.expectState {
+				data, err := os.ReadFile(
+					filepath.Join(dir, "cache", "mocks.json"))
+				assert.NoError(t, err)
+				assert.Contains(t, string(data), content)
+			}
+		})
+}
@@ -0,0 +1,66 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"slices"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/picker"
+)
+
+// pickTargets lets the user pick a target from the annotated targets of the
+// target catalog using the standard input and output, and executes the
+// picked target with its arguments together with the given additional make
+// arguments. The signal handling is only set up for executing the target,
+// so that an interrupt while picking terminates go-make as usual.
+func (gm *GoMake) pickTargets(mode cmd.Mode, args []string) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	if err := gm.setupConfig(ctx); err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+
+	db, err := gm.database(ctx, *SuffixTargets)
+	if err != nil {
+		gm.error("build catalog", err)
+		return ExitTargetFailure, err
+	}
+	targets, err := gm.catalog(gm.makefiles(db), *SuffixTargets)
+	if err != nil {
+		gm.error("build catalog", err)
+		return ExitTargetFailure, err
+	}
+
+	picked, err := picker.New(gm.Stdin, gm.Stdout).Pick(targets)
+	if err != nil {
+		gm.error("pick target", err)
+		return ExitCommandFailure, err
+	} else if picked == nil {
+		return ExitSuccess, nil
+	}
+	gm.Picked = picked
+
+	ctx, stop := gm.signalContext(ctx)
+	defer stop()
+
+	return gm.execTargets(ctx, mode, append(args, picked...))
+}
+
+// pickedArgs returns the given arguments and make targets with the pick
+// option replaced by the picked target and arguments, if a target was picked
+// interactively, so that the run history records the executed make targets.
+// Else the arguments and make targets are returned unchanged.
+func (gm *GoMake) pickedArgs(args, targets []string) ([]string, []string) {
+	if gm.Picked == nil {
+		return args, targets
+	}
+
+	args = slices.DeleteFunc(slices.Clone(args), func(arg string) bool {
+		return arg == "--pick"
+	})
+	return append(args, gm.Picked...),
+		append(slices.Clone(targets), gm.Picked...)
+}
@@ -0,0 +1,251 @@
+package make_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/history"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/makedb"
+	"github.com/tkrop/go-make/internal/picker"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// argsPick contains the arguments to pick the target interactively.
+	argsPick = []string{"go-make", "--pick"}
+
+	// outputPick contains the output of the target picker for the catalog
+	// fixture.
+	outputPick = "stdout" +
+		"Standard:\n" +
+		"   1  all            executes the default targets.\n" +
+		"Build:\n" +
+		"   2  build-*        build executables for a specific platform.\n" +
+		"   3  build [<pkg>]  build the executables.\n" +
+		"filter or number (empty to quit)> "
+)
+
+type PickTargetsParams struct {
+	mockSetup    mock.SetupFunc
+	args         []string
+	input        string
+	stdin        io.Reader
+	expectOutput string
+	expectArgs   []string
+	expectError  error
+	expectExit   int
+}
+
+var pickTargetsTestCases = map[string]PickTargetsParams{
+	"pick target": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"build", "cmd/go-make"},
+				dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
+		),
+		args:         argsPick,
+		input:        "3\ncmd/go-make\n",
+		expectArgs:   []string{"go-make", "build", "cmd/go-make"},
+		expectOutput: outputPick + "arguments [<pkg>]> ",
+	},
+	"pick target family with make arguments": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{
+				"--jobs=4", "build-linux",
+			}, dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
+		),
+		args:         []string{"go-make", "--pick", "--jobs=4"},
+		input:        "2\nlinux\n",
+		expectArgs:   []string{"go-make", "--jobs=4", "build-linux"},
+		expectOutput: outputPick + "value of % in build-%> ",
+	},
+	"pick target with ignore errors": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"-i", "all"},
+				dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
+		),
+		args:         []string{"go-make", "--pick", "-i"},
+		input:        "1\n",
+		expectArgs:   []string{"go-make", "-i", "all"},
+		expectOutput: outputPick,
+	},
+	"pick target failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"all"},
+				dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", assert.AnError),
+			LogError("stderr", "execute make", NewErrCallFailed(
+				CmdMakeTargets(makeInfoBase, []string{"all"}, dirRoot),
+				assert.AnError)),
+			ExecCommit(dirRoot),
+		),
+		args:         argsPick,
+		input:        "1\n",
+		expectArgs:   []string{"go-make", "all"},
+		expectOutput: outputPick,
+		expectError: NewErrCallFailed(CmdMakeTargets(
+			makeInfoBase, []string{"all"}, dirRoot), assert.AnError),
+		expectExit: ExitTargetFailure,
+	},
+	"pick target quit": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			ExecCommit(dirRoot),
+		),
+		args:         argsPick,
+		input:        "\n",
+		expectArgs:   argsPick,
+		expectOutput: outputPick,
+	},
+	"pick target read failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			LogError("stderr", "pick target",
+				picker.NewErrPick(assert.AnError)),
+			ExecCommit(dirRoot),
+		),
+		args:         argsPick,
+		stdin:        iotest.ErrReader(assert.AnError),
+		expectArgs:   argsPick,
+		expectOutput: outputPick,
+		expectError:  picker.NewErrPick(assert.AnError),
+		expectExit:   ExitCommandFailure,
+	},
+	"pick target config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version, dirRoot),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version, dirRoot),
+					assert.AnError))),
+			ExecCommit(dirRoot),
+		),
+		args:         argsPick,
+		expectArgs:   argsPick,
+		expectOutput: "stdout",
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				dirRoot), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"pick target database failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", "# Files\n\ninvalid\n", "", nil),
+			LogError("stderr", "build catalog", NewErrTargets("",
+				makedb.NewErrParse(3, errors.New("invalid rule [invalid]")))),
+			ExecCommit(dirRoot),
+		),
+		args:         argsPick,
+		expectArgs:   argsPick,
+		expectOutput: "stdout",
+		expectError: NewErrTargets("",
+			makedb.NewErrParse(3, errors.New("invalid rule [invalid]"))),
+		expectExit: ExitTargetFailure,
+	},
+	"pick target catalog failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard",
+				"# Variables\n\n# makefile\n"+
+					"MAKEFILE_LIST :=  missing\n", "", nil),
+			LogError("stderr", "build catalog", NewErrTargets("",
+				&fs.PathError{
+					Op: "open", Path: filepath.Join(dirRoot, "missing"),
+					Err: syscall.ENOENT,
+				})),
+			ExecCommit(dirRoot),
+		),
+		args:         argsPick,
+		expectArgs:   argsPick,
+		expectOutput: "stdout",
+		expectError: NewErrTargets("", &fs.PathError{
+			Op: "open", Path: filepath.Join(dirRoot, "missing"),
+			Err: syscall.ENOENT,
+		}),
+		expectExit: ExitTargetFailure,
+	},
+}
+
+func TestPickTargets(t *testing.T) {
+	test.Map(t, pickTargetsTestCases).
+		Run(func(t test.Test, param PickTargetsParams) {
+			// Given
+			gm, mocks := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+			})
+			mocks.GetArg("stdin").(*strings.Reader).Reset(param.input)
+			if param.stdin != nil {
+				gm.Stdin = param.stdin
+			}
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			assert.Equal(t, param.expectOutput,
+				mocks.GetArg("stdout").(*strings.Builder).String())
+			records, err := history.New(gm.HistoryFile).Read()
+			assert.NoError(t, err)
+			if assert.Len(t, records, 1) {
+				assert.Equal(t, param.expectArgs, records[0].Args)
+			}
+		})
+}
@@ -0,0 +1,370 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	"github.com/tkrop/go-make/internal/history"
+	"github.com/tkrop/go-make/internal/semver"
+)
+
+const (
+	// CmdVersionPreflight provides the name of the native version-preflight
+	// command.
+	CmdVersionPreflight = "version-preflight"
+	// EnvReleaseChecks provides the name of the makefile variable containing
+	// the targets that must have succeeded before a release.
+	EnvReleaseChecks = "RELEASE_CHECKS"
+	// DefaultReleaseChecks provides the default targets that must have
+	// succeeded before a release.
+	DefaultReleaseChecks = "test lint"
+	// FileGoMod provides the name of the go module file.
+	FileGoMod = "go.mod"
+)
+
+// regexMajor matches the major version suffix of a module path.
+var regexMajor = regexp.MustCompile(`/v([0-9]+)$`)
+
+// ErrPreflight represents a failed release preflight.
+var ErrPreflight = errors.New("preflight failed")
+
+// NewErrPreflight creates a release preflight failure reporting the given
+// number of failed checks.
+func NewErrPreflight(failed int) error {
+	return fmt.Errorf("%w [failed=%d]", ErrPreflight, failed)
+}
+
+// CmdGitStatus creates the argument array of a `git status` command to list
+// the commit hash of `HEAD` and the uncommitted changes of the working tree.
+func CmdGitStatus(dir string, env ...string) *cmd.Cmd {
+	return cmd.New("git", "status", "--porcelain=v2", "--branch").
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// CmdGitCommit creates the argument array of a `git rev-parse HEAD` command
+// to get the commit hash of `HEAD`.
+func CmdGitCommit(dir string, env ...string) *cmd.Cmd {
+	return cmd.New("git", "rev-parse", "HEAD").
+		WithEnv(env...).WithWorkDir(dir)
+}
+
+// gitState represents the state of the working tree.
+type gitState struct {
+	// commit provides the commit hash of `HEAD`, if any.
+	commit string
+	// changes provides the number of uncommitted changes.
+	changes int
+}
+
+// parseGitState parses the state of the working tree from the given output
+// of the `git status` command.
+func parseGitState(output string) *gitState {
+	state := &gitState{}
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			state.commit = strings.TrimPrefix(line, "# branch.oid ")
+			if state.commit == "(initial)" {
+				state.commit = ""
+			}
+		case line != "" && !strings.HasPrefix(line, "#"):
+			state.changes++
+		}
+	}
+	return state
+}
+
+// gitState returns the state of the working tree writing the errors of the
+// `git status` command to the given writer.
+func (gm *GoMake) gitState(
+	ctx context.Context, stderr io.Writer,
+) (*gitState, error) {
+	output := &strings.Builder{}
+	if err := gm.exec(ctx, CmdGitStatus(gm.WorkDir, gm.Env...).
+		WithIO(nil, output, stderr)); err != nil {
+		return nil, err
+	}
+	return parseGitState(strings.TrimSpace(output.String())), nil
+}
+
+// preflightCheck represents the result of a release preflight check.
+type preflightCheck struct {
+	// name provides the name of the check.
+	name string
+	// ok indicates whether the check succeeded.
+	ok bool
+	// detail provides the details of the check result.
+	detail string
+}
+
+// String returns the checklist entry of the release preflight check.
+func (c *preflightCheck) String() string {
+	if c.ok {
+		return fmt.Sprintf("- [x] %s [%s]", c.name, c.detail)
+	}
+	return fmt.Sprintf("- [ ] %s [%s]", c.name, c.detail)
+}
+
+// parsePreflight parses the arguments of the version-preflight command
+// returning the requested version, if any.
+func parsePreflight(args ...string) (*semver.Version, error) {
+	var version *semver.Version
+	for _, arg := range args {
+		if version != nil || strings.HasPrefix(arg, "-") {
+			return nil, NewErrInvalidArg(CmdVersionPreflight, arg, nil)
+		}
+		parsed, err := semver.Parse(strings.TrimPrefix(arg, "v"))
+		if err != nil {
+			return nil, NewErrInvalidArg(CmdVersionPreflight, arg, err)
+		}
+		version = parsed
+	}
+	return version, nil
+}
+
+// versionPreflight runs the native version-preflight command with given
+// arguments. It checks whether the project is ready for releasing the given
+// or the current version, i.e. the working tree is clean, `HEAD` is on the
+// default branch, the `VERSION` file matches the version, `go.mod` has no
+// `replace` directives, the module path follows the major version rules,
+// and the targets of `RELEASE_CHECKS` have succeeded since the last commit
+// according to the run history. The checks are reported as checklist.
+func (gm *GoMake) versionPreflight(args ...string) (int, error) {
+	requested, err := parsePreflight(args...)
+	if err != nil {
+		gm.error("parse version-preflight", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	value, err := gm.variable(ctx, EnvReleaseChecks, DefaultReleaseChecks)
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+
+	state, err := gm.gitState(ctx, gm.Stderr)
+	check, version := gm.preflightVersion(requested)
+	checks := []*preflightCheck{
+		preflightTree(state, err), gm.preflightBranch(ctx), check,
+	}
+	checks = append(checks, gm.preflightModule(version)...)
+	checks = append(checks, gm.preflightResults(state, err,
+		strings.Fields(value))...)
+
+	failed := 0
+	for _, check := range checks {
+		gm.Logger.Message(gm.Stdout, check.String())
+		if !check.ok {
+			failed++
+		}
+	}
+	if failed != 0 {
+		err := NewErrPreflight(failed)
+		gm.error(CmdVersionPreflight, err)
+		return ExitCommandFailure, err
+	}
+	return ExitSuccess, nil
+}
+
+// preflightTree checks whether the given working tree state, or its error,
+// has no uncommitted changes.
+func preflightTree(state *gitState, err error) *preflightCheck {
+	check := &preflightCheck{name: "working tree clean"}
+	if err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	check.ok = state.changes == 0
+	check.detail = fmt.Sprintf("changes=%d", state.changes)
+	return check
+}
+
+// preflightBranch checks whether the current branch is the default branch
+// of the remote repository.
+func (gm *GoMake) preflightBranch(ctx context.Context) *preflightCheck {
+	check := &preflightCheck{name: "default branch"}
+	branch, err := gm.gitOutput(ctx, CmdGitBranch(gm.WorkDir, gm.Env...))
+	if err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	main, err := gm.gitMainBranch(ctx)
+	if err != nil {
+		check.detail = err.Error()
+		return check
+	}
+	check.ok = branch == main
+	check.detail = "branch=" + branch + ", default=" + main
+	return check
+}
+
+// preflightVersion checks whether the project `VERSION` file provides a
+// valid version matching the given requested version, if any. It returns
+// the check and the version to release, if known.
+func (gm *GoMake) preflightVersion(
+	requested *semver.Version,
+) (*preflightCheck, *semver.Version) {
+	check := &preflightCheck{name: "version matches"}
+	// #nosec G304 -- file is the version file of the project.
+	data, err := os.ReadFile(filepath.Join(gm.WorkDir, FileVersion))
+	if err != nil {
+		check.detail = err.Error()
+		return check, requested
+	}
+	version, err := semver.Parse(strings.TrimSpace(string(data)))
+	if err != nil {
+		check.detail = err.Error()
+		return check, requested
+	}
+
+	check.detail = "version=" + version.String()
+	if requested == nil {
+		check.ok = true
+		return check, version
+	}
+	check.ok = requested.String() == version.String()
+	check.detail += ", requested=" + requested.String()
+	return check, requested
+}
+
+// preflightModule checks whether the `go.mod` file has no `replace`
+// directives and whether the module path follows the major version rules for
+// the given release version, i.e. a major version of two or higher requires
+// a matching `/vN` module path suffix.
+func (gm *GoMake) preflightModule(
+	version *semver.Version,
+) []*preflightCheck {
+	replace := &preflightCheck{name: "no replace directives"}
+	major := &preflightCheck{name: "module major version"}
+	module, replaces, err := readGoMod(filepath.Join(gm.WorkDir, FileGoMod))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		replace.ok, replace.detail = true, "module=none"
+		major.ok, major.detail = true, "module=none"
+		return []*preflightCheck{replace, major}
+	case err != nil:
+		replace.detail, major.detail = err.Error(), err.Error()
+		return []*preflightCheck{replace, major}
+	}
+
+	replace.ok = len(replaces) == 0
+	replace.detail = "replaces=" + strconv.Itoa(len(replaces))
+	if len(replaces) != 0 {
+		replace.detail += ", modules=" + strings.Join(replaces, " ")
+	}
+
+	major.detail = "module=" + module
+	if version == nil {
+		major.detail += ", tag=unknown"
+		return []*preflightCheck{replace, major}
+	}
+	major.detail += ", tag=v" + version.String()
+	suffix := uint64(0)
+	if match := regexMajor.FindStringSubmatch(module); match != nil &&
+		!strings.HasPrefix(module, "gopkg.in/") {
+		suffix, _ = strconv.ParseUint(match[1], 10, 64)
+	}
+	major.ok = suffix == version.Major || (suffix == 0 && version.Major < 2)
+	return []*preflightCheck{replace, major}
+}
+
+// preflightResults checks whether the given targets have succeeded in the
+// working directory on the commit of the given working tree state, or its
+// error, as recorded by their last run in the run history. Only runs of exactly one of the given targets are considered,
+// e.g. `test-all` must be listed explicitly to be accepted.
+func (gm *GoMake) preflightResults(
+	state *gitState, err error, targets []string,
+) []*preflightCheck {
+	checks := make([]*preflightCheck, 0, len(targets))
+	records := []*history.Record{}
+	if err == nil {
+		records, err = history.New(gm.fileHistory()).Read()
+	}
+
+	for _, target := range targets {
+		check := &preflightCheck{name: target + " succeeded"}
+		checks = append(checks, check)
+		if err != nil {
+			check.detail = err.Error()
+			continue
+		}
+
+		index := len(records) - 1
+		for ; index >= 0; index-- {
+			if gm.preflightMatch(records[index], target, state.commit) {
+				break
+			}
+		}
+		switch {
+		case index < 0:
+			check.detail = "run=missing"
+		case records[index].Failed():
+			check.detail = "run=failed, args=" +
+				strings.Join(records[index].Args[1:], " ")
+		default:
+			check.ok = true
+			check.detail = "run=succeeded, args=" +
+				strings.Join(records[index].Args[1:], " ")
+		}
+	}
+	return checks
+}
+
+// preflightMatch returns whether the given record is a run of exactly the
+// given target in the working directory on the given commit. Runs of multiple targets are not matched, since their
+// outcome is not recorded per target.
+func (gm *GoMake) preflightMatch(
+	record *history.Record, target, commit string,
+) bool {
+	if record.Dir != gm.WorkDir || commit == "" || record.Commit != commit {
+		return false
+	}
+	return slices.Equal(record.Targets, []string{target})
+}
+
+// readGoMod reads the module path and the modules of the `replace`
+// directives from the given go module file.
+func readGoMod(file string) (string, []string, error) {
+	// #nosec G304 -- file is the go module file of the project.
+	reader, err := os.Open(file)
+	if err != nil {
+		return "", nil, err //nolint:wrapcheck // wrapped by caller.
+	}
+	defer reader.Close()
+
+	module, replaces, block := "", []string{}, false
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case block && fields[0] == ")":
+			block = false
+		case block:
+			replaces = append(replaces, fields[0])
+		case fields[0] == "module" && len(fields) > 1:
+			module = strings.Trim(fields[1], `"`)
+		case fields[0] == "replace" && len(fields) > 1 && fields[1] == "(":
+			block = true
+		case fields[0] == "replace" && len(fields) > 1:
+			replaces = append(replaces, fields[1])
+		}
+	}
+	//nolint:wrapcheck // wrapped by caller.
+	return module, replaces, scanner.Err()
+}
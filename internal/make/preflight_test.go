@@ -0,0 +1,401 @@
+package make_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/history"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/semver"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// envPreflight contains the environment providing the release checks.
+	envPreflight = []string{EnvReleaseChecks + "=" + DefaultReleaseChecks}
+	// envPreflightAll contains the environment providing explicit release
+	// check targets.
+	envPreflightAll = []string{EnvReleaseChecks + "=test lint-all"}
+
+	// commitPreflight contains the commit hash of `HEAD` for preflight
+	// testing.
+	commitPreflight = "5114f85d2c3b1e1f4a6b0c9d8e7f6a5b4c3d2e1f"
+	// commitOld contains the commit hash of a previous commit.
+	commitOld = "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567"
+
+	// goModPreflight contains a go module file without replace directives.
+	goModPreflight = "module github.com/org/repo\n\ngo 1.26\n"
+	// goModReplace contains a go module file with replace directives.
+	goModReplace = "module github.com/org/repo // comment\n\ngo 1.26\n\n" +
+		"replace github.com/org/lib => ../lib\n\n" +
+		"replace (\n\t// comment\n\tgithub.com/org/a => ../a\n" +
+		"\tgithub.com/org/b v1.0.0 => ../b\n)\n"
+)
+
+// DirPreflight returns the project directory of the preflight test case with
+// given name.
+func DirPreflight(name string) string {
+	return filepath.Join(dirTargets, "preflight", name)
+}
+
+// RecordPreflight creates a run history record of the preflight test case
+// with given name for the given space separated targets run on the given
+// commit using the given exit code.
+func RecordPreflight(name, targets, commit string, exit int) *history.Record {
+	return &history.Record{
+		Args:    append([]string{"go-make"}, strings.Fields(targets)...),
+		Targets: strings.Fields(targets), Dir: DirPreflight(name),
+		Start: time.Now(), Commit: commit, Exit: exit,
+	}
+}
+
+// StatusPreflight returns the output of the git status command providing the
+// `HEAD` commit of the preflight tests, the given branch, and the given
+// uncommitted changes.
+func StatusPreflight(branch, changes string) string {
+	return "# branch.oid " + commitPreflight + "\n" +
+		"# branch.head " + branch + "\n" + changes
+}
+
+// PreflightSetup sets up the git commands of the preflight test case with
+// given name using the given environment, providing the given uncommitted
+// changes and current branch on the default branch `main`.
+func PreflightSetup(
+	name, changes, branch string, env ...string,
+) func(*mock.Mocks) any {
+	dir := DirPreflight(name)
+	return mock.Chain(
+		Exec(CmdGitStatus(dir, env...), "nil", "builder", "stderr",
+			StatusPreflight(branch, changes), "", nil),
+		Exec(CmdGitBranch(dir, env...),
+			"nil", "builder", "stderr", branch+"\n", "", nil),
+		Exec(CmdGitRemote(dir, env...), "nil", "builder", "stderr",
+			"* remote origin\n  HEAD branch: main\n", "", nil),
+	)
+}
+
+// LogChecks logs the given checklist entries as messages.
+func LogChecks(entries ...string) func(*mock.Mocks) any {
+	setups := []func(*mock.Mocks) any{}
+	for _, entry := range entries {
+		setups = append(setups, LogMessage("stdout", entry))
+	}
+	return mock.Chain(setups...)
+}
+
+type VersionPreflightParams struct {
+	mockSetup   mock.SetupFunc
+	dir         string
+	env         []string
+	args        []string
+	files       map[string]string
+	records     []*history.Record
+	expectError error
+	expectExit  int
+}
+
+var versionPreflightTestCases = map[string]VersionPreflightParams{
+	"preflight succeeded": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envPreflight...), "nil", "builder",
+				"stderr", DirPreflight("succeeded"), "", nil),
+			PreflightSetup("succeeded", "", "main", envPreflight...),
+			LogChecks(
+				"- [x] working tree clean [changes=0]",
+				"- [x] default branch [branch=main, default=main]",
+				"- [x] version matches [version=1.2.3, requested=1.2.3]",
+				"- [x] no replace directives [replaces=0]",
+				"- [x] module major version "+
+					"[module=github.com/org/repo, tag=v1.2.3]",
+				"- [x] test succeeded [run=succeeded, args=test]",
+				"- [x] lint succeeded [run=succeeded, args=lint]",
+			),
+		),
+		dir:  "succeeded",
+		env:  envPreflight,
+		args: []string{"go-make", "--version-preflight", "v1.2.3"},
+		files: map[string]string{
+			"VERSION": "1.2.3\n", "go.mod": goModPreflight,
+		},
+		records: []*history.Record{
+			RecordPreflight("succeeded", "test", commitPreflight, 0),
+			RecordPreflight("succeeded", "test-clean", commitPreflight, 2),
+			RecordPreflight("succeeded", "lint", commitPreflight, 0),
+			RecordPreflight("succeeded", "test lint", commitPreflight, 2),
+			RecordPreflight("succeeded", "lint", commitOld, 2),
+			RecordPreflight("other", "lint", commitPreflight, 2),
+		},
+	},
+	"preflight module major version": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envPreflightAll...), "nil", "builder",
+				"stderr", DirPreflight("major"), "", nil),
+			PreflightSetup("major", "", "main", envPreflightAll...),
+			LogChecks(
+				"- [x] working tree clean [changes=0]",
+				"- [x] default branch [branch=main, default=main]",
+				"- [x] version matches [version=2.1.0]",
+				"- [x] no replace directives [replaces=0]",
+				"- [x] module major version "+
+					"[module=github.com/org/repo/v2, tag=v2.1.0]",
+				"- [x] test succeeded [run=succeeded, args=test]",
+				"- [x] lint-all succeeded [run=succeeded, args=lint-all]",
+			),
+		),
+		dir:  "major",
+		env:  envPreflightAll,
+		args: []string{"go-make", "--version-preflight"},
+		files: map[string]string{
+			"VERSION": "2.1.0\n",
+			"go.mod":  "module github.com/org/repo/v2\n",
+		},
+		records: []*history.Record{
+			RecordPreflight("major", "test", commitPreflight, 0),
+			RecordPreflight("major", "lint-all", commitPreflight, 0),
+		},
+	},
+	"preflight without module": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envPreflight...), "nil", "builder",
+				"stderr", DirPreflight("module"), "", nil),
+			PreflightSetup("module", "", "main", envPreflight...),
+			LogChecks(
+				"- [x] working tree clean [changes=0]",
+				"- [x] default branch [branch=main, default=main]",
+				"- [x] version matches [version=3.0.0]",
+				"- [x] no replace directives [module=none]",
+				"- [x] module major version [module=none]",
+				"- [x] test succeeded [run=succeeded, args=test]",
+				"- [x] lint succeeded [run=succeeded, args=lint]",
+			),
+		),
+		dir:   "module",
+		env:   envPreflight,
+		args:  []string{"go-make", "--version-preflight"},
+		files: map[string]string{"VERSION": "3.0.0\n"},
+		records: []*history.Record{
+			RecordPreflight("module", "test", commitPreflight, 0),
+			RecordPreflight("module", "lint", commitPreflight, 0),
+		},
+	},
+	"preflight checks from config": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirPreflight("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirPreflight("config")),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, DirPreflight("config")),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvReleaseChecks+" := test-unit\n", "", nil),
+			PreflightSetup("config", "", "main"),
+			LogChecks(
+				"- [x] working tree clean [changes=0]",
+				"- [x] default branch [branch=main, default=main]",
+				"- [x] version matches [version=1.2.3]",
+				"- [x] no replace directives [replaces=0]",
+				"- [x] module major version "+
+					"[module=github.com/org/repo, tag=v1.2.3]",
+				"- [x] test-unit succeeded "+
+					"[run=succeeded, args=test-unit]",
+			),
+		),
+		dir:  "config",
+		args: []string{"go-make", "--version-preflight"},
+		files: map[string]string{
+			"VERSION": "1.2.3\n", "go.mod": goModPreflight,
+		},
+		records: []*history.Record{
+			RecordPreflight("config", "test-unit", commitPreflight, 0),
+		},
+	},
+
+	"preflight failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envPreflight...), "nil", "builder",
+				"stderr", DirPreflight("failed"), "", nil),
+			PreflightSetup("failed", " M VERSION\n?? new.go\n",
+				"feature", envPreflight...),
+			LogChecks(
+				"- [ ] working tree clean [changes=2]",
+				"- [ ] default branch [branch=feature, default=main]",
+				"- [ ] version matches [version=1.2.3, requested=2.0.0]",
+				"- [ ] no replace directives [replaces=3, modules="+
+					"github.com/org/lib github.com/org/a github.com/org/b]",
+				"- [ ] module major version "+
+					"[module=github.com/org/repo, tag=v2.0.0]",
+				"- [ ] test succeeded [run=failed, args=test]",
+				"- [ ] lint succeeded [run=missing]",
+			),
+			LogError("stderr", CmdVersionPreflight, NewErrPreflight(7)),
+		),
+		dir:  "failed",
+		env:  envPreflight,
+		args: []string{"go-make", "--version-preflight", "2.0.0"},
+		files: map[string]string{
+			"VERSION": "1.2.3\n", "go.mod": goModReplace,
+		},
+		records: []*history.Record{
+			RecordPreflight("failed", "test", commitPreflight, 0),
+			RecordPreflight("failed", "test", commitPreflight, 2),
+			RecordPreflight("failed", "test-all", commitPreflight, 0),
+			RecordPreflight("failed", "lint", commitOld, 0),
+			RecordPreflight("failed", "test lint", commitPreflight, 0),
+		},
+		expectError: NewErrPreflight(7),
+		expectExit:  ExitCommandFailure,
+	},
+	"preflight git failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envPreflight...), "nil", "builder",
+				"stderr", DirPreflight("git"), "", nil),
+			Exec(CmdGitStatus(DirPreflight("git"), envPreflight...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			Exec(CmdGitBranch(DirPreflight("git"), envPreflight...),
+				"nil", "builder", "stderr", "main\n", "", nil),
+			Exec(CmdGitRemote(DirPreflight("git"), envPreflight...),
+				"nil", "builder", "stderr", "* remote origin\n", "", nil),
+			LogChecks(
+				"- [ ] working tree clean ["+NewErrCallFailed(
+					CmdGitStatus(DirPreflight("git")),
+					assert.AnError).Error()+"]",
+				"- [ ] default branch ["+NewErrCallFailed(
+					CmdGitRemote(DirPreflight("git")),
+					io.ErrUnexpectedEOF).Error()+"]",
+				"- [ ] version matches [open "+filepath.Join(
+					DirPreflight("git"), "VERSION")+
+					": no such file or directory]",
+				"- [x] no replace directives [module=none]",
+				"- [x] module major version [module=none]",
+				"- [ ] test succeeded ["+NewErrCallFailed(
+					CmdGitStatus(DirPreflight("git")),
+					assert.AnError).Error()+"]",
+				"- [ ] lint succeeded ["+NewErrCallFailed(
+					CmdGitStatus(DirPreflight("git")),
+					assert.AnError).Error()+"]",
+			),
+			LogError("stderr", CmdVersionPreflight, NewErrPreflight(5)),
+		),
+		dir:         "git",
+		env:         envPreflight,
+		args:        []string{"go-make", "--version-preflight"},
+		expectError: NewErrPreflight(5),
+		expectExit:  ExitCommandFailure,
+	},
+	"preflight branch failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envPreflight...), "nil", "builder",
+				"stderr", DirPreflight("branch"), "", nil),
+			Exec(CmdGitStatus(DirPreflight("branch"), envPreflight...),
+				"nil", "builder", "stderr", "# branch.oid (initial)\n",
+				"", nil),
+			Exec(CmdGitBranch(DirPreflight("branch"), envPreflight...),
+				"nil", "builder", "stderr", "", "", assert.AnError),
+			LogChecks(
+				"- [x] working tree clean [changes=0]",
+				"- [ ] default branch ["+NewErrCallFailed(
+					CmdGitBranch(DirPreflight("branch")),
+					assert.AnError).Error()+"]",
+				"- [ ] version matches ["+semver.NewErrInvalid("1.2").Error()+"]",
+				"- [ ] no replace directives [read "+DirPreflight("branch")+
+					"/go.mod: is a directory]",
+				"- [ ] module major version [read "+DirPreflight("branch")+
+					"/go.mod: is a directory]",
+				"- [ ] test succeeded [run=missing]",
+				"- [ ] lint succeeded [run=missing]",
+			),
+			LogError("stderr", CmdVersionPreflight, NewErrPreflight(6)),
+		),
+		dir:  "branch",
+		env:  envPreflight,
+		args: []string{"go-make", "--version-preflight", "1.2.3"},
+		files: map[string]string{
+			"VERSION": "1.2\n", "go.mod/file": "",
+		},
+		records: []*history.Record{
+			RecordPreflight("branch", "test", "", 0),
+		},
+		expectError: NewErrPreflight(6),
+		expectExit:  ExitCommandFailure,
+	},
+	"preflight config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirPreflight("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirPreflight("config")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirPreflight("config")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirPreflight("config")), assert.AnError))),
+		),
+		dir:  "config",
+		args: []string{"go-make", "--version-preflight"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirPreflight("config")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+
+	"preflight invalid version arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-preflight", NewErrInvalidArg(
+				CmdVersionPreflight, "1.2", semver.NewErrInvalid("1.2"))),
+		),
+		args: []string{"go-make", "--version-preflight", "1.2"},
+		expectError: NewErrInvalidArg(CmdVersionPreflight, "1.2",
+			semver.NewErrInvalid("1.2")),
+		expectExit: ExitCommandFailure,
+	},
+	"preflight invalid extra arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-preflight",
+				NewErrInvalidArg(CmdVersionPreflight, "1.2.4", nil)),
+		),
+		args: []string{"go-make", "--version-preflight", "1.2.3", "1.2.4"},
+		expectError: NewErrInvalidArg(CmdVersionPreflight,
+			"1.2.4", nil),
+		expectExit: ExitCommandFailure,
+	},
+}
+
+func TestVersionPreflight(t *testing.T) {
+	test.Map(t, versionPreflightTestCases).
+		Run(func(t test.Test, param VersionPreflightParams) {
+			// Given
+			dir := DirPreflight(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				WriteFile(path, 0o600, content)
+			}
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+			store := history.New(gm.HistoryFile)
+			for _, record := range param.records {
+				assert.NoError(t, store.Append(record))
+			}
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+		})
+}
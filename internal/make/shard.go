@@ -0,0 +1,113 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/junit"
+	"github.com/tkrop/go-make/internal/shard"
+	"github.com/tkrop/go-make/internal/testargs"
+)
+
+const (
+	// EnvShard provides the name of the makefile variable containing the
+	// shard of the packages to test, e.g. `2/4`.
+	EnvShard = "SHARD"
+	// EnvTestDurations provides the name of the makefile variable containing
+	// the file of the historical test durations.
+	EnvTestDurations = "TEST_DURATIONS"
+	// DefaultTestDurations provides the default file of the historical test
+	// durations.
+	DefaultTestDurations = "build/test-durations.json"
+)
+
+// durationsFile returns the path of the historical test durations file
+// relative to the working directory.
+func (gm *GoMake) durationsFile(file string) string {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(gm.WorkDir, file)
+	}
+	return file
+}
+
+// importPath returns the import path of the given local package using the
+// given module path, or the local package path, if the module is unknown.
+func importPath(module, pkg string) string {
+	switch {
+	case filepath.IsAbs(pkg) || strings.HasPrefix(pkg, "../"):
+		return pkg
+	case pkg == ".":
+		return module
+	case module == "":
+		return strings.TrimPrefix(pkg, "./")
+	}
+	return path.Join(module, strings.TrimPrefix(pkg, "./"))
+}
+
+// testShard restricts the default packages of the given resolved test
+// arguments to the packages of the given shard. The packages are partitioned
+// by their historical test durations, or by package count, if no durations
+// are known. An empty shard runs no tests.
+func (gm *GoMake) testShard(
+	ctx context.Context, value string, resolved *testargs.Args,
+) (int, error) {
+	selected, err := shard.Parse(value)
+	if err != nil {
+		gm.error("parse shard", err)
+		return ExitCommandFailure, err
+	}
+
+	file, err := gm.variable(ctx, EnvTestDurations, DefaultTestDurations)
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+	durations, err := shard.ReadDurations(gm.durationsFile(file))
+	if err != nil {
+		gm.error("read durations", err)
+		return ExitCommandFailure, err
+	}
+	module, _, err := readGoMod(filepath.Join(gm.WorkDir, FileGoMod))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		gm.error("read go.mod", err)
+		return ExitCommandFailure, err
+	}
+
+	weights := map[string]float64{}
+	for _, pkg := range resolved.Packages {
+		if duration, ok := durations[importPath(module, pkg)]; ok {
+			weights[pkg] = duration
+		}
+	}
+
+	packages := selected.Select(resolved.Packages, weights)
+	if len(packages) == 0 {
+		gm.Logger.Warning(gm.Stderr, fmt.Sprintf("empty shard "+
+			"[shard=%s, packages=%d]", selected, len(resolved.Packages)))
+		resolved.Regex = []string{"^$"}
+		packages = []string{testargs.PackagesAll}
+	}
+	resolved.Packages = packages
+	return ExitSuccess, nil
+}
+
+// recordDurations records the test durations of the given report in the
+// historical test durations file. Failures are only reported, since the
+// durations are not essential for the test run.
+func (gm *GoMake) recordDurations(file string, report *junit.Report) {
+	file = gm.durationsFile(file)
+	durations, err := shard.ReadDurations(file)
+	if err != nil {
+		gm.error("read durations", err)
+		return
+	}
+	durations.Update(report)
+	if err := durations.Write(file); err != nil {
+		gm.error("write durations", err)
+	}
+}
@@ -0,0 +1,588 @@
+package make
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tkrop/go-make/internal/catalog"
+	"github.com/tkrop/go-make/internal/makedb"
+	"github.com/tkrop/go-make/internal/suggest"
+)
+
+const (
+	// EnvGoMakeOptions provides the name of the makefile variable containing
+	// the additional go-make options offered for completion.
+	EnvGoMakeOptions = "GOMAKE_OPTIONS"
+	// MakefileProject provides the name of the project makefile used for the
+	// make targets file.
+	MakefileProject = "Makefile"
+	// FormatJSON provides the JSON output format of the native show commands.
+	FormatJSON = "json"
+	// EnvGoMakeAutoCorrect provides the name of the environment variable to
+	// enable the auto-correction of unknown targets with a single suggestion.
+	EnvGoMakeAutoCorrect = "GOMAKE_AUTOCORRECT"
+	// EnvCmdWords provides the name of the makefile variable containing the
+	// target prefixes of commands that consume the following arguments.
+	EnvCmdWords = "CMDWORDS"
+	// CmdWords provides the default target prefixes of commands that consume
+	// the following arguments as defined in the `Makefile.base`.
+	CmdWords = "call show git- test- lint run- version- update"
+)
+
+// makeValueOptions provides the make options expecting a separate value.
+var makeValueOptions = []string{
+	"-C", "-f", "-I", "-j", "-l", "-o", "-W", "--directory", "--file",
+	"--makefile", "--include-dir", "--jobs", "--load-average", "--old-file",
+	"--assume-old", "--what-if", "--new-file", "--assume-new",
+}
+
+// ErrTargets represent a targets file failure.
+var ErrTargets = errors.New("targets failed")
+
+// NewErrTargets wraps the error of a failed targets file operation for the
+// targets file with given suffix.
+func NewErrTargets(suffix string, err error) error {
+	return fmt.Errorf("%w [suffix=%s]: %w", ErrTargets, suffix, err)
+}
+
+// ErrUnknownTarget represent an unknown target failure.
+var ErrUnknownTarget = errors.New("unknown target")
+
+// NewErrUnknownTarget creates an unknown target error for the given target
+// offering the given suggestions.
+func NewErrUnknownTarget(target string, suggestions []string) error {
+	return fmt.Errorf("%w [target=%s, did-you-mean=%s]",
+		ErrUnknownTarget, target, suggestions)
+}
+
+// showTargets shows the targets of the targets file with given suffix. If the
+// targets file exists and its cache key still matches the config and the
+// makefiles, it is shown immediately without setting up the config. Else the
+// targets file is regenerated synchronously from the make data base before it
+// is shown. The targets file is resolved after setting up the working
+// directory to cache the targets per repository root.
+func (gm *GoMake) showTargets(suffix string) (int, error) {
+	if gm.Format == FormatJSON {
+		return gm.showCatalog(suffix, false)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer gm.Tracer.Start("show-targets", "suffix", suffix).End()
+
+	gm.setupWorkDir(ctx)
+	file := gm.fileTargets(suffix)
+	if gm.readTargetsKey(file) != nil {
+		// #nosec G304 -- file is safe to dump.
+		if content, err := os.ReadFile(file); err == nil {
+			gm.Logger.Message(gm.Stdout, string(content))
+			return ExitSuccess, nil
+		}
+	}
+
+	if err := gm.setupConfig(ctx); err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+
+	content, exit, err := gm.updateTargets(ctx, file, suffix)
+	if err != nil {
+		return exit, err
+	}
+	gm.Logger.Message(gm.Stdout, string(content))
+	return ExitSuccess, nil
+}
+
+// updateTargets builds the targets for the targets file with given suffix from
+// the make data base and writes them to the given targets file together with
+// the cache key. It returns the content of the targets file, or the exit code
+// and error, if building the targets fails. A failure to write the targets
+// file is only logged.
+func (gm *GoMake) updateTargets(
+	ctx context.Context, file, suffix string,
+) ([]byte, int, error) {
+	db, err := gm.database(ctx, suffix)
+	if err != nil {
+		gm.error("build targets", err)
+		return nil, ExitTargetFailure, err
+	}
+	targets, err := gm.buildTargets(ctx, db, suffix)
+	if err != nil {
+		gm.error("build targets", err)
+		return nil, ExitTargetFailure, err
+	}
+
+	content := []byte(strings.Join(targets, "\n") + "\n")
+	if err := gm.writeTargets(file, content, db); err != nil {
+		gm.error("write targets", NewErrTargets(suffix, err))
+	}
+	return content, ExitSuccess, nil
+}
+
+// showCatalog shows the target catalog for the targets file with given suffix
+// as JSON. For the help it shows the annotated targets and target families of
+// the makefiles, else all targets with the metadata of the matching annotated
+// target or target family.
+func (gm *GoMake) showCatalog(suffix string, help bool) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer gm.Tracer.Start("show-catalog", "suffix", suffix).End()
+
+	gm.setupWorkDir(ctx)
+	if err := gm.setupConfig(ctx); err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+
+	db, err := gm.database(ctx, suffix)
+	if err != nil {
+		gm.error("build catalog", err)
+		return ExitTargetFailure, err
+	}
+	targets, err := gm.catalog(gm.makefiles(db), suffix)
+	if err != nil {
+		gm.error("build catalog", err)
+		return ExitTargetFailure, err
+	}
+	if !help {
+		targets = targets.Resolve(db.Targets()...)
+	}
+
+	content := &strings.Builder{}
+	encoder := json.NewEncoder(content)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(targets)
+	gm.Logger.Message(gm.Stdout, content.String())
+	return ExitSuccess, nil
+}
+
+// buildTargets builds the sorted list of targets and options for the targets
+// file with given suffix from the given make data base and the `make --help`
+// output.
+// The make targets file is built from the project makefile, while the other
+// targets files are built from the go-make config makefile extended by the
+// additional go-make options.
+func (gm *GoMake) buildTargets(
+	ctx context.Context, db *makedb.Database, suffix string,
+) ([]string, error) {
+	targets := db.Targets()
+	if suffix != *SuffixTargetsMake {
+		if variable := db.Variable(EnvGoMakeOptions); variable != nil {
+			targets = append(targets, strings.Fields(variable.Value)...)
+		}
+	}
+
+	help := &strings.Builder{}
+	if err := gm.exec(ctx, CmdMakeHelp(gm.WorkDir, gm.Env...).
+		WithIO(nil, help, io.Discard)); err != nil {
+		return nil, err
+	}
+	options, err := makedb.Options(strings.NewReader(help.String()))
+	if err != nil {
+		return nil, NewErrTargets(suffix, err)
+	}
+
+	targets = append(targets, options...)
+	slices.Sort(targets)
+	return slices.Compact(targets), nil
+}
+
+// database reads the make data base for the targets file with given suffix,
+// i.e. of the project makefile for the make targets file and of the go-make
+// config makefile for the other targets files.
+func (gm *GoMake) database(
+	ctx context.Context, suffix string,
+) (*makedb.Database, error) {
+	makefile := gm.Makefile
+	if suffix == *SuffixTargetsMake {
+		makefile = MakefileProject
+	}
+
+	// Make fails in question mode, if targets are not up-to-date, while the
+	// data base is still printed. Missing makefiles result in no targets.
+	buffer := &strings.Builder{}
+	_ = gm.exec(ctx, CmdMakeDatabase(makefile, gm.WorkDir, gm.Env...).
+		WithIO(nil, buffer, io.Discard))
+	db, err := makedb.Parse(strings.NewReader(buffer.String()))
+	if err != nil {
+		return nil, NewErrTargets(suffix, err)
+	}
+	return db, nil
+}
+
+// makefiles returns the makefiles read by make, as listed in the
+// `MAKEFILE_LIST` variable of the given make data base, resolving relative
+// paths against the working directory.
+func (gm *GoMake) makefiles(db *makedb.Database) []string {
+	variable := db.Variable("MAKEFILE_LIST")
+	if variable == nil {
+		return []string{}
+	}
+
+	files := strings.Fields(variable.Value)
+	for index, file := range files {
+		if !filepath.IsAbs(file) {
+			files[index] = filepath.Join(gm.WorkDir, file)
+		}
+	}
+	return files
+}
+
+// variable returns the value of the makefile variable with given name as
+// provided by the environment, or by the make data base of the go-make config,
+// or the given default value, if the variable is not defined or empty.
+func (gm *GoMake) variable(
+	ctx context.Context, name, deflt string,
+) (string, error) {
+	values, err := gm.variables(ctx, map[string]string{name: deflt})
+	if err != nil {
+		return "", err
+	}
+	return values[name], nil
+}
+
+// variables returns the values of the makefile variables with the names of
+// the given default values as provided by the environment, or by the make data
+// base of the go-make config, or the default value, if the variable is not
+// defined or empty. The make data base is only read once, if any variable is
+// not provided by the environment.
+func (gm *GoMake) variables(
+	ctx context.Context, deflts map[string]string,
+) (map[string]string, error) {
+	values := make(map[string]string, len(deflts))
+	missing := []string{}
+	for name, deflt := range deflts {
+		if value := gm.GetEnvDefault(name, ""); value != "" {
+			values[name] = value
+		} else {
+			values[name] = deflt
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	if err := gm.setupConfig(ctx); err != nil {
+		return nil, err
+	}
+	if db, err := gm.database(ctx, *SuffixTargets); err == nil {
+		for _, name := range missing {
+			if variable := db.Variable(name); variable != nil &&
+				strings.TrimSpace(variable.Value) != "" {
+				values[name] = variable.Value
+			}
+		}
+	}
+	return values, nil
+}
+
+// catalog parses the target annotations of the given makefiles.
+func (gm *GoMake) catalog(
+	files []string, suffix string,
+) (catalog.Catalog, error) {
+	content := &strings.Builder{}
+	for _, file := range files {
+		// #nosec G304 -- file is a makefile read by make.
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, NewErrTargets(suffix, err)
+		}
+		content.Write(data)
+		content.WriteString("\n")
+	}
+
+	targets, err := catalog.Parse(strings.NewReader(content.String()))
+	if err != nil {
+		return nil, NewErrTargets(suffix, err)
+	}
+	return targets, nil
+}
+
+// targetsKey represents the cache key of a targets file consisting of the
+// config version, the config directory, and the state of all makefiles read
+// by make to build the targets file. It also records the patterns of the
+// pattern rules, since they are not listed in the targets file.
+type targetsKey struct {
+	// Version provides the config version.
+	Version string `json:"version"`
+	// Dir provides the config directory.
+	Dir string `json:"dir"`
+	// Files provides the state of the makefiles.
+	Files []*fileKey `json:"files"`
+	// Patterns provides the patterns of the pattern rules.
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// fileKey represents the state of a makefile in the cache key.
+type fileKey struct {
+	// Path provides the absolute path of the makefile.
+	Path string `json:"path"`
+	// ModTime provides the modification time of the makefile.
+	ModTime time.Time `json:"mtime"`
+	// Size provides the size of the makefile.
+	Size int64 `json:"size"`
+	// Hash provides the hex encoded SHA-256 hash of the makefile.
+	Hash string `json:"hash"`
+}
+
+// newFileKey creates the state of the makefile with given path.
+func newFileKey(path string) (*fileKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by caller.
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileKey{
+		Path: path, ModTime: info.ModTime().UTC(),
+		Size: info.Size(), Hash: hash,
+	}, nil
+}
+
+// matches returns whether the makefile is unchanged. The makefile is
+// unchanged, if its size and modification time are unchanged, or if only the
+// modification time has changed while the content hash is still the same.
+func (key *fileKey) matches() bool {
+	info, err := os.Stat(key.Path)
+	if err != nil || info.Size() != key.Size {
+		return false
+	} else if info.ModTime().Equal(key.ModTime) {
+		return true
+	}
+	hash, err := hashFile(key.Path)
+	return err == nil && hash == key.Hash
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the file with given path.
+func hashFile(path string) (string, error) {
+	// #nosec G304 -- file is a makefile read by make.
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err //nolint:wrapcheck // wrapped by caller.
+	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// fileTargetsKey returns the path of the cache key file of the given targets
+// file.
+func fileTargetsKey(file string) string {
+	return file + ".key"
+}
+
+// configKey returns the config version and the config directory expected in
+// the cache key. If the config is not set up yet, they are derived cheaply
+// like in `setupConfig` without installing the config.
+func (gm *GoMake) configKey() (string, string) {
+	if gm.ConfigDir != "" {
+		return gm.ConfigVersion, gm.ConfigDir
+	} else if gm.Config == "" {
+		return gm.Info.Version, GoMakePath(gm.Info.Path, gm.Info.Version)
+	}
+
+	path := AbsPath(gm.Config)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "custom", path
+	}
+	return gm.Config, GoMakePath(gm.Info.Path, gm.Config)
+}
+
+// readTargetsKey reads the cache key of the given targets file, if the cache
+// key exists and matches the current config and makefiles. Else it returns
+// nil.
+func (gm *GoMake) readTargetsKey(file string) *targetsKey {
+	// #nosec G304 -- file is the targets key file in the cache directory.
+	data, err := os.ReadFile(fileTargetsKey(file))
+	if err != nil {
+		return nil
+	}
+
+	key := &targetsKey{}
+	version, dir := gm.configKey()
+	if err := json.Unmarshal(data, key); err != nil ||
+		key.Version != version || key.Dir != dir {
+		return nil
+	}
+	for _, file := range key.Files {
+		if !file.matches() {
+			return nil
+		}
+	}
+	return key
+}
+
+// writeTargets writes the given content to the given targets file together
+// with the cache key of the current config and the makefiles of the given make
+// data base creating the parent directories as needed. Both files are written
+// atomically, so that concurrent readers never see a partial file.
+func (gm *GoMake) writeTargets(
+	file string, content []byte, db *makedb.Database,
+) error {
+	key := &targetsKey{
+		Version: gm.ConfigVersion, Dir: gm.ConfigDir,
+		Files: []*fileKey{}, Patterns: db.Patterns(),
+	}
+	for _, path := range gm.makefiles(db) {
+		file, err := newFileKey(path)
+		if err != nil {
+			return err
+		}
+		key.Files = append(key.Files, file)
+	}
+	data, _ := json.Marshal(key)
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	if err := writeFileAtomic(file, content); err != nil {
+		return err
+	}
+	return writeFileAtomic(fileTargetsKey(file), data)
+}
+
+// writeFileAtomic writes the given content to a temporary file in the
+// directory of the given file and renames it to the given file afterwards.
+func writeFileAtomic(file string, content []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	defer func() { _ = os.Remove(temp.Name()) }()
+
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		return err //nolint:wrapcheck // wrapped by caller.
+	} else if err := temp.Close(); err != nil {
+		return err //nolint:wrapcheck // wrapped by caller.
+	}
+	//nolint:wrapcheck // wrapped by caller.
+	return os.Rename(temp.Name(), file)
+}
+
+// checkTargets checks the given make arguments against the targets of the
+// cached targets files before make is called. Options, variable assignments,
+// and the arguments following a command target, as identified by `CMDWORDS`,
+// are not checked. Existing files and targets matching a pattern rule are
+// considered known, since make may build them. An unknown target with close
+// matches fails with the suggestions, while a single suggestion is used
+// instead of the unknown target, if auto-correction is enabled via
+// `GOMAKE_AUTOCORRECT=true`. If there is no valid targets file or no close
+// match, the arguments are passed to make unchanged.
+func (gm *GoMake) checkTargets(args []string) ([]string, error) {
+	suggester := gm.suggester()
+	if suggester == nil {
+		return args, nil
+	}
+
+	words := strings.Fields(gm.GetEnvDefault(EnvCmdWords, CmdWords))
+	correct := gm.GetEnvDefault(EnvGoMakeAutoCorrect, "") == "true"
+	checked := slices.Clone(args)
+	for index, target := range checked {
+		if strings.HasPrefix(target, "-") || strings.Contains(target, "=") ||
+			(index > 0 && slices.Contains(makeValueOptions, checked[index-1])) {
+			continue
+		}
+
+		if !suggester.Known(target) && !gm.isFile(target) {
+			suggestions := suggester.Suggest(target)
+			switch {
+			case len(suggestions) == 0:
+			case correct && len(suggestions) == 1:
+				gm.Logger.Warning(gm.Stderr, fmt.Sprintf(
+					"auto-correcting target [%s] to [%s]",
+					target, suggestions[0]))
+				target, checked[index] = suggestions[0], suggestions[0]
+			default:
+				return nil, NewErrUnknownTarget(target, suggestions)
+			}
+		}
+
+		if slices.ContainsFunc(words, func(word string) bool {
+			return strings.HasPrefix(target, word)
+		}) {
+			break
+		}
+	}
+	return checked, nil
+}
+
+// isFile returns whether the given target is an existing file or directory
+// relative to the working directory.
+func (gm *GoMake) isFile(target string) bool {
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(gm.WorkDir, target)
+	}
+	_, err := os.Stat(target)
+	return err == nil
+}
+
+// suggester creates the suggester for the targets of the cached targets file
+// and the cached make targets file of the project makefile using the target
+// families annotated in the makefiles and the pattern rules recorded in their
+// cache keys. If the targets file is missing or outdated, it returns nil,
+// while an outdated make targets file is ignored.
+func (gm *GoMake) suggester() *suggest.Suggester {
+	names, key := gm.readNames(*SuffixTargets)
+	if key == nil {
+		return nil
+	}
+	rules := key.Patterns
+	if more, key := gm.readNames(*SuffixTargetsMake); key != nil {
+		names, rules = append(names, more...), append(rules, key.Patterns...)
+	}
+
+	files := make([]string, 0, len(key.Files))
+	for _, file := range key.Files {
+		files = append(files, file.Path)
+	}
+	targets, err := gm.catalog(files, *SuffixTargets)
+	if err != nil {
+		return nil
+	}
+
+	patterns := []string{}
+	for _, target := range targets {
+		if target.Pattern != "" {
+			patterns = append(patterns, target.Pattern)
+		}
+	}
+	return suggest.New(names, patterns).WithRules(rules...)
+}
+
+// readNames reads the target names of the targets file with given suffix
+// together with its cache key, if the targets file exists and its cache key
+// matches the current config and makefiles. Else it returns a nil key.
+func (gm *GoMake) readNames(suffix string) ([]string, *targetsKey) {
+	file := gm.fileTargets(suffix)
+	key := gm.readTargetsKey(file)
+	if key == nil {
+		return nil, nil
+	}
+	// #nosec G304 -- file is the targets file in the cache directory.
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil
+	}
+
+	names := []string{}
+	for _, name := range strings.Fields(string(content)) {
+		if !strings.HasPrefix(name, "-") {
+			names = append(names, name)
+		}
+	}
+	return names, key
+}
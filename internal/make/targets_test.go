@@ -0,0 +1,1188 @@
+package make_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tkrop/go-config/info"
+
+	"github.com/tkrop/go-make/internal/cmd"
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/makedb"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// dirTargets contains the per process directory for the targets files.
+	dirTargets = filepath.Join(os.TempDir(),
+		"go-make-test-"+strconv.Itoa(os.Getpid()))
+	// timeMakefile contains the modification time of the test makefiles.
+	timeMakefile = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// dbTargets contains a minimal make data base for the targets tests.
+	dbTargets = DBTargets()
+	// helpTargets contains a minimal `make --help` output for the targets
+	// tests.
+	helpTargets = "Options:\n" +
+		"  -h, --help                  Print this message and exit.\n"
+
+	// targetsGoMake contains the targets built for the go-make targets files.
+	targetsGoMake = "--config=\n--help\n--trace\nall\nbuild\n"
+	// targetsMake contains the targets built for the make targets file.
+	targetsMake = "--help\nall\nbuild\n"
+	// makefileTargets contains the content of the test makefiles.
+	makefileTargets = "all: build\n"
+)
+
+// DBTargets returns a minimal make data base for the targets tests listing
+// the given makefiles in `MAKEFILE_LIST`.
+func DBTargets(makefiles ...string) string {
+	db := "# Variables\n\n" +
+		"# makefile (from 'Makefile', line 1)\n" +
+		"GOMAKE_OPTIONS := --config= --trace\n"
+	for _, makefile := range makefiles {
+		db += "\n# makefile\nMAKEFILE_LIST :=  " + makefile + "\n"
+	}
+	return db + "\n# Files\n\n" +
+		"# Not a target:\nMakefile:\n\n" +
+		"all: build\n#  Phony target (prerequisite of .PHONY).\n\n" +
+		"build:\n#  recipe to execute (from 'Makefile', line 3):\n" +
+		"\t@echo build\n\n" +
+		".PHONY: all build\n"
+}
+
+// EnvTargets returns the environment variables for the targets files of the
+// test case with given name.
+func EnvTargets(name string) []string {
+	dir := filepath.Join(dirTargets, name)
+	return []string{
+		"FILE_TARGETS=" + filepath.Join(dir, "targets"),
+		"FILE_TARGETS_MAKE=" + filepath.Join(dir, "targets.make"),
+		"FILE_TARGETS_GOMAKE=" + filepath.Join(dir, "targets.go-make"),
+	}
+}
+
+// FileTargets returns the targets file with given name of the test case with
+// given name.
+func FileTargets(name, file string) string {
+	return filepath.Join(dirTargets, name, file)
+}
+
+// TargetsKey represents the cache key of a targets file.
+type TargetsKey struct {
+	Version  string    `json:"version"`
+	Dir      string    `json:"dir"`
+	Files    []FileKey `json:"files"`
+	Patterns []string  `json:"patterns,omitempty"`
+}
+
+// FileKey represents the state of a makefile in the cache key.
+type FileKey struct {
+	Path    string    `json:"path"`
+	ModTime time.Time `json:"mtime"`
+	Size    int64     `json:"size"`
+	Hash    string    `json:"hash"`
+}
+
+// NewFileKey creates the state of the test makefile of the test case with
+// given name using the given modification time and content.
+func NewFileKey(name string, mtime time.Time, content string) FileKey {
+	hash := sha256.Sum256([]byte(content))
+	return FileKey{
+		Path: FileTargets(name, "Makefile"), ModTime: mtime,
+		Size: int64(len(content)), Hash: hex.EncodeToString(hash[:]),
+	}
+}
+
+// SetupTargets sets up a clean directory for the given targets file
+// containing the given content, cache key, and makefile content, if provided.
+// The makefile is written with a fixed modification time.
+func SetupTargets(
+	t test.Test, file, content string, key *TargetsKey, makefile string,
+) {
+	if file == "" {
+		return
+	}
+
+	dir := filepath.Dir(file)
+	assert.NoError(t, os.RemoveAll(dir))
+	assert.NoError(t, os.MkdirAll(dir, 0o750))
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	if content != "" {
+		WriteFile(file, 0o600, content)
+	}
+	if key != nil {
+		data, err := json.Marshal(key)
+		assert.NoError(t, err)
+		WriteFile(file+".key", 0o600, string(data))
+	}
+	if makefile != "" {
+		path := filepath.Join(dir, "Makefile")
+		WriteFile(path, 0o600, makefile)
+		assert.NoError(t, os.Chtimes(path, timeMakefile, timeMakefile))
+	}
+}
+
+type ShowTargetsParams struct {
+	mockSetup     mock.SetupFunc
+	info          *info.Info
+	env           []string
+	args          []string
+	file          string
+	content       string
+	key           *TargetsKey
+	makefile      string
+	expectContent string
+	expectKey     *TargetsKey
+	expectError   error
+	expectExit    int
+}
+
+var showTargetsTestCases = map[string]ShowTargetsParams{
+	"show targets": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("default")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("default")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, EnvTargets("default")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("default")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:          infoBase,
+		env:           EnvTargets("default"),
+		args:          argsShowTargets,
+		file:          FileTargets("default", "targets"),
+		expectContent: targetsGoMake,
+		expectKey: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase, Files: []FileKey{},
+		},
+	},
+	"show targets with makefile": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("makefile")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("makefile")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot,
+				EnvTargets("makefile")...), "nil", "builder", "discard",
+				DBTargets(FileTargets("makefile", "Makefile")), "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("makefile")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:          infoBase,
+		env:           EnvTargets("makefile"),
+		args:          argsShowTargets,
+		file:          FileTargets("makefile", "targets"),
+		makefile:      makefileTargets,
+		expectContent: targetsGoMake,
+		expectKey: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("makefile",
+				timeMakefile, makefileTargets)},
+		},
+	},
+	"show targets with file without key": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("file")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("file")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, EnvTargets("file")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("file")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:          infoBase,
+		env:           EnvTargets("file"),
+		args:          argsShowTargets,
+		file:          FileTargets("file", "targets"),
+		content:       "cached\n",
+		expectContent: targetsGoMake,
+	},
+	"show targets with file and key": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("key")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogMessage("stdout", "cached\n"),
+		),
+		info:    infoBase,
+		env:     EnvTargets("key"),
+		args:    argsShowTargets,
+		file:    FileTargets("key", "targets"),
+		content: "cached\n",
+		key: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("key",
+				timeMakefile, makefileTargets)},
+		},
+		makefile:      makefileTargets,
+		expectContent: "cached\n",
+	},
+	"show targets with file and key of config dir": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("config-dir")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogMessage("stdout", "cached\n"),
+		),
+		info: infoBase,
+		env:  EnvTargets("config-dir"),
+		args: []string{
+			"go-make", "--config=" + FileTargets("config-dir", ""),
+			"show-targets",
+		},
+		file:    FileTargets("config-dir", "targets"),
+		content: "cached\n",
+		key: &TargetsKey{
+			Version: "custom", Dir: FileTargets("config-dir", ""),
+			Files: []FileKey{},
+		},
+		expectContent: "cached\n",
+	},
+	"show targets with file and key touched": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("touched")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			LogMessage("stdout", "cached\n"),
+		),
+		info:    infoBase,
+		env:     EnvTargets("touched"),
+		args:    argsShowTargets,
+		file:    FileTargets("touched", "targets"),
+		content: "cached\n",
+		key: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("touched",
+				timeMakefile.Add(-time.Hour), makefileTargets)},
+		},
+		makefile:      makefileTargets,
+		expectContent: "cached\n",
+	},
+	"show targets with file and key changed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("changed")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("changed")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot,
+				EnvTargets("changed")...), "nil", "builder", "discard",
+				DBTargets(FileTargets("changed", "Makefile")), "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("changed")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:    infoBase,
+		env:     EnvTargets("changed"),
+		args:    argsShowTargets,
+		file:    FileTargets("changed", "targets"),
+		content: "cached\n",
+		key: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("changed",
+				timeMakefile.Add(-time.Hour), "all: test\n")},
+		},
+		makefile:      makefileTargets,
+		expectContent: targetsGoMake,
+		expectKey: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("changed",
+				timeMakefile, makefileTargets)},
+		},
+	},
+	"show targets with file and key resized": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("resized")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("resized")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot,
+				EnvTargets("resized")...), "nil", "builder", "discard",
+				DBTargets(FileTargets("resized", "Makefile")), "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("resized")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:    infoBase,
+		env:     EnvTargets("resized"),
+		args:    argsShowTargets,
+		file:    FileTargets("resized", "targets"),
+		content: "cached\n",
+		key: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("resized",
+				timeMakefile, "all: build test\n")},
+		},
+		makefile:      makefileTargets,
+		expectContent: targetsGoMake,
+	},
+	"show targets with file and key removed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("removed")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("removed")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, EnvTargets("removed")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("removed")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:    infoBase,
+		env:     EnvTargets("removed"),
+		args:    argsShowTargets,
+		file:    FileTargets("removed", "targets"),
+		content: "cached\n",
+		key: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("removed",
+				timeMakefile, makefileTargets)},
+		},
+		expectContent: targetsGoMake,
+	},
+	"show targets with file and key of other config": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("config")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("config")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, EnvTargets("config")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("config")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:    infoBase,
+		env:     EnvTargets("config"),
+		args:    argsShowTargets,
+		file:    FileTargets("config", "targets"),
+		content: "cached\n",
+		key: &TargetsKey{
+			Version: infoNew.Version, Dir: goMakeInfoNew, Files: []FileKey{},
+		},
+		expectContent: targetsGoMake,
+	},
+	"show targets with key without file": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("no-file")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("no-file")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, EnvTargets("no-file")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("no-file")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info: infoBase,
+		env:  EnvTargets("no-file"),
+		args: argsShowTargets,
+		file: FileTargets("no-file", "targets"),
+		key: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase, Files: []FileKey{},
+		},
+		expectContent: targetsGoMake,
+	},
+	"show targets make": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("make")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("make")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(MakefileProject, dirRoot,
+				EnvTargets("make")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("make")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsMake),
+		),
+		info:          infoBase,
+		env:           EnvTargets("make"),
+		args:          argsShowTargetsMake,
+		file:          FileTargets("make", "targets.make"),
+		expectContent: targetsMake,
+	},
+	"show targets go-make": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("go-make")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("go-make")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot,
+				EnvTargets("go-make")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("go-make")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:          infoBase,
+		env:           EnvTargets("go-make"),
+		args:          argsShowTargetsGoMake,
+		file:          FileTargets("go-make", "targets.go-make"),
+		expectContent: targetsGoMake,
+	},
+	"show targets install": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("install")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoNew, dirRoot, EnvTargets("install")...),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot,
+				EnvTargets("install")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoNew, dirRoot, EnvTargets("install")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("install")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:          infoNew,
+		env:           EnvTargets("install"),
+		args:          argsShowTargets,
+		file:          FileTargets("install", "targets"),
+		expectContent: targetsGoMake,
+	},
+	"show targets config custom": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("custom")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(AbsPath("custom"), dirRoot, EnvTargets("custom")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(filepath.Join(AbsPath("custom"), Makefile),
+				dirRoot, EnvTargets("custom")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("custom")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:          infoBase,
+		env:           EnvTargets("custom"),
+		args:          argsShowTargetsCustom,
+		file:          FileTargets("custom", "targets"),
+		expectContent: targetsGoMake,
+	},
+	"show targets config version latest": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("latest")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(AbsPath("latest"), dirRoot, EnvTargets("latest")...),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdTestDir(GoMakePath(infoBase.Path, "latest"), dirRoot,
+				EnvTargets("latest")...),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, "latest", dirRoot,
+				EnvTargets("latest")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(MakefilePath(infoBase.Path, "latest"),
+				dirRoot, EnvTargets("latest")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("latest")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info:          infoBase,
+		env:           EnvTargets("latest"),
+		args:          argsShowTargetsLatest,
+		file:          FileTargets("latest", "targets"),
+		expectContent: targetsGoMake,
+	},
+
+	"show targets install failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("install-failed")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoNew, dirRoot,
+				EnvTargets("install-failed")...),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot,
+				EnvTargets("install-failed")...),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoNew.Path, infoNew.Version, NewErrCallFailed(
+					CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
+					assert.AnError))),
+		),
+		info: infoNew,
+		env:  EnvTargets("install-failed"),
+		args: argsShowTargets,
+		file: FileTargets("install-failed", "targets"),
+		expectError: NewErrNotFound(
+			infoNew.Path, infoNew.Version, NewErrCallFailed(
+				CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
+				assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"show targets database failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("database")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("database")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot,
+				EnvTargets("database")...),
+				"nil", "builder", "discard", "", "", assert.AnError),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("database")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogMessage("stdout", "--help\n"),
+		),
+		info:          infoBase,
+		env:           EnvTargets("database"),
+		args:          argsShowTargets,
+		file:          FileTargets("database", "targets"),
+		expectContent: "--help\n",
+	},
+	"show targets parse failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("parse")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("parse")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, EnvTargets("parse")...),
+				"nil", "builder", "discard", "# Files\n\ninvalid\n", "", nil),
+			LogError("stderr", "build targets", NewErrTargets("",
+				makedb.NewErrParse(3, errors.New("invalid rule [invalid]")))),
+		),
+		info: infoBase,
+		env:  EnvTargets("parse"),
+		args: argsShowTargets,
+		file: FileTargets("parse", "targets"),
+		expectError: NewErrTargets("",
+			makedb.NewErrParse(3, errors.New("invalid rule [invalid]"))),
+		expectExit: ExitTargetFailure,
+	},
+	"show targets help failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("help")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("help")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot, EnvTargets("help")...),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("help")...),
+				"nil", "builder", "discard", "", "", assert.AnError),
+			LogError("stderr", "build targets", NewErrCallFailed(
+				CmdMakeHelp(dirRoot), assert.AnError)),
+		),
+		info: infoBase,
+		env:  EnvTargets("help"),
+		args: argsShowTargets,
+		file: FileTargets("help", "targets"),
+		expectError: NewErrCallFailed(
+			CmdMakeHelp(dirRoot), assert.AnError),
+		expectExit: ExitTargetFailure,
+	},
+	"show targets write failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, "FILE_TARGETS=/dev/null/targets"),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				"FILE_TARGETS=/dev/null/targets"),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot,
+				"FILE_TARGETS=/dev/null/targets"),
+				"nil", "builder", "discard", dbTargets, "", nil),
+			Exec(CmdMakeHelp(dirRoot, "FILE_TARGETS=/dev/null/targets"),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogErrorAny("stderr", "write targets"),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info: infoBase,
+		env:  []string{"FILE_TARGETS=/dev/null/targets"},
+		args: argsShowTargets,
+	},
+	"show targets write key failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvTargets("write-key")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvTargets("write-key")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot,
+				EnvTargets("write-key")...), "nil", "builder", "discard",
+				DBTargets(FileTargets("write-key", "Makefile")), "", nil),
+			Exec(CmdMakeHelp(dirRoot, EnvTargets("write-key")...),
+				"nil", "builder", "discard", helpTargets, "", nil),
+			LogError("stderr", "write targets", NewErrTargets("",
+				&fs.PathError{
+					Op: "stat", Path: FileTargets("write-key", "Makefile"),
+					Err: syscall.ENOENT,
+				})),
+			LogMessage("stdout", targetsGoMake),
+		),
+		info: infoBase,
+		env:  EnvTargets("write-key"),
+		args: argsShowTargets,
+		file: FileTargets("write-key", "targets"),
+	},
+}
+
+func TestShowTargets(t *testing.T) {
+	test.Map(t, showTargetsTestCases).
+		Run(func(t test.Test, param ShowTargetsParams) {
+			// Given
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      param.info,
+				env:       param.env,
+			})
+			SetupTargets(t, param.file, param.content,
+				param.key, param.makefile)
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			if param.file != "" {
+				content, _ := os.ReadFile(param.file)
+				assert.Equal(t, param.expectContent, string(content))
+			}
+			if param.expectKey != nil {
+				data, err := os.ReadFile(param.file + ".key")
+				assert.NoError(t, err)
+				key := &TargetsKey{}
+				assert.NoError(t, json.Unmarshal(data, key))
+				assert.Equal(t, param.expectKey, key)
+			}
+		})
+}
+
+var (
+	// targetsCheck contains the cached targets for the targets check tests.
+	targetsCheck = "--help\n--trace\nall\nbuild\nbuild-linux\n" +
+		"test\ntest-unit\n"
+	// makefileCheck contains the makefile for the targets check tests
+	// annotating the `build-*` target family.
+	makefileCheck = "## Build: build targets.\n" +
+		"#@ build-*: build the matched command.\n" +
+		"build-%:\n\t@echo $*\n"
+)
+
+// EnvCheck returns the environment variables for the targets check test case
+// with given name extended by the given environment variables.
+func EnvCheck(name string, env ...string) []string {
+	return append(EnvTargets(name), env...)
+}
+
+// KeyCheck returns the valid cache key for the targets check test case with
+// given name.
+func KeyCheck(name string) *TargetsKey {
+	return &TargetsKey{
+		Version: infoBase.Version, Dir: goMakeInfoBase,
+		Files: []FileKey{NewFileKey(name, timeMakefile, makefileCheck)},
+	}
+}
+
+type CheckTargetsParams struct {
+	mockSetup   mock.SetupFunc
+	env         []string
+	args        []string
+	file        string
+	content     string
+	key         *TargetsKey
+	makefile    string
+	contentMake string
+	expectError error
+	expectExit  int
+}
+
+var checkTargetsTestCases = map[string]CheckTargetsParams{
+	"check targets without targets file": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-none")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvCheck("check-none")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"tset"}, dirRoot,
+				MakeEnv(EnvCheck("check-none")...)...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-none")...),
+		),
+		env:  EnvCheck("check-none"),
+		args: []string{"go-make", "tset"},
+		file: FileTargets("check-none", "targets"),
+	},
+	"check targets outdated": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-outdated")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-outdated")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"tset"}, dirRoot,
+				MakeEnv(EnvCheck("check-outdated")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-outdated")...),
+		),
+		env:      EnvCheck("check-outdated"),
+		args:     []string{"go-make", "tset"},
+		file:     FileTargets("check-outdated", "targets"),
+		content:  targetsCheck,
+		key:      KeyCheck("check-outdated"),
+		makefile: "all: build\n",
+	},
+	"check targets known": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-known")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-known")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{
+				"-j", "4", "--file", "other", "VAR=value", "build-darwin", "test",
+			}, dirRoot, MakeEnv(EnvCheck("check-known")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-known")...),
+		),
+		env: EnvCheck("check-known"),
+		args: []string{
+			"go-make", "-j", "4", "--file", "other", "VAR=value",
+			"build-darwin", "test",
+		},
+		file:     FileTargets("check-known", "targets"),
+		content:  targetsCheck,
+		key:      KeyCheck("check-known"),
+		makefile: makefileCheck,
+	},
+	"check targets command arguments": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-args")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-args")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"test-unit", "tset"},
+				dirRoot, MakeEnv(EnvCheck("check-args")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-args")...),
+		),
+		env:      EnvCheck("check-args"),
+		args:     []string{"go-make", "test-unit", "tset"},
+		file:     FileTargets("check-args", "targets"),
+		content:  targetsCheck,
+		key:      KeyCheck("check-args"),
+		makefile: makefileCheck,
+	},
+	"check targets file": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-file")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-file")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"go.mod"},
+				dirRoot, MakeEnv(EnvCheck("check-file")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-file")...),
+		),
+		env:      EnvCheck("check-file"),
+		args:     []string{"go-make", "go.mod"},
+		file:     FileTargets("check-file", "targets"),
+		content:  targetsCheck + "go-mod\n",
+		key:      KeyCheck("check-file"),
+		makefile: makefileCheck,
+	},
+	"check targets pattern rule": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-rule")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-rule")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"main.o"},
+				dirRoot, MakeEnv(EnvCheck("check-rule")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-rule")...),
+		),
+		env:     EnvCheck("check-rule"),
+		args:    []string{"go-make", "main.o"},
+		file:    FileTargets("check-rule", "targets"),
+		content: targetsCheck + "main\n",
+		key: &TargetsKey{
+			Version: infoBase.Version, Dir: goMakeInfoBase,
+			Files: []FileKey{NewFileKey("check-rule",
+				timeMakefile, makefileCheck)},
+			Patterns: []string{"%.o"},
+		},
+		makefile: makefileCheck,
+	},
+	"check targets project makefile": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-make")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-make")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"tests"},
+				dirRoot, MakeEnv(EnvCheck("check-make")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-make")...),
+		),
+		env:         EnvCheck("check-make"),
+		args:        []string{"go-make", "tests"},
+		file:        FileTargets("check-make", "targets"),
+		content:     targetsCheck,
+		key:         KeyCheck("check-make"),
+		makefile:    makefileCheck,
+		contentMake: "--help\ntests\n",
+	},
+	"check targets without suggestion": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-unknown")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-unknown")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"deploy"},
+				dirRoot, MakeEnv(EnvCheck("check-unknown")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-unknown")...),
+		),
+		env:      EnvCheck("check-unknown"),
+		args:     []string{"go-make", "deploy"},
+		file:     FileTargets("check-unknown", "targets"),
+		content:  targetsCheck,
+		key:      KeyCheck("check-unknown"),
+		makefile: makefileCheck,
+	},
+	"check targets with suggestion": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-suggest")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-suggest")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			LogError("stderr", "check targets",
+				NewErrUnknownTarget("tset", []string{"test"})),
+			ExecCommit(dirRoot, EnvCheck("check-suggest")...),
+		),
+		env:         EnvCheck("check-suggest"),
+		args:        []string{"go-make", "tset"},
+		file:        FileTargets("check-suggest", "targets"),
+		content:     targetsCheck,
+		key:         KeyCheck("check-suggest"),
+		makefile:    makefileCheck,
+		expectError: NewErrUnknownTarget("tset", []string{"test"}),
+		expectExit:  ExitTargetFailure,
+	},
+	"check targets with family suggestion": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-family")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot,
+				EnvCheck("check-family")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			LogError("stderr", "check targets", NewErrUnknownTarget(
+				"biuld-darwin", []string{"build-darwin"})),
+			ExecCommit(dirRoot, EnvCheck("check-family")...),
+		),
+		env:      EnvCheck("check-family"),
+		args:     []string{"go-make", "biuld-darwin"},
+		file:     FileTargets("check-family", "targets"),
+		content:  targetsCheck,
+		key:      KeyCheck("check-family"),
+		makefile: makefileCheck,
+		expectError: NewErrUnknownTarget(
+			"biuld-darwin", []string{"build-darwin"}),
+		expectExit: ExitTargetFailure,
+	},
+	"check targets auto-correct": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-correct",
+				EnvGoMakeAutoCorrect+"=true")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvCheck("check-correct",
+				EnvGoMakeAutoCorrect+"=true")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			LogWarning("stderr", "auto-correcting target [tset-unit] "+
+				"to [test-unit]"),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"test-unit", "tset"},
+				dirRoot, MakeEnv(EnvCheck("check-correct",
+					EnvGoMakeAutoCorrect+"=true")...)...).
+				WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot, EnvCheck("check-correct",
+				EnvGoMakeAutoCorrect+"=true")...),
+		),
+		env: EnvCheck("check-correct",
+			EnvGoMakeAutoCorrect+"=true"),
+		args:     []string{"go-make", "tset-unit", "tset"},
+		file:     FileTargets("check-correct", "targets"),
+		content:  targetsCheck,
+		key:      KeyCheck("check-correct"),
+		makefile: makefileCheck,
+	},
+	"check targets auto-correct ambiguous": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, EnvCheck("check-ambiguous",
+				EnvGoMakeAutoCorrect+"=true")...),
+				"nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot, EnvCheck("check-ambiguous",
+				EnvGoMakeAutoCorrect+"=true")...),
+				"nil", "stderr", "stderr", "", "", nil),
+			LogError("stderr", "check targets", NewErrUnknownTarget(
+				"tes", []string{"test", "test-unit"})),
+			ExecCommit(dirRoot, EnvCheck("check-ambiguous",
+				EnvGoMakeAutoCorrect+"=true")...),
+		),
+		env: EnvCheck("check-ambiguous",
+			EnvGoMakeAutoCorrect+"=true"),
+		args:     []string{"go-make", "tes"},
+		file:     FileTargets("check-ambiguous", "targets"),
+		content:  targetsCheck,
+		key:      KeyCheck("check-ambiguous"),
+		makefile: makefileCheck,
+		expectError: NewErrUnknownTarget(
+			"tes", []string{"test", "test-unit"}),
+		expectExit: ExitTargetFailure,
+	},
+}
+
+func TestCheckTargets(t *testing.T) {
+	test.Map(t, checkTargetsTestCases).
+		Run(func(t test.Test, param CheckTargetsParams) {
+			// Given
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+			SetupTargets(t, param.file, param.content,
+				param.key, param.makefile)
+			if param.contentMake != "" {
+				file := filepath.Join(filepath.Dir(param.file), "targets.make")
+				WriteFile(file, 0o600, param.contentMake)
+				data, err := json.Marshal(param.key)
+				assert.NoError(t, err)
+				WriteFile(file+".key", 0o600, string(data))
+			}
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+		})
+}
+
+var (
+	// argsShowHelpJSON contains the arguments to show the help as JSON.
+	argsShowHelpJSON = []string{"go-make", "--format=json", "show-help"}
+	// argsShowTargetsJSON contains the arguments to show the targets as JSON.
+	argsShowTargetsJSON = []string{"go-make", "--format=json", "show-targets"}
+
+	// dbCatalog contains a minimal make data base for the catalog tests.
+	dbCatalog = "# Variables\n\n# makefile\nMAKEFILE_LIST :=  " +
+		filepath.Join(dirRoot, "internal", "make", "fixtures", "catalog",
+			"Makefile") + "\n\n" +
+		"# Files\n\nall: build\n\nbuild:\n\t@echo build\n"
+)
+
+var showCatalogTestCases = map[string]MakeParams{
+	"show help json": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			LogMessage("stdout",
+				ReadFile(fixtures, "fixtures/catalog/help.json")),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: argsShowHelpJSON,
+	},
+	"show help json alias": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			LogMessage("stdout",
+				ReadFile(fixtures, "fixtures/catalog/help.json")),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: []string{"go-make", "--format=json", "help"},
+	},
+	"show help json without makefiles": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", "", "", assert.AnError),
+			LogMessage("stdout", "[]\n"),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: argsShowHelpJSON,
+	},
+	"show targets json": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			LogMessage("stdout",
+				ReadFile(fixtures, "fixtures/catalog/targets.json")),
+		),
+		info: infoBase,
+		args: argsShowTargetsJSON,
+	},
+	"show targets make json": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(MakefileProject, dirRoot),
+				"nil", "builder", "discard", dbCatalog, "", nil),
+			LogMessage("stdout",
+				ReadFile(fixtures, "fixtures/catalog/targets.json")),
+		),
+		info: infoBase,
+		args: []string{"go-make", "--format=json", "show-targets-make"},
+	},
+	"show help text": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{"show-help"}, dirRoot,
+				MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: []string{"go-make", "--format=text", "show-help"},
+	},
+	"format passed to make for other targets": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{
+				"--format=json", "build",
+			}, dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: []string{"go-make", "--format=json", "build"},
+	},
+	"format passed to make with trace": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeTargets(makeInfoBase, []string{
+				"--trace", "--format=json", "show-targets",
+			}, dirRoot, MakeEnv()...).WithMode(cmd.Forward),
+				"stdin", "stdout", "stderr", "", "", nil),
+		),
+		info: infoBase,
+		args: []string{"go-make", "--trace", "--format=json", "show-targets"},
+	},
+
+	"show help json config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoNew, dirRoot),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoNew.Path, infoNew.Version, NewErrCallFailed(
+					CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
+					assert.AnError))),
+			ExecCommit(dirRoot),
+		),
+		info: infoNew,
+		args: argsShowHelpJSON,
+		expectError: NewErrNotFound(
+			infoNew.Path, infoNew.Version, NewErrCallFailed(
+				CmdGoInstall(infoNew.Path, infoNew.Version, dirRoot),
+				assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"show help json parse failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", "# Files\n\ninvalid\n", "", nil),
+			LogError("stderr", "build catalog", NewErrTargets("",
+				makedb.NewErrParse(3, errors.New("invalid rule [invalid]")))),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: argsShowHelpJSON,
+		expectError: NewErrTargets("",
+			makedb.NewErrParse(3, errors.New("invalid rule [invalid]"))),
+		expectExit: ExitTargetFailure,
+	},
+	"show help json read failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr", dirRoot, "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, dirRoot),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirRoot),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					"MAKEFILE_LIST :=  missing\n", "", nil),
+			LogError("stderr", "build catalog", NewErrTargets("",
+				&fs.PathError{
+					Op: "open", Path: filepath.Join(dirRoot, "missing"),
+					Err: syscall.ENOENT,
+				})),
+			ExecCommit(dirRoot),
+		),
+		info: infoBase,
+		args: argsShowHelpJSON,
+		expectError: NewErrTargets("", &fs.PathError{
+			Op: "open", Path: filepath.Join(dirRoot, "missing"),
+			Err: syscall.ENOENT,
+		}),
+		expectExit: ExitTargetFailure,
+	},
+}
+
+func TestShowCatalog(t *testing.T) {
+	test.Map(t, showCatalogTestCases).
+		Run(func(t test.Test, param MakeParams) {
+			// Given
+			gm, _ := GoMakeSetup(t, param)
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+		})
+}
@@ -0,0 +1,75 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/testargs"
+)
+
+const (
+	// CmdTestArgs provides the name of the native test-args command.
+	CmdTestArgs = "test-args"
+	// EnvPackages provides the name of the makefile variable containing the
+	// default packages to test.
+	EnvPackages = "PACKAGES"
+)
+
+// parseTestArgs parses the arguments of the test-args command returning the
+// test filter mode and the unified test arguments.
+func parseTestArgs(args ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return testargs.ModeRun, args, nil
+	}
+	switch args[0] {
+	case testargs.ModeRun, testargs.ModeBench:
+		return args[0], args[1:], nil
+	}
+	if strings.HasPrefix(args[0], "-") {
+		return "", nil, NewErrInvalidArg(CmdTestArgs, args[0], nil)
+	}
+	return testargs.ModeRun, args, nil
+}
+
+// testArgs runs the native test-args command with given arguments. It
+// resolves the unified test arguments, i.e. coverage scopes, test files,
+// directories, and test regexes, relative to the working directory into the
+// arguments of `go test` using the `PACKAGES` as default packages. Arguments
+// that cannot be resolved are reported as warning. If a `SHARD` is given, the
+// default packages are restricted to the packages of the shard.
+func (gm *GoMake) testArgs(args ...string) (int, error) {
+	mode, args, err := parseTestArgs(args...)
+	if err != nil {
+		gm.error("parse test-args", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	packages, err := gm.variable(ctx, EnvPackages, testargs.PackagesAll)
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+
+	resolved, err := testargs.Resolve(gm.WorkDir,
+		mode, strings.Fields(packages), args...)
+	if err != nil {
+		gm.error("resolve test-args", err)
+		return ExitCommandFailure, err
+	}
+	if value := gm.GetEnvDefault(EnvShard, ""); value != "" &&
+		len(resolved.Files) == 0 {
+		if exit, err := gm.testShard(ctx, value, resolved); err != nil {
+			return exit, err
+		}
+	}
+	if len(resolved.Invalid) != 0 {
+		gm.Logger.Warning(gm.Stderr, fmt.Sprintf("invalid test args [%s]",
+			strings.Join(resolved.Invalid, " ")))
+	}
+	gm.Logger.Message(gm.Stdout, resolved.String())
+	return ExitSuccess, nil
+}
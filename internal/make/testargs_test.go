@@ -0,0 +1,244 @@
+package make_test
+
+import (
+	"go/build"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/shard"
+	"github.com/tkrop/go-make/internal/testargs"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// dirTestArgs contains the directory of the broken test-args package.
+	dirTestArgs = filepath.Join(dirTargets, "test-args")
+	// fileTestArgs contains the test file of the broken test-args package.
+	fileTestArgs = filepath.Join(dirTestArgs, "b_test.go")
+	// envShard contains the environment of a shard without durations file.
+	envShard = []string{EnvPackages + "=internal/make", EnvShard + "=1/2"}
+	// fileDurations contains the historical test durations of the shards.
+	fileDurations = filepath.Join("fixtures", "shard", "test-durations.json")
+)
+
+type TestArgsParams struct {
+	mockSetup   mock.SetupFunc
+	env         []string
+	args        []string
+	expectError error
+	expectExit  int
+}
+
+var testArgsTestCases = map[string]TestArgsParams{
+	"test-args default": {
+		mockSetup: mock.Chain(
+			LogMessage("stdout", "'-run=^Test' ./internal/make"),
+		),
+		env:  []string{EnvPackages + "=internal/make"},
+		args: []string{"go-make", "--test-args"},
+	},
+	"test-args run": {
+		mockSetup: mock.Chain(
+			LogMessage("stdout", "-coverpkg=./... "+
+				"'-run=TestTestArgs|TestMake' ./fixtures"),
+		),
+		env: []string{EnvPackages + "=internal/make"},
+		args: []string{
+			"go-make", "--test-args", "-run", "scope:.",
+			"TestTestArgs", "fixtures", "TestMake",
+		},
+	},
+	"test-args bench": {
+		mockSetup: mock.Chain(
+			LogMessage("stdout", "'-bench=^Benchmark' ./... ./cmd"),
+		),
+		env:  []string{EnvPackages + "=./... cmd"},
+		args: []string{"go-make", "--test-args", "-bench"},
+	},
+	"test-args invalid": {
+		mockSetup: mock.Chain(
+			LogWarning("stderr", "invalid test args [scope:missing make.go]"),
+			LogMessage("stdout", "-run=TestMake ./..."),
+		),
+		env: []string{EnvPackages + "=./..."},
+		args: []string{
+			"go-make", "--test-args", "scope:missing", "make.go", "TestMake",
+		},
+	},
+	"test-args from config": {
+		mockSetup: mock.Chain(
+			Exec(CmdTestDir(goMakeInfoBase, dirWork),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dirWork),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvPackages+" := internal/make cmd/go-make\n", "", nil),
+			LogMessage("stdout",
+				"'-run=^Test' ./internal/make ./cmd/go-make"),
+		),
+		args: []string{"go-make", "--test-args", "-run"},
+	},
+
+	"test-args package failed": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "resolve test-args",
+				testargs.NewErrInvalidPackage(fileTestArgs,
+					&build.MultiplePackageError{
+						Dir:      dirTestArgs,
+						Packages: []string{"a", "b"},
+						Files:    []string{"a.go", "b_test.go"},
+					})),
+		),
+		env:  []string{EnvPackages + "=./..."},
+		args: []string{"go-make", "--test-args", fileTestArgs},
+		expectError: testargs.NewErrInvalidPackage(fileTestArgs,
+			&build.MultiplePackageError{
+				Dir:      dirTestArgs,
+				Packages: []string{"a", "b"},
+				Files:    []string{"a.go", "b_test.go"},
+			}),
+		expectExit: ExitCommandFailure,
+	},
+	"test-args config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdTestDir(goMakeInfoBase, dirWork),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version, dirWork),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version, dirWork),
+					assert.AnError))),
+		),
+		args: []string{"go-make", "--test-args"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				dirWork), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"test-args shard by duration": {
+		mockSetup: mock.Chain(
+			LogMessage("stdout", "'-run=^Test' ./cmd/go-make ./internal/cmd"),
+		),
+		env: []string{
+			EnvPackages + "=internal/make cmd/go-make internal/cmd",
+			EnvShard + "=2/2", EnvTestDurations + "=" + fileDurations,
+		},
+		args: []string{"go-make", "--test-args"},
+	},
+	"test-args shard by count": {
+		mockSetup: mock.Chain(
+			LogMessage("stdout", "'-run=^Test' ./b . /abs"),
+		),
+		env: []string{
+			EnvPackages + "=b a . c /abs",
+			EnvShard + "=1/2", EnvTestDurations + "=fixtures/shard/missing.json",
+		},
+		args: []string{"go-make", "--test-args"},
+	},
+	"test-args shard empty": {
+		mockSetup: mock.Chain(
+			LogWarning("stderr", "empty shard [shard=2/2, packages=1]"),
+			LogMessage("stdout", "'-run=^$' ./..."),
+		),
+		env: []string{
+			EnvPackages + "=internal/make",
+			EnvShard + "=2/2", EnvTestDurations + "=" + fileDurations,
+		},
+		args: []string{"go-make", "--test-args"},
+	},
+	"test-args shard files": {
+		mockSetup: mock.Chain(
+			LogMessage("stdout", "'-run=^Test' ./fixtures"),
+		),
+		env: []string{
+			EnvPackages + "=internal/make cmd/go-make",
+			EnvShard + "=2/2", EnvTestDurations + "=" + fileDurations,
+		},
+		args: []string{"go-make", "--test-args", "fixtures"},
+	},
+	"test-args shard invalid": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse shard", shard.NewErrInvalidShard("3/2")),
+		),
+		env: []string{
+			EnvPackages + "=internal/make",
+			EnvShard + "=3/2", EnvTestDurations + "=" + fileDurations,
+		},
+		args:        []string{"go-make", "--test-args"},
+		expectError: shard.NewErrInvalidShard("3/2"),
+		expectExit:  ExitCommandFailure,
+	},
+	"test-args shard durations failed": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "read durations", shard.NewErrDurations(
+				"fixtures/shard", &fs.PathError{
+					Op: "read", Path: "fixtures/shard", Err: syscall.EISDIR,
+				})),
+		),
+		env: []string{
+			EnvPackages + "=internal/make",
+			EnvShard + "=1/2", EnvTestDurations + "=fixtures/shard",
+		},
+		args: []string{"go-make", "--test-args"},
+		expectError: shard.NewErrDurations("fixtures/shard", &fs.PathError{
+			Op: "read", Path: "fixtures/shard", Err: syscall.EISDIR,
+		}),
+		expectExit: ExitCommandFailure,
+	},
+	"test-args shard config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdTestDir(goMakeInfoBase, dirWork, envShard...),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version, dirWork,
+				envShard...), "nil", "stderr", "stderr", "", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version, dirWork,
+						envShard...), assert.AnError))),
+		),
+		env:  envShard,
+		args: []string{"go-make", "--test-args"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				dirWork, envShard...), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"test-args invalid mode": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse test-args",
+				NewErrInvalidArg(CmdTestArgs, "-list", nil)),
+		),
+		args:        []string{"go-make", "--test-args", "-list", "Test"},
+		expectError: NewErrInvalidArg(CmdTestArgs, "-list", nil),
+		expectExit:  ExitCommandFailure,
+	},
+}
+
+func TestTestArgs(t *testing.T) {
+	test.Map(t, testArgsTestCases).
+		Run(func(t test.Test, param TestArgsParams) {
+			// Given
+			assert.NoError(t, os.MkdirAll(dirTestArgs, 0o750))
+			WriteFile(filepath.Join(dirTestArgs, "a.go"), 0o600, "package a\n")
+			WriteFile(fileTestArgs, 0o600, "package b\n")
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+		})
+}
@@ -0,0 +1,104 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/junit"
+)
+
+const (
+	// CmdTestReport provides the name of the native test-report command.
+	CmdTestReport = "test-report"
+	// EnvTestReport provides the name of the makefile variable containing the
+	// JUnit XML test report file.
+	EnvTestReport = "TEST_REPORT"
+	// DefaultTestReport provides the default JUnit XML test report file.
+	DefaultTestReport = "build/test-report.xml"
+)
+
+// ErrTestFailed represents a failed test run.
+var ErrTestFailed = errors.New("test failed")
+
+// NewErrTestFailed creates a failed test run error for the given number of
+// failed tests and tests with errors.
+func NewErrTestFailed(failures, errors int) error {
+	return fmt.Errorf("%w [failures=%d, errors=%d]",
+		ErrTestFailed, failures, errors)
+}
+
+// parseTestReport parses the arguments of the test-report command returning
+// the report file, if any.
+func parseTestReport(args ...string) (string, error) {
+	file := ""
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--report=") && arg != "--report=":
+			file = arg[len("--report="):]
+		default:
+			return "", NewErrInvalidArg(CmdTestReport, arg, nil)
+		}
+	}
+	return file, nil
+}
+
+// testReport runs the native test-report command with given arguments. It
+// converts the `go test -json` events read from standard input into a JUnit
+// XML report written to the `TEST_REPORT` file, while forwarding the
+// human-readable test output to standard output. The package durations are
+// recorded in the `TEST_DURATIONS` file for sharding, even if the report
+// cannot be written. It fails, if a test or package failed.
+func (gm *GoMake) testReport(args ...string) (int, error) {
+	file, err := parseTestReport(args...)
+	if err != nil {
+		gm.error("parse test-report", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	values, err := gm.variables(ctx, map[string]string{
+		EnvTestReport:    DefaultTestReport,
+		EnvTestDurations: DefaultTestDurations,
+	})
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+	if file == "" {
+		file = values[EnvTestReport]
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(gm.WorkDir, file)
+	}
+
+	report := junit.NewReport()
+	if err := junit.Convert(gm.Stdin, gm.Stdout, report); err != nil {
+		gm.error("convert test output", err)
+		return ExitCommandFailure, err
+	}
+	gm.recordDurations(values[EnvTestDurations], report)
+	if err := writeReport(file, func(writer io.Writer) error {
+		return report.Write(writer)
+	}); err != nil {
+		gm.error("write report", err)
+		return ExitCommandFailure, err
+	}
+
+	totals := report.Totals()
+	gm.Logger.Message(gm.Stdout, fmt.Sprintf("test report [file=%s, "+
+		"tests=%d, failures=%d, errors=%d, skipped=%d]", file,
+		totals.Tests, totals.Failures, totals.Errors, totals.Skipped))
+	if totals.Failures != 0 || totals.Errors != 0 {
+		err := NewErrTestFailed(totals.Failures, totals.Errors)
+		gm.error(CmdTestReport, err)
+		return ExitCommandFailure, err
+	}
+	return ExitSuccess, nil
+}
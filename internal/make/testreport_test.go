@@ -0,0 +1,257 @@
+package make_test
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/shard"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// reportPass contains the events of a passed test run.
+	reportPass = `{"Action":"start","Package":"example.com/report"}
+{"Action":"run","Package":"example.com/report","Test":"TestRun"}
+{"Action":"output","Package":"example.com/report","Test":"TestRun",` +
+		`"Output":"=== RUN   TestRun\n"}
+{"Action":"output","Package":"example.com/report","Test":"TestRun",` +
+		`"Output":"--- PASS: TestRun (0.01s)\n"}
+{"Action":"pass","Package":"example.com/report","Test":"TestRun",` +
+		`"Elapsed":0.01}
+{"Action":"output","Package":"example.com/report",` +
+		`"Output":"ok  \texample.com/report\t0.02s\n"}
+{"Action":"pass","Package":"example.com/report","Elapsed":0.02}
+`
+	// reportFail contains the events of a failed test run.
+	reportFail = `{"Action":"run","Package":"example.com/report",` +
+		`"Test":"TestRun"}
+{"Action":"output","Package":"example.com/report","Test":"TestRun",` +
+		`"Output":"--- FAIL: TestRun (0.01s)\n"}
+{"Action":"fail","Package":"example.com/report","Test":"TestRun",` +
+		`"Elapsed":0.01}
+{"Action":"fail","Package":"example.com/report","Elapsed":0.02}
+`
+	// reportPassXML contains the JUnit XML report of the passed test run.
+	reportPassXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="1" failures="0" errors="0" skipped="0" time="0.020">
+  <testsuite name="example.com/report" tests="1" failures="0" errors="0"` +
+		` skipped="0" time="0.020">
+    <testcase name="TestRun" classname="example.com/report"` +
+		` time="0.010"></testcase>
+  </testsuite>
+</testsuites>
+`
+)
+
+// envReport contains the environment of the test report.
+var envReport = []string{
+	EnvTestReport + "=" + DefaultTestReport,
+	EnvTestDurations + "=" + DefaultTestDurations,
+}
+
+// envReportWrite contains the environment of the test report with the test
+// durations outside of the build directory.
+var envReportWrite = []string{
+	EnvTestReport + "=" + DefaultTestReport,
+	EnvTestDurations + "=test-durations.json",
+}
+
+// DirReport returns the project directory of the test report test case with
+// given name.
+func DirReport(name string) string {
+	return filepath.Join(dirTargets, "report", name)
+}
+
+type TestReportParams struct {
+	mockSetup    mock.SetupFunc
+	dir          string
+	env          []string
+	args         []string
+	stdin        io.Reader
+	files        map[string]string
+	expectStdout string
+	expectFiles  map[string]string
+	expectError  error
+	expectExit   int
+}
+
+var testReportTestCases = map[string]TestReportParams{
+	"report passed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envReport...), "nil", "builder",
+				"stderr", DirReport("passed"), "", nil),
+			LogMessage("stdout", "test report [file="+filepath.Join(
+				DirReport("passed"), DefaultTestReport)+", tests=1, "+
+				"failures=0, errors=0, skipped=0]"),
+		),
+		dir:   "passed",
+		env:   envReport,
+		args:  []string{"go-make", "--test-report"},
+		stdin: strings.NewReader(reportPass),
+		expectStdout: "--- PASS: TestRun (0.01s)\n" +
+			"ok  \texample.com/report\t0.02s\n",
+		expectFiles: map[string]string{
+			DefaultTestReport:    reportPassXML,
+			DefaultTestDurations: "{\n  \"example.com/report\": 0.02\n}\n",
+		},
+	},
+	"report durations failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envReport...), "nil", "builder",
+				"stderr", DirReport("durations"), "", nil),
+			LogError("stderr", "read durations", shard.NewErrDurations(
+				filepath.Join(DirReport("durations"), DefaultTestDurations),
+				&fs.PathError{
+					Op: "read", Err: syscall.EISDIR, Path: filepath.Join(
+						DirReport("durations"), DefaultTestDurations),
+				})),
+			LogMessage("stdout", "test report [file="+filepath.Join(
+				DirReport("durations"), DefaultTestReport)+", tests=1, "+
+				"failures=0, errors=0, skipped=0]"),
+		),
+		dir:   "durations",
+		env:   envReport,
+		args:  []string{"go-make", "--test-report"},
+		stdin: strings.NewReader(reportPass),
+		files: map[string]string{DefaultTestDurations + "/file": ""},
+		expectStdout: "--- PASS: TestRun (0.01s)\n" +
+			"ok  \texample.com/report\t0.02s\n",
+		expectFiles: map[string]string{DefaultTestReport: reportPassXML},
+	},
+	"report failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envReport...), "nil", "builder",
+				"stderr", DirReport("failed"), "", nil),
+			LogMessage("stdout", "test report [file="+filepath.Join(
+				DirReport("failed"), "out/report.xml")+", tests=1, "+
+				"failures=1, errors=0, skipped=0]"),
+			LogError("stderr", CmdTestReport, NewErrTestFailed(1, 0)),
+		),
+		dir: "failed",
+		env: envReport,
+		args: []string{
+			"go-make", "--test-report", "--report=out/report.xml",
+		},
+		stdin:        strings.NewReader(reportFail),
+		expectStdout: "--- FAIL: TestRun (0.01s)\n",
+		expectError:  NewErrTestFailed(1, 0),
+		expectExit:   ExitCommandFailure,
+	},
+	"convert failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envReport...), "nil", "builder",
+				"stderr", DirReport("convert"), "", nil),
+			LogError("stderr", "convert test output", assert.AnError),
+		),
+		dir:         "convert",
+		env:         envReport,
+		args:        []string{"go-make", "--test-report"},
+		stdin:       iotest.ErrReader(assert.AnError),
+		expectError: assert.AnError,
+		expectExit:  ExitCommandFailure,
+	},
+	"write report failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork, envReportWrite...), "nil", "builder",
+				"stderr", DirReport("write"), "", nil),
+			LogError("stderr", "write report", &fs.PathError{
+				Op: "mkdir", Path: filepath.Join(DirReport("write"), "build"),
+				Err: syscall.ENOTDIR,
+			}),
+		),
+		dir:   "write",
+		env:   envReportWrite,
+		args:  []string{"go-make", "--test-report"},
+		stdin: strings.NewReader(reportPass),
+		files: map[string]string{"build": ""},
+		expectStdout: "--- PASS: TestRun (0.01s)\n" +
+			"ok  \texample.com/report\t0.02s\n",
+		expectFiles: map[string]string{
+			"test-durations.json": "{\n  \"example.com/report\": 0.02\n}\n",
+		},
+		expectError: &fs.PathError{
+			Op: "mkdir", Path: filepath.Join(DirReport("write"), "build"),
+			Err: syscall.ENOTDIR,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirReport("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirReport("config")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirReport("config")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirReport("config")), assert.AnError))),
+		),
+		dir:  "config",
+		args: []string{"go-make", "--test-report"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirReport("config")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+	"invalid argument": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse test-report",
+				NewErrInvalidArg(CmdTestReport, "--report=", nil)),
+		),
+		args: []string{"go-make", "--test-report", "--report="},
+		expectError: NewErrInvalidArg(CmdTestReport,
+			"--report=", nil),
+		expectExit: ExitCommandFailure,
+	},
+}
+
+func TestTestReport(t *testing.T) {
+	test.Map(t, testReportTestCases).
+		Run(func(t test.Test, param TestReportParams) {
+			// Given
+			dir := DirReport(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				WriteFile(path, 0o600, content)
+			}
+			gm, mocks := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+			if param.stdin != nil {
+				gm.Stdin = param.stdin
+			}
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			assert.Equal(t, "stdout"+param.expectStdout,
+				mocks.GetArg("stdout").(*strings.Builder).String())
+			for name, content := range param.expectFiles {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				assert.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+		})
+}
@@ -0,0 +1,524 @@
+package make //nolint:predeclared // package name is make.
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/changelog"
+	"github.com/tkrop/go-make/internal/semver"
+	"github.com/tkrop/go-make/internal/verify"
+)
+
+const (
+	// CmdVersionBump provides the name of the native version-bump command.
+	CmdVersionBump = "version-bump"
+	// EnvVersionFiles provides the name of the makefile variable containing
+	// the extra version files of the project.
+	EnvVersionFiles = "VERSION_FILES"
+	// EnvVersionBump provides the prefix of the makefile variables containing
+	// the bump operations of breaking changes and conventional commit types.
+	EnvVersionBump = "VERSION_BUMP_"
+	// VersionBumpAuto provides the argument to infer the bump operation from
+	// the commits since the last release.
+	VersionBumpAuto = "auto"
+	// FileVersion provides the name of the project version file.
+	FileVersion = "VERSION"
+	// VersionInitial provides the initial version, if no version file exists.
+	VersionInitial = "0.0.0"
+)
+
+// bumpOps contains the supported version bump operations.
+var bumpOps = []string{
+	semver.BumpMajor, semver.BumpMinor, semver.BumpPatch,
+	semver.BumpPreMajor, semver.BumpPreMinor, semver.BumpPrePatch,
+	semver.BumpPreRelease,
+}
+
+// bumpBreaking provides the name of breaking changes in the bump operations.
+const bumpBreaking = "BREAKING"
+
+// bumpDefaults contains the default bump operations of breaking changes and
+// conventional commit types. Other commit types default to patch.
+var bumpDefaults = map[string]string{
+	bumpBreaking: semver.BumpMajor,
+	"FEAT":       semver.BumpMinor,
+	"DEPRECATE":  semver.BumpMinor,
+	"REMOVE":     semver.BumpMinor,
+}
+
+// regexLegacy matches the legacy bump operations, i.e. `major+`, `minor-`,
+// or `+`, `++`, and `+++` for patch, minor, and major, where a trailing `-`
+// decrements the version part instead of incrementing it.
+var regexLegacy = regexp.MustCompile(`^(major|minor|patch)?([+-]+)$`)
+
+// legacyOps contains the bump operations of the legacy bump forms without
+// operation by the number of signs.
+var legacyOps = []string{semver.BumpPatch, semver.BumpMinor, semver.BumpMajor}
+
+// bumpRanks contains the ranks of the bump operations, that can be inferred.
+var bumpRanks = map[string]int{
+	semver.BumpPatch: 1, semver.BumpMinor: 2, semver.BumpMajor: 3,
+}
+
+var (
+	// ErrDowngrade represents a refused version downgrade.
+	ErrDowngrade = errors.New("version downgrade")
+	// ErrVersionMissing represents an extra version file not containing the
+	// current version.
+	ErrVersionMissing = errors.New("version missing")
+	// ErrVersionAmbiguous represents an extra version file containing the
+	// current version more than once.
+	ErrVersionAmbiguous = errors.New("version ambiguous")
+	// ErrVersionFile represents an extra version file with invalid pattern.
+	ErrVersionFile = errors.New("invalid version file")
+	// ErrBumpOp represents an invalid configured bump operation.
+	ErrBumpOp = errors.New("invalid bump operation")
+	// ErrNoChanges represents a missing change to infer the bump operation.
+	ErrNoChanges = errors.New("no changes")
+)
+
+// NewErrDowngrade creates an error for the refused downgrade from the given
+// current version to the given next version.
+func NewErrDowngrade(version, next string) error {
+	return fmt.Errorf("%w [version=%s, next=%s]", ErrDowngrade, version, next)
+}
+
+// NewErrVersionMissing creates an error for the given extra version file not
+// containing the given current version.
+func NewErrVersionMissing(file, version string) error {
+	return fmt.Errorf("%w [file=%s, version=%s]",
+		ErrVersionMissing, file, version)
+}
+
+// NewErrVersionAmbiguous creates an error for the given extra version file
+// containing the given current version the given number of times.
+func NewErrVersionAmbiguous(file, version string, count int) error {
+	return fmt.Errorf("%w [file=%s, version=%s, count=%d]",
+		ErrVersionAmbiguous, file, version, count)
+}
+
+// NewErrVersionFile creates an error for the given extra version file with
+// invalid pattern.
+func NewErrVersionFile(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrVersionFile, file, err)
+}
+
+// NewErrBumpOp creates an error for the invalid bump operation configured by
+// the makefile variable with given name.
+func NewErrBumpOp(name, op string) error {
+	return fmt.Errorf("%w [name=%s, op=%s]", ErrBumpOp, name, op)
+}
+
+// NewErrNoChanges creates an error for the given revision range containing no
+// changes to infer the bump operation.
+func NewErrNoChanges(rng string) error {
+	return fmt.Errorf("%w [range=%s]", ErrNoChanges, rng)
+}
+
+// bumpArgs contains the parsed arguments of the version-bump command.
+type bumpArgs struct {
+	// op provides the version bump operation.
+	op string
+	// id provides the pre-release identifier of the pre-release operations.
+	id string
+	// version provides the explicit next version.
+	version *semver.Version
+	// build provides the build metadata of the next version.
+	build string
+	// force indicates whether to allow downgrades.
+	force bool
+	// auto indicates whether to infer the bump operation.
+	auto bool
+	// lower indicates whether to decrement the version part of the bump
+	// operation as requested by the legacy bump forms.
+	lower bool
+	// dryRun indicates whether to only report the next version.
+	dryRun bool
+}
+
+// parseBump parses the arguments of the version-bump command.
+func parseBump(args ...string) (*bumpArgs, error) {
+	params := &bumpArgs{}
+	for _, arg := range args {
+		switch {
+		case arg == "--force":
+			params.force = true
+		case arg == "--dry-run":
+			params.dryRun = true
+		case strings.HasPrefix(arg, "--build="):
+			params.build = arg[len("--build="):]
+			if _, err := semver.Parse(VersionInitial + "+" +
+				params.build); err != nil {
+				return nil, NewErrInvalidArg(CmdVersionBump, arg, err)
+			}
+		case params.unset() && arg == VersionBumpAuto:
+			params.auto = true
+		case params.unset() && slices.Contains(bumpOps, arg):
+			params.op = arg
+		case params.unset() && params.legacy(arg):
+		case params.unset():
+			version, err := semver.ParseShort(arg)
+			if err != nil {
+				return nil, NewErrInvalidArg(CmdVersionBump, arg, err)
+			}
+			params.version = version
+		case strings.HasPrefix(params.op, "pre") && params.id == "":
+			params.id = arg
+		default:
+			return nil, NewErrInvalidArg(CmdVersionBump, arg, nil)
+		}
+	}
+
+	if params.unset() {
+		params.op = semver.BumpPatch
+	}
+	return params, nil
+}
+
+// legacy parses the given legacy bump operation, e.g. `major+` or `--`, and
+// returns whether the bump operation is valid.
+func (params *bumpArgs) legacy(arg string) bool {
+	match := regexLegacy.FindStringSubmatch(arg)
+	if match == nil {
+		return false
+	} else if match[1] == "" && len(match[2]) > len(legacyOps) {
+		return false
+	} else if match[1] == "" {
+		match[1] = legacyOps[len(match[2])-1]
+	}
+	params.op, params.lower = match[1], strings.HasSuffix(arg, "-")
+	return true
+}
+
+// unset returns whether neither a bump operation, nor an explicit version,
+// nor the inference of the bump operation is requested.
+func (params *bumpArgs) unset() bool {
+	return params.op == "" && params.version == nil && !params.auto
+}
+
+// versionBump runs the native version-bump command with given arguments. It
+// bumps the semantic version of the project `VERSION` file using the given
+// or inferred bump operation or explicit version, and updates the version in
+// the extra version files of `VERSION_FILES`. Downgrades are refused unless
+// forced, while a dry-run only reports the next version.
+func (gm *GoMake) versionBump(args ...string) (int, error) {
+	params, err := parseBump(args...)
+	if err != nil {
+		gm.error("parse version-bump", err)
+		return ExitCommandFailure, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gm.setupWorkDir(ctx)
+	current, raw, err := gm.readVersion()
+	if err != nil {
+		gm.error("read version", err)
+		return ExitCommandFailure, err
+	}
+
+	if params.auto {
+		if exit, err := gm.versionInfer(ctx, params); err != nil {
+			return exit, err
+		}
+	}
+	next, err := params.next(current)
+	if err != nil {
+		gm.error("bump version", err)
+		return ExitCommandFailure, err
+	} else if params.dryRun {
+		gm.Logger.Message(gm.Stdout, fmt.Sprintf(
+			"next version [%s => %s]", current, next))
+		return ExitSuccess, nil
+	}
+
+	value, err := gm.variable(ctx, EnvVersionFiles, "")
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+	if err := gm.writeVersion(raw, next,
+		strings.Fields(value)); err != nil {
+		gm.error("write version", err)
+		return ExitCommandFailure, err
+	}
+
+	gm.Logger.Message(gm.Stdout, fmt.Sprintf(
+		"bumped version [%s => %s]", current, next))
+	return ExitSuccess, nil
+}
+
+// versionInfer infers the bump operation of the given version-bump arguments
+// from the commits since the last `v*` release tag and reports the commits
+// that drove the bump operation. The commits are parsed using the project
+// commit rules like the changelog. The bump operations of breaking changes and
+// of the conventional commit types are provided by the `VERSION_BUMP_*`
+// makefile variables.
+func (gm *GoMake) versionInfer(
+	ctx context.Context, params *bumpArgs,
+) (int, error) {
+	rng := gm.changelogRange(ctx)
+	commits, err := gm.gitCommits(ctx, rng)
+	if err != nil {
+		gm.error("read commits", err)
+		return ExitCommandFailure, err
+	}
+
+	verifier, exit, err := gm.changeVerifier(ctx)
+	if err != nil {
+		return exit, err
+	}
+
+	changes := make([]*verify.Change, 0, len(commits))
+	deflts := map[string]string{
+		EnvVersionBump + bumpBreaking: bumpDefaults[bumpBreaking],
+	}
+	for _, commit := range commits {
+		change := verifier.Change(commit)
+		if name := strings.ToUpper(change.Type); name != "" {
+			deflts[EnvVersionBump+name] = cmp.Or(
+				bumpDefaults[name], semver.BumpPatch)
+		}
+		changes = append(changes, change)
+	}
+
+	ops, err := gm.variables(ctx, deflts)
+	if err != nil {
+		gm.error("ensure config", err)
+		return ExitConfigFailure, err
+	}
+	drivers, err := inferBump(rng, changes, ops)
+	if err != nil {
+		gm.error("infer version", err)
+		return ExitCommandFailure, err
+	}
+
+	params.op = bumpOp(changes[drivers[0]], ops)
+	gm.Logger.Message(gm.Stdout, fmt.Sprintf(
+		"inferred %s bump [range=%s, commits=%d]",
+		params.op, rng, len(commits)))
+	for _, index := range drivers {
+		gm.Logger.Message(gm.Stdout, fmt.Sprintf("  %s %s",
+			changelog.ShortHash(commits[index].Hash), commits[index].Title()))
+	}
+	return ExitSuccess, nil
+}
+
+// inferBump returns the indexes of the given changes of the given revision
+// range that drove the highest ranked bump operation using the given bump
+// operations of breaking changes and conventional commit types. An error is
+// returned, if there are no changes or a bump operation is invalid.
+func inferBump(
+	rng string, changes []*verify.Change, ops map[string]string,
+) ([]int, error) {
+	for name, op := range ops {
+		if _, ok := bumpRanks[op]; !ok {
+			return nil, NewErrBumpOp(name, op)
+		}
+	}
+
+	rank, drivers := 0, []int{}
+	for index, change := range changes {
+		switch next := bumpRanks[bumpOp(change, ops)]; {
+		case next > rank:
+			rank, drivers = next, []int{index}
+		case next == rank:
+			drivers = append(drivers, index)
+		}
+	}
+	if len(drivers) == 0 {
+		return nil, NewErrNoChanges(rng)
+	}
+	return drivers, nil
+}
+
+// bumpOp returns the bump operation of the given change using the given bump
+// operations of breaking changes and conventional commit types. Changes not
+// following the conventional commit types are bumped as patch.
+func bumpOp(change *verify.Change, ops map[string]string) string {
+	if change.Breaking != "" {
+		return ops[EnvVersionBump+bumpBreaking]
+	} else if change.Type != "" {
+		return ops[EnvVersionBump+strings.ToUpper(change.Type)]
+	}
+	return semver.BumpPatch
+}
+
+// next returns the next version for the given current version applying the
+// bump operation or the explicit version, and the build metadata. An error
+// is returned, if the bump operation fails or is a downgrade without force,
+// including the legacy decrements.
+func (params *bumpArgs) next(
+	current *semver.Version,
+) (*semver.Version, error) {
+	next := params.version
+	if next == nil && params.lower {
+		lowered, err := current.Lower(params.op)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // wrapped by caller.
+		}
+		next = lowered
+	} else if next == nil {
+		bumped, err := current.Bump(params.op, params.id)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // wrapped by caller.
+		}
+		next = bumped
+	}
+	if params.build != "" {
+		next.Build = strings.Split(params.build, ".")
+	}
+
+	if !params.force && next.Compare(current) < 0 {
+		return nil, NewErrDowngrade(current.String(), next.String())
+	}
+	return next, nil
+}
+
+// readVersion reads the current semantic version from the project `VERSION`
+// file, or returns the initial version, if the file does not exist. Short
+// versions, e.g. `1.2`, are completed. The version is also returned as
+// written to find it in the extra version files.
+func (gm *GoMake) readVersion() (*semver.Version, string, error) {
+	file := filepath.Join(gm.WorkDir, FileVersion)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		version, err := semver.Parse(VersionInitial)
+		return version, VersionInitial, err
+	} else if err != nil {
+		return nil, "", err //nolint:wrapcheck // wrapped by caller.
+	}
+
+	raw := strings.TrimSpace(string(data))
+	version, err := semver.ParseShort(raw)
+	return version, raw, err
+}
+
+// writeVersion writes the next version to the project `VERSION` file and
+// replaces the given current version by the next version in the given extra
+// version files. An extra version file may be followed by a `:<regex>`
+// pattern selecting the lines to search, and may be given multiple times.
+// All extra version files are checked to contain the current version exactly
+// once, before any file is written.
+func (gm *GoMake) writeVersion(
+	current string, next *semver.Version, files []string,
+) error {
+	paths, contents := []string{}, map[string]string{}
+	for _, entry := range files {
+		file, expr, _ := strings.Cut(entry, ":")
+		pattern, err := compileVersion(expr)
+		if err != nil {
+			return NewErrVersionFile(entry, err)
+		}
+
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(gm.WorkDir, file)
+		}
+		content, ok := contents[path]
+		if !ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err //nolint:wrapcheck // wrapped by caller.
+			}
+			content, paths = string(data), append(paths, path)
+		}
+
+		content, err = replaceVersion(entry, content, pattern,
+			current, next.String())
+		if err != nil {
+			return err
+		}
+		contents[path] = content
+	}
+
+	for _, path := range paths {
+		if err := os.WriteFile(path,
+			[]byte(contents[path]), 0o644); err != nil {
+			return err //nolint:wrapcheck // wrapped by caller.
+		}
+	}
+	//nolint:wrapcheck // wrapped by caller.
+	return os.WriteFile(filepath.Join(gm.WorkDir, FileVersion),
+		[]byte(next.String()+"\n"), 0o644)
+}
+
+// compileVersion compiles the given optional pattern selecting the lines of
+// an extra version file to search for the current version.
+func compileVersion(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr) //nolint:wrapcheck // wrapped by caller.
+}
+
+// replaceVersion replaces the given current version by the given next
+// version in the lines of the given content of the given extra version file
+// matching the given optional pattern. The current version must occur
+// exactly once as a whole version, i.e. not as part of a longer version like
+// `11.2.3` or `1.2.3-rc.1`.
+func replaceVersion(
+	file, content string, pattern *regexp.Regexp, current, next string,
+) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	matches := [][2]int{}
+	for index, line := range lines {
+		if pattern != nil && !pattern.MatchString(line) {
+			continue
+		}
+		for offset := 0; ; offset++ {
+			pos := strings.Index(line[offset:], current)
+			if pos < 0 {
+				break
+			}
+			offset += pos
+			if versionBounded(line, offset, offset+len(current)) {
+				matches = append(matches, [2]int{index, offset})
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", NewErrVersionMissing(file, current)
+	case 1:
+		line, offset := lines[matches[0][0]], matches[0][1]
+		lines[matches[0][0]] = line[:offset] + next +
+			line[offset+len(current):]
+		return strings.Join(lines, ""), nil
+	default:
+		return "", NewErrVersionAmbiguous(file, current, len(matches))
+	}
+}
+
+// versionBounded returns whether the version found at the given start and
+// end position of the given line is not part of a longer version.
+func versionBounded(line string, start, end int) bool {
+	if start > 0 && (isDigit(line[start-1]) || line[start-1] == '.') {
+		return false
+	} else if end == len(line) {
+		return true
+	}
+
+	switch next := line[end]; {
+	case isDigit(next) || next == '-' || next == '+' ||
+		('a' <= next && next <= 'z') || ('A' <= next && next <= 'Z'):
+		return false
+	case next == '.':
+		return end+1 == len(line) || !isDigit(line[end+1])
+	}
+	return true
+}
+
+// isDigit returns whether the given character is a decimal digit.
+func isDigit(char byte) bool {
+	return '0' <= char && char <= '9'
+}
@@ -0,0 +1,783 @@
+package make_test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/tkrop/go-make/internal/make"
+	"github.com/tkrop/go-make/internal/semver"
+	"github.com/tkrop/go-make/internal/verify"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// DirVersion returns the project directory of the version test case with
+// given name.
+func DirVersion(name string) string {
+	return filepath.Join(dirTargets, "version", name)
+}
+
+// EnvVersion returns the environment declaring the given extra version files.
+func EnvVersion(files string) []string {
+	return []string{EnvVersionFiles + "=" + files}
+}
+
+// filesChart contains the extra version files updating the version of a
+// package file and both versions of a helm chart file.
+var filesChart = "package.json " +
+	"chart/Chart.yaml:^version: chart/Chart.yaml:^appVersion:"
+
+// errPattern contains the error of compiling an invalid version pattern.
+var errPattern = func() error {
+	_, err := regexp.Compile("[")
+	return err
+}()
+
+// envBumpHuge contains the environment declaring an invalid bump operation.
+var envBumpHuge = []string{
+	EnvCommitConvention + "=feat fix",
+	EnvVersionBump + "BREAKING=" + semver.BumpMajor,
+	EnvVersionBump + "FEAT=huge",
+}
+
+// envBumpRules contains the environment declaring the default bump
+// operations without commit types, that are provided by the project rules.
+var envBumpRules = []string{
+	EnvVersionBump + "BREAKING=" + semver.BumpMajor,
+	EnvVersionBump + "FEAT=" + semver.BumpMinor,
+	EnvVersionBump + "FIX=" + semver.BumpPatch,
+}
+
+// EnvBump returns the environment declaring the commit types, the default
+// bump operations, and the given extra version files.
+func EnvBump(files string) []string {
+	return append(EnvVersion(files),
+		EnvCommitConvention+"=feat fix docs",
+		EnvVersionBump+"BREAKING="+semver.BumpMajor,
+		EnvVersionBump+"FEAT="+semver.BumpMinor,
+		EnvVersionBump+"FIX="+semver.BumpPatch,
+		EnvVersionBump+"DOCS="+semver.BumpPatch)
+}
+
+// LogBump returns a raw git log with the commits of the given titles using
+// commit hashes prefixed by the index of the title.
+func LogBump(titles ...string) string {
+	log := ""
+	for index, title := range titles {
+		log += fmt.Sprintf("commit %dabcdef123456\ntree abc123\n\n"+
+			"    %s\n\n", index, title)
+	}
+	return log
+}
+
+// VersionSetup sets up the working directory of the version test case with
+// given name and the config providing no extra version files.
+func VersionSetup(name string, env ...string) mock.SetupFunc {
+	dir := DirVersion(name)
+	setups := []func(*mock.Mocks) any{
+		Exec(CmdGitTop(dirWork, env...),
+			"nil", "builder", "stderr", dir, "", nil),
+	}
+	if len(env) == 0 {
+		setups = append(setups,
+			Exec(CmdTestDir(goMakeInfoBase, dir),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, dir),
+				"nil", "builder", "discard", "", "", nil))
+	}
+	return mock.Chain(setups...)
+}
+
+type VersionBumpParams struct {
+	mockSetup   mock.SetupFunc
+	dir         string
+	env         []string
+	args        []string
+	files       map[string]string
+	expectFiles map[string]string
+	expectError error
+	expectExit  int
+}
+
+var versionBumpTestCases = map[string]VersionBumpParams{
+	"bump default patch": {
+		mockSetup: mock.Chain(
+			VersionSetup("default"),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.2.4]"),
+		),
+		dir:         "default",
+		args:        []string{"go-make", "--version-bump"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.4\n"},
+	},
+	"bump without version file": {
+		mockSetup: mock.Chain(
+			VersionSetup("initial"),
+			LogMessage("stdout", "bumped version [0.0.0 => 0.1.0]"),
+		),
+		dir:         "initial",
+		args:        []string{"go-make", "--version-bump", "minor"},
+		files:       map[string]string{},
+		expectFiles: map[string]string{"VERSION": "0.1.0\n"},
+	},
+	"bump minor with extra files": {
+		mockSetup: mock.Chain(
+			VersionSetup("extra", EnvVersion(filesChart)...),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.3.0]"),
+		),
+		env:  EnvVersion(filesChart),
+		dir:  "extra",
+		args: []string{"go-make", "--version-bump", "minor"},
+		files: map[string]string{
+			"VERSION":          "1.2.3\n",
+			"package.json":     `{"name": "x", "version": "1.2.3"}`,
+			"chart/Chart.yaml": "version: 1.2.3\nappVersion: v1.2.3\n",
+		},
+		expectFiles: map[string]string{
+			"VERSION":          "1.3.0\n",
+			"package.json":     `{"name": "x", "version": "1.3.0"}`,
+			"chart/Chart.yaml": "version: 1.3.0\nappVersion: v1.3.0\n",
+		},
+	},
+	"bump prerelease with id": {
+		mockSetup: mock.Chain(
+			VersionSetup("prerelease"),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.2.4-rc.0]"),
+		),
+		dir:         "prerelease",
+		args:        []string{"go-make", "--version-bump", "prerelease", "rc"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.4-rc.0\n"},
+	},
+	"bump premajor release": {
+		mockSetup: mock.Chain(
+			VersionSetup("premajor"),
+			LogMessage("stdout", "bumped version [2.0.0-rc.3 => 2.0.0]"),
+		),
+		dir:         "premajor",
+		args:        []string{"go-make", "--version-bump", "major"},
+		files:       map[string]string{"VERSION": "2.0.0-rc.3\n"},
+		expectFiles: map[string]string{"VERSION": "2.0.0\n"},
+	},
+	"bump explicit version with build": {
+		mockSetup: mock.Chain(
+			VersionSetup("explicit"),
+			LogMessage("stdout", "bumped version [1.2.3 => 2.0.0+sha.5114f85]"),
+		),
+		dir: "explicit",
+		args: []string{
+			"go-make", "--version-bump", "2.0.0+build", "--build=sha.5114f85",
+		},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "2.0.0+sha.5114f85\n"},
+	},
+	"bump extra file whole version": {
+		mockSetup: mock.Chain(
+			VersionSetup("extra-whole", EnvVersion("package.json")...),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.2.4]"),
+		),
+		env:  EnvVersion("package.json"),
+		dir:  "extra-whole",
+		args: []string{"go-make", "--version-bump"},
+		files: map[string]string{
+			"VERSION": "1.2.3\n",
+			"package.json": `{"version": "1.2.3", "a": "11.2.3", ` +
+				`"b": "1.2.3-rc.1", "c": "1.2.3.4", "d": "v1.2.30"}`,
+		},
+		expectFiles: map[string]string{
+			"VERSION": "1.2.4\n",
+			"package.json": `{"version": "1.2.4", "a": "11.2.3", ` +
+				`"b": "1.2.3-rc.1", "c": "1.2.3.4", "d": "v1.2.30"}`,
+		},
+	},
+	"bump short version file": {
+		mockSetup: mock.Chain(
+			VersionSetup("short", EnvVersion("package.json")...),
+			LogMessage("stdout", "bumped version [1.2.0 => 1.2.1]"),
+		),
+		env:  EnvVersion("package.json"),
+		dir:  "short",
+		args: []string{"go-make", "--version-bump"},
+		files: map[string]string{
+			"VERSION":      "1.2\n",
+			"package.json": `{"version": "1.2"}`,
+		},
+		expectFiles: map[string]string{
+			"VERSION":      "1.2.1\n",
+			"package.json": `{"version": "1.2.1"}`,
+		},
+	},
+	"bump short explicit version": {
+		mockSetup: mock.Chain(
+			VersionSetup("short-explicit"),
+			LogMessage("stdout", "bumped version [1.2.3 => 2.0.0-rc.1]"),
+		),
+		dir:         "short-explicit",
+		args:        []string{"go-make", "--version-bump", "2-rc.1"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "2.0.0-rc.1\n"},
+	},
+	"bump legacy major": {
+		mockSetup: mock.Chain(
+			VersionSetup("legacy-major"),
+			LogMessage("stdout", "bumped version [1.2.3 => 2.0.0]"),
+		),
+		dir:         "legacy-major",
+		args:        []string{"go-make", "--version-bump", "major+"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "2.0.0\n"},
+	},
+	"bump legacy minor signs": {
+		mockSetup: mock.Chain(
+			VersionSetup("legacy-signs"),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.3.0]"),
+		),
+		dir:         "legacy-signs",
+		args:        []string{"go-make", "--version-bump", "++"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.3.0\n"},
+	},
+	"bump legacy patch decrement": {
+		mockSetup: mock.Chain(
+			VersionSetup("legacy-patch"),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.2.2]"),
+		),
+		dir:         "legacy-patch",
+		args:        []string{"go-make", "--version-bump", "--force", "patch-"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.2\n"},
+	},
+	"bump legacy major decrement signs": {
+		mockSetup: mock.Chain(
+			VersionSetup("legacy-down"),
+			LogMessage("stdout", "bumped version [2.2.3 => 1.0.0]"),
+		),
+		dir:         "legacy-down",
+		args:        []string{"go-make", "--version-bump", "--force", "---"},
+		files:       map[string]string{"VERSION": "2.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.0.0\n"},
+	},
+	"bump downgrade forced": {
+		mockSetup: mock.Chain(
+			VersionSetup("forced"),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.0.0]"),
+		),
+		dir:         "forced",
+		args:        []string{"go-make", "--version-bump", "--force", "1.0.0"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.0.0\n"},
+	},
+
+	"bump auto minor": {
+		mockSetup: mock.Chain(
+			VersionSetup("auto-minor", EnvBump("package.json")...),
+			Exec(CmdGitDescribe(DirVersion("auto-minor"),
+				EnvBump("package.json")...),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"}, DirVersion("auto-minor"),
+				EnvBump("package.json")...), "nil", "builder", "stderr",
+				LogBump("fix: repair bump (#3)", "feat: add bump (#2)",
+					"docs: describe bump (#1)"), "", nil),
+			LogMessage("stdout", "inferred minor bump "+
+				"[range=v1.2.3..HEAD, commits=3]"),
+			LogMessage("stdout", "  1abcdef feat: add bump (#2)"),
+			LogMessage("stdout", "bumped version [1.2.3 => 1.3.0]"),
+		),
+		env:  EnvBump("package.json"),
+		dir:  "auto-minor",
+		args: []string{"go-make", "--version-bump", "auto"},
+		files: map[string]string{
+			"VERSION":      "1.2.3\n",
+			"package.json": `{"version": "1.2.3"}`,
+		},
+		expectFiles: map[string]string{
+			"VERSION":      "1.3.0\n",
+			"package.json": `{"version": "1.3.0"}`,
+		},
+	},
+	"bump auto patch dry-run": {
+		mockSetup: mock.Chain(
+			VersionSetup("auto-patch", EnvBump("package.json")...),
+			Exec(CmdGitDescribe(DirVersion("auto-patch"),
+				EnvBump("package.json")...),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"}, DirVersion("auto-patch"),
+				EnvBump("package.json")...), "nil", "builder", "stderr",
+				LogBump("fix: repair bump (#2)", "update readme"), "", nil),
+			LogMessage("stdout", "inferred patch bump "+
+				"[range=v1.2.3..HEAD, commits=2]"),
+			LogMessage("stdout", "  0abcdef fix: repair bump (#2)"),
+			LogMessage("stdout", "  1abcdef update readme"),
+			LogMessage("stdout", "next version [1.2.3 => 1.2.4]"),
+		),
+		env:         EnvBump("package.json"),
+		dir:         "auto-patch",
+		args:        []string{"go-make", "--version-bump", "--dry-run", "auto"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+	},
+	"bump auto breaking from config": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirVersion("auto-config"), "", nil),
+			Exec(CmdGitDescribe(DirVersion("auto-config")),
+				"nil", "builder", "discard", "", "", assert.AnError),
+			Exec(CmdGitLog([]string{"HEAD"}, DirVersion("auto-config")),
+				"nil", "builder", "stderr", LogBump("feat!: drop bump (#2)",
+					"chore!: drop make (#1)"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirVersion("auto-config")),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, DirVersion("auto-config")),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvCommitConvention+" := feat chore\n", "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirVersion("auto-config")),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, DirVersion("auto-config")),
+				"nil", "builder", "discard", "# Variables\n\n# makefile\n"+
+					EnvVersionBump+"BREAKING := minor\n", "", nil),
+			LogMessage("stdout", "inferred minor bump "+
+				"[range=HEAD, commits=2]"),
+			LogMessage("stdout", "  0abcdef feat!: drop bump (#2)"),
+			LogMessage("stdout", "  1abcdef chore!: drop make (#1)"),
+			Exec(CmdTestDir(goMakeInfoBase, DirVersion("auto-config")),
+				"nil", "stderr", "stderr", "", "", nil),
+			Exec(CmdMakeDatabase(makeInfoBase, DirVersion("auto-config")),
+				"nil", "builder", "discard", "", "", nil),
+			LogMessage("stdout", "bumped version [0.9.1 => 0.10.0]"),
+		),
+		dir:         "auto-config",
+		args:        []string{"go-make", "--version-bump", "auto"},
+		files:       map[string]string{"VERSION": "0.9.1\n"},
+		expectFiles: map[string]string{"VERSION": "0.10.0\n"},
+	},
+	"bump auto with project rules": {
+		mockSetup: mock.Chain(
+			VersionSetup("auto-rules", envBumpRules...),
+			Exec(CmdGitDescribe(DirVersion("auto-rules"), envBumpRules...),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"}, DirVersion("auto-rules"),
+				envBumpRules...), "nil", "builder", "stderr",
+				LogBump("fix: repair bump (ABC-2)", "feat: add bump (ABC-1)"),
+				"", nil),
+			LogMessage("stdout", "inferred minor bump "+
+				"[range=v1.2.3..HEAD, commits=2]"),
+			LogMessage("stdout", "  1abcdef feat: add bump (ABC-1)"),
+			LogMessage("stdout", "next version [1.2.3 => 1.3.0]"),
+		),
+		env:  envBumpRules,
+		dir:  "auto-rules",
+		args: []string{"go-make", "--version-bump", "--dry-run", "auto"},
+		files: map[string]string{
+			"VERSION": "1.2.3\n",
+			FileGitVerify: `{"types": ["feat", "fix"], ` +
+				`"issue": "[A-Z]+-[0-9]+"}`,
+		},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+	},
+	"bump auto invalid rules": {
+		mockSetup: mock.Chain(
+			VersionSetup("auto-invalid", envBumpRules...),
+			Exec(CmdGitDescribe(DirVersion("auto-invalid"), envBumpRules...),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"},
+				DirVersion("auto-invalid"), envBumpRules...),
+				"nil", "builder", "stderr",
+				LogBump("feat: add bump (#1)"), "", nil),
+			LogError("stderr", "verify rules",
+				verify.NewErrRuleValue("title-min", "-1")),
+		),
+		env:  envBumpRules,
+		dir:  "auto-invalid",
+		args: []string{"go-make", "--version-bump", "auto"},
+		files: map[string]string{
+			"VERSION":     "1.2.3\n",
+			FileGitVerify: `{"types": ["feat"], "title-min": -1}`,
+		},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: verify.NewErrRuleValue("title-min", "-1"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump auto invalid op": {
+		mockSetup: mock.Chain(
+			VersionSetup("auto-op", envBumpHuge...),
+			Exec(CmdGitDescribe(DirVersion("auto-op"),
+				envBumpHuge...),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"}, DirVersion("auto-op"),
+				envBumpHuge...), "nil", "builder", "stderr",
+				LogBump("feat: add bump (#1)"), "", nil),
+			LogError("stderr", "infer version",
+				NewErrBumpOp(EnvVersionBump+"FEAT", "huge")),
+		),
+		env:         envBumpHuge,
+		dir:         "auto-op",
+		args:        []string{"go-make", "--version-bump", "auto"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: NewErrBumpOp(EnvVersionBump+"FEAT", "huge"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump auto no changes": {
+		mockSetup: mock.Chain(
+			VersionSetup("auto-none", EnvBump("package.json")...),
+			Exec(CmdGitDescribe(DirVersion("auto-none"),
+				EnvBump("package.json")...),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"}, DirVersion("auto-none"),
+				EnvBump("package.json")...), "nil", "builder", "stderr",
+				"", "", nil),
+			LogError("stderr", "infer version",
+				NewErrNoChanges("v1.2.3..HEAD")),
+		),
+		env:         EnvBump("package.json"),
+		dir:         "auto-none",
+		args:        []string{"go-make", "--version-bump", "auto"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: NewErrNoChanges("v1.2.3..HEAD"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump auto log failed": {
+		mockSetup: mock.Chain(
+			VersionSetup("auto-log", EnvBump("package.json")...),
+			Exec(CmdGitDescribe(DirVersion("auto-log"),
+				EnvBump("package.json")...),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"}, DirVersion("auto-log"),
+				EnvBump("package.json")...), "nil", "builder", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "read commits", NewErrCallFailed(
+				CmdGitLog([]string{"v1.2.3..HEAD"}, DirVersion("auto-log")),
+				assert.AnError)),
+		),
+		env:  EnvBump("package.json"),
+		dir:  "auto-log",
+		args: []string{"go-make", "--version-bump", "auto"},
+		expectError: NewErrCallFailed(CmdGitLog([]string{"v1.2.3..HEAD"},
+			DirVersion("auto-log")), assert.AnError),
+		expectExit: ExitCommandFailure,
+	},
+	"bump auto config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirVersion("auto-failed"), "", nil),
+			Exec(CmdGitDescribe(DirVersion("auto-failed")),
+				"nil", "builder", "discard", "v1.2.3\n", "", nil),
+			Exec(CmdGitLog([]string{"v1.2.3..HEAD"},
+				DirVersion("auto-failed")), "nil", "builder", "stderr",
+				LogBump("feat: add bump (#1)"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirVersion("auto-failed")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirVersion("auto-failed")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirVersion("auto-failed")), assert.AnError))),
+		),
+		dir:  "auto-failed",
+		args: []string{"go-make", "--version-bump", "auto"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirVersion("auto-failed")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+
+	"bump downgrade refused": {
+		mockSetup: mock.Chain(
+			VersionSetup("downgrade", EnvVersion("package.json")...),
+			LogError("stderr", "bump version",
+				NewErrDowngrade("1.2.3", "1.2.3-rc.1")),
+		),
+		env:         EnvVersion("package.json"),
+		dir:         "downgrade",
+		args:        []string{"go-make", "--version-bump", "1.2.3-rc.1"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: NewErrDowngrade("1.2.3", "1.2.3-rc.1"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump legacy decrement refused": {
+		mockSetup: mock.Chain(
+			VersionSetup("legacy-refused", EnvVersion("package.json")...),
+			LogError("stderr", "bump version",
+				NewErrDowngrade("1.2.3", "1.2.2")),
+		),
+		env:         EnvVersion("package.json"),
+		dir:         "legacy-refused",
+		args:        []string{"go-make", "--version-bump", "-"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: NewErrDowngrade("1.2.3", "1.2.2"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump invalid pre-release id": {
+		mockSetup: mock.Chain(
+			VersionSetup("invalid-id", EnvVersion("package.json")...),
+			LogError("stderr", "bump version", semver.NewErrInvalidBump(
+				semver.BumpPreRelease, "r_c")),
+		),
+		env:         EnvVersion("package.json"),
+		dir:         "invalid-id",
+		args:        []string{"go-make", "--version-bump", "prerelease", "r_c"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: semver.NewErrInvalidBump(semver.BumpPreRelease, "r_c"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump legacy decrement zero": {
+		mockSetup: mock.Chain(
+			VersionSetup("legacy-zero", EnvVersion("package.json")...),
+			LogError("stderr", "bump version",
+				semver.NewErrInvalidBump("minor-", "")),
+		),
+		env:         EnvVersion("package.json"),
+		dir:         "legacy-zero",
+		args:        []string{"go-make", "--version-bump", "--force", "minor-"},
+		files:       map[string]string{"VERSION": "1.0.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.0.3\n"},
+		expectError: semver.NewErrInvalidBump("minor-", ""),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump invalid version file": {
+		mockSetup: mock.Chain(
+			VersionSetup("invalid-file", EnvVersion("package.json")...),
+			LogError("stderr", "read version", semver.NewErrInvalid("1.2.x")),
+		),
+		env:         EnvVersion("package.json"),
+		dir:         "invalid-file",
+		args:        []string{"go-make", "--version-bump"},
+		files:       map[string]string{"VERSION": "1.2.x\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.x\n"},
+		expectError: semver.NewErrInvalid("1.2.x"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump unreadable version file": {
+		mockSetup: mock.Chain(
+			VersionSetup("unreadable", EnvVersion("package.json")...),
+			LogError("stderr", "read version", &fs.PathError{
+				Op: "read", Path: filepath.Join(DirVersion("unreadable"),
+					"VERSION"), Err: syscall.EISDIR,
+			}),
+		),
+		env:   EnvVersion("package.json"),
+		dir:   "unreadable",
+		args:  []string{"go-make", "--version-bump"},
+		files: map[string]string{"VERSION/file": "1.2.3\n"},
+		expectError: &fs.PathError{
+			Op: "read", Path: filepath.Join(DirVersion("unreadable"),
+				"VERSION"), Err: syscall.EISDIR,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"bump extra file without version": {
+		mockSetup: mock.Chain(
+			VersionSetup("extra-version", EnvVersion("package.json")...),
+			LogError("stderr", "write version",
+				NewErrVersionMissing("package.json", "1.2.3")),
+		),
+		env:  EnvVersion("package.json"),
+		dir:  "extra-version",
+		args: []string{"go-make", "--version-bump"},
+		files: map[string]string{
+			"VERSION":      "1.2.3\n",
+			"package.json": `{"version": "1.2.2"}`,
+		},
+		expectFiles: map[string]string{
+			"VERSION":      "1.2.3\n",
+			"package.json": `{"version": "1.2.2"}`,
+		},
+		expectError: NewErrVersionMissing("package.json", "1.2.3"),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump extra file ambiguous": {
+		mockSetup: mock.Chain(
+			VersionSetup("extra-ambiguous", EnvVersion("package.json")...),
+			LogError("stderr", "write version",
+				NewErrVersionAmbiguous("package.json", "1.2.3", 2)),
+		),
+		env:  EnvVersion("package.json"),
+		dir:  "extra-ambiguous",
+		args: []string{"go-make", "--version-bump"},
+		files: map[string]string{
+			"VERSION":      "1.2.3\n",
+			"package.json": `{"version": "1.2.3", "dep": "^1.2.3"}`,
+		},
+		expectFiles: map[string]string{
+			"VERSION":      "1.2.3\n",
+			"package.json": `{"version": "1.2.3", "dep": "^1.2.3"}`,
+		},
+		expectError: NewErrVersionAmbiguous("package.json", "1.2.3", 2),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump extra file pattern without version": {
+		mockSetup: mock.Chain(
+			VersionSetup("extra-pattern", EnvVersion(filesChart)...),
+			LogError("stderr", "write version", NewErrVersionMissing(
+				"chart/Chart.yaml:^appVersion:", "1.2.3")),
+		),
+		env:  EnvVersion(filesChart),
+		dir:  "extra-pattern",
+		args: []string{"go-make", "--version-bump"},
+		files: map[string]string{
+			"VERSION":          "1.2.3\n",
+			"package.json":     `{"version": "1.2.3"}`,
+			"chart/Chart.yaml": "version: 1.2.3\nappVersion: 1.0.0\n",
+		},
+		expectFiles: map[string]string{
+			"VERSION":          "1.2.3\n",
+			"package.json":     `{"version": "1.2.3"}`,
+			"chart/Chart.yaml": "version: 1.2.3\nappVersion: 1.0.0\n",
+		},
+		expectError: NewErrVersionMissing(
+			"chart/Chart.yaml:^appVersion:", "1.2.3"),
+		expectExit: ExitCommandFailure,
+	},
+	"bump extra file invalid pattern": {
+		mockSetup: mock.Chain(
+			VersionSetup("extra-invalid", EnvVersion("package.json:[")...),
+			LogError("stderr", "write version",
+				NewErrVersionFile("package.json:[", errPattern)),
+		),
+		env:         EnvVersion("package.json:["),
+		dir:         "extra-invalid",
+		args:        []string{"go-make", "--version-bump"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: NewErrVersionFile("package.json:[", errPattern),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump extra file missing": {
+		mockSetup: mock.Chain(
+			VersionSetup("extra-missing", EnvVersion("package.json")...),
+			LogError("stderr", "write version", &fs.PathError{
+				Op: "open", Path: filepath.Join(DirVersion("extra-missing"),
+					"package.json"), Err: syscall.ENOENT,
+			}),
+		),
+		env:         EnvVersion("package.json"),
+		dir:         "extra-missing",
+		args:        []string{"go-make", "--version-bump"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: &fs.PathError{
+			Op: "open", Path: filepath.Join(DirVersion("extra-missing"),
+				"package.json"), Err: syscall.ENOENT,
+		},
+		expectExit: ExitCommandFailure,
+	},
+	"bump config failed": {
+		mockSetup: mock.Chain(
+			Exec(CmdGitTop(dirWork), "nil", "builder", "stderr",
+				DirVersion("config"), "", nil),
+			Exec(CmdTestDir(goMakeInfoBase, DirVersion("config")),
+				"nil", "stderr", "stderr", "", "", assert.AnError),
+			Exec(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirVersion("config")), "nil", "stderr", "stderr",
+				"", "", assert.AnError),
+			LogError("stderr", "ensure config", NewErrNotFound(
+				infoBase.Path, infoBase.Version, NewErrCallFailed(
+					CmdGoInstall(infoBase.Path, infoBase.Version,
+						DirVersion("config")), assert.AnError))),
+		),
+		dir:         "config",
+		args:        []string{"go-make", "--version-bump"},
+		files:       map[string]string{"VERSION": "1.2.3\n"},
+		expectFiles: map[string]string{"VERSION": "1.2.3\n"},
+		expectError: NewErrNotFound(infoBase.Path, infoBase.Version,
+			NewErrCallFailed(CmdGoInstall(infoBase.Path, infoBase.Version,
+				DirVersion("config")), assert.AnError)),
+		expectExit: ExitConfigFailure,
+	},
+
+	"bump invalid version arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-bump", NewErrInvalidArg(
+				CmdVersionBump, "micro", semver.NewErrInvalid("micro"))),
+		),
+		args: []string{"go-make", "--version-bump", "micro"},
+		expectError: NewErrInvalidArg(CmdVersionBump, "micro",
+			semver.NewErrInvalid("micro")),
+		expectExit: ExitCommandFailure,
+	},
+	"bump invalid build arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-bump", NewErrInvalidArg(
+				CmdVersionBump, "--build=a..b",
+				semver.NewErrInvalid("0.0.0+a..b"))),
+		),
+		args: []string{"go-make", "--version-bump", "--build=a..b"},
+		expectError: NewErrInvalidArg(CmdVersionBump, "--build=a..b",
+			semver.NewErrInvalid("0.0.0+a..b")),
+		expectExit: ExitCommandFailure,
+	},
+	"bump invalid extra arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-bump",
+				NewErrInvalidArg(CmdVersionBump, "rc", nil)),
+		),
+		args:        []string{"go-make", "--version-bump", "patch", "rc"},
+		expectError: NewErrInvalidArg(CmdVersionBump, "rc", nil),
+		expectExit:  ExitCommandFailure,
+	},
+	"bump invalid legacy arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-bump", NewErrInvalidArg(
+				CmdVersionBump, "++++", semver.NewErrInvalid("++++"))),
+		),
+		args: []string{"go-make", "--version-bump", "++++"},
+		expectError: NewErrInvalidArg(CmdVersionBump, "++++",
+			semver.NewErrInvalid("++++")),
+		expectExit: ExitCommandFailure,
+	},
+	"bump invalid auto arg": {
+		mockSetup: mock.Chain(
+			LogError("stderr", "parse version-bump",
+				NewErrInvalidArg(CmdVersionBump, "minor", nil)),
+		),
+		args:        []string{"go-make", "--version-bump", "auto", "minor"},
+		expectError: NewErrInvalidArg(CmdVersionBump, "minor", nil),
+		expectExit:  ExitCommandFailure,
+	},
+}
+
+func TestVersionBump(t *testing.T) {
+	test.Map(t, versionBumpTestCases).
+		Run(func(t test.Test, param VersionBumpParams) {
+			// Given
+			dir := DirVersion(param.dir)
+			assert.NoError(t, os.RemoveAll(dir))
+			assert.NoError(t, os.MkdirAll(dir, 0o750))
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for name, content := range param.files {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				WriteFile(path, 0o600, content)
+			}
+			gm, _ := GoMakeSetup(t, MakeParams{
+				mockSetup: param.mockSetup,
+				info:      infoBase,
+				env:       param.env,
+			})
+
+			// When
+			exit, err := gm.Make(param.args...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectExit, exit)
+			for name, content := range param.expectFiles {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				assert.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+		})
+}
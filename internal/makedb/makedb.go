@@ -0,0 +1,380 @@
+// Package makedb provides a parser for the data base printed by `make
+// --print-data-base` and the long options printed by `make --help`.
+package makedb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Origin represents the origin of a variable definition.
+type Origin string
+
+const (
+	// OriginDefault represents a variable defined by default.
+	OriginDefault Origin = "default"
+	// OriginEnvironment represents a variable defined by the environment.
+	OriginEnvironment Origin = "environment"
+	// OriginEnvironmentOverride represents a variable defined by the
+	// environment overriding the makefile (`--environment-overrides`).
+	OriginEnvironmentOverride Origin = "environment override"
+	// OriginFile represents a variable defined in a makefile.
+	OriginFile Origin = "makefile"
+	// OriginCommandLine represents a variable defined on the command line.
+	OriginCommandLine Origin = "command line"
+	// OriginOverride represents a variable defined via `override` directive.
+	OriginOverride Origin = "override"
+	// OriginAutomatic represents an automatic variable.
+	OriginAutomatic Origin = "automatic"
+)
+
+// Variable represents a variable definition of the make data base.
+type Variable struct {
+	// Name provides the name of the variable.
+	Name string
+	// Value provides the unexpanded value of the variable.
+	Value string
+	// Origin provides the origin of the variable definition.
+	Origin Origin
+	// Recursive indicates whether the variable is recursively expanded.
+	Recursive bool
+	// File provides the makefile defining the variable, if known.
+	File string
+	// Line provides the line of the variable definition, if known.
+	Line int
+}
+
+// Rule represents a file rule of the make data base.
+type Rule struct {
+	// Target provides the target name of the rule.
+	Target string
+	// Prerequisites provides the normal prerequisites of the rule.
+	Prerequisites []string
+	// OrderOnly provides the order-only prerequisites of the rule.
+	OrderOnly []string
+	// DoubleColon indicates whether the rule is a double-colon rule.
+	DoubleColon bool
+	// Phony indicates whether the target is a prerequisite of `.PHONY`.
+	Phony bool
+	// NotTarget indicates whether the file is not a target, i.e. a makefile
+	// or a prerequisite without rule.
+	NotTarget bool
+	// Recipe provides the recipe lines of the rule.
+	Recipe []string
+	// File provides the makefile defining the recipe, if known.
+	File string
+	// Line provides the line of the recipe, if known.
+	Line int
+}
+
+// Database represents the parsed make data base.
+type Database struct {
+	// Variables provides the global variables in order of appearance.
+	Variables []*Variable
+	// Rules provides the file rules in order of appearance.
+	Rules []*Rule
+	// Implicit provides the implicit, i.e. pattern, rules in order of
+	// appearance.
+	Implicit []*Rule
+}
+
+// ErrParse represents a make data base parse failure.
+var ErrParse = errors.New("parse failed")
+
+// NewErrParse wraps the error of a failed make data base parse operation.
+func NewErrParse(line int, err error) error {
+	return fmt.Errorf("%w [line=%d]: %w", ErrParse, line, err)
+}
+
+// section represents the section of the make data base.
+type section int
+
+const (
+	// sectionOther represents a section that is not parsed.
+	sectionOther section = iota
+	// sectionVariables represents the global variables section.
+	sectionVariables
+	// sectionFiles represents the file rules section.
+	sectionFiles
+	// sectionImplicit represents the implicit rules section.
+	sectionImplicit
+)
+
+var (
+	// regexOrigin matches the origin comment of a variable definition.
+	regexOrigin = regexp.MustCompile(`^# (default|environment override|` +
+		`environment|makefile|command line|'override' directive|automatic)` +
+		`(?: \(from '(.*)', line ([0-9]+)\))?$`)
+	// regexRecipe matches the comment announcing the recipe of a rule.
+	regexRecipe = regexp.MustCompile(
+		`^#  recipe to execute \(from '(.*)', line ([0-9]+)\):$`)
+	// regexOption matches the long options in the `make --help` output.
+	regexOption = regexp.MustCompile(`--[^, \s]*`)
+	// regexRule matches a rule line splitting the target from the
+	// prerequisites at the first single or double colon followed by a white
+	// space or the line end, since target names may contain colons.
+	regexRule = regexp.MustCompile(`^(.+?)(::?)(?:\s+(.*))?$`)
+)
+
+// parser contains the state of the make data base parser.
+type parser struct {
+	// db provides the parsed make data base.
+	db *Database
+	// section provides the current section.
+	section section
+	// variable provides the pending variable of an origin comment.
+	variable *Variable
+	// define provides the pending multi-line variable definition.
+	define *Variable
+	// lines provides the collected lines of a multi-line definition.
+	lines []string
+	// rule provides the current rule of the files section.
+	rule *Rule
+	// notTarget indicates whether the next rule is not a target.
+	notTarget bool
+}
+
+// Parse parses the make data base printed by `make --print-data-base` from
+// the given reader. It extracts the global variables with their origins, and
+// the file and implicit rules with their prerequisites, while all other
+// sections, e.g. directories and statistics, are skipped.
+func Parse(reader io.Reader) (*Database, error) {
+	p := &parser{db: &Database{}}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		if err := p.parse(scanner.Text()); err != nil {
+			return nil, NewErrParse(number, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewErrParse(0, err)
+	}
+	return p.db, nil
+}
+
+// parse parses the given line of the make data base.
+func (p *parser) parse(line string) error {
+	if p.define != nil {
+		p.parseDefine(line)
+		return nil
+	}
+
+	switch line {
+	case "# Variables":
+		p.section = sectionVariables
+		return nil
+	case "# Files":
+		p.section, p.rule = sectionFiles, nil
+		return nil
+	case "# Implicit Rules":
+		p.section, p.rule = sectionImplicit, nil
+		return nil
+	case "# Pattern-specific Variable Values", "# Directories",
+		"# files hash-table stats:":
+		p.section, p.rule = sectionOther, nil
+		return nil
+	}
+
+	switch p.section {
+	case sectionVariables:
+		return p.parseVariable(line)
+	case sectionFiles, sectionImplicit:
+		return p.parseFile(line)
+	case sectionOther:
+	}
+	return nil
+}
+
+// parseVariable parses the given line of the variables section.
+func (p *parser) parseVariable(line string) error {
+	if line == "# variable set hash-table stats:" {
+		p.section = sectionOther
+		return nil
+	}
+
+	if variable := parseOrigin(line); variable != nil {
+		p.variable = variable
+		return nil
+	} else if p.variable == nil || line == "" || line[0] == '#' {
+		return nil
+	}
+
+	variable := p.variable
+	p.variable = nil
+	if name, ok := strings.CutPrefix(line, "define "); ok {
+		fields := strings.Fields(name)
+		variable.Name = fields[0]
+		variable.Recursive = len(fields) == 1 || fields[1] == "="
+		p.define, p.lines = variable, nil
+		return nil
+	}
+
+	name, value, ok := strings.Cut(line, " ")
+	if !ok {
+		return fmt.Errorf("invalid variable [%s]", line)
+	}
+	switch {
+	case strings.HasPrefix(value, ":="):
+		variable.Value = strings.TrimPrefix(value[2:], " ")
+	case strings.HasPrefix(value, "="):
+		variable.Value = strings.TrimPrefix(value[1:], " ")
+		variable.Recursive = true
+	default:
+		return fmt.Errorf("invalid variable [%s]", line)
+	}
+	variable.Name = name
+	p.db.Variables = append(p.db.Variables, variable)
+	return nil
+}
+
+// parseDefine parses the given line of a multi-line variable definition.
+func (p *parser) parseDefine(line string) {
+	if line != "endef" {
+		p.lines = append(p.lines, line)
+		return
+	}
+	p.define.Value = strings.Join(p.lines, "\n")
+	p.db.Variables = append(p.db.Variables, p.define)
+	p.define, p.lines = nil, nil
+}
+
+// parseOrigin parses the given origin comment returning a new variable with
+// the origin, file, and line information, or nil if the line is no origin.
+func parseOrigin(line string) *Variable {
+	match := regexOrigin.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	variable := &Variable{Origin: Origin(match[1]), File: match[2]}
+	if variable.Origin == "'override' directive" {
+		variable.Origin = OriginOverride
+	}
+	if match[3] != "" {
+		variable.Line, _ = strconv.Atoi(match[3])
+	}
+	return variable
+}
+
+// parseFile parses the given line of the files or the implicit rules section.
+func (p *parser) parseFile(line string) error {
+	switch {
+	case line == "":
+		p.rule, p.variable, p.notTarget = nil, nil, false
+	case line == "# Not a target:":
+		p.notTarget = true
+	case p.rule == nil && parseOrigin(line) != nil:
+		// Target-specific variable announced before the rule.
+		p.variable = &Variable{}
+	case p.rule == nil && p.variable != nil:
+		// Skip target-specific variable definition.
+		p.variable = nil
+	case p.rule == nil && line[0] != '#' && line[0] != '\t':
+		rule, err := parseRule(line)
+		if err != nil {
+			return err
+		}
+		rule.NotTarget = p.notTarget
+		p.rule = rule
+		if p.section == sectionImplicit {
+			p.db.Implicit = append(p.db.Implicit, rule)
+		} else {
+			p.db.Rules = append(p.db.Rules, rule)
+		}
+	case p.rule == nil:
+	case line == "#  Phony target (prerequisite of .PHONY).":
+		p.rule.Phony = true
+	case line[0] == '\t':
+		p.rule.Recipe = append(p.rule.Recipe, line[1:])
+	default:
+		if match := regexRecipe.FindStringSubmatch(line); match != nil {
+			p.rule.File = match[1]
+			p.rule.Line, _ = strconv.Atoi(match[2])
+		}
+	}
+	return nil
+}
+
+// parseRule parses the given rule line, i.e. the target followed by a single
+// or double colon, the normal prerequisites, and the order-only prerequisites.
+func parseRule(line string) (*Rule, error) {
+	match := regexRule.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("invalid rule [%s]", line)
+	}
+
+	rule := &Rule{Target: match[1], DoubleColon: match[2] == "::"}
+	normal, order, _ := strings.Cut(match[3], "|")
+	rule.Prerequisites = strings.Fields(normal)
+	rule.OrderOnly = strings.Fields(order)
+	return rule, nil
+}
+
+// Variable returns the global variable with given name, or nil if the
+// variable is not defined.
+func (db *Database) Variable(name string) *Variable {
+	for _, variable := range db.Variables {
+		if variable.Name == name {
+			return variable
+		}
+	}
+	return nil
+}
+
+// Targets returns the sorted unique names of all targets that can be called
+// explicitly, i.e. excluding files that are not targets, as well as special
+// targets and absolute file targets starting with `.` or `/`.
+func (db *Database) Targets() []string {
+	targets := make([]string, 0, len(db.Rules))
+	for _, rule := range db.Rules {
+		if !rule.NotTarget && !strings.HasPrefix(rule.Target, ".") &&
+			!strings.HasPrefix(rule.Target, "/") {
+			targets = append(targets, rule.Target)
+		}
+	}
+	slices.Sort(targets)
+	return slices.Compact(targets)
+}
+
+// Patterns returns the sorted unique target patterns of all implicit rules,
+// e.g. `%.o`, matching the targets make can build via pattern rules.
+func (db *Database) Patterns() []string {
+	patterns := make([]string, 0, len(db.Implicit))
+	for _, rule := range db.Implicit {
+		patterns = append(patterns, strings.Fields(rule.Target)...)
+	}
+	slices.Sort(patterns)
+	return slices.Compact(patterns)
+}
+
+// Options parses the long options from the `make --help` output provided
+// by the given reader. Options with a required argument are returned with
+// a trailing `=`, while options with an optional argument are returned both
+// with and without trailing `=`.
+func Options(reader io.Reader) ([]string, error) {
+	options := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		for _, option := range regexOption.FindAllString(scanner.Text(), -1) {
+			if name, _, ok := strings.Cut(option, "[="); ok {
+				options = append(options, name, name+"=")
+			} else if name, _, ok := strings.Cut(option, "="); ok {
+				options = append(options, name+"=")
+			} else {
+				options = append(options, option)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewErrParse(0, err)
+	}
+	slices.Sort(options)
+	return slices.Compact(options), nil
+}
@@ -0,0 +1,369 @@
+package makedb_test
+
+import (
+	"embed"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/makedb"
+	"github.com/tkrop/go-testing/test"
+)
+
+//go:embed fixtures/*
+var fixtures embed.FS
+
+// ReadFile reads the given fixture file and returns its content as string.
+func ReadFile(name string) string {
+	content, err := fixtures.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(content)
+}
+
+var (
+	// dbFixture contains the recorded make data base of `Makefile.test`.
+	dbFixture = ReadFile("fixtures/database.out")
+	// helpFixture contains the recorded `make --help` output.
+	helpFixture = ReadFile("fixtures/help.out")
+)
+
+type ParseVariableParams struct {
+	name           string
+	expectVariable *makedb.Variable
+}
+
+var parseVariableTestCases = map[string]ParseVariableParams{
+	"simple": {
+		name: "SIMPLE",
+		expectVariable: &makedb.Variable{
+			Name: "SIMPLE", Value: "simple value",
+			Origin: makedb.OriginFile, File: "Makefile.test", Line: 1,
+		},
+	},
+	"recursive": {
+		name: "RECURSIVE",
+		expectVariable: &makedb.Variable{
+			Name: "RECURSIVE", Value: "$(SIMPLE) recursive", Recursive: true,
+			Origin: makedb.OriginFile, File: "Makefile.test", Line: 2,
+		},
+	},
+	"append": {
+		name: "APPEND",
+		expectVariable: &makedb.Variable{
+			Name: "APPEND", Value: "one two",
+			Origin: makedb.OriginFile, File: "Makefile.test", Line: 4,
+		},
+	},
+	"override": {
+		name: "OVER",
+		expectVariable: &makedb.Variable{
+			Name: "OVER", Value: "forced",
+			Origin: makedb.OriginOverride, File: "Makefile.test", Line: 5,
+		},
+	},
+	"define": {
+		name: "MULTI",
+		expectVariable: &makedb.Variable{
+			Name: "MULTI", Value: "line one\nline two", Recursive: true,
+			Origin: makedb.OriginFile, File: "Makefile.test", Line: 6,
+		},
+	},
+	"exported": {
+		name: "EXPORTED",
+		expectVariable: &makedb.Variable{
+			Name: "EXPORTED", Value: "exported", Recursive: true,
+			Origin: makedb.OriginFile, File: "Makefile.test", Line: 10,
+		},
+	},
+	"command line": {
+		name: "CLI",
+		expectVariable: &makedb.Variable{
+			Name: "CLI", Value: "cmd", Recursive: true,
+			Origin: makedb.OriginCommandLine,
+		},
+	},
+	"environment": {
+		name: "HOME",
+		expectVariable: &makedb.Variable{
+			Name: "HOME", Value: "/home/user", Recursive: true,
+			Origin: makedb.OriginEnvironment,
+		},
+	},
+	"default": {
+		name: "SHELL",
+		expectVariable: &makedb.Variable{
+			Name: "SHELL", Value: "/bin/sh", Origin: makedb.OriginDefault,
+		},
+	},
+	"automatic": {
+		name: "@D",
+		expectVariable: &makedb.Variable{
+			Name: "@D", Value: "$(patsubst %/,%,$(dir $@))", Recursive: true,
+			Origin: makedb.OriginAutomatic,
+		},
+	},
+	"makefile list": {
+		name: "MAKEFILE_LIST",
+		expectVariable: &makedb.Variable{
+			Name: "MAKEFILE_LIST", Value: "Makefile.test",
+			Origin: makedb.OriginFile, File: "Makefile.test", Line: 1,
+		},
+	},
+	"empty": {
+		name: "GNUMAKEFLAGS",
+		expectVariable: &makedb.Variable{
+			Name: "GNUMAKEFLAGS", Origin: makedb.OriginOverride,
+		},
+	},
+	"target-specific": {
+		name: "TVAR",
+	},
+	"undefined": {
+		name: "UNDEFINED",
+	},
+}
+
+func TestParseVariable(t *testing.T) {
+	db, err := makedb.Parse(strings.NewReader(dbFixture))
+	assert.NoError(t, err)
+
+	test.Map(t, parseVariableTestCases).
+		Run(func(t test.Test, param ParseVariableParams) {
+			// When
+			variable := db.Variable(param.name)
+
+			// Then
+			assert.Equal(t, param.expectVariable, variable)
+		})
+}
+
+type ParseRuleParams struct {
+	target      string
+	expectRules []*makedb.Rule
+}
+
+var parseRuleTestCases = map[string]ParseRuleParams{
+	"phony with prerequisites": {
+		target: "all",
+		expectRules: []*makedb.Rule{{
+			Target: "all", Prerequisites: []string{"build", "test"},
+			OrderOnly: []string{}, Phony: true,
+		}},
+	},
+	"order-only prerequisites": {
+		target: "build",
+		expectRules: []*makedb.Rule{{
+			Target: "build", Prerequisites: []string{"main.go"},
+			OrderOnly: []string{"out"}, Phony: true,
+			Recipe: []string{"@echo build"}, File: "Makefile.test", Line: 15,
+		}},
+	},
+	"double-colon": {
+		target: "test",
+		expectRules: []*makedb.Rule{{
+			Target: "test", Prerequisites: []string{"build"},
+			OrderOnly: []string{}, DoubleColon: true, Phony: true,
+			Recipe: []string{"@echo test"}, File: "Makefile.test", Line: 17,
+		}, {
+			Target: "test", Prerequisites: []string{"lint"},
+			OrderOnly: []string{}, DoubleColon: true, Phony: true,
+			Recipe: []string{"@echo more"}, File: "Makefile.test", Line: 19,
+		}},
+	},
+	"inline recipe": {
+		target: "lint",
+		expectRules: []*makedb.Rule{{
+			Target: "lint", Prerequisites: []string{}, OrderOnly: []string{},
+			Recipe: []string{" @echo $@"}, File: "Makefile.test", Line: 27,
+		}},
+	},
+	"target-specific variable": {
+		target: "target-var",
+		expectRules: []*makedb.Rule{{
+			Target: "target-var", Prerequisites: []string{},
+			OrderOnly: []string{}, Recipe: []string{"@echo $(TVAR)"},
+			File: "Makefile.test", Line: 26,
+		}},
+	},
+	"automatic variables": {
+		target: "out",
+		expectRules: []*makedb.Rule{{
+			Target: "out", Prerequisites: []string{}, OrderOnly: []string{},
+			Recipe: []string{"mkdir -p out"}, File: "Makefile.test", Line: 21,
+		}},
+	},
+	"not a target": {
+		target: "main.go",
+		expectRules: []*makedb.Rule{{
+			Target: "main.go", Prerequisites: []string{},
+			OrderOnly: []string{}, NotTarget: true,
+		}},
+	},
+	"implicit rule": {
+		target: "%.o",
+	},
+}
+
+func TestParseRule(t *testing.T) {
+	db, err := makedb.Parse(strings.NewReader(dbFixture))
+	assert.NoError(t, err)
+
+	test.Map(t, parseRuleTestCases).
+		Run(func(t test.Test, param ParseRuleParams) {
+			// When
+			var rules []*makedb.Rule
+			for _, rule := range db.Rules {
+				if rule.Target == param.target {
+					rules = append(rules, rule)
+				}
+			}
+
+			// Then
+			assert.Equal(t, param.expectRules, rules)
+		})
+}
+
+type ParseParams struct {
+	reader         io.Reader
+	expectTargets  []string
+	expectRules    []*makedb.Rule
+	expectPatterns []string
+	expectError    error
+}
+
+var parseTestCases = map[string]ParseParams{
+	"empty": {
+		reader:         strings.NewReader(""),
+		expectTargets:  []string{},
+		expectPatterns: []string{},
+	},
+	"recorded": {
+		reader: strings.NewReader(dbFixture),
+		expectTargets: []string{
+			"all", "build", "clean", "lint", "out", "target-var", "test",
+		},
+		expectPatterns: []string{"%.o"},
+	},
+	"implicit rules": {
+		reader: strings.NewReader("# Implicit Rules\n\n" +
+			"%.o %.d: %.c\n#  recipe to execute (from 'Makefile', line 3):\n" +
+			"\tcc -c $<\n\nbin/%: %.o | bin\n\n" +
+			"# 2 implicit rules, 0 (0.0%) terminal.\n# Files\n\nall:\n"),
+		expectTargets:  []string{"all"},
+		expectPatterns: []string{"%.d", "%.o", "bin/%"},
+	},
+	"target with colons": {
+		reader: strings.NewReader("# Files\n\n" +
+			"foo:bar: baz qux:x | out:dir\n\nqux:x:\n\nrun:a:: baz\n"),
+		expectTargets:  []string{"foo:bar", "qux:x", "run:a"},
+		expectPatterns: []string{},
+		expectRules: []*makedb.Rule{{
+			Target: "foo:bar", Prerequisites: []string{"baz", "qux:x"},
+			OrderOnly: []string{"out:dir"},
+		}, {
+			Target: "qux:x", Prerequisites: []string{}, OrderOnly: []string{},
+		}, {
+			Target: "run:a", Prerequisites: []string{"baz"},
+			OrderOnly: []string{}, DoubleColon: true,
+		}},
+	},
+	"invalid variable": {
+		reader: strings.NewReader("# Variables\n\n" +
+			"# makefile (from 'Makefile', line 1)\nINVALID\n"),
+		expectError: makedb.NewErrParse(4,
+			errors.New("invalid variable [INVALID]")),
+	},
+	"invalid variable operator": {
+		reader: strings.NewReader("# Variables\n\n" +
+			"# default\nINVALID ?= value\n"),
+		expectError: makedb.NewErrParse(4,
+			errors.New("invalid variable [INVALID ?= value]")),
+	},
+	"invalid rule": {
+		reader: strings.NewReader("# Files\n\ninvalid\n"),
+		expectError: makedb.NewErrParse(3,
+			errors.New("invalid rule [invalid]")),
+	},
+	"read failure": {
+		reader:      iotest.ErrReader(assert.AnError),
+		expectError: makedb.NewErrParse(0, assert.AnError),
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, parseTestCases).
+		Run(func(t test.Test, param ParseParams) {
+			// When
+			db, err := makedb.Parse(param.reader)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectError == nil {
+				assert.Equal(t, param.expectTargets, db.Targets())
+				assert.Equal(t, param.expectPatterns, db.Patterns())
+			}
+			if param.expectRules != nil {
+				assert.Equal(t, param.expectRules, db.Rules)
+			}
+		})
+}
+
+type OptionsParams struct {
+	reader        io.Reader
+	expectOptions []string
+	expectError   error
+}
+
+var optionsTestCases = map[string]OptionsParams{
+	"empty": {
+		reader:        strings.NewReader(""),
+		expectOptions: []string{},
+	},
+	"options": {
+		reader: strings.NewReader("  -f FILE, --file=FILE, --makefile=FILE\n" +
+			"  --debug[=FLAGS]             Print debugging information.\n" +
+			"  -h, --help                  Print this message and exit.\n"),
+		expectOptions: []string{
+			"--debug", "--debug=", "--file=", "--help", "--makefile=",
+		},
+	},
+	"recorded": {
+		reader: strings.NewReader(helpFixture),
+		expectOptions: []string{
+			"--always-make", "--assume-new=", "--assume-old=",
+			"--check-symlink-times", "--debug", "--debug=", "--directory=",
+			"--dry-run", "--environment-overrides", "--eval=", "--file=",
+			"--help", "--ignore-errors", "--include-dir=", "--jobs",
+			"--jobs=", "--just-print", "--keep-going", "--load-average",
+			"--load-average=", "--makefile=", "--max-load", "--max-load=",
+			"--new-file=", "--no-builtin-rules", "--no-builtin-variables",
+			"--no-keep-going", "--no-print-directory", "--no-silent",
+			"--old-file=", "--output-sync", "--output-sync=",
+			"--print-data-base", "--print-directory", "--question",
+			"--quiet", "--recon", "--silent", "--stop", "--touch", "--trace",
+			"--version", "--warn-undefined-variables", "--what-if=",
+		},
+	},
+	"read failure": {
+		reader:      iotest.ErrReader(assert.AnError),
+		expectError: makedb.NewErrParse(0, assert.AnError),
+	},
+}
+
+func TestOptions(t *testing.T) {
+	test.Map(t, optionsTestCases).
+		Run(func(t test.Test, param OptionsParams) {
+			// When
+			options, err := makedb.Options(param.reader)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectOptions, options)
+		})
+}
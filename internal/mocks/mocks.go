@@ -0,0 +1,450 @@
+// Package mocks provides the planning of the mock generation for the
+// `//go:generate mockgen` and `//go:generate mock` directives of a project.
+// The directives are parsed from the go source files, their source and
+// destination paths are resolved relative to the project root, and the
+// staleness of the generated mocks is computed from content hashes of the
+// sources, the versions of the source packages, and the version of the mock
+// generator recorded in a persistent state.
+package mocks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// ArgPackage provides the argument of the mock package name.
+	ArgPackage = "-package"
+	// ArgSource provides the argument of the mock source file.
+	ArgSource = "-source"
+	// ArgDestination provides the argument of the mock destination file.
+	ArgDestination = "-destination"
+
+	// ReasonForced provides the reason of a forced mock generation.
+	ReasonForced = "forced"
+	// ReasonMissing provides the reason of a missing mock.
+	ReasonMissing = "missing"
+	// ReasonNew provides the reason of a mock without recorded state.
+	ReasonNew = "new"
+	// ReasonCommand provides the reason of a changed mock command.
+	ReasonCommand = "command changed"
+	// ReasonVersion provides the reason of a changed mock generator version.
+	ReasonVersion = "version changed"
+	// ReasonSource provides the reason of a changed mock source file.
+	ReasonSource = "source changed"
+	// ReasonPackage provides the reason of a changed mock source package.
+	ReasonPackage = "package changed"
+
+	// prefixGenerate provides the prefix of the go generate directives.
+	prefixGenerate = "//go:generate "
+)
+
+// regexCommand matches the supported mock generator commands.
+var regexCommand = regexp.MustCompile(`^mock(gen)?$`)
+
+// ErrDirective represents an invalid mock directive.
+var ErrDirective = errors.New("invalid mock directive")
+
+// NewErrDirective creates an invalid mock directive error for the directive
+// in the given file and line with the given reason.
+func NewErrDirective(file string, line int, reason string) error {
+	return fmt.Errorf("%w [file=%s, line=%d]: %s",
+		ErrDirective, file, line, reason)
+}
+
+// ErrParse represents a go source file parse failure.
+var ErrParse = errors.New("parse source failed")
+
+// NewErrParse wraps the error of a failed go source file parsing.
+func NewErrParse(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrParse, file, err)
+}
+
+// Mock contains a resolved mock directive.
+type Mock struct {
+	// File provides the file of the directive relative to the root.
+	File string
+	// Line provides the line of the directive.
+	Line int
+	// Command provides the mock generator command with the source and
+	// destination resolved relative to the root.
+	Command []string
+	// Source provides the source file relative to the root, if any.
+	Source string
+	// Target provides the destination file relative to the root.
+	Target string
+	// Package provides the import path of the source package, if no source
+	// file is given.
+	Package string
+}
+
+// String returns the mock generator command of the mock.
+func (m *Mock) String() string {
+	return strings.Join(m.Command, " ")
+}
+
+// Parse parses the mock directives of the given go source files relative to
+// the given root directory. Mocks with identical commands are reported only
+// once, while invalid directives are reported as joined error. The mocks are
+// sorted by target.
+func Parse(root string, files ...string) ([]*Mock, error) {
+	mocks, errs := []*Mock{}, []error{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		path := join(root, file)
+		ast, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			errs = append(errs, NewErrParse(file, err))
+			continue
+		}
+
+		for _, group := range ast.Comments {
+			for _, comment := range group.List {
+				args, ok := directive(comment.Text)
+				if !ok {
+					continue
+				}
+				line := fset.Position(comment.Pos()).Line
+				mock, err := resolve(relative(root, path), line, args)
+				if err != nil {
+					errs = append(errs, err)
+				} else if !slices.ContainsFunc(mocks, func(m *Mock) bool {
+					return m.String() == mock.String()
+				}) {
+					mocks = append(mocks, mock)
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(mocks, func(a, b *Mock) int {
+		return strings.Compare(a.Target, b.Target)
+	})
+	return mocks, errors.Join(errs...)
+}
+
+// directive returns the arguments of the given comment, if the comment is a
+// go generate directive of a supported mock generator command.
+func directive(text string) ([]string, bool) {
+	if !strings.HasPrefix(text, prefixGenerate) {
+		return nil, false
+	}
+	args := strings.Fields(text[len(prefixGenerate):])
+	if len(args) == 0 || !regexCommand.MatchString(args[0]) {
+		return nil, false
+	}
+	return args, true
+}
+
+// resolve resolves the given mock directive arguments of the given file and
+// line, where the file is relative to the root directory.
+func resolve(file string, line int, args []string) (*Mock, error) {
+	mock := &Mock{File: file, Line: line, Command: []string{args[0]}}
+	dir := filepath.Dir(file)
+	for index := 1; index < len(args); index++ {
+		arg := args[index]
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok && index+1 < len(args) &&
+			(name == ArgPackage || name == ArgSource || name == ArgDestination) {
+			index++
+			value = args[index]
+		}
+
+		switch name {
+		case ArgPackage:
+			arg = ArgPackage + "=" + value
+		case ArgSource:
+			mock.Source = filepath.Join(dir, value)
+			arg = ArgSource + "=" + mock.Source
+		case ArgDestination:
+			mock.Target = filepath.Join(dir, value)
+			arg = ArgDestination + "=" + mock.Target
+		}
+		mock.Command = append(mock.Command, arg)
+	}
+
+	if mock.Target == "" {
+		return nil, NewErrDirective(file, line, "destination missing")
+	} else if mock.Source == "" {
+		index := len(mock.Command) - 2
+		if index < 1 || strings.HasPrefix(mock.Command[index], "-") {
+			return nil, NewErrDirective(file, line, "package missing")
+		}
+		mock.Package = mock.Command[index]
+		if build.IsLocalImport(mock.Package) {
+			mock.Package = "./" + filepath.Join(dir, mock.Package)
+			mock.Command[index] = mock.Package
+		}
+	}
+	return mock, nil
+}
+
+// Entry contains the recorded state of a generated mock.
+type Entry struct {
+	// Command provides the mock generator command.
+	Command string `json:"command"`
+	// Version provides the version of the mock generator.
+	Version string `json:"version"`
+	// Source provides the content hash of the source file, if any.
+	Source string `json:"source,omitempty"`
+	// Package provides the version or content hash of the source package,
+	// if any.
+	Package string `json:"package,omitempty"`
+}
+
+// Reason returns the reason why a mock with the given entry needs to be
+// regenerated compared to the given recorded entry, or an empty string, if
+// the mock is up-to-date.
+func (e *Entry) Reason(recorded *Entry) string {
+	switch {
+	case recorded == nil:
+		return ReasonNew
+	case e.Command != recorded.Command:
+		return ReasonCommand
+	case e.Version != recorded.Version:
+		return ReasonVersion
+	case e.Source != recorded.Source:
+		return ReasonSource
+	case e.Package != recorded.Package:
+		return ReasonPackage
+	}
+	return ""
+}
+
+// Resolver resolves the entries of mocks from the project root, the module
+// versions, and the mock generator versions.
+type Resolver struct {
+	// Root provides the project root directory.
+	Root string
+	// Modules provides the module versions by module path, where the main
+	// module has an empty version.
+	Modules map[string]string
+	// Versions provides the mock generator versions by command.
+	Versions map[string]string
+}
+
+// Entry returns the current entry of the given mock. The source package is
+// identified by its module version, if it is provided by a dependency, and by
+// its content hash, if it is provided by the main module or a local path.
+func (r *Resolver) Entry(mock *Mock) (*Entry, error) {
+	entry := &Entry{
+		Command: mock.String(), Version: r.Versions[mock.Command[0]],
+	}
+
+	if mock.Source != "" {
+		hash, err := hashFiles(join(r.Root, mock.Source))
+		if err != nil {
+			return nil, err
+		}
+		entry.Source = hash
+		return entry, nil
+	}
+
+	dir := ""
+	switch module, version := r.module(mock.Package); {
+	case build.IsLocalImport(mock.Package):
+		dir = mock.Package
+	case module == "":
+		entry.Package = mock.Package
+		return entry, nil
+	case version != "":
+		entry.Package = module + "@" + version
+		return entry, nil
+	default:
+		dir = strings.TrimPrefix(strings.TrimPrefix(mock.Package, module), "/")
+	}
+
+	hash, err := hashPackage(join(r.Root, dir))
+	if err != nil {
+		return nil, err
+	}
+	entry.Package = hash
+	return entry, nil
+}
+
+// Plan contains the planned generation of a mock.
+type Plan struct {
+	// Mock provides the mock to generate.
+	Mock *Mock
+	// Entry provides the current entry of the mock.
+	Entry *Entry
+	// Reason provides the reason for generating the mock, or an empty string,
+	// if the mock is up-to-date.
+	Reason string
+}
+
+// Plan plans the generation of the given mock by comparing its current entry
+// with the given recorded entry. If force is set, the mock is always
+// regenerated.
+func (r *Resolver) Plan(
+	mock *Mock, recorded *Entry, force bool,
+) (*Plan, error) {
+	entry, err := r.Entry(mock)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Mock: mock, Entry: entry}
+	if _, err := os.Stat(join(r.Root, mock.Target)); force {
+		plan.Reason = ReasonForced
+	} else if err != nil {
+		plan.Reason = ReasonMissing
+	} else {
+		plan.Reason = entry.Reason(recorded)
+	}
+	return plan, nil
+}
+
+// module returns the module and its version providing the given package
+// using the longest matching module path.
+func (r *Resolver) module(pkg string) (string, string) {
+	module := ""
+	for path := range r.Modules {
+		if (pkg == path || strings.HasPrefix(pkg, path+"/")) &&
+			len(path) > len(module) {
+			module = path
+		}
+	}
+	return module, r.Modules[module]
+}
+
+// ParseModules parses the module versions from the output of `go list -m
+// all`, where the main module has an empty version.
+func ParseModules(output string) map[string]string {
+	modules := map[string]string{}
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+		case 1:
+			modules[fields[0]] = ""
+		default:
+			modules[fields[0]] = fields[1]
+		}
+	}
+	return modules
+}
+
+// ParseVersion parses the main module version of a binary from the output of
+// `go version -m <binary>`, or returns an empty string, if no version is
+// found.
+func ParseVersion(output string) string {
+	for line := range strings.Lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "mod" {
+			return fields[1] + "@" + fields[2]
+		}
+	}
+	return ""
+}
+
+// ErrHash represents a failure to hash the sources of a mock.
+var ErrHash = errors.New("hash sources failed")
+
+// NewErrHash wraps the error of failed hashing of the given path.
+func NewErrHash(path string, err error) error {
+	return fmt.Errorf("%w [path=%s]: %w", ErrHash, path, err)
+}
+
+// hashFiles returns the content hash of the given files.
+func hashFiles(files ...string) (string, error) {
+	hash := sha256.New()
+	for _, file := range files {
+		// #nosec G304 -- file is a source file of the project.
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", NewErrHash(file, err)
+		}
+		hash.Write([]byte(filepath.Base(file) + "\x00"))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashPackage returns the content hash of the non-test go files of the
+// package in the given directory.
+func hashPackage(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", NewErrHash(dir, err)
+	}
+	files := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") &&
+			!strings.HasSuffix(name, "_test.go") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return hashFiles(files...)
+}
+
+// ErrState represents a mock state failure.
+var ErrState = errors.New("mock state failed")
+
+// NewErrState wraps the error of a failed mock state operation.
+func NewErrState(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrState, file, err)
+}
+
+// State contains the recorded entries of the generated mocks by target.
+type State map[string]*Entry
+
+// ReadState reads the mock state from the given file. A missing file results
+// in an empty state.
+func ReadState(file string) (State, error) {
+	state := State{}
+	// #nosec G304 -- file is the mock state in the cache directory.
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, NewErrState(file, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, NewErrState(file, err)
+	}
+	return state, nil
+}
+
+// Write writes the mock state to the given file creating the parent
+// directories as needed.
+func (s State) Write(file string) error {
+	data, _ := json.MarshalIndent(s, "", "  ")
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return NewErrState(file, err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o600); err != nil {
+		return NewErrState(file, err)
+	}
+	return nil
+}
+
+// join returns the given path relative to the given directory, if the path
+// is not absolute.
+func join(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// relative returns the given path relative to the given directory, if
+// possible, or the path itself otherwise.
+func relative(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return rel
+	}
+	return path
+}
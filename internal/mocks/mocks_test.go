@@ -0,0 +1,572 @@
+package mocks_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/mocks"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// sourceDirectives contains a source file with mock directives.
+	sourceDirectives = `package make_test
+
+//go:generate mockgen -package=make_test -destination=mock_cmd_test.go -source=../cmd/cmd.go Executor
+//go:generate mockgen -package make_test -destination mock_log_test.go -source ../log/log.go Logger
+//go:generate mock -destination=mock_io_test.go io Reader,Writer
+//go:generate mockgen -destination=mock_local_test.go . Local
+//go:generate mockgen -package=make_test -destination=mock_cmd_test.go -source=../cmd/cmd.go Executor
+//go:generate stringer -type=Mode
+// comment without directive
+`
+	// sourceInvalid contains a source file with invalid mock directives.
+	sourceInvalid = `package make_test
+
+//go:generate mockgen -package=make_test -source=cmd.go Executor
+//go:generate mockgen -destination=mock_test.go -package=x
+`
+)
+
+// WriteFiles writes the given files with content into the given directory
+// creating the parent directories as needed.
+func WriteFiles(t test.Test, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+type ParseParams struct {
+	files       map[string]string
+	args        []string
+	expectMocks []*mocks.Mock
+	expectError func(dir string) error
+}
+
+var parseTestCases = map[string]ParseParams{
+	"directives": {
+		files: map[string]string{
+			"internal/make/make_test.go": sourceDirectives,
+		},
+		args: []string{"internal/make/make_test.go"},
+		expectMocks: []*mocks.Mock{{
+			File: "internal/make/make_test.go", Line: 3,
+			Command: []string{
+				"mockgen", "-package=make_test",
+				"-destination=internal/make/mock_cmd_test.go",
+				"-source=internal/cmd/cmd.go", "Executor",
+			},
+			Source: "internal/cmd/cmd.go",
+			Target: "internal/make/mock_cmd_test.go",
+		}, {
+			File: "internal/make/make_test.go", Line: 5,
+			Command: []string{
+				"mock", "-destination=internal/make/mock_io_test.go",
+				"io", "Reader,Writer",
+			},
+			Target:  "internal/make/mock_io_test.go",
+			Package: "io",
+		}, {
+			File: "internal/make/make_test.go", Line: 6,
+			Command: []string{
+				"mockgen", "-destination=internal/make/mock_local_test.go",
+				"./internal/make", "Local",
+			},
+			Target:  "internal/make/mock_local_test.go",
+			Package: "./internal/make",
+		}, {
+			File: "internal/make/make_test.go", Line: 4,
+			Command: []string{
+				"mockgen", "-package=make_test",
+				"-destination=internal/make/mock_log_test.go",
+				"-source=internal/log/log.go", "Logger",
+			},
+			Source: "internal/log/log.go",
+			Target: "internal/make/mock_log_test.go",
+		}},
+	},
+	"invalid directives": {
+		files: map[string]string{
+			"invalid.go": sourceInvalid,
+			"broken.go":  "package",
+		},
+		args:        []string{"invalid.go", "broken.go"},
+		expectMocks: []*mocks.Mock{},
+		expectError: func(dir string) error {
+			return errors.Join(
+				mocks.NewErrDirective("invalid.go", 3, "destination missing"),
+				mocks.NewErrDirective("invalid.go", 4, "package missing"),
+				mocks.NewErrParse("broken.go", errors.New(filepath.Join(
+					dir, "broken.go")+":1:8: expected 'IDENT', found 'EOF'")),
+			)
+		},
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, parseTestCases).
+		Run(func(t test.Test, param ParseParams) {
+			// Given
+			dir := t.TempDir()
+			WriteFiles(t, dir, param.files)
+
+			// When
+			result, err := mocks.Parse(dir, param.args...)
+
+			// Then
+			if param.expectError != nil {
+				assert.Equal(t, param.expectError(dir).Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, param.expectMocks, result)
+		})
+}
+
+type ReasonParams struct {
+	entry        *mocks.Entry
+	recorded     *mocks.Entry
+	expectReason string
+}
+
+// entryBase contains the base entry of a mock.
+var entryBase = mocks.Entry{
+	Command: "mockgen -source=a.go", Version: "v0.6.0",
+	Source: "hash", Package: "io",
+}
+
+// Entry returns a copy of the base entry modified by the given function.
+func Entry(modify func(*mocks.Entry)) *mocks.Entry {
+	entry := entryBase
+	modify(&entry)
+	return &entry
+}
+
+var reasonTestCases = map[string]ReasonParams{
+	"new": {
+		entry:        &entryBase,
+		expectReason: mocks.ReasonNew,
+	},
+	"command changed": {
+		entry: &entryBase,
+		recorded: Entry(func(e *mocks.Entry) {
+			e.Command = "mockgen"
+		}),
+		expectReason: mocks.ReasonCommand,
+	},
+	"version changed": {
+		entry: &entryBase,
+		recorded: Entry(func(e *mocks.Entry) {
+			e.Version = "v0.5.0"
+		}),
+		expectReason: mocks.ReasonVersion,
+	},
+	"source changed": {
+		entry: &entryBase,
+		recorded: Entry(func(e *mocks.Entry) {
+			e.Source = "other"
+		}),
+		expectReason: mocks.ReasonSource,
+	},
+	"package changed": {
+		entry: &entryBase,
+		recorded: Entry(func(e *mocks.Entry) {
+			e.Package = "other"
+		}),
+		expectReason: mocks.ReasonPackage,
+	},
+	"up-to-date": {
+		entry:    &entryBase,
+		recorded: Entry(func(*mocks.Entry) {}),
+	},
+}
+
+func TestEntryReason(t *testing.T) {
+	test.Map(t, reasonTestCases).
+		Run(func(t test.Test, param ReasonParams) {
+			// Given
+
+			// When
+			reason := param.entry.Reason(param.recorded)
+
+			// Then
+			assert.Equal(t, param.expectReason, reason)
+		})
+}
+
+// contentSource contains the content of a source file.
+const contentSource = "package a\n"
+
+type PlanParams struct {
+	mock         *mocks.Mock
+	files        map[string]string
+	recorded     *mocks.Entry
+	force        bool
+	expectEntry  *mocks.Entry
+	expectReason string
+	expectError  func(dir string) error
+}
+
+var planTestCases = map[string]PlanParams{
+	"source missing target": {
+		mock: &mocks.Mock{
+			Command: []string{"mockgen", "-source=a/a.go"},
+			Source:  "a/a.go", Target: "a/mock_test.go",
+		},
+		files:        map[string]string{"a/a.go": contentSource},
+		expectReason: mocks.ReasonMissing,
+	},
+	"source new": {
+		mock: &mocks.Mock{
+			Command: []string{"mockgen", "-source=a/a.go"},
+			Source:  "a/a.go", Target: "a/mock_test.go",
+		},
+		files: map[string]string{
+			"a/a.go": contentSource, "a/mock_test.go": "",
+		},
+		expectReason: mocks.ReasonNew,
+	},
+	"source forced": {
+		mock: &mocks.Mock{
+			Command: []string{"mockgen", "-source=a/a.go"},
+			Source:  "a/a.go", Target: "a/mock_test.go",
+		},
+		files: map[string]string{
+			"a/a.go": contentSource, "a/mock_test.go": "",
+		},
+		force:        true,
+		expectReason: mocks.ReasonForced,
+	},
+	"source failed": {
+		mock: &mocks.Mock{
+			Command: []string{"mockgen", "-source=a/a.go"},
+			Source:  "a/a.go", Target: "a/mock_test.go",
+		},
+		expectError: func(dir string) error {
+			path := filepath.Join(dir, "a/a.go")
+			return mocks.NewErrHash(path, &fs.PathError{
+				Op: "open", Path: path, Err: syscall.ENOENT,
+			})
+		},
+	},
+	"package dependency": {
+		mock: &mocks.Mock{
+			Command: []string{"mock", "github.com/dep/pkg/sub/x", "Iface"},
+			Package: "github.com/dep/pkg/sub/x", Target: "mock_test.go",
+		},
+		files: map[string]string{"mock_test.go": ""},
+		recorded: &mocks.Entry{
+			Command: "mock github.com/dep/pkg/sub/x Iface",
+			Version: "go.uber.org/mock@v0.6.0",
+			Package: "github.com/dep/pkg@v1.0.0",
+		},
+		expectEntry: &mocks.Entry{
+			Command: "mock github.com/dep/pkg/sub/x Iface",
+			Version: "go.uber.org/mock@v0.6.0",
+			Package: "github.com/dep/pkg/sub@v1.1.0",
+		},
+		expectReason: mocks.ReasonPackage,
+	},
+	"package standard": {
+		mock: &mocks.Mock{
+			Command: []string{"mock", "io", "Reader"},
+			Package: "io", Target: "mock_test.go",
+		},
+		files: map[string]string{"mock_test.go": ""},
+		recorded: &mocks.Entry{
+			Command: "mock io Reader", Version: "go.uber.org/mock@v0.6.0",
+			Package: "io",
+		},
+		expectEntry: &mocks.Entry{
+			Command: "mock io Reader", Version: "go.uber.org/mock@v0.6.0",
+			Package: "io",
+		},
+	},
+	"package main module": {
+		mock: &mocks.Mock{
+			Command: []string{"mockgen", "example.com/main/a", "Iface"},
+			Package: "example.com/main/a", Target: "a/mock_test.go",
+		},
+		files: map[string]string{
+			"a/a.go": contentSource, "a/a_test.go": "package a\n",
+			"a/mock_test.go": "", "a/sub/b.go": "package b\n",
+		},
+		expectReason: mocks.ReasonNew,
+	},
+	"package local": {
+		mock: &mocks.Mock{
+			Command: []string{"mockgen", "./a", "Iface"},
+			Package: "./a", Target: "a/mock_test.go",
+		},
+		files: map[string]string{
+			"a/a.go": contentSource, "a/mock_test.go": "",
+		},
+		expectReason: mocks.ReasonNew,
+	},
+	"package failed": {
+		mock: &mocks.Mock{
+			Command: []string{"mockgen", "./a", "Iface"},
+			Package: "./a", Target: "a/mock_test.go",
+		},
+		expectError: func(dir string) error {
+			path := filepath.Join(dir, "a")
+			return mocks.NewErrHash(path, &fs.PathError{
+				Op: "open", Path: path, Err: syscall.ENOENT,
+			})
+		},
+	},
+}
+
+func TestResolverPlan(t *testing.T) {
+	test.Map(t, planTestCases).
+		Run(func(t test.Test, param PlanParams) {
+			// Given
+			dir := t.TempDir()
+			WriteFiles(t, dir, param.files)
+			resolver := &mocks.Resolver{
+				Root: dir,
+				Modules: map[string]string{
+					"example.com/main":       "",
+					"github.com/dep":         "v0.1.0",
+					"github.com/dep/pkg":     "v1.0.0",
+					"github.com/dep/pkg/sub": "v1.1.0",
+				},
+				Versions: map[string]string{
+					"mock": "go.uber.org/mock@v0.6.0",
+				},
+			}
+
+			// When
+			plan, err := resolver.Plan(param.mock, param.recorded, param.force)
+
+			// Then
+			if param.expectError != nil {
+				assert.Equal(t, param.expectError(dir), err)
+				assert.Nil(t, plan)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, param.mock, plan.Mock)
+			assert.Equal(t, param.expectReason, plan.Reason)
+			if param.expectEntry != nil {
+				assert.Equal(t, param.expectEntry, plan.Entry)
+			} else {
+				assert.Len(t, plan.Entry.Source+plan.Entry.Package, 64)
+			}
+		})
+}
+
+func TestResolverPlanHash(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	WriteFiles(t, dir, map[string]string{
+		"a/a.go": contentSource, "a/mock_test.go": "",
+	})
+	resolver := &mocks.Resolver{Root: dir}
+	mock := &mocks.Mock{
+		Command: []string{"mockgen", "-source=a/a.go"},
+		Source:  "a/a.go", Target: "a/mock_test.go",
+	}
+	plan, err := resolver.Plan(mock, nil, false)
+	assert.NoError(t, err)
+
+	// When
+	fresh, err := resolver.Plan(mock, plan.Entry, false)
+	assert.NoError(t, err)
+	WriteFiles(t, dir, map[string]string{"a/a.go": "package a // changed\n"})
+	changed, err := resolver.Plan(mock, plan.Entry, false)
+	assert.NoError(t, err)
+
+	// Then
+	assert.Empty(t, fresh.Reason)
+	assert.Equal(t, mocks.ReasonSource, changed.Reason)
+}
+
+func TestParseModules(t *testing.T) {
+	// Given
+	output := "example.com/main\n" +
+		"github.com/dep/pkg v1.0.0\n\n" +
+		"go.uber.org/mock v0.6.0 => ../mock\n"
+
+	// When
+	modules := mocks.ParseModules(output)
+
+	// Then
+	assert.Equal(t, map[string]string{
+		"example.com/main":   "",
+		"github.com/dep/pkg": "v1.0.0",
+		"go.uber.org/mock":   "v0.6.0",
+	}, modules)
+}
+
+type ParseVersionParams struct {
+	output        string
+	expectVersion string
+}
+
+var parseVersionTestCases = map[string]ParseVersionParams{
+	"version": {
+		output: "/go/bin/mockgen: go1.26.4\n" +
+			"\tpath\tgo.uber.org/mock/mockgen\n" +
+			"\tmod\tgo.uber.org/mock\tv0.6.0\th1:hash=\n" +
+			"\tdep\tgolang.org/x/mod\tv0.27.0\th1:hash=\n",
+		expectVersion: "go.uber.org/mock@v0.6.0",
+	},
+	"no version": {
+		output: "/go/bin/mockgen: go1.26.4\n",
+	},
+}
+
+func TestParseVersion(t *testing.T) {
+	test.Map(t, parseVersionTestCases).
+		Run(func(t test.Test, param ParseVersionParams) {
+			// Given
+
+			// When
+			version := mocks.ParseVersion(param.output)
+
+			// Then
+			assert.Equal(t, param.expectVersion, version)
+		})
+}
+
+type ReadStateParams struct {
+	content     *string
+	dir         bool
+	expectState mocks.State
+	expectError func(file string) error
+}
+
+// content returns a pointer to the given content.
+func content(content string) *string {
+	return &content
+}
+
+var readStateTestCases = map[string]ReadStateParams{
+	"missing file": {
+		expectState: mocks.State{},
+	},
+	"valid file": {
+		content: content(`{"mock_test.go":{"command":"mockgen",` +
+			`"version":"v0.6.0","source":"hash"}}`),
+		expectState: mocks.State{"mock_test.go": {
+			Command: "mockgen", Version: "v0.6.0", Source: "hash",
+		}},
+	},
+	"invalid file": {
+		content: content(`[]`),
+		expectError: func(file string) error {
+			return mocks.NewErrState(file, errors.New("json: cannot "+
+				"unmarshal array into Go value of type mocks.State"))
+		},
+	},
+	"read failed": {
+		dir: true,
+		expectError: func(file string) error {
+			return mocks.NewErrState(file, &fs.PathError{
+				Op: "read", Path: file, Err: syscall.EISDIR,
+			})
+		},
+	},
+}
+
+func TestReadState(t *testing.T) {
+	test.Map(t, readStateTestCases).
+		Run(func(t test.Test, param ReadStateParams) {
+			// Given
+			file := filepath.Join(t.TempDir(), "mocks.json")
+			if param.content != nil {
+				assert.NoError(t, os.WriteFile(file,
+					[]byte(*param.content), 0o600))
+			} else if param.dir {
+				assert.NoError(t, os.Mkdir(file, 0o750))
+			}
+
+			// When
+			state, err := mocks.ReadState(file)
+
+			// Then
+			if param.expectError != nil {
+				assert.ErrorIs(t, err, mocks.ErrState)
+				assert.Equal(t, param.expectError(file).Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectState, state)
+		})
+}
+
+type WriteStateParams struct {
+	file          string
+	setup         func(test.Test, string)
+	expectContent string
+	expectError   func(string) error
+}
+
+var writeStateTestCases = map[string]WriteStateParams{
+	"new file": {
+		file: "cache/mocks.json",
+		expectContent: "{\n  \"mock_test.go\": {\n" +
+			"    \"command\": \"mockgen\",\n" +
+			"    \"version\": \"v0.6.0\",\n" +
+			"    \"package\": \"io\"\n  }\n}\n",
+	},
+	"mkdir failed": {
+		file: "cache/mocks.json",
+		setup: func(t test.Test, file string) {
+			assert.NoError(t, os.WriteFile(
+				filepath.Dir(file), []byte{}, 0o600))
+		},
+		expectError: func(file string) error {
+			return mocks.NewErrState(file, &fs.PathError{
+				Op: "mkdir", Path: filepath.Dir(file), Err: syscall.ENOTDIR,
+			})
+		},
+	},
+	"write failed": {
+		file: "mocks.json",
+		setup: func(t test.Test, file string) {
+			assert.NoError(t, os.Mkdir(file, 0o750))
+		},
+		expectError: func(file string) error {
+			return mocks.NewErrState(file, &fs.PathError{
+				Op: "open", Path: file, Err: syscall.EISDIR,
+			})
+		},
+	},
+}
+
+func TestStateWrite(t *testing.T) {
+	test.Map(t, writeStateTestCases).
+		Run(func(t test.Test, param WriteStateParams) {
+			// Given
+			file := filepath.Join(t.TempDir(), param.file)
+			if param.setup != nil {
+				param.setup(t, file)
+			}
+			state := mocks.State{"mock_test.go": {
+				Command: "mockgen", Version: "v0.6.0", Package: "io",
+			}}
+
+			// When
+			err := state.Write(file)
+
+			// Then
+			if param.expectError != nil {
+				assert.Equal(t, param.expectError(file), err)
+				return
+			}
+			assert.NoError(t, err)
+			data, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectContent, string(data))
+		})
+}
@@ -0,0 +1,184 @@
+// Package picker provides a line based interactive picker for the annotated
+// targets of the target catalog, that allows to filter the targets by name
+// and description, to select a target by number, and to provide the target
+// arguments.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/catalog"
+)
+
+// ErrPick represents a target picker failure.
+var ErrPick = errors.New("pick failed")
+
+// NewErrPick wraps the error of a failed target picker operation.
+func NewErrPick(err error) error {
+	return fmt.Errorf("%w: %w", ErrPick, err)
+}
+
+// Picker provides the interactive target picker reading the user input from
+// the given reader and writing the target list and prompts to given writer.
+// The user input is read unbuffered line by line, so that the remaining input
+// is still available for the picked target.
+type Picker struct {
+	// reader provides the user input reader.
+	reader io.Reader
+	// writer provides the output writer.
+	writer io.Writer
+}
+
+// New creates a new target picker using the given reader for user input and
+// the given writer for the target list and the prompts.
+func New(reader io.Reader, writer io.Writer) *Picker {
+	return &Picker{reader: reader, writer: writer}
+}
+
+// Pick shows the given targets grouped by their group and lets the user pick
+// a target by number or filter the targets by words contained in their name
+// or description. For a target family the user is asked for the value
+// replacing the `%` wildcard of the pattern, and for targets with argument
+// synopsis for the arguments. Pick returns the picked target followed by the
+// arguments, or nil, if the user quits with empty input or end of input.
+func (p *Picker) Pick(targets catalog.Catalog) ([]string, error) {
+	target, err := p.pickTarget(targets)
+	if err != nil || target == nil {
+		return nil, err
+	}
+
+	name := target.Name
+	if target.Pattern != "" {
+		value, err := p.prompt("value of % in " + target.Pattern)
+		if err != nil || value == "" {
+			return nil, err
+		}
+		name = strings.Replace(target.Pattern, "%", value, 1)
+	}
+
+	picked := []string{name}
+	if target.Args != "" {
+		args, err := p.prompt("arguments " + target.Args)
+		if err != nil {
+			return nil, err
+		}
+		picked = append(picked, strings.Fields(args)...)
+	}
+	return picked, nil
+}
+
+// pickTarget shows the targets matching the current filter and prompts for
+// a target number or a new filter, until a target is picked or the user
+// quits.
+func (p *Picker) pickTarget(targets catalog.Catalog) (*catalog.Target, error) {
+	shown := targets
+	for {
+		p.show(shown)
+		input, err := p.prompt("filter or number (empty to quit)")
+		if err != nil || input == "" {
+			return nil, err
+		}
+
+		if index, err := strconv.Atoi(input); err == nil {
+			if index > 0 && index <= len(shown) {
+				return shown[index-1], nil
+			}
+			fmt.Fprintf(p.writer, "no target with number [%d]\n", index)
+			continue
+		}
+
+		if filtered := filter(targets, input); len(filtered) != 0 {
+			shown = filtered
+		} else {
+			fmt.Fprintf(p.writer, "no targets matching [%s]\n", input)
+		}
+	}
+}
+
+// show writes the given targets with their number grouped by their group,
+// aligning the descriptions after the target names and argument synopsis.
+func (p *Picker) show(targets catalog.Catalog) {
+	width := 0
+	for _, target := range targets {
+		width = max(width, len(synopsis(target)))
+	}
+
+	group := ""
+	for index, target := range targets {
+		if target.Group != group && target.Group != "" {
+			fmt.Fprintf(p.writer, "%s:\n", target.Group)
+		}
+		group = target.Group
+		fmt.Fprintf(p.writer, "%4d  %-*s  %s\n", index+1,
+			width, synopsis(target), target.Description)
+	}
+}
+
+// prompt writes the prompt with given label and returns the trimmed line of
+// user input. At the end of input it returns an empty input.
+func (p *Picker) prompt(label string) (string, error) {
+	fmt.Fprintf(p.writer, "%s> ", label)
+	line, err := p.readLine()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", NewErrPick(err)
+	} else if err != nil && line == "" {
+		fmt.Fprintln(p.writer)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readLine reads the next line of user input including the line feed byte by
+// byte to not consume any input beyond the line.
+func (p *Picker) readLine() (string, error) {
+	line := &strings.Builder{}
+	buffer := make([]byte, 1)
+	for {
+		n, err := p.reader.Read(buffer)
+		if n > 0 {
+			line.WriteByte(buffer[0])
+			if buffer[0] == '\n' {
+				return line.String(), nil
+			}
+		}
+		if err != nil {
+			return line.String(), err //nolint:wrapcheck // wrapped by caller.
+		}
+	}
+}
+
+// synopsis returns the name of the given target followed by its argument
+// synopsis, if any.
+func synopsis(target *catalog.Target) string {
+	if target.Args != "" {
+		return target.Name + " " + target.Args
+	}
+	return target.Name
+}
+
+// filter returns the targets containing all words of the given filter input
+// in their name or description ignoring the case.
+func filter(targets catalog.Catalog, input string) catalog.Catalog {
+	words := strings.Fields(strings.ToLower(input))
+	filtered := catalog.Catalog{}
+	for _, target := range targets {
+		text := strings.ToLower(target.Name + " " + target.Description)
+		if matchAll(text, words) {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered
+}
+
+// matchAll returns whether the given text contains all given words.
+func matchAll(text string, words []string) bool {
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
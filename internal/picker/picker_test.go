@@ -0,0 +1,170 @@
+package picker_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/catalog"
+	"github.com/tkrop/go-make/internal/picker"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// targets contains the target catalog of the tests.
+	targets = catalog.Catalog{{
+		Name: "all", Group: "Standard",
+		Description: "executes the default targets.",
+	}, {
+		Name: "test-unit", Group: "Standard", Args: "[<pkg>|<test>]",
+		Description: "execute only unit tests.",
+	}, {
+		Name: "build-*", Group: "Build", Pattern: "build-%",
+		Description: "build executables for a specific platform.",
+	}, {
+		Name: "commit", Group: "Build", Args: "<message>",
+		Description: "creates a commit.",
+	}, {
+		Name: "commit-<type>", Group: "Build", Args: "<message>",
+		Pattern: "commit-%", Description: "creates a commit using given type.",
+	}, {
+		Name: "clean", Description: "removes all build artifacts.",
+	}}
+
+	// outputTargets contains the output of the target list.
+	outputTargets = "Standard:\n" +
+		"   1  all                       executes the default targets.\n" +
+		"   2  test-unit [<pkg>|<test>]  execute only unit tests.\n" +
+		"Build:\n" +
+		"   3  build-*                   build executables for a specific platform.\n" +
+		"   4  commit <message>          creates a commit.\n" +
+		"   5  commit-<type> <message>   creates a commit using given type.\n" +
+		"   6  clean                     removes all build artifacts.\n"
+	// outputCommit contains the output of the target list filtered by
+	// `commit`.
+	outputCommit = "Build:\n" +
+		"   1  commit <message>         creates a commit.\n" +
+		"   2  commit-<type> <message>  creates a commit using given type.\n"
+
+	// promptFilter contains the prompt for the filter or number.
+	promptFilter = "filter or number (empty to quit)> "
+)
+
+type PickParams struct {
+	reader       io.Reader
+	expectPicked []string
+	expectOutput string
+	expectRemain string
+	expectError  error
+}
+
+var pickTestCases = map[string]PickParams{
+	"quit": {
+		reader:       strings.NewReader("\n"),
+		expectOutput: outputTargets + promptFilter,
+	},
+	"end of input": {
+		reader:       strings.NewReader(""),
+		expectOutput: outputTargets + promptFilter + "\n",
+	},
+	"pick target": {
+		reader:       strings.NewReader("1\n"),
+		expectPicked: []string{"all"},
+		expectOutput: outputTargets + promptFilter,
+	},
+	"pick target keeping input": {
+		reader:       strings.NewReader("1\nmake input\n"),
+		expectPicked: []string{"all"},
+		expectOutput: outputTargets + promptFilter,
+		expectRemain: "make input\n",
+	},
+	"pick target with arguments": {
+		reader:       strings.NewReader("2\n pkg  test \n"),
+		expectPicked: []string{"test-unit", "pkg", "test"},
+		expectOutput: outputTargets + promptFilter +
+			"arguments [<pkg>|<test>]> ",
+	},
+	"pick target without arguments": {
+		reader:       strings.NewReader("2\n"),
+		expectPicked: []string{"test-unit"},
+		expectOutput: outputTargets + promptFilter +
+			"arguments [<pkg>|<test>]> \n",
+	},
+	"pick target family": {
+		reader:       strings.NewReader("3\nlinux\n"),
+		expectPicked: []string{"build-linux"},
+		expectOutput: outputTargets + promptFilter +
+			"value of % in build-%> ",
+	},
+	"pick target family without value": {
+		reader: strings.NewReader("3\n\n"),
+		expectOutput: outputTargets + promptFilter +
+			"value of % in build-%> ",
+	},
+	"pick filtered target": {
+		reader:       strings.NewReader("Commit\n2\nfix\nfix: bug\n"),
+		expectPicked: []string{"commit-fix", "fix:", "bug"},
+		expectOutput: outputTargets + promptFilter +
+			outputCommit + promptFilter +
+			"value of % in commit-%> arguments <message>> ",
+	},
+	"pick filtered target by description": {
+		reader:       strings.NewReader("artifacts remove\n1\n"),
+		expectPicked: []string{"clean"},
+		expectOutput: outputTargets + promptFilter +
+			"   1  clean  removes all build artifacts.\n" + promptFilter,
+	},
+	"filter without match": {
+		reader: strings.NewReader("deploy\n\n"),
+		expectOutput: outputTargets + promptFilter +
+			"no targets matching [deploy]\n" +
+			outputTargets + promptFilter,
+	},
+	"number out of range": {
+		reader: strings.NewReader("7\n\n"),
+		expectOutput: outputTargets + promptFilter +
+			"no target with number [7]\n" +
+			outputTargets + promptFilter,
+	},
+	"read failure": {
+		reader:       iotest.ErrReader(assert.AnError),
+		expectOutput: outputTargets + promptFilter,
+		expectError:  picker.NewErrPick(assert.AnError),
+	},
+	"read value failure": {
+		reader: io.MultiReader(strings.NewReader("3\n"),
+			iotest.ErrReader(assert.AnError)),
+		expectOutput: outputTargets + promptFilter +
+			"value of % in build-%> ",
+		expectError: picker.NewErrPick(assert.AnError),
+	},
+	"read arguments failure": {
+		reader: io.MultiReader(strings.NewReader("4\n"),
+			iotest.ErrReader(assert.AnError)),
+		expectOutput: outputTargets + promptFilter +
+			"arguments <message>> ",
+		expectError: picker.NewErrPick(assert.AnError),
+	},
+}
+
+func TestPick(t *testing.T) {
+	test.Map(t, pickTestCases).
+		Run(func(t test.Test, param PickParams) {
+			// Given
+			output := &strings.Builder{}
+			picker := picker.New(param.reader, output)
+
+			// When
+			picked, err := picker.Pick(targets)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectPicked, picked)
+			assert.Equal(t, param.expectOutput, output.String())
+			remain, _ := io.ReadAll(param.reader)
+			assert.Equal(t, param.expectRemain, string(remain))
+		})
+}
@@ -0,0 +1,266 @@
+// Package semver provides parsing, comparing, and bumping of semantic
+// versions following the [SemVer 2.0] specification.
+//
+// [SemVer 2.0]: https://semver.org/spec/v2.0.0.html
+package semver
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Available version bump operations.
+const (
+	// BumpMajor bumps the major version.
+	BumpMajor = "major"
+	// BumpMinor bumps the minor version.
+	BumpMinor = "minor"
+	// BumpPatch bumps the patch version.
+	BumpPatch = "patch"
+	// BumpPreMajor bumps the major version to a pre-release.
+	BumpPreMajor = "premajor"
+	// BumpPreMinor bumps the minor version to a pre-release.
+	BumpPreMinor = "preminor"
+	// BumpPrePatch bumps the patch version to a pre-release.
+	BumpPrePatch = "prepatch"
+	// BumpPreRelease bumps the pre-release version.
+	BumpPreRelease = "prerelease"
+)
+
+// regexVersion matches a semantic version as recommended by the SemVer 2.0
+// specification.
+var regexVersion = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.` +
+	`(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)` +
+	`(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// regexShort matches a short version missing the minor or patch version,
+// e.g. `1` or `1.2-rc.1`.
+var regexShort = regexp.MustCompile(
+	`^(0|[1-9]\d*)(\.(?:0|[1-9]\d*))?([-+].*)?$`)
+
+var (
+	// ErrInvalid represents an invalid semantic version.
+	ErrInvalid = errors.New("invalid version")
+	// ErrInvalidBump represents an invalid version bump operation.
+	ErrInvalidBump = errors.New("invalid bump")
+)
+
+// NewErrInvalid creates an error for the given invalid version.
+func NewErrInvalid(version string) error {
+	return fmt.Errorf("%w [version=%s]", ErrInvalid, version)
+}
+
+// NewErrInvalidBump creates an error for the given invalid version bump
+// operation and pre-release identifier.
+func NewErrInvalidBump(op, id string) error {
+	return fmt.Errorf("%w [op=%s, id=%s]", ErrInvalidBump, op, id)
+}
+
+// Version represents a semantic version.
+type Version struct {
+	// Major provides the major version.
+	Major uint64
+	// Minor provides the minor version.
+	Minor uint64
+	// Patch provides the patch version.
+	Patch uint64
+	// Pre provides the pre-release identifiers.
+	Pre []string
+	// Build provides the build metadata identifiers.
+	Build []string
+}
+
+// Parse parses the given semantic version.
+func Parse(version string) (*Version, error) {
+	match := regexVersion.FindStringSubmatch(version)
+	if match == nil {
+		return nil, NewErrInvalid(version)
+	}
+
+	v := &Version{}
+	for index, part := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		value, err := strconv.ParseUint(match[index+1], 10, 64)
+		if err != nil {
+			return nil, NewErrInvalid(version)
+		}
+		*part = value
+	}
+	if match[4] != "" {
+		v.Pre = strings.Split(match[4], ".")
+	}
+	if match[5] != "" {
+		v.Build = strings.Split(match[5], ".")
+	}
+	return v, nil
+}
+
+// ParseShort parses the given semantic version like Parse, but also accepts
+// short versions missing the minor or patch version, e.g. `1.2`, completing
+// the missing versions with zero.
+func ParseShort(version string) (*Version, error) {
+	match := regexShort.FindStringSubmatch(version)
+	if match == nil {
+		return Parse(version)
+	} else if match[2] == "" {
+		match[2] = ".0"
+	}
+
+	v, err := Parse(match[1] + match[2] + ".0" + match[3])
+	if err != nil {
+		return nil, NewErrInvalid(version)
+	}
+	return v, nil
+}
+
+// String returns the canonical string representation of the version.
+func (v *Version) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Pre) != 0 {
+		version += "-" + strings.Join(v.Pre, ".")
+	}
+	if len(v.Build) != 0 {
+		version += "+" + strings.Join(v.Build, ".")
+	}
+	return version
+}
+
+// Compare compares the precedence of the version with the given version. It
+// returns -1, 0, or 1, if the version is lower, equal, or higher. The build
+// metadata is ignored.
+func (v *Version) Compare(other *Version) int {
+	for _, pair := range [][2]uint64{
+		{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch},
+	} {
+		if pair[0] != pair[1] {
+			return cmp.Compare(pair[0], pair[1])
+		}
+	}
+
+	switch {
+	case len(v.Pre) == 0 && len(other.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(other.Pre) == 0:
+		return -1
+	}
+
+	for index := 0; index < len(v.Pre) && index < len(other.Pre); index++ {
+		if result := compareIdent(v.Pre[index], other.Pre[index]); result != 0 {
+			return result
+		}
+	}
+	return cmp.Compare(len(v.Pre), len(other.Pre))
+}
+
+// Bump returns a new version created by applying the given bump operation
+// using the given optional pre-release identifier. Bumping a pre-release to
+// its release version, e.g. `1.0.0-rc.1` to `1.0.0` via `major`, follows the
+// common convention. The build metadata is always dropped.
+func (v *Version) Bump(op, id string) (*Version, error) {
+	if id != "" && (!regexVersion.MatchString("0.0.0-"+id) ||
+		strings.Contains(id, ".") || op == BumpMajor ||
+		op == BumpMinor || op == BumpPatch) {
+		return nil, NewErrInvalidBump(op, id)
+	}
+
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch op {
+	case BumpMajor:
+		if len(v.Pre) == 0 || v.Minor != 0 || v.Patch != 0 {
+			next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+		}
+	case BumpMinor:
+		if len(v.Pre) == 0 || v.Patch != 0 {
+			next.Minor, next.Patch = v.Minor+1, 0
+		}
+	case BumpPatch:
+		if len(v.Pre) == 0 {
+			next.Patch = v.Patch + 1
+		}
+	case BumpPreMajor:
+		next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+		next.Pre = preRelease(id)
+	case BumpPreMinor:
+		next.Minor, next.Patch = v.Minor+1, 0
+		next.Pre = preRelease(id)
+	case BumpPrePatch:
+		next.Patch = v.Patch + 1
+		next.Pre = preRelease(id)
+	case BumpPreRelease:
+		next.Pre = v.bumpPre(id)
+		if len(v.Pre) == 0 {
+			next.Patch = v.Patch + 1
+		}
+	default:
+		return nil, NewErrInvalidBump(op, id)
+	}
+	return next, nil
+}
+
+// Lower returns a new version created by decrementing the version part of
+// the given major, minor, or patch bump operation and resetting the lower
+// version parts. The pre-release and build metadata are always dropped.
+func (v *Version) Lower(op string) (*Version, error) {
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch {
+	case op == BumpMajor && v.Major != 0:
+		next.Major, next.Minor, next.Patch = v.Major-1, 0, 0
+	case op == BumpMinor && v.Minor != 0:
+		next.Minor, next.Patch = v.Minor-1, 0
+	case op == BumpPatch && v.Patch != 0:
+		next.Patch = v.Patch - 1
+	default:
+		return nil, NewErrInvalidBump(op+"-", "")
+	}
+	return next, nil
+}
+
+// bumpPre returns the next pre-release identifiers for the given optional
+// pre-release identifier.
+func (v *Version) bumpPre(id string) []string {
+	if len(v.Pre) == 0 || (id != "" && v.Pre[0] != id) {
+		return preRelease(id)
+	}
+
+	pre := slices.Clone(v.Pre)
+	for index := len(pre) - 1; index >= 0; index-- {
+		if value, err := strconv.ParseUint(pre[index], 10, 64); err == nil {
+			pre[index] = strconv.FormatUint(value+1, 10)
+			return pre
+		}
+	}
+	return append(pre, "0")
+}
+
+// preRelease returns the initial pre-release identifiers for the given
+// optional pre-release identifier.
+func preRelease(id string) []string {
+	if id == "" {
+		return []string{"0"}
+	}
+	return []string{id, "0"}
+}
+
+// compareIdent compares the given pre-release identifiers. Numeric
+// identifiers are compared numerically and have lower precedence than
+// alphanumeric identifiers, that are compared lexically.
+func compareIdent(ident, other string) int {
+	value, err := strconv.ParseUint(ident, 10, 64)
+	otherValue, otherErr := strconv.ParseUint(other, 10, 64)
+	switch {
+	case err == nil && otherErr == nil:
+		return cmp.Compare(value, otherValue)
+	case err == nil:
+		return -1
+	case otherErr == nil:
+		return 1
+	}
+	return strings.Compare(ident, other)
+}
@@ -0,0 +1,378 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/semver"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Version parses the given version failing on errors.
+func Version(version string) *semver.Version {
+	v, err := semver.Parse(version)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+type ParseParams struct {
+	version       string
+	expectVersion *semver.Version
+	expectError   error
+}
+
+var parseTestCases = map[string]ParseParams{
+	"release": {
+		version:       "1.2.3",
+		expectVersion: &semver.Version{Major: 1, Minor: 2, Patch: 3},
+	},
+	"pre-release": {
+		version: "1.0.0-rc.1",
+		expectVersion: &semver.Version{
+			Major: 1, Pre: []string{"rc", "1"},
+		},
+	},
+	"build metadata": {
+		version: "1.0.0+build.007",
+		expectVersion: &semver.Version{
+			Major: 1, Build: []string{"build", "007"},
+		},
+	},
+	"pre-release with build metadata": {
+		version: "0.4.16-alpha-1.x.7+sha.5114f85",
+		expectVersion: &semver.Version{
+			Minor: 4, Patch: 16, Pre: []string{"alpha-1", "x", "7"},
+			Build: []string{"sha", "5114f85"},
+		},
+	},
+	"missing patch": {
+		version:     "1.2",
+		expectError: semver.NewErrInvalid("1.2"),
+	},
+	"leading zero": {
+		version:     "1.02.3",
+		expectError: semver.NewErrInvalid("1.02.3"),
+	},
+	"leading zero pre-release": {
+		version:     "1.2.3-rc.01",
+		expectError: semver.NewErrInvalid("1.2.3-rc.01"),
+	},
+	"prefix": {
+		version:     "v1.2.3",
+		expectError: semver.NewErrInvalid("v1.2.3"),
+	},
+	"empty pre-release": {
+		version:     "1.2.3-",
+		expectError: semver.NewErrInvalid("1.2.3-"),
+	},
+	"empty build metadata": {
+		version:     "1.2.3+",
+		expectError: semver.NewErrInvalid("1.2.3+"),
+	},
+	"overflow": {
+		version:     "18446744073709551616.0.0",
+		expectError: semver.NewErrInvalid("18446744073709551616.0.0"),
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, parseTestCases).
+		Run(func(t test.Test, param ParseParams) {
+			// When
+			version, err := semver.Parse(param.version)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectVersion, version)
+			if version != nil {
+				assert.Equal(t, param.version, version.String())
+			}
+		})
+}
+
+type ParseShortParams struct {
+	version       string
+	expectVersion string
+	expectError   error
+}
+
+var parseShortTestCases = map[string]ParseShortParams{
+	"release": {
+		version:       "1.2.3",
+		expectVersion: "1.2.3",
+	},
+	"missing patch": {
+		version:       "1.2",
+		expectVersion: "1.2.0",
+	},
+	"missing minor": {
+		version:       "1",
+		expectVersion: "1.0.0",
+	},
+	"missing patch with pre-release": {
+		version:       "1.2-rc.1+build",
+		expectVersion: "1.2.0-rc.1+build",
+	},
+	"leading zero": {
+		version:     "1.02",
+		expectError: semver.NewErrInvalid("1.02"),
+	},
+	"invalid pre-release": {
+		version:     "1.2-rc.01",
+		expectError: semver.NewErrInvalid("1.2-rc.01"),
+	},
+	"invalid": {
+		version:     "1.2.x",
+		expectError: semver.NewErrInvalid("1.2.x"),
+	},
+}
+
+func TestParseShort(t *testing.T) {
+	test.Map(t, parseShortTestCases).
+		Run(func(t test.Test, param ParseShortParams) {
+			// When
+			version, err := semver.ParseShort(param.version)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectVersion != "" {
+				assert.Equal(t, param.expectVersion, version.String())
+			} else {
+				assert.Nil(t, version)
+			}
+		})
+}
+
+type CompareParams struct {
+	version       string
+	other         string
+	expectCompare int
+}
+
+var compareTestCases = map[string]CompareParams{
+	"equal": {
+		version: "1.2.3", other: "1.2.3",
+	},
+	"equal build metadata": {
+		version: "1.2.3+build.1", other: "1.2.3+build.2",
+	},
+	"major lower": {
+		version: "1.9.9", other: "2.0.0", expectCompare: -1,
+	},
+	"minor higher": {
+		version: "1.10.0", other: "1.9.0", expectCompare: 1,
+	},
+	"patch lower": {
+		version: "1.2.3", other: "1.2.4", expectCompare: -1,
+	},
+	"pre-release lower": {
+		version: "1.0.0-rc.1", other: "1.0.0", expectCompare: -1,
+	},
+	"release higher": {
+		version: "1.0.0", other: "1.0.0-rc.1", expectCompare: 1,
+	},
+	"pre-release equal": {
+		version: "1.0.0-rc.1", other: "1.0.0-rc.1",
+	},
+	"pre-release numeric": {
+		version: "1.0.0-beta.2", other: "1.0.0-beta.11", expectCompare: -1,
+	},
+	"pre-release numeric lower than alpha": {
+		version: "1.0.0-1", other: "1.0.0-alpha", expectCompare: -1,
+	},
+	"pre-release alpha higher than numeric": {
+		version: "1.0.0-alpha", other: "1.0.0-1", expectCompare: 1,
+	},
+	"pre-release lexical": {
+		version: "1.0.0-alpha", other: "1.0.0-beta", expectCompare: -1,
+	},
+	"pre-release shorter": {
+		version: "1.0.0-alpha", other: "1.0.0-alpha.1", expectCompare: -1,
+	},
+}
+
+func TestCompare(t *testing.T) {
+	test.Map(t, compareTestCases).
+		Run(func(t test.Test, param CompareParams) {
+			// Given
+			version, other := Version(param.version), Version(param.other)
+
+			// When
+			result := version.Compare(other)
+
+			// Then
+			assert.Equal(t, param.expectCompare, result)
+		})
+}
+
+type BumpParams struct {
+	version       string
+	op            string
+	id            string
+	expectVersion string
+	expectError   error
+}
+
+var bumpTestCases = map[string]BumpParams{
+	"major": {
+		version: "1.2.3+build.1", op: semver.BumpMajor,
+		expectVersion: "2.0.0",
+	},
+	"major pre-release": {
+		version: "2.0.0-rc.1", op: semver.BumpMajor,
+		expectVersion: "2.0.0",
+	},
+	"major pre-release minor": {
+		version: "1.2.0-rc.1", op: semver.BumpMajor,
+		expectVersion: "2.0.0",
+	},
+	"minor": {
+		version: "1.2.3", op: semver.BumpMinor,
+		expectVersion: "1.3.0",
+	},
+	"minor pre-release": {
+		version: "1.3.0-rc.1", op: semver.BumpMinor,
+		expectVersion: "1.3.0",
+	},
+	"minor pre-release patch": {
+		version: "1.2.3-rc.1", op: semver.BumpMinor,
+		expectVersion: "1.3.0",
+	},
+	"patch": {
+		version: "1.2.3", op: semver.BumpPatch,
+		expectVersion: "1.2.4",
+	},
+	"patch pre-release": {
+		version: "1.2.3-rc.1", op: semver.BumpPatch,
+		expectVersion: "1.2.3",
+	},
+	"premajor": {
+		version: "1.2.3", op: semver.BumpPreMajor,
+		expectVersion: "2.0.0-0",
+	},
+	"premajor with id": {
+		version: "1.2.3", op: semver.BumpPreMajor, id: "rc",
+		expectVersion: "2.0.0-rc.0",
+	},
+	"preminor with id": {
+		version: "1.2.3", op: semver.BumpPreMinor, id: "beta",
+		expectVersion: "1.3.0-beta.0",
+	},
+	"prepatch": {
+		version: "1.2.3", op: semver.BumpPrePatch,
+		expectVersion: "1.2.4-0",
+	},
+	"prerelease of release": {
+		version: "1.2.3", op: semver.BumpPreRelease, id: "rc",
+		expectVersion: "1.2.4-rc.0",
+	},
+	"prerelease increment": {
+		version: "1.2.4-rc.0", op: semver.BumpPreRelease, id: "rc",
+		expectVersion: "1.2.4-rc.1",
+	},
+	"prerelease increment without id": {
+		version: "1.2.4-rc.9", op: semver.BumpPreRelease,
+		expectVersion: "1.2.4-rc.10",
+	},
+	"prerelease increment inner number": {
+		version: "1.2.4-rc.1.beta", op: semver.BumpPreRelease,
+		expectVersion: "1.2.4-rc.2.beta",
+	},
+	"prerelease append number": {
+		version: "1.2.4-beta", op: semver.BumpPreRelease,
+		expectVersion: "1.2.4-beta.0",
+	},
+	"prerelease switch id": {
+		version: "1.2.4-beta.3", op: semver.BumpPreRelease, id: "rc",
+		expectVersion: "1.2.4-rc.0",
+	},
+	"invalid op": {
+		version: "1.2.3", op: "micro",
+		expectError: semver.NewErrInvalidBump("micro", ""),
+	},
+	"invalid id": {
+		version: "1.2.3", op: semver.BumpPreRelease, id: "r_c",
+		expectError: semver.NewErrInvalidBump(semver.BumpPreRelease, "r_c"),
+	},
+	"invalid id with dot": {
+		version: "1.2.3", op: semver.BumpPreRelease, id: "rc.1",
+		expectError: semver.NewErrInvalidBump(semver.BumpPreRelease, "rc.1"),
+	},
+	"invalid id for release": {
+		version: "1.2.3", op: semver.BumpPatch, id: "rc",
+		expectError: semver.NewErrInvalidBump(semver.BumpPatch, "rc"),
+	},
+}
+
+func TestBump(t *testing.T) {
+	test.Map(t, bumpTestCases).
+		Run(func(t test.Test, param BumpParams) {
+			// Given
+			version := Version(param.version)
+
+			// When
+			next, err := version.Bump(param.op, param.id)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectVersion != "" {
+				assert.Equal(t, param.expectVersion, next.String())
+				assert.Equal(t, Version(param.version), version)
+			} else {
+				assert.Nil(t, next)
+			}
+		})
+}
+
+type LowerParams struct {
+	version       string
+	op            string
+	expectVersion string
+	expectError   error
+}
+
+var lowerTestCases = map[string]LowerParams{
+	"major": {
+		version: "2.3.4-rc.1+build", op: semver.BumpMajor,
+		expectVersion: "1.0.0",
+	},
+	"minor": {
+		version: "2.3.4", op: semver.BumpMinor,
+		expectVersion: "2.2.0",
+	},
+	"patch": {
+		version: "2.3.4", op: semver.BumpPatch,
+		expectVersion: "2.3.3",
+	},
+	"patch zero": {
+		version: "2.3.0", op: semver.BumpPatch,
+		expectError: semver.NewErrInvalidBump("patch-", ""),
+	},
+	"invalid op": {
+		version: "2.3.4", op: semver.BumpPreRelease,
+		expectError: semver.NewErrInvalidBump("prerelease-", ""),
+	},
+}
+
+func TestLower(t *testing.T) {
+	test.Map(t, lowerTestCases).
+		Run(func(t test.Test, param LowerParams) {
+			// Given
+			version := Version(param.version)
+
+			// When
+			next, err := version.Lower(param.op)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectVersion != "" {
+				assert.Equal(t, param.expectVersion, next.String())
+				assert.Equal(t, Version(param.version), version)
+			} else {
+				assert.Nil(t, next)
+			}
+		})
+}
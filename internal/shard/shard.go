@@ -0,0 +1,184 @@
+// Package shard partitions the packages of a test run into deterministic
+// shards for parallel test runs. The packages are partitioned by their
+// historical test durations as recorded from previous `go test -json` runs,
+// or by package count, if no durations are known.
+package shard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tkrop/go-make/internal/junit"
+)
+
+// ErrInvalidShard represents an invalid shard definition.
+var ErrInvalidShard = errors.New("invalid shard")
+
+// NewErrInvalidShard creates an invalid shard definition error for the given
+// shard definition.
+func NewErrInvalidShard(value string) error {
+	return fmt.Errorf("%w [shard=%s]", ErrInvalidShard, value)
+}
+
+// Shard contains the shard definition of a test run.
+type Shard struct {
+	// Index provides the one-based index of the shard.
+	Index int
+	// Count provides the number of shards.
+	Count int
+}
+
+// Parse parses the given shard definition of the form `<index>/<count>`
+// using a one-based index.
+func Parse(value string) (*Shard, error) {
+	index, count, ok := strings.Cut(value, "/")
+	if !ok {
+		return nil, NewErrInvalidShard(value)
+	}
+	shard := &Shard{}
+	var err error
+	if shard.Index, err = strconv.Atoi(index); err != nil {
+		return nil, NewErrInvalidShard(value)
+	} else if shard.Count, err = strconv.Atoi(count); err != nil {
+		return nil, NewErrInvalidShard(value)
+	} else if shard.Index < 1 || shard.Index > shard.Count {
+		return nil, NewErrInvalidShard(value)
+	}
+	return shard, nil
+}
+
+// String returns the shard definition of the form `<index>/<count>`.
+func (s *Shard) String() string {
+	return strconv.Itoa(s.Index) + "/" + strconv.Itoa(s.Count)
+}
+
+// Select returns the packages of the shard from the given packages using the
+// given weights. The packages are returned in the given order.
+func (s *Shard) Select(
+	packages []string, weights map[string]float64,
+) []string {
+	shard := Partition(packages, weights, s.Count)[s.Index-1]
+	selected := []string{}
+	for _, pkg := range packages {
+		if slices.Contains(shard, pkg) {
+			selected = append(selected, pkg)
+		}
+	}
+	return selected
+}
+
+// Partition partitions the given packages into the given number of shards
+// using the given weights, e.g. the test durations. Packages without weight
+// are weighted by the average weight of the known packages, or equally, if no
+// weight is known, so that the packages are partitioned by count. The
+// packages are assigned in order of descending weight to the shard with the
+// least total weight, resolving ties by package name and shard index.
+func Partition(
+	packages []string, weights map[string]float64, count int,
+) [][]string {
+	total, known := 0.0, 0
+	for _, pkg := range packages {
+		if weight, ok := weights[pkg]; ok {
+			total, known = total+weight, known+1
+		}
+	}
+	deflt := 1.0
+	if known != 0 && total > 0 {
+		deflt = total / float64(known)
+	}
+
+	weight := func(pkg string) float64 {
+		if weight, ok := weights[pkg]; ok {
+			return weight
+		}
+		return deflt
+	}
+
+	sorted := slices.Clone(packages)
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		if wa, wb := weight(a), weight(b); wa != wb {
+			if wa > wb {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	shards := make([][]string, count)
+	totals := make([]float64, count)
+	for _, pkg := range sorted {
+		index := 0
+		for i := 1; i < count; i++ {
+			if totals[i] < totals[index] {
+				index = i
+			}
+		}
+		shards[index] = append(shards[index], pkg)
+		totals[index] += weight(pkg)
+	}
+	return shards
+}
+
+// ErrDurations represents a test durations failure.
+var ErrDurations = errors.New("test durations failed")
+
+// NewErrDurations wraps the error of a failed test durations operation.
+func NewErrDurations(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrDurations, file, err)
+}
+
+// Durations contains the historical test durations in seconds by package
+// import path.
+type Durations map[string]float64
+
+// ReadDurations reads the test durations from the given file. A missing file
+// results in empty test durations.
+func ReadDurations(file string) (Durations, error) {
+	durations := Durations{}
+	// #nosec G304 -- file is the test durations file of the project.
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return durations, nil
+	} else if err != nil {
+		return nil, NewErrDurations(file, err)
+	}
+	if err := json.Unmarshal(data, &durations); err != nil {
+		return nil, NewErrDurations(file, err)
+	}
+	return durations, nil
+}
+
+// Update updates the test durations with the elapsed times of the completed
+// packages of the given report, i.e. of passed and failed packages.
+func (d Durations) Update(report *junit.Report) {
+	for _, suite := range report.Suites {
+		if suite.Action == junit.ActionPass ||
+			suite.Action == junit.ActionFail {
+			d[suite.Name] = suite.Elapsed
+		}
+	}
+}
+
+// Write writes the test durations to the given file creating the parent
+// directories as needed.
+func (d Durations) Write(file string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return NewErrDurations(file, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return NewErrDurations(file, err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o600); err != nil {
+		return NewErrDurations(file, err)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package shard_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/junit"
+	"github.com/tkrop/go-make/internal/shard"
+	"github.com/tkrop/go-testing/test"
+)
+
+type ParseParams struct {
+	value       string
+	expectShard *shard.Shard
+	expectError error
+}
+
+var parseTestCases = map[string]ParseParams{
+	"first shard": {
+		value:       "1/4",
+		expectShard: &shard.Shard{Index: 1, Count: 4},
+	},
+	"last shard": {
+		value:       "4/4",
+		expectShard: &shard.Shard{Index: 4, Count: 4},
+	},
+	"missing separator": {
+		value:       "2",
+		expectError: shard.NewErrInvalidShard("2"),
+	},
+	"invalid index": {
+		value:       "x/4",
+		expectError: shard.NewErrInvalidShard("x/4"),
+	},
+	"invalid count": {
+		value:       "2/x",
+		expectError: shard.NewErrInvalidShard("2/x"),
+	},
+	"zero index": {
+		value:       "0/4",
+		expectError: shard.NewErrInvalidShard("0/4"),
+	},
+	"index beyond count": {
+		value:       "5/4",
+		expectError: shard.NewErrInvalidShard("5/4"),
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, parseTestCases).
+		Run(func(t test.Test, param ParseParams) {
+			// Given
+
+			// When
+			shard, err := shard.Parse(param.value)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectShard, shard)
+			if shard != nil {
+				assert.Equal(t, param.value, shard.String())
+			}
+		})
+}
+
+type PartitionParams struct {
+	packages     []string
+	weights      map[string]float64
+	count        int
+	expectShards [][]string
+}
+
+var partitionTestCases = map[string]PartitionParams{
+	"by count": {
+		packages: []string{"./e", "./d", "./c", "./b", "./a"},
+		count:    2,
+		expectShards: [][]string{
+			{"./a", "./c", "./e"}, {"./b", "./d"},
+		},
+	},
+	"by duration": {
+		packages: []string{"./a", "./b", "./c", "./d"},
+		weights: map[string]float64{
+			"./a": 10, "./b": 6, "./c": 3, "./d": 2,
+		},
+		count: 2,
+		expectShards: [][]string{
+			{"./a"}, {"./b", "./c", "./d"},
+		},
+	},
+	"by average duration": {
+		packages: []string{"./a", "./b", "./c", "./d"},
+		weights: map[string]float64{
+			"./a": 8, "./b": 4, "./x": 100,
+		},
+		count: 2,
+		expectShards: [][]string{
+			{"./a", "./b"}, {"./c", "./d"},
+		},
+	},
+	"zero durations": {
+		packages: []string{"./a", "./b", "./c"},
+		weights:  map[string]float64{"./a": 0, "./b": 0},
+		count:    2,
+		expectShards: [][]string{
+			{"./c"}, {"./a", "./b"},
+		},
+	},
+	"empty shards": {
+		packages:     []string{"./a"},
+		count:        3,
+		expectShards: [][]string{{"./a"}, nil, nil},
+	},
+}
+
+func TestPartition(t *testing.T) {
+	test.Map(t, partitionTestCases).
+		Run(func(t test.Test, param PartitionParams) {
+			// Given
+
+			// When
+			shards := shard.Partition(param.packages,
+				param.weights, param.count)
+
+			// Then
+			assert.Equal(t, param.expectShards, shards)
+		})
+}
+
+func TestSelect(t *testing.T) {
+	// Given
+	packages := []string{"./d", "./c", "./b", "./a"}
+	weights := map[string]float64{"./a": 1, "./b": 2, "./c": 3, "./d": 4}
+
+	// When
+	first := (&shard.Shard{Index: 1, Count: 2}).Select(packages, weights)
+	second := (&shard.Shard{Index: 2, Count: 2}).Select(packages, weights)
+
+	// Then
+	assert.Equal(t, []string{"./d", "./a"}, first)
+	assert.Equal(t, []string{"./c", "./b"}, second)
+}
+
+type ReadDurationsParams struct {
+	content         *string
+	dir             bool
+	expectDurations shard.Durations
+	expectError     func(file string) error
+}
+
+// content returns a pointer to the given content.
+func content(content string) *string {
+	return &content
+}
+
+var readDurationsTestCases = map[string]ReadDurationsParams{
+	"missing file": {
+		expectDurations: shard.Durations{},
+	},
+	"valid file": {
+		content: content(`{"example.com/a":1.5,"example.com/b":2}`),
+		expectDurations: shard.Durations{
+			"example.com/a": 1.5, "example.com/b": 2,
+		},
+	},
+	"invalid file": {
+		content: content(`[]`),
+		expectError: func(file string) error {
+			return shard.NewErrDurations(file, errors.New("json: cannot "+
+				"unmarshal array into Go value of type shard.Durations"))
+		},
+	},
+	"read failed": {
+		dir: true,
+		expectError: func(file string) error {
+			return shard.NewErrDurations(file, &fs.PathError{
+				Op: "read", Path: file, Err: syscall.EISDIR,
+			})
+		},
+	},
+}
+
+func TestReadDurations(t *testing.T) {
+	test.Map(t, readDurationsTestCases).
+		Run(func(t test.Test, param ReadDurationsParams) {
+			// Given
+			file := filepath.Join(t.TempDir(), "durations.json")
+			if param.content != nil {
+				assert.NoError(t, os.WriteFile(file,
+					[]byte(*param.content), 0o600))
+			} else if param.dir {
+				assert.NoError(t, os.Mkdir(file, 0o750))
+			}
+
+			// When
+			durations, err := shard.ReadDurations(file)
+
+			// Then
+			if param.expectError != nil {
+				assert.ErrorIs(t, err, shard.ErrDurations)
+				assert.Equal(t, param.expectError(file).Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectDurations, durations)
+		})
+}
+
+func TestDurationsUpdate(t *testing.T) {
+	// Given
+	report := junit.NewReport()
+	assert.NoError(t, junit.Convert(strings.NewReader(""+
+		`{"Action":"pass","Package":"example.com/a","Elapsed":1.5}`+"\n"+
+		`{"Action":"fail","Package":"example.com/b","Elapsed":2}`+"\n"+
+		`{"Action":"skip","Package":"example.com/c","Elapsed":0}`+"\n",
+	), io.Discard, report))
+	durations := shard.Durations{"example.com/a": 3, "example.com/d": 4}
+
+	// When
+	durations.Update(report)
+
+	// Then
+	assert.Equal(t, shard.Durations{
+		"example.com/a": 1.5, "example.com/b": 2, "example.com/d": 4,
+	}, durations)
+}
+
+type WriteDurationsParams struct {
+	file          string
+	durations     shard.Durations
+	setup         func(test.Test, string)
+	expectContent string
+	expectError   func(string) error
+}
+
+var writeDurationsTestCases = map[string]WriteDurationsParams{
+	"new file": {
+		file: "build/durations.json",
+		durations: shard.Durations{
+			"example.com/b": 2, "example.com/a": 1.5,
+		},
+		expectContent: "{\n  \"example.com/a\": 1.5,\n" +
+			"  \"example.com/b\": 2\n}\n",
+	},
+	"marshal failed": {
+		file:      "durations.json",
+		durations: shard.Durations{"example.com/a": math.Inf(1)},
+		expectError: func(file string) error {
+			return shard.NewErrDurations(file, &json.UnsupportedValueError{
+				Str: "+Inf",
+			})
+		},
+	},
+	"mkdir failed": {
+		file:      "build/durations.json",
+		durations: shard.Durations{},
+		setup: func(t test.Test, file string) {
+			assert.NoError(t, os.WriteFile(
+				filepath.Dir(file), []byte{}, 0o600))
+		},
+		expectError: func(file string) error {
+			return shard.NewErrDurations(file, &fs.PathError{
+				Op: "mkdir", Path: filepath.Dir(file), Err: syscall.ENOTDIR,
+			})
+		},
+	},
+	"write failed": {
+		file:      "durations.json",
+		durations: shard.Durations{},
+		setup: func(t test.Test, file string) {
+			assert.NoError(t, os.Mkdir(file, 0o750))
+		},
+		expectError: func(file string) error {
+			return shard.NewErrDurations(file, &fs.PathError{
+				Op: "open", Path: file, Err: syscall.EISDIR,
+			})
+		},
+	},
+}
+
+func TestDurationsWrite(t *testing.T) {
+	test.Map(t, writeDurationsTestCases).
+		Run(func(t test.Test, param WriteDurationsParams) {
+			// Given
+			file := filepath.Join(t.TempDir(), param.file)
+			if param.setup != nil {
+				param.setup(t, file)
+			}
+
+			// When
+			err := param.durations.Write(file)
+
+			// Then
+			if param.expectError != nil {
+				assert.ErrorIs(t, err, shard.ErrDurations)
+				assert.Equal(t, param.expectError(file).Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			data, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectContent, string(data))
+		})
+}
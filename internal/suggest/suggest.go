@@ -0,0 +1,172 @@
+// Package suggest provides "did you mean" suggestions for unknown targets
+// based on the edit distance and prefix matching against the known targets
+// and target families.
+package suggest
+
+import (
+	"slices"
+	"strings"
+)
+
+// MaxSuggestions provides the maximum number of suggestions returned.
+const MaxSuggestions = 5
+
+// Suggester provides suggestions for unknown targets based on the known
+// targets and the make patterns of the known target families, e.g. `run-%`.
+type Suggester struct {
+	// names contains the sorted known target names.
+	names []string
+	// patterns contains the make patterns of the known target families.
+	patterns []string
+	// rules contains the make patterns of the pattern rules, that are known
+	// but never suggested.
+	rules []string
+}
+
+// New creates a new suggester for the given known target names and the given
+// make patterns of target families with a single `%` wildcard.
+func New(names, patterns []string) *Suggester {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	return &Suggester{
+		names:    slices.Compact(names),
+		patterns: patterns,
+	}
+}
+
+// WithRules adds the given make patterns of pattern rules, e.g. `%.o`, to the
+// suggester. Names matching these patterns are known, while the patterns are
+// not used for suggestions, since they usually match any file name.
+func (s *Suggester) WithRules(patterns ...string) *Suggester {
+	s.rules = append(s.rules, patterns...)
+	return s
+}
+
+// Known returns whether the given name is a known target or matches one of
+// the known target families or pattern rules.
+func (s *Suggester) Known(name string) bool {
+	if _, ok := slices.BinarySearch(s.names, name); ok {
+		return true
+	}
+	for _, pattern := range slices.Concat(s.patterns, s.rules) {
+		if _, ok := stem(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// candidate represents a suggestion with its edit distance.
+type candidate struct {
+	// name contains the suggested target name.
+	name string
+	// distance contains the edit distance to the unknown name.
+	distance int
+}
+
+// Suggest returns the suggestions for the given unknown name ordered by edit
+// distance and name. A known target is suggested, if its edit distance is
+// within the tolerance of the name length, or if the name is a prefix of it.
+// The tolerance allows one edit per three characters, but at least one.
+// A target family is suggested by replacing the part of the name that is
+// close to the fixed parts of the family pattern, e.g. `run-og-cmd` results
+// in `run-go-cmd` for the pattern `run-go-%`.
+func (s *Suggester) Suggest(name string) []string {
+	limit := max(1, len(name)/3) //nolint:mnd // one typo per three chars.
+
+	candidates := map[string]int{}
+	for _, target := range s.names {
+		if dist := Distance(name, target); dist <= limit ||
+			strings.HasPrefix(target, name) {
+			candidates[target] = dist
+		}
+	}
+	for _, pattern := range s.patterns {
+		// The tolerance of target families depends on their fixed parts only.
+		limit := max(1, (len(pattern)-1)/3) //nolint:mnd // see above.
+		if target, dist := family(pattern, name); dist <= limit {
+			if found, ok := candidates[target]; !ok || dist < found {
+				candidates[target] = dist
+			}
+		}
+	}
+	delete(candidates, name)
+
+	sorted := make([]candidate, 0, len(candidates))
+	for target, dist := range candidates {
+		sorted = append(sorted, candidate{name: target, distance: dist})
+	}
+	slices.SortFunc(sorted, func(x, y candidate) int {
+		if x.distance != y.distance {
+			return x.distance - y.distance
+		}
+		return strings.Compare(x.name, y.name)
+	})
+
+	suggestions := make([]string, 0, min(len(sorted), MaxSuggestions))
+	for _, candidate := range sorted[:min(len(sorted), MaxSuggestions)] {
+		suggestions = append(suggestions, candidate.name)
+	}
+	return suggestions
+}
+
+// family returns the member of the target family with given make pattern
+// closest to the given name together with its edit distance. The name is
+// split into a prefix, a non-empty stem, and a suffix, so that the sum of the
+// edit distances of prefix and suffix to the fixed parts of the pattern is
+// minimal.
+func family(pattern, name string) (string, int) {
+	prefix, suffix, _ := strings.Cut(pattern, "%")
+
+	target, dist := "", len(name)+len(pattern)
+	for i := 0; i < len(name); i++ {
+		head := Distance(name[:i], prefix)
+		for j := len(name); j > i; j-- {
+			if d := head + Distance(name[j:], suffix); d < dist {
+				target, dist = prefix+name[i:j]+suffix, d
+			}
+		}
+	}
+	return target, dist
+}
+
+// stem returns the stem of the given name matched by the `%` wildcard of the
+// given make pattern, and whether the pattern matches the name at all.
+func stem(pattern, name string) (string, bool) {
+	prefix, suffix, _ := strings.Cut(pattern, "%")
+	if len(name) <= len(prefix)+len(suffix) ||
+		!strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	return name[len(prefix) : len(name)-len(suffix)], true
+}
+
+// Distance returns the optimal string alignment distance between the given
+// strings, i.e. the number of insertions, deletions, substitutions, and
+// transpositions of adjacent characters needed to transform one string into
+// the other.
+func Distance(x, y string) int {
+	rows := make([][]int, len(x)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(y)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1,
+				rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(x)][len(y)]
+}
@@ -0,0 +1,176 @@
+package suggest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/suggest"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// names contains the known target names of the tests.
+	names = []string{
+		"all", "build", "build-linux", "commit", "lint", "lint-base",
+		"lint-markdown", "run-go-service", "test", "test-all", "test-unit",
+		"test", "version-bump",
+	}
+	// patterns contains the target family patterns of the tests.
+	patterns = []string{"build-%", "run-go-%", "image-%-push"}
+	// rules contains the pattern rules of the tests.
+	rules = []string{"%.o", "bin/%"}
+)
+
+type DistanceParams struct {
+	x, y           string
+	expectDistance int
+}
+
+var distanceTestCases = map[string]DistanceParams{
+	"equal": {
+		x: "test", y: "test",
+		expectDistance: 0,
+	},
+	"empty": {
+		x: "", y: "test",
+		expectDistance: 4,
+	},
+	"insertion": {
+		x: "tst", y: "test",
+		expectDistance: 1,
+	},
+	"deletion": {
+		x: "tesst", y: "test",
+		expectDistance: 1,
+	},
+	"substitution": {
+		x: "tast", y: "test",
+		expectDistance: 1,
+	},
+	"transposition": {
+		x: "tset", y: "test",
+		expectDistance: 1,
+	},
+	"different": {
+		x: "lint", y: "test",
+		expectDistance: 3,
+	},
+}
+
+func TestDistance(t *testing.T) {
+	test.Map(t, distanceTestCases).
+		Run(func(t test.Test, param DistanceParams) {
+			// When
+			distance := suggest.Distance(param.x, param.y)
+
+			// Then
+			assert.Equal(t, param.expectDistance, distance)
+		})
+}
+
+type KnownParams struct {
+	name        string
+	expectKnown bool
+}
+
+var knownTestCases = map[string]KnownParams{
+	"target": {
+		name:        "test-unit",
+		expectKnown: true,
+	},
+	"target family": {
+		name:        "run-go-other",
+		expectKnown: true,
+	},
+	"target family with suffix": {
+		name:        "image-base-push",
+		expectKnown: true,
+	},
+	"target family without stem": {
+		name: "run-go-",
+	},
+	"pattern rule": {
+		name:        "main.o",
+		expectKnown: true,
+	},
+	"pattern rule with prefix": {
+		name:        "bin/go-make",
+		expectKnown: true,
+	},
+	"target unknown": {
+		name: "tset",
+	},
+}
+
+func TestKnown(t *testing.T) {
+	suggester := suggest.New(names, patterns).WithRules(rules...)
+
+	test.Map(t, knownTestCases).
+		Run(func(t test.Test, param KnownParams) {
+			// When
+			known := suggester.Known(param.name)
+
+			// Then
+			assert.Equal(t, param.expectKnown, known)
+		})
+}
+
+type SuggestParams struct {
+	name              string
+	expectSuggestions []string
+}
+
+var suggestTestCases = map[string]SuggestParams{
+	"transposition": {
+		name:              "tset",
+		expectSuggestions: []string{"test"},
+	},
+	"ambiguous": {
+		name: "tes",
+		expectSuggestions: []string{
+			"test", "test-all", "test-unit",
+		},
+	},
+	"prefix": {
+		name: "lint-",
+		expectSuggestions: []string{
+			"lint", "lint-base", "lint-markdown",
+		},
+	},
+	"target family": {
+		name:              "run-og-other",
+		expectSuggestions: []string{"run-go-other"},
+	},
+	"target family with suffix": {
+		name:              "image-base-psuh",
+		expectSuggestions: []string{"image-base-push"},
+	},
+	"target family and target": {
+		name:              "biuld-linux",
+		expectSuggestions: []string{"build-linux"},
+	},
+	"limited": {
+		name: "",
+		expectSuggestions: []string{
+			"all", "lint", "test", "build", "commit",
+		},
+	},
+	"none": {
+		name:              "deploy",
+		expectSuggestions: []string{},
+	},
+}
+
+func TestSuggest(t *testing.T) {
+	suggester := suggest.New(names, patterns)
+
+	test.Map(t, suggestTestCases).
+		Run(func(t test.Test, param SuggestParams) {
+			// When
+			suggestions := suggester.Suggest(param.name)
+
+			// Then
+			assert.Equal(t, param.expectSuggestions, suggestions)
+		})
+}
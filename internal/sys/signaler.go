@@ -5,30 +5,43 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
 // Signals a common and extensive list of operating system signals to wait
-// for before shutting down a service or daemon.
+// for before shutting down a service or daemon. `SIGSTOP` and `SIGKILL` are
+// not included, since they cannot be caught.
 var Signals = []os.Signal{
 	syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT,
-	syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGSTOP,
+	syscall.SIGABRT, syscall.SIGQUIT,
 }
 
-// SignalFunc defines the function signature for signal handler functions.
-type SignalFunc func(cancel context.CancelFunc, signal os.Signal)
+// SignalFunc defines the function signature for signal handler functions. The
+// count provides the number of signals received since activation, allowing
+// handlers to distinguish the first signal (count 1) from repeated signals.
+type SignalFunc func(cancel context.CancelFunc, signal os.Signal, count int)
 
 // Signaler provides a signal handler that listens for configured signals
-// and calls the provided signal handler function when a signal is received.
+// and calls the provided signal handler function for each signal received
+// until it is stopped or the context is done.
 type Signaler struct {
 	handler SignalFunc
+	reload  func()
 	signals []os.Signal
 	channel chan os.Signal
+
+	// mutex protects the stop channel and the cancel function.
+	mutex sync.Mutex
+	// stop is closed to stop the active signal listener.
+	stop chan struct{}
+	// cancel cancels the context of the active signal listener.
+	cancel context.CancelFunc
 }
 
 // NewSignaler returns a new signal handler that listens for the given
 // signals and calls the provided signal handler function when a signal is
-// received. To activate the signal handler, call the `Handle` method with a
+// received. To activate the signal handler, call the `Signal` method with a
 // context to setup the cancel context and start listening for signals.
 func NewSignaler(handler SignalFunc, signals ...os.Signal) *Signaler {
 	return &Signaler{
@@ -38,25 +51,91 @@ func NewSignaler(handler SignalFunc, signals ...os.Signal) *Signaler {
 	}
 }
 
+// WithReload sets up the given reload function as hook for `SIGHUP`. If set,
+// a `SIGHUP` calls the reload function instead of the signal handler and is
+// not counted as a repeated signal. This is intended for long-running modes.
+func (s *Signaler) WithReload(reload func()) *Signaler {
+	s.reload = reload
+	for _, signal := range s.signals {
+		if signal == syscall.SIGHUP {
+			return s
+		}
+	}
+	s.signals = append(s.signals, syscall.SIGHUP)
+	return s
+}
+
 // Signal extends the given context with a cancel context and starts listening
-// for the configured signals to call the provided signal handler function.
+// for the configured signals to call the provided signal handler function. A
+// signaler can be reused, i.e. calling `Signal` again restarts the listener
+// and resets the signal count, while the context of the previous listener is
+// cancelled.
 func (s *Signaler) Signal(ctx context.Context) context.Context {
 	ctx, cancel := context.WithCancel(ctx)
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unregister()
+
+	s.stop, s.cancel = make(chan struct{}), cancel
 	signal.Notify(s.channel, s.signals...)
-	go s.signal(ctx, cancel)
+	go s.signal(ctx, cancel, s.stop)
 
 	return ctx
 }
 
+// Stop stops listening for signals and unregisters the signal channel, so
+// that signals are handled by their default behavior again. It also cancels
+// the context of the listener to release its resources. Stopping an inactive
+// signaler is a no-op.
+func (s *Signaler) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unregister()
+}
+
+// Close stops listening for signals implementing `io.Closer`.
+func (s *Signaler) Close() error {
+	s.Stop()
+	return nil
+}
+
+// unregister unregisters the signal channel, stops the active listener, and
+// cancels its context. The caller must hold the mutex.
+func (s *Signaler) unregister() {
+	if s.stop != nil {
+		signal.Stop(s.channel)
+		close(s.stop)
+		s.cancel()
+		s.stop, s.cancel = nil, nil
+	}
+}
+
 // signal waits for signals and calls the provided signal handler function
-// when a signal is received or exits when the context is done.
+// for each signal received until the signaler is stopped or the context is
+// done.
 func (s *Signaler) signal(
-	ctx context.Context, cancel context.CancelFunc,
+	ctx context.Context, cancel context.CancelFunc, stop chan struct{},
 ) {
-	select {
-	case signal := <-s.channel:
-		s.handler(cancel, signal)
-	case <-ctx.Done():
+	count := 0
+	for {
+		select {
+		case signal := <-s.channel:
+			if signal == syscall.SIGHUP && s.reload != nil {
+				s.reload()
+				continue
+			}
+			count++
+			s.handler(cancel, signal, count)
+		case <-ctx.Done():
+			s.mutex.Lock()
+			if s.stop == stop {
+				s.unregister()
+			}
+			s.mutex.Unlock()
+			return
+		case <-stop:
+			return
+		}
 	}
 }
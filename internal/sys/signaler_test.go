@@ -12,6 +12,22 @@ import (
 	"github.com/tkrop/go-make/internal/sys"
 )
 
+// sendSignal sends the given signal to the current process after a short
+// delay.
+func sendSignal(signal os.Signal) {
+	time.Sleep(20 * time.Millisecond)
+	proc, _ := os.FindProcess(os.Getpid())
+	_ = proc.Signal(signal)
+}
+
+func TestSignals(t *testing.T) {
+	t.Parallel()
+
+	// Then
+	assert.NotContains(t, sys.Signals, syscall.SIGSTOP)
+	assert.NotContains(t, sys.Signals, syscall.SIGKILL)
+}
+
 func TestSignaler(t *testing.T) {
 	t.Parallel()
 
@@ -19,23 +35,23 @@ func TestSignaler(t *testing.T) {
 	done := make(chan struct{})
 	signaled := atomic.Value{}
 	cancelled := atomic.Bool{}
+	counted := atomic.Int32{}
 
-	signaler := sys.NewSignaler(func(cancel context.CancelFunc, sig os.Signal) {
+	signaler := sys.NewSignaler(func(
+		cancel context.CancelFunc, sig os.Signal, count int,
+	) {
 		assert.NotNil(t, cancel)
 		cancelled.Store(true)
 		signaled.Store(sig)
+		counted.Store(int32(count))
 		close(done)
+		cancel()
 	}, syscall.SIGUSR1)
+	defer signaler.Stop()
 
 	// When
 	ctx := signaler.Signal(context.Background())
-
-	go func() {
-		// Send SIGUSR1 to self after a short delay.
-		time.Sleep(20 * time.Millisecond)
-		proc, _ := os.FindProcess(os.Getpid())
-		_ = proc.Signal(syscall.SIGUSR1)
-	}()
+	go sendSignal(syscall.SIGUSR1)
 
 	select {
 	case <-done: // handler should be called.
@@ -46,6 +62,7 @@ func TestSignaler(t *testing.T) {
 	// Then
 	assert.True(t, cancelled.Load())
 	assert.Equal(t, syscall.SIGUSR1, signaled.Load())
+	assert.Equal(t, int32(1), counted.Load())
 	assert.NotNil(t, ctx.Done())
 }
 
@@ -54,7 +71,7 @@ func TestSignalerDone(t *testing.T) {
 
 	// Given
 	called := atomic.Bool{}
-	signaler := sys.NewSignaler(func(_ context.CancelFunc, _ os.Signal) {
+	signaler := sys.NewSignaler(func(context.CancelFunc, os.Signal, int) {
 		called.Store(true) // should not be called in this test.
 	}, syscall.SIGUSR1)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -67,3 +84,109 @@ func TestSignalerDone(t *testing.T) {
 	// Then
 	assert.False(t, called.Load())
 }
+
+func TestSignalerRepeated(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	done := make(chan struct{})
+	counts := make(chan int, 2)
+	signaler := sys.NewSignaler(func(
+		_ context.CancelFunc, _ os.Signal, count int,
+	) {
+		counts <- count
+		if count == 2 {
+			close(done)
+		}
+	}, syscall.SIGUSR2)
+	defer signaler.Stop()
+
+	// When
+	signaler.Signal(context.Background())
+	go func() {
+		sendSignal(syscall.SIGUSR2)
+		sendSignal(syscall.SIGUSR2)
+	}()
+
+	select {
+	case <-done: // handler should be called twice.
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("timeout waiting for repeated signal")
+	}
+
+	// Then
+	assert.Equal(t, 1, <-counts)
+	assert.Equal(t, 2, <-counts)
+}
+
+func TestSignalerStop(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	called := atomic.Int32{}
+	signaler := sys.NewSignaler(func(context.CancelFunc, os.Signal, int) {
+		called.Add(1)
+	}, syscall.SIGWINCH)
+
+	// When
+	ctx := signaler.Signal(context.Background())
+	signaler.Stop()
+	sendSignal(syscall.SIGWINCH)
+	time.Sleep(20 * time.Millisecond)
+
+	// Then
+	assert.Equal(t, int32(0), called.Load())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.NoError(t, signaler.Close())
+}
+
+func TestSignalerReuse(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	counts := make(chan int, 2)
+	signaler := sys.NewSignaler(func(
+		_ context.CancelFunc, _ os.Signal, count int,
+	) {
+		counts <- count
+	}, syscall.SIGCONT)
+	defer signaler.Stop()
+
+	// When
+	ctx := signaler.Signal(context.Background())
+	sendSignal(syscall.SIGCONT)
+	first := <-counts
+	signaler.Signal(context.Background())
+	sendSignal(syscall.SIGCONT)
+	second := <-counts
+
+	// Then
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 1, second)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestSignalerReload(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	done := make(chan struct{})
+	called := atomic.Bool{}
+	signaler := sys.NewSignaler(func(context.CancelFunc, os.Signal, int) {
+		called.Store(true) // should not be called in this test.
+	}).WithReload(func() { close(done) })
+	defer signaler.Stop()
+
+	// When
+	signaler.Signal(context.Background())
+	go sendSignal(syscall.SIGHUP)
+
+	select {
+	case <-done: // reload should be called.
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for reload")
+	}
+
+	// Then
+	assert.False(t, called.Load())
+}
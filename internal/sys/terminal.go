@@ -0,0 +1,37 @@
+package sys
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// IsTerminal returns whether the given input or output stream is a terminal
+// device. Other character devices, e.g. `/dev/null`, are no terminals, since
+// reading their terminal attributes fails.
+func IsTerminal(stream any) bool {
+	if file, ok := stream.(*os.File); ok && file != nil {
+		_, err := unix.IoctlGetTermios(int(file.Fd()), ioctlReadTermios)
+		return err == nil
+	}
+	return false
+}
+
+// IsForeground returns whether the given input or output stream is the
+// controlling terminal of the current process and the process group of the
+// current process is the foreground process group of this terminal, i.e.
+// whether the process receives the signals generated by the terminal.
+func IsForeground(stream any) bool {
+	if file, ok := stream.(*os.File); ok && file != nil {
+		pgrp := int32(0)
+		// #nosec G103 -- required to read the terminal process group.
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(),
+			uintptr(syscall.TIOCGPGRP),
+			uintptr(unsafe.Pointer(&pgrp))); errno == 0 {
+			return int(pgrp) == syscall.Getpgrp()
+		}
+	}
+	return false
+}
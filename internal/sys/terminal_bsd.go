@@ -0,0 +1,8 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package sys
+
+import "golang.org/x/sys/unix"
+
+// ioctlReadTermios provides the ioctl request to read the terminal attributes.
+const ioctlReadTermios = unix.TIOCGETA
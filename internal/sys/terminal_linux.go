@@ -0,0 +1,6 @@
+package sys
+
+import "golang.org/x/sys/unix"
+
+// ioctlReadTermios provides the ioctl request to read the terminal attributes.
+const ioctlReadTermios = unix.TCGETS
@@ -0,0 +1,110 @@
+package sys_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/sys"
+	"github.com/tkrop/go-testing/test"
+)
+
+type IsTerminalParams struct {
+	stream         func(t test.Test) any
+	expectTerminal bool
+}
+
+var isTerminalTestCases = map[string]IsTerminalParams{
+	"nil stream": {
+		stream: func(test.Test) any { return nil },
+	},
+	"nil file": {
+		stream: func(test.Test) any { return (*os.File)(nil) },
+	},
+	"string builder": {
+		stream: func(test.Test) any { return &strings.Builder{} },
+	},
+	"regular file": {
+		stream: func(t test.Test) any {
+			file, err := os.CreateTemp(t.TempDir(), "terminal")
+			assert.NoError(t, err)
+			return file
+		},
+	},
+	"closed file": {
+		stream: func(t test.Test) any {
+			file, err := os.CreateTemp(t.TempDir(), "terminal")
+			assert.NoError(t, err)
+			assert.NoError(t, file.Close())
+			return file
+		},
+	},
+	"character device": {
+		stream: func(t test.Test) any {
+			file, err := os.Open(os.DevNull)
+			assert.NoError(t, err)
+			return file
+		},
+	},
+}
+
+func TestIsTerminal(t *testing.T) {
+	test.Map(t, isTerminalTestCases).
+		Run(func(t test.Test, param IsTerminalParams) {
+			// Given
+			stream := param.stream(t)
+
+			// When
+			terminal := sys.IsTerminal(stream)
+
+			// Then
+			assert.Equal(t, param.expectTerminal, terminal)
+		})
+}
+
+type IsForegroundParams struct {
+	stream           func(t test.Test) any
+	expectForeground bool
+}
+
+var isForegroundTestCases = map[string]IsForegroundParams{
+	"nil stream": {
+		stream: func(test.Test) any { return nil },
+	},
+	"nil file": {
+		stream: func(test.Test) any { return (*os.File)(nil) },
+	},
+	"string builder": {
+		stream: func(test.Test) any { return &strings.Builder{} },
+	},
+	"regular file": {
+		stream: func(t test.Test) any {
+			file, err := os.CreateTemp(t.TempDir(), "terminal")
+			assert.NoError(t, err)
+			return file
+		},
+	},
+	"character device": {
+		stream: func(t test.Test) any {
+			file, err := os.Open(os.DevNull)
+			assert.NoError(t, err)
+			return file
+		},
+	},
+}
+
+func TestIsForeground(t *testing.T) {
+	test.Map(t, isForegroundTestCases).
+		Run(func(t test.Test, param IsForegroundParams) {
+			// Given
+			stream := param.stream(t)
+
+			// When
+			foreground := sys.IsForeground(stream)
+
+			// Then
+			assert.Equal(t, param.expectForeground, foreground)
+		})
+}
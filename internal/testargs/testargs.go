@@ -0,0 +1,230 @@
+// Package testargs resolves the unified test arguments of the test targets
+// into the arguments of `go test`. The unified test arguments allow to define
+// multiple test goals using the following grammar:
+//
+//   - `scope:<pkg>` adds the package to the coverage packages (`-coverpkg`),
+//     where `scope:.` covers all packages (`./...`).
+//   - `<file>_test.go` adds the test file together with the source files and
+//     the test helper files, i.e. `common_test.go` and `mock_*_test.go`, of
+//     the same package.
+//   - `<dir>` adds the directory with all its test and source files.
+//   - `<regex>` adds a regex to filter the test functions, i.e. via `-run` or
+//     `-bench`, if the argument is neither an existing file nor directory.
+//
+// If no files or directories are given, the default packages are tested.
+package testargs
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// ModeRun provides the test filter mode for running tests.
+	ModeRun = "-run"
+	// ModeBench provides the test filter mode for running benchmarks.
+	ModeBench = "-bench"
+
+	// PrefixScope provides the prefix of the coverage scope arguments.
+	PrefixScope = "scope:"
+	// SuffixTest provides the suffix of the test files.
+	SuffixTest = "_test.go"
+	// PackagesAll provides the pattern matching all packages.
+	PackagesAll = "./..."
+)
+
+// regexSafe matches arguments that can be passed to the shell unquoted.
+var regexSafe = regexp.MustCompile(`^[a-zA-Z0-9_./,=:@%+-]+$`)
+
+// ErrInvalidPackage represents an error for a package that cannot be read.
+var ErrInvalidPackage = errors.New("invalid package")
+
+// NewErrInvalidPackage creates an error for the package of the given test
+// file that cannot be read because of the given error.
+func NewErrInvalidPackage(file string, err error) error {
+	return fmt.Errorf("%w [file=%s]: %w", ErrInvalidPackage, file, err)
+}
+
+// Args contains the resolved test arguments.
+type Args struct {
+	// Mode provides the test filter mode, i.e. `-run` or `-bench`.
+	Mode string
+	// Cover provides the coverage packages.
+	Cover []string
+	// Regex provides the regexes to filter the test functions.
+	Regex []string
+	// Files provides the test files and directories.
+	Files []string
+	// Packages provides the default packages tested without files.
+	Packages []string
+	// Invalid provides the arguments that could not be resolved.
+	Invalid []string
+}
+
+// Resolve resolves the given unified test arguments relative to the given
+// directory for the given test filter mode using the given default packages,
+// or all packages, if no default packages are given.
+func Resolve(
+	dir, mode string, packages []string, args ...string,
+) (*Args, error) {
+	resolved := &Args{
+		Mode: mode, Cover: []string{}, Regex: []string{},
+		Files: []string{}, Packages: []string{}, Invalid: []string{},
+	}
+	for _, pkg := range packages {
+		resolved.Packages = appendUnique(resolved.Packages, local(pkg))
+	}
+	if len(resolved.Packages) == 0 {
+		resolved.Packages = append(resolved.Packages, PackagesAll)
+	}
+
+	for _, arg := range args {
+		if err := resolved.add(dir, arg); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// add resolves the given unified test argument relative to the given
+// directory and adds it to the resolved test arguments.
+func (a *Args) add(dir, arg string) error {
+	if scope, ok := strings.CutPrefix(arg, PrefixScope); ok {
+		switch {
+		case scope == ".":
+			a.Cover = appendUnique(a.Cover, PackagesAll)
+		case isDir(join(dir, scope)):
+			a.Cover = appendUnique(a.Cover, local(scope))
+		default:
+			a.Invalid = append(a.Invalid, arg)
+		}
+		return nil
+	}
+
+	info, err := os.Stat(join(dir, arg))
+	switch {
+	case err != nil:
+		a.Regex = appendUnique(a.Regex, arg)
+	case info.IsDir():
+		a.Files = appendUnique(a.Files, local(arg))
+	case strings.HasSuffix(arg, SuffixTest):
+		return a.addTestFile(dir, arg)
+	default:
+		a.Invalid = append(a.Invalid, arg)
+	}
+	return nil
+}
+
+// addTestFile adds the given test file together with the source files and
+// the test helper files of the same package as provided by `go/build`.
+func (a *Args) addTestFile(dir, file string) error {
+	path := filepath.Dir(file)
+	pkg, err := build.ImportDir(join(dir, path), 0)
+	if err != nil {
+		return NewErrInvalidPackage(file, err)
+	}
+
+	tests := pkg.TestGoFiles
+	if slices.Contains(pkg.XTestGoFiles, filepath.Base(file)) {
+		tests = pkg.XTestGoFiles
+	}
+
+	a.Files = appendUnique(a.Files, local(file))
+	for _, name := range slices.Concat(pkg.GoFiles, pkg.CgoFiles) {
+		a.Files = appendUnique(a.Files, local(filepath.Join(path, name)))
+	}
+	for _, name := range tests {
+		if isHelper(name) {
+			a.Files = appendUnique(a.Files, local(filepath.Join(path, name)))
+		}
+	}
+	return nil
+}
+
+// Fields returns the resolved test arguments as arguments of `go test`.
+func (a *Args) Fields() []string {
+	fields := []string{}
+	if len(a.Cover) != 0 {
+		fields = append(fields, "-coverpkg="+strings.Join(a.Cover, ","))
+	}
+
+	switch {
+	case len(a.Regex) != 0:
+		fields = append(fields, a.Mode+"="+strings.Join(a.Regex, "|"))
+	case a.Mode == ModeBench:
+		fields = append(fields, a.Mode+"=^Benchmark")
+	default:
+		fields = append(fields, a.Mode+"=^Test")
+	}
+
+	if len(a.Files) != 0 {
+		return append(fields, a.Files...)
+	}
+	return append(fields, a.Packages...)
+}
+
+// String returns the resolved test arguments as shell quoted arguments of
+// `go test`.
+func (a *Args) String() string {
+	fields := a.Fields()
+	for index, field := range fields {
+		fields[index] = quote(field)
+	}
+	return strings.Join(fields, " ")
+}
+
+// join returns the given path relative to the given directory, if the path
+// is not absolute.
+func join(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// isDir returns whether the given path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// isHelper returns whether the given test file is a test helper file, i.e.
+// `common_test.go` or a `mock_*_test.go` file.
+func isHelper(name string) bool {
+	return name == "common"+SuffixTest ||
+		strings.HasPrefix(name, "mock_")
+}
+
+// local returns the given path as local path starting with `./`, if the path
+// is neither absolute nor starting with `./` or `../` already.
+func local(path string) string {
+	path = filepath.Clean(path)
+	if path == "." || path == ".." || filepath.IsAbs(path) ||
+		strings.HasPrefix(path, "../") {
+		return path
+	}
+	return "./" + path
+}
+
+// quote returns the given argument quoted for the shell, if necessary.
+func quote(arg string) string {
+	if regexSafe.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// appendUnique appends the given value to the given values, if the value is
+// not contained yet.
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,247 @@
+package testargs_test
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/testargs"
+	"github.com/tkrop/go-testing/test"
+)
+
+// files contains the files of the test project.
+var files = map[string]string{
+	"go.mod":               "module example.com/project\n",
+	"pkg/pkg.go":           "package pkg\n",
+	"pkg/util.go":          "package pkg\n",
+	"pkg/ignored.go":       "//go:build ignore\n\npackage pkg\n",
+	"pkg/README.md":        "# Package\n",
+	"pkg/pkg_test.go":      "package pkg\n",
+	"pkg/common_test.go":   "package pkg\n",
+	"pkg/other_test.go":    "package pkg\n",
+	"pkg/ext_test.go":      "package pkg_test\n",
+	"pkg/mock_api_test.go": "package pkg_test\n",
+	"cmd/main.go":          "package main\n",
+	"broken/a.go":          "package a\n",
+	"broken/b_test.go":     "package b\n",
+}
+
+// setupProject sets up the test project in a temporary directory.
+func setupProject(t test.Test) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return dir
+}
+
+type ResolveParams struct {
+	mode         string
+	packages     []string
+	args         []string
+	expectArgs   *testargs.Args
+	expectFields []string
+	expectString string
+	expectError  func(dir string) error
+}
+
+var resolveTestCases = map[string]ResolveParams{
+	"run defaults": {
+		mode: testargs.ModeRun,
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeRun, Cover: []string{}, Regex: []string{},
+			Files: []string{}, Packages: []string{"./..."},
+			Invalid: []string{},
+		},
+		expectFields: []string{"-run=^Test", "./..."},
+		expectString: "'-run=^Test' ./...",
+	},
+	"bench defaults": {
+		mode:     testargs.ModeBench,
+		packages: []string{"pkg", "./cmd", "pkg", "."},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeBench, Cover: []string{}, Regex: []string{},
+			Files: []string{}, Packages: []string{"./pkg", "./cmd", "."},
+			Invalid: []string{},
+		},
+		expectFields: []string{"-bench=^Benchmark", "./pkg", "./cmd", "."},
+		expectString: "'-bench=^Benchmark' ./pkg ./cmd .",
+	},
+	"scope packages": {
+		mode: testargs.ModeRun,
+		args: []string{
+			"scope:.", "scope:pkg", "scope:./cmd", "scope:pkg", "scope:/",
+		},
+		expectArgs: &testargs.Args{
+			Mode:  testargs.ModeRun,
+			Cover: []string{"./...", "./pkg", "./cmd", "/"},
+			Regex: []string{}, Files: []string{},
+			Packages: []string{"./..."}, Invalid: []string{},
+		},
+		expectFields: []string{
+			"-coverpkg=./...,./pkg,./cmd,/", "-run=^Test", "./...",
+		},
+		expectString: "-coverpkg=./...,./pkg,./cmd,/ '-run=^Test' ./...",
+	},
+	"regex filters": {
+		mode: testargs.ModeRun,
+		args: []string{"TestResolve", "TestFields/run", "TestResolve"},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeRun, Cover: []string{},
+			Regex:    []string{"TestResolve", "TestFields/run"},
+			Files:    []string{},
+			Packages: []string{"./..."}, Invalid: []string{},
+		},
+		expectFields: []string{
+			"-run=TestResolve|TestFields/run", "./...",
+		},
+		expectString: "'-run=TestResolve|TestFields/run' ./...",
+	},
+	"bench filters": {
+		mode: testargs.ModeBench,
+		args: []string{"BenchmarkParse$"},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeBench, Cover: []string{},
+			Regex: []string{"BenchmarkParse$"}, Files: []string{},
+			Packages: []string{"./..."}, Invalid: []string{},
+		},
+		expectFields: []string{"-bench=BenchmarkParse$", "./..."},
+		expectString: "'-bench=BenchmarkParse$' ./...",
+	},
+	"directories": {
+		mode: testargs.ModeRun,
+		args: []string{"pkg", "./cmd/", "pkg"},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeRun, Cover: []string{}, Regex: []string{},
+			Files:    []string{"./pkg", "./cmd"},
+			Packages: []string{"./..."}, Invalid: []string{},
+		},
+		expectFields: []string{"-run=^Test", "./pkg", "./cmd"},
+		expectString: "'-run=^Test' ./pkg ./cmd",
+	},
+	"internal test file": {
+		mode: testargs.ModeRun,
+		args: []string{"pkg/pkg_test.go"},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeRun, Cover: []string{}, Regex: []string{},
+			Files: []string{
+				"./pkg/pkg_test.go", "./pkg/pkg.go", "./pkg/util.go",
+				"./pkg/common_test.go",
+			},
+			Packages: []string{"./..."}, Invalid: []string{},
+		},
+		expectFields: []string{
+			"-run=^Test", "./pkg/pkg_test.go", "./pkg/pkg.go",
+			"./pkg/util.go", "./pkg/common_test.go",
+		},
+		expectString: "'-run=^Test' ./pkg/pkg_test.go ./pkg/pkg.go " +
+			"./pkg/util.go ./pkg/common_test.go",
+	},
+	"external test file": {
+		mode: testargs.ModeRun,
+		args: []string{"./pkg/ext_test.go", "pkg/pkg_test.go"},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeRun, Cover: []string{}, Regex: []string{},
+			Files: []string{
+				"./pkg/ext_test.go", "./pkg/pkg.go", "./pkg/util.go",
+				"./pkg/mock_api_test.go", "./pkg/pkg_test.go",
+				"./pkg/common_test.go",
+			},
+			Packages: []string{"./..."}, Invalid: []string{},
+		},
+		expectFields: []string{
+			"-run=^Test", "./pkg/ext_test.go", "./pkg/pkg.go",
+			"./pkg/util.go", "./pkg/mock_api_test.go",
+			"./pkg/pkg_test.go", "./pkg/common_test.go",
+		},
+		expectString: "'-run=^Test' ./pkg/ext_test.go ./pkg/pkg.go " +
+			"./pkg/util.go ./pkg/mock_api_test.go ./pkg/pkg_test.go " +
+			"./pkg/common_test.go",
+	},
+	"unified arguments": {
+		mode: testargs.ModeRun,
+		args: []string{
+			"scope:pkg", "pkg/other_test.go", "TestOther", "cmd",
+		},
+		packages: []string{"pkg"},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeRun, Cover: []string{"./pkg"},
+			Regex: []string{"TestOther"},
+			Files: []string{
+				"./pkg/other_test.go", "./pkg/pkg.go", "./pkg/util.go",
+				"./pkg/common_test.go", "./cmd",
+			},
+			Packages: []string{"./pkg"}, Invalid: []string{},
+		},
+		expectFields: []string{
+			"-coverpkg=./pkg", "-run=TestOther", "./pkg/other_test.go",
+			"./pkg/pkg.go", "./pkg/util.go", "./pkg/common_test.go",
+			"./cmd",
+		},
+		expectString: "-coverpkg=./pkg -run=TestOther ./pkg/other_test.go " +
+			"./pkg/pkg.go ./pkg/util.go ./pkg/common_test.go ./cmd",
+	},
+	"invalid arguments": {
+		mode: testargs.ModeRun,
+		args: []string{"scope:missing", "pkg/pkg.go", "pkg/README.md"},
+		expectArgs: &testargs.Args{
+			Mode: testargs.ModeRun, Cover: []string{}, Regex: []string{},
+			Files: []string{}, Packages: []string{"./..."},
+			Invalid: []string{
+				"scope:missing", "pkg/pkg.go", "pkg/README.md",
+			},
+		},
+		expectFields: []string{"-run=^Test", "./..."},
+		expectString: "'-run=^Test' ./...",
+	},
+	"invalid package": {
+		mode: testargs.ModeRun,
+		args: []string{"broken/b_test.go"},
+		expectError: func(dir string) error {
+			_, err := build.ImportDir(filepath.Join(dir, "broken"), 0)
+			return testargs.NewErrInvalidPackage("broken/b_test.go", err)
+		},
+	},
+}
+
+func TestResolve(t *testing.T) {
+	test.Map(t, resolveTestCases).
+		Run(func(t test.Test, param ResolveParams) {
+			// Given
+			dir := setupProject(t)
+
+			// When
+			args, err := testargs.Resolve(dir,
+				param.mode, param.packages, param.args...)
+
+			// Then
+			if param.expectError != nil {
+				assert.Equal(t, param.expectError(dir), err)
+				assert.Nil(t, args)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectArgs, args)
+			assert.Equal(t, param.expectFields, args.Fields())
+			assert.Equal(t, param.expectString, args.String())
+		})
+}
+
+func TestQuote(t *testing.T) {
+	// Given
+	args := &testargs.Args{
+		Mode: testargs.ModeRun, Regex: []string{"Test'Quote", "Test Space"},
+		Packages: []string{"./..."},
+	}
+
+	// When
+	result := args.String()
+
+	// Then
+	assert.Equal(t, `'-run=Test'\''Quote|Test Space' ./...`, result)
+}
@@ -0,0 +1,210 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// randReader provides the default random source for creating OTLP ids.
+var randReader io.Reader = rand.Reader
+
+// PathOTLPTraces provides the default OTLP/HTTP path for exporting traces.
+const PathOTLPTraces = "/v1/traces"
+
+// ErrExport represents an OTLP export failure.
+var ErrExport = errors.New("export failed")
+
+// NewErrExport wraps the error of a failed OTLP export to the given endpoint.
+func NewErrExport(endpoint string, err error) error {
+	return fmt.Errorf("%w [endpoint=%s]: %w", ErrExport, endpoint, err)
+}
+
+// NewErrExportStatus creates an OTLP export error for an unexpected response
+// status of the given endpoint.
+func NewErrExportStatus(endpoint string, status string) error {
+	return fmt.Errorf("%w [endpoint=%s, status=%s]", ErrExport, endpoint, status)
+}
+
+// otlpRequest represents the OTLP/HTTP JSON encoding of an export traces
+// service request reduced to the fields used by go-make.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpResourceSpans represents the spans of a single resource.
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+// otlpResource represents the resource, i.e. the service, emitting spans.
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+// otlpScopeSpans represents the spans of a single instrumentation scope.
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+// otlpScope represents the instrumentation scope.
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+// otlpSpan represents a single span with hex encoded ids and timestamps in
+// nanoseconds encoded as strings.
+type otlpSpan struct {
+	TraceID      string          `json:"traceId"`
+	SpanID       string          `json:"spanId"`
+	ParentSpanID string          `json:"parentSpanId,omitempty"`
+	Name         string          `json:"name"`
+	Kind         int             `json:"kind"`
+	StartTime    string          `json:"startTimeUnixNano"`
+	EndTime      string          `json:"endTimeUnixNano"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+}
+
+// otlpAttribute represents a key value attribute with a string value.
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpValue represents a string attribute value.
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// otlpSpanKindInternal provides the OTLP span kind for internal spans.
+const otlpSpanKindInternal = 1
+
+// Export exports the recorded spans via OTLP/HTTP using the JSON encoding
+// to the given endpoint. If the endpoint has no path, the default traces path
+// `/v1/traces` is appended. The spans are exported with the parent spans they
+// have been nested into while recording.
+func (t *Tracer) Export(
+	ctx context.Context, client *http.Client, endpoint string,
+) error {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return NewErrExport(endpoint, err)
+	} else if target.Path == "" || target.Path == "/" {
+		target.Path = PathOTLPTraces
+	}
+
+	request, err := t.request()
+	if err != nil {
+		return NewErrExport(endpoint, err)
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return NewErrExport(endpoint, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		target.String(), bytes.NewReader(body))
+	if err != nil {
+		return NewErrExport(endpoint, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return NewErrExport(endpoint, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return NewErrExportStatus(endpoint, resp.Status)
+	}
+	return nil
+}
+
+// request creates the OTLP export traces service request for the recorded
+// spans creating a new trace id and span ids.
+func (t *Tracer) request() (*otlpRequest, error) {
+	traceID, err := t.id(16)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := t.Spans()
+	ids := make(map[int]string, len(spans))
+	result := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		spanID, err := t.id(8)
+		if err != nil {
+			return nil, err
+		}
+		ids[span.ID] = spanID
+
+		result = append(result, otlpSpan{
+			TraceID:      traceID,
+			SpanID:       spanID,
+			ParentSpanID: ids[span.Parent],
+			Name:         span.Name,
+			Kind:         otlpSpanKindInternal,
+			StartTime:    nanos(span.Start.UnixNano()),
+			EndTime:      nanos(span.Start.Add(span.Duration).UnixNano()),
+			Attributes:   attributes(span.Attrs),
+		})
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: attributes(map[string]string{
+					"service.name": t.Name,
+				}),
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: t.Name},
+				Spans: result,
+			}},
+		}},
+	}, nil
+}
+
+// id creates a new random hex encoded id with the given number of bytes.
+func (t *Tracer) id(size int) (string, error) {
+	id := make([]byte, size)
+	if _, err := io.ReadFull(t.Rand, id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// nanos encodes the given nanoseconds as decimal string as required by the
+// OTLP JSON encoding for 64 bit integers.
+func nanos(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+// attributes converts the given attribute map into a list of OTLP attributes
+// sorted by key to ensure a stable output.
+func attributes(attrs map[string]string) []otlpAttribute {
+	result := make([]otlpAttribute, 0, len(attrs))
+	for key, value := range attrs {
+		result = append(result, otlpAttribute{
+			Key: key, Value: otlpValue{StringValue: value},
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
@@ -0,0 +1,165 @@
+package trace_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/trace"
+	"github.com/tkrop/go-testing/test"
+)
+
+// otlpRequestNested contains the expected OTLP request for nested spans.
+const otlpRequestNested = `{"resourceSpans":[{` +
+	`"resource":{"attributes":[` +
+	`{"key":"service.name","value":{"stringValue":"go-make"}}]},` +
+	`"scopeSpans":[{"scope":{"name":"go-make"},"spans":[{` +
+	`"traceId":"0102030405060708090a0b0c0d0e0f10",` +
+	`"spanId":"1112131415161718",` +
+	`"name":"go-make","kind":1,` +
+	`"startTimeUnixNano":"1700000000000000000",` +
+	`"endTimeUnixNano":"1700000000007000000",` +
+	`"attributes":[{"key":"args","value":{"stringValue":"go-make all"}},` +
+	`{"key":"exit","value":{"stringValue":"0"}}]},{` +
+	`"traceId":"0102030405060708090a0b0c0d0e0f10",` +
+	`"spanId":"191a1b1c1d1e1f20",` +
+	`"parentSpanId":"1112131415161718",` +
+	`"name":"setup-config","kind":1,` +
+	`"startTimeUnixNano":"1700000000001000000",` +
+	`"endTimeUnixNano":"1700000000004000000"},{` +
+	`"traceId":"0102030405060708090a0b0c0d0e0f10",` +
+	`"spanId":"2122232425262728",` +
+	`"parentSpanId":"191a1b1c1d1e1f20",` +
+	`"name":"exec","kind":1,` +
+	`"startTimeUnixNano":"1700000000002000000",` +
+	`"endTimeUnixNano":"1700000000003000000"},{` +
+	`"traceId":"0102030405060708090a0b0c0d0e0f10",` +
+	`"spanId":"292a2b2c2d2e2f30",` +
+	`"parentSpanId":"1112131415161718",` +
+	`"name":"make","kind":1,` +
+	`"startTimeUnixNano":"1700000000005000000",` +
+	`"endTimeUnixNano":"1700000000006000000"}]}]}]}`
+
+type ExportParams struct {
+	path         string
+	status       int
+	rand         io.Reader
+	expectPath   string
+	expectBody   string
+	expectError  func(endpoint string) error
+	expectCalled bool
+}
+
+var exportTestCases = map[string]ExportParams{
+	"export default path": {
+		status:       http.StatusOK,
+		expectPath:   trace.PathOTLPTraces,
+		expectBody:   otlpRequestNested,
+		expectCalled: true,
+	},
+	"export root path": {
+		path:         "/",
+		status:       http.StatusOK,
+		expectPath:   trace.PathOTLPTraces,
+		expectBody:   otlpRequestNested,
+		expectCalled: true,
+	},
+	"export custom path": {
+		path:         "/custom/traces",
+		status:       http.StatusAccepted,
+		expectPath:   "/custom/traces",
+		expectBody:   otlpRequestNested,
+		expectCalled: true,
+	},
+	"export failed status": {
+		status:       http.StatusBadRequest,
+		expectPath:   trace.PathOTLPTraces,
+		expectBody:   otlpRequestNested,
+		expectCalled: true,
+		expectError: func(endpoint string) error {
+			return trace.NewErrExportStatus(endpoint, "400 Bad Request")
+		},
+	},
+	"export failed random": {
+		rand: strings.NewReader(""),
+		expectError: func(endpoint string) error {
+			return trace.NewErrExport(endpoint, io.EOF)
+		},
+	},
+}
+
+func TestExport(t *testing.T) {
+	test.Map(t, exportTestCases).
+		Run(func(t test.Test, param ExportParams) {
+			// Given
+			called, path, body := false, "", ""
+			server := httptest.NewServer(http.HandlerFunc(
+				func(writer http.ResponseWriter, req *http.Request) {
+					data, err := io.ReadAll(req.Body)
+					assert.NoError(t, err)
+					assert.Equal(t, "application/json",
+						req.Header.Get("Content-Type"))
+					called, path, body = true, req.URL.Path, string(data)
+					writer.WriteHeader(param.status)
+				}))
+			defer server.Close()
+
+			tracer := NewTracer()
+			if param.rand != nil {
+				tracer.Rand = param.rand
+			}
+			root := tracer.Start("go-make", "args", "go-make all")
+			config := tracer.Start("setup-config")
+			tracer.Start("exec").End()
+			config.End()
+			tracer.Start("make").End()
+			root.SetAttr("exit", "0")
+			root.End()
+			endpoint := server.URL + param.path
+
+			// When
+			err := tracer.Export(context.Background(),
+				server.Client(), endpoint)
+
+			// Then
+			if param.expectError != nil {
+				assert.Equal(t, param.expectError(endpoint), err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, param.expectCalled, called)
+			assert.Equal(t, param.expectPath, path)
+			assert.Equal(t, param.expectBody, body)
+		})
+}
+
+func TestExportInvalidEndpoint(t *testing.T) {
+	// Given
+	tracer := NewTracer()
+	endpoint := "http://[::1"
+
+	// When
+	err := tracer.Export(context.Background(), http.DefaultClient, endpoint)
+
+	// Then
+	assert.ErrorIs(t, err, trace.ErrExport)
+}
+
+func TestExportUnreachable(t *testing.T) {
+	// Given
+	tracer := NewTracer()
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL
+	server.Close()
+
+	// When
+	err := tracer.Export(context.Background(), http.DefaultClient, endpoint)
+
+	// Then
+	assert.ErrorIs(t, err, trace.ErrExport)
+}
@@ -0,0 +1,199 @@
+// Package trace provides a lightweight span recorder for go-make runs that
+// can be written in the Chrome trace-event format or exported via OTLP/HTTP.
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Span represents a single timed execution step of a go-make run.
+type Span struct {
+	// ID provides the sequence number of the span starting with 1.
+	ID int
+	// Parent provides the id of the parent span, or 0 for the root span.
+	Parent int
+	// Name provides the name of the span.
+	Name string
+	// Start provides the start time of the span.
+	Start time.Time
+	// Duration provides the duration of the span.
+	Duration time.Duration
+	// Attrs provides the additional attributes of the span.
+	Attrs map[string]string
+
+	// tracer provides the tracer the span is recorded with.
+	tracer *Tracer
+	// ended indicates whether the span has been ended.
+	ended bool
+}
+
+// End ends the span recording its duration. Calling `End` on a nil span is
+// a no-op, so that tracing can be disabled by using a nil tracer.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	if !s.ended {
+		s.Duration, s.ended = s.tracer.Now().Sub(s.Start), true
+		s.tracer.active = slices.DeleteFunc(s.tracer.active,
+			func(span *Span) bool { return span == s })
+	}
+}
+
+// SetAttr sets the attribute with given key to the given value. Calling
+// `SetAttr` on a nil span is a no-op.
+func (s *Span) SetAttr(key, value string) {
+	if s == nil {
+		return
+	}
+
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.Attrs[key] = value
+}
+
+// Tracer records the spans of a go-make run. A nil tracer is valid and
+// records nothing.
+type Tracer struct {
+	// Name provides the service name used for exporting the spans.
+	Name string
+	// Pid provides the process id used for the Chrome trace events.
+	Pid int
+	// Now provides the clock used for recording the spans.
+	Now func() time.Time
+	// Rand provides the random source used for creating OTLP ids.
+	Rand io.Reader
+
+	// mutex protects the recorded spans.
+	mutex sync.Mutex
+	// spans contains the recorded spans in order of their start.
+	spans []*Span
+	// active contains the spans not ended yet in order of their start.
+	active []*Span
+}
+
+// NewTracer creates a new tracer with given service name using the process
+// id of the current process, the system clock, and a secure random source.
+func NewTracer(name string) *Tracer {
+	return &Tracer{
+		Name: name,
+		Pid:  os.Getpid(),
+		Now:  time.Now,
+		Rand: randReader,
+	}
+}
+
+// Start starts a new span with given name and attributes provided as pairs
+// of keys and values. The span is nested into the latest started span that
+// has not been ended yet, while the first span started is considered the root
+// span of the run. On a nil tracer, it returns a nil span.
+func (t *Tracer) Start(name string, attrs ...string) *Span {
+	if t == nil {
+		return nil
+	}
+
+	span := &Span{
+		Name:   name,
+		Start:  t.Now(),
+		Attrs:  make(map[string]string, len(attrs)/2),
+		tracer: t,
+	}
+	for index := 0; index+1 < len(attrs); index += 2 {
+		span.Attrs[attrs[index]] = attrs[index+1]
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	span.ID = len(t.spans) + 1
+	if len(t.active) != 0 {
+		span.Parent = t.active[len(t.active)-1].ID
+	}
+	t.spans = append(t.spans, span)
+	t.active = append(t.active, span)
+
+	return span
+}
+
+// Spans returns a copy of the recorded spans in order of their start. Spans
+// that have not been ended yet are reported with their duration up to now.
+func (t *Tracer) Spans() []Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.Now()
+	spans := make([]Span, 0, len(t.spans))
+	for _, span := range t.spans {
+		clone := Span{
+			ID:       span.ID,
+			Parent:   span.Parent,
+			Name:     span.Name,
+			Start:    span.Start,
+			Duration: span.Duration,
+			Attrs:    maps.Clone(span.Attrs),
+		}
+		if !span.ended {
+			clone.Duration = now.Sub(span.Start)
+		}
+		spans = append(spans, clone)
+	}
+	return spans
+}
+
+// chromeTrace represents the JSON object format of the Chrome trace-event
+// format.
+type chromeTrace struct {
+	TraceEvents     []chromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+// chromeEvent represents a complete event (phase `X`) of the Chrome
+// trace-event format with timestamps and durations in microseconds.
+type chromeEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp int64             `json:"ts"`
+	Duration  int64             `json:"dur"`
+	Pid       int               `json:"pid"`
+	Tid       int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+// WriteChrome writes the recorded spans in the Chrome trace-event format to
+// the given writer, that can be loaded via `chrome://tracing` or Perfetto.
+func (t *Tracer) WriteChrome(writer io.Writer) error {
+	spans := t.Spans()
+	events := make([]chromeEvent, 0, len(spans))
+	for _, span := range spans {
+		events = append(events, chromeEvent{
+			Name:      span.Name,
+			Category:  t.Name,
+			Phase:     "X",
+			Timestamp: span.Start.UnixMicro(),
+			Duration:  span.Duration.Microseconds(),
+			Pid:       t.Pid,
+			Tid:       1,
+			Args:      span.Attrs,
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(chromeTrace{
+		TraceEvents:     events,
+		DisplayTimeUnit: "ms",
+	})
+}
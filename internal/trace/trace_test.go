@@ -0,0 +1,297 @@
+package trace_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/trace"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	// timeBase contains an arbitrary base time for testing.
+	timeBase = time.UnixMicro(1700000000000000)
+)
+
+// NewClock creates a clock returning the base time advanced by one
+// millisecond on each call.
+func NewClock() func() time.Time {
+	now := timeBase.Add(-time.Millisecond)
+	return func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+}
+
+// NewRand creates a deterministic random source providing the byte sequence
+// 0x01, 0x02, ..., to create distinguishable ids for testing.
+func NewRand() io.Reader {
+	data := make([]byte, 255)
+	for index := range data {
+		data[index] = byte(index + 1)
+	}
+	return bytes.NewReader(data)
+}
+
+// NewTracer creates a new tracer with deterministic clock, process id, and
+// random source for testing.
+func NewTracer() *trace.Tracer {
+	tracer := trace.NewTracer("go-make")
+	tracer.Pid = 42
+	tracer.Now = NewClock()
+	tracer.Rand = NewRand()
+	return tracer
+}
+
+type SpansParams struct {
+	setup       func(tracer *trace.Tracer)
+	expectSpans []trace.Span
+}
+
+var spansTestCases = map[string]SpansParams{
+	"no spans": {
+		setup:       func(*trace.Tracer) {},
+		expectSpans: []trace.Span{},
+	},
+	"single span": {
+		setup: func(tracer *trace.Tracer) {
+			tracer.Start("make", "key", "value").End()
+		},
+		expectSpans: []trace.Span{{
+			ID:       1,
+			Name:     "make",
+			Start:    timeBase,
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{"key": "value"},
+		}},
+	},
+	"single span odd attrs": {
+		setup: func(tracer *trace.Tracer) {
+			tracer.Start("make", "key", "value", "other").End()
+		},
+		expectSpans: []trace.Span{{
+			ID:       1,
+			Name:     "make",
+			Start:    timeBase,
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{"key": "value"},
+		}},
+	},
+	"single span ended twice": {
+		setup: func(tracer *trace.Tracer) {
+			span := tracer.Start("make")
+			span.End()
+			span.End()
+		},
+		expectSpans: []trace.Span{{
+			ID:       1,
+			Name:     "make",
+			Start:    timeBase,
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{},
+		}},
+	},
+	"nested spans": {
+		setup: func(tracer *trace.Tracer) {
+			root := tracer.Start("go-make")
+			tracer.Start("setup-workdir").End()
+			root.SetAttr("exit", "0")
+			root.End()
+		},
+		expectSpans: []trace.Span{{
+			ID:       1,
+			Name:     "go-make",
+			Start:    timeBase,
+			Duration: 3 * time.Millisecond,
+			Attrs:    map[string]string{"exit": "0"},
+		}, {
+			ID:       2,
+			Parent:   1,
+			Name:     "setup-workdir",
+			Start:    timeBase.Add(time.Millisecond),
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{},
+		}},
+	},
+	"deeply nested spans": {
+		setup: func(tracer *trace.Tracer) {
+			root := tracer.Start("go-make")
+			config := tracer.Start("setup-config")
+			tracer.Start("exec").End()
+			config.End()
+			tracer.Start("make").End()
+			root.End()
+		},
+		expectSpans: []trace.Span{{
+			ID:       1,
+			Name:     "go-make",
+			Start:    timeBase,
+			Duration: 7 * time.Millisecond,
+			Attrs:    map[string]string{},
+		}, {
+			ID:       2,
+			Parent:   1,
+			Name:     "setup-config",
+			Start:    timeBase.Add(time.Millisecond),
+			Duration: 3 * time.Millisecond,
+			Attrs:    map[string]string{},
+		}, {
+			ID:       3,
+			Parent:   2,
+			Name:     "exec",
+			Start:    timeBase.Add(2 * time.Millisecond),
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{},
+		}, {
+			ID:       4,
+			Parent:   1,
+			Name:     "make",
+			Start:    timeBase.Add(5 * time.Millisecond),
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{},
+		}},
+	},
+	"overlapping spans": {
+		setup: func(tracer *trace.Tracer) {
+			first := tracer.Start("first")
+			second := tracer.Start("second")
+			first.End()
+			tracer.Start("third").End()
+			second.End()
+		},
+		expectSpans: []trace.Span{{
+			ID:       1,
+			Name:     "first",
+			Start:    timeBase,
+			Duration: 2 * time.Millisecond,
+			Attrs:    map[string]string{},
+		}, {
+			ID:       2,
+			Parent:   1,
+			Name:     "second",
+			Start:    timeBase.Add(time.Millisecond),
+			Duration: 4 * time.Millisecond,
+			Attrs:    map[string]string{},
+		}, {
+			ID:       3,
+			Parent:   2,
+			Name:     "third",
+			Start:    timeBase.Add(3 * time.Millisecond),
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{},
+		}},
+	},
+	"open span": {
+		setup: func(tracer *trace.Tracer) {
+			tracer.Start("make")
+		},
+		expectSpans: []trace.Span{{
+			ID:       1,
+			Name:     "make",
+			Start:    timeBase,
+			Duration: time.Millisecond,
+			Attrs:    map[string]string{},
+		}},
+	},
+}
+
+func TestSpans(t *testing.T) {
+	test.Map(t, spansTestCases).
+		Run(func(t test.Test, param SpansParams) {
+			// Given
+			tracer := NewTracer()
+			param.setup(tracer)
+
+			// When
+			spans := tracer.Spans()
+
+			// Then
+			assert.Equal(t, param.expectSpans, spans)
+		})
+}
+
+func TestNilTracer(t *testing.T) {
+	// Given
+	var tracer *trace.Tracer
+
+	// When
+	span := tracer.Start("make", "key", "value")
+	span.SetAttr("key", "value")
+	span.End()
+
+	// Then
+	assert.Nil(t, span)
+	assert.Nil(t, tracer.Spans())
+}
+
+type WriteChromeParams struct {
+	setup        func(tracer *trace.Tracer)
+	expectString string
+}
+
+var writeChromeTestCases = map[string]WriteChromeParams{
+	"no spans": {
+		setup: func(*trace.Tracer) {},
+		expectString: `{
+  "traceEvents": [],
+  "displayTimeUnit": "ms"
+}
+`,
+	},
+	"nested spans": {
+		setup: func(tracer *trace.Tracer) {
+			root := tracer.Start("go-make", "args", "go-make all")
+			tracer.Start("setup-workdir").End()
+			root.End()
+		},
+		expectString: `{
+  "traceEvents": [
+    {
+      "name": "go-make",
+      "cat": "go-make",
+      "ph": "X",
+      "ts": 1700000000000000,
+      "dur": 3000,
+      "pid": 42,
+      "tid": 1,
+      "args": {
+        "args": "go-make all"
+      }
+    },
+    {
+      "name": "setup-workdir",
+      "cat": "go-make",
+      "ph": "X",
+      "ts": 1700000000001000,
+      "dur": 1000,
+      "pid": 42,
+      "tid": 1
+    }
+  ],
+  "displayTimeUnit": "ms"
+}
+`,
+	},
+}
+
+func TestWriteChrome(t *testing.T) {
+	test.Map(t, writeChromeTestCases).
+		Run(func(t test.Test, param WriteChromeParams) {
+			// Given
+			tracer := NewTracer()
+			param.setup(tracer)
+			writer := &strings.Builder{}
+
+			// When
+			err := tracer.WriteChrome(writer)
+
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectString, writer.String())
+		})
+}
@@ -0,0 +1,143 @@
+package verify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultIssue provides the default pattern of a single issue reference, i.e.
+// a GitHub issue with optional organization and repository.
+const DefaultIssue = `(?:[a-z]+/[a-z]+)?#[0-9]+`
+
+// Available issue reference modes.
+const (
+	// IssueRequired requires issue references at the end of the title.
+	IssueRequired = "required"
+	// IssueOptional verifies the issue references only if present.
+	IssueOptional = "optional"
+	// IssueDisabled disables the issue references, i.e. they are neither
+	// verified nor extracted from the title.
+	IssueDisabled = "disabled"
+)
+
+// Available `Signed-off-by` trailer modes.
+const (
+	// SignedRequired requires a single final `Signed-off-by` trailer.
+	SignedRequired = "required"
+	// SignedOptional verifies the `Signed-off-by` trailer only if present.
+	SignedOptional = "optional"
+	// SignedForbidden forbids any `Signed-off-by` trailer.
+	SignedForbidden = "forbidden"
+)
+
+var (
+	// ErrRules represents a commit rules read failure.
+	ErrRules = errors.New("invalid rules")
+	// ErrRuleValue represents an invalid value of a commit rule.
+	ErrRuleValue = errors.New("invalid rule value")
+)
+
+// NewErrRules wraps the error of a failed read operation of the commit rules
+// with given name.
+func NewErrRules(name string, err error) error {
+	return fmt.Errorf("%w [name=%s]: %w", ErrRules, name, err)
+}
+
+// NewErrRuleValue creates an error for the invalid value of the given commit
+// rule.
+func NewErrRuleValue(rule, value string) error {
+	return fmt.Errorf("%w [rule=%s, value=%s]", ErrRuleValue, rule, value)
+}
+
+// Rules represents the configurable commit rules of a project.
+type Rules struct {
+	// Types provides the allowed conventional commit types.
+	Types []string `json:"types,omitempty"`
+	// Scopes provides the allowed conventional commit scopes. If empty, any
+	// scope is allowed.
+	Scopes []string `json:"scopes,omitempty"`
+	// TitleMin provides the minimum number of characters of the title.
+	TitleMin int `json:"title-min,omitempty"`
+	// TitleMax provides the maximum number of characters of the title, if
+	// not zero.
+	TitleMax int `json:"title-max,omitempty"`
+	// Issue provides the pattern of a single issue reference expected at the
+	// end of the title, e.g. `[A-Z]+-[0-9]+` for Jira keys.
+	Issue string `json:"issue,omitempty"`
+	// IssueMode provides the issue reference mode, i.e. either `required`,
+	// `optional`, or `disabled`.
+	IssueMode string `json:"issue-mode,omitempty"`
+	// SignedOffBy provides the `Signed-off-by` trailer mode, i.e. either
+	// `required`, `optional`, or `forbidden`.
+	SignedOffBy string `json:"signed-off-by,omitempty"`
+	// BreakingFooter requires a `BREAKING CHANGE:` footer for titles with
+	// breaking change marker.
+	BreakingFooter bool `json:"breaking-footer,omitempty"`
+}
+
+// DefaultRules creates the default commit rules for the given space separated
+// allowed conventional commit types.
+func DefaultRules(types string) *Rules {
+	return &Rules{
+		Types:       strings.Fields(types),
+		Issue:       DefaultIssue,
+		IssueMode:   IssueRequired,
+		SignedOffBy: SignedRequired,
+	}
+}
+
+// ReadRules reads the commit rules from the JSON input with given name as
+// provided by the given reader. Rules missing in the input are taken from the
+// given default rules.
+func ReadRules(name string, reader io.Reader, deflt *Rules) (*Rules, error) {
+	rules := *deflt
+	rules.Types = slices.Clone(deflt.Types)
+	rules.Scopes = slices.Clone(deflt.Scopes)
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, NewErrRules(name, err)
+	}
+	return &rules, nil
+}
+
+// compile validates the commit rules and compiles the regular expression
+// matching the issue references at the end of a title. If the issue references
+// are disabled, the issue pattern is ignored and no expression is returned.
+func (r *Rules) compile() (*regexp.Regexp, error) {
+	switch {
+	case len(r.Types) == 0:
+		return nil, NewErrRuleValue("types", "[]")
+	case r.TitleMin < 0:
+		return nil, NewErrRuleValue("title-min", strconv.Itoa(r.TitleMin))
+	case r.TitleMax < 0 || (r.TitleMax > 0 && r.TitleMax < r.TitleMin):
+		return nil, NewErrRuleValue("title-max", strconv.Itoa(r.TitleMax))
+	}
+
+	switch r.SignedOffBy {
+	case SignedRequired, SignedOptional, SignedForbidden:
+	default:
+		return nil, NewErrRuleValue("signed-off-by", r.SignedOffBy)
+	}
+
+	switch r.IssueMode {
+	case IssueDisabled:
+		return nil, nil
+	case IssueRequired, IssueOptional:
+	default:
+		return nil, NewErrRuleValue("issue-mode", r.IssueMode)
+	}
+
+	if _, err := regexp.Compile(r.Issue); r.Issue == "" || err != nil {
+		return nil, NewErrRuleValue("issue", r.Issue)
+	}
+	issue := `(?:` + r.Issue + `)`
+	return regexp.MustCompile(`\(` + issue +
+		`(?:(?:,|\) *\()` + issue + `)*\)$`), nil
+}
@@ -0,0 +1,144 @@
+package verify_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/verify"
+	"github.com/tkrop/go-testing/test"
+)
+
+type ReadRulesParams struct {
+	reader      io.Reader
+	expectRules *verify.Rules
+	expectError error
+}
+
+var readRulesTestCases = map[string]ReadRulesParams{
+	"empty object": {
+		reader:      strings.NewReader("{}"),
+		expectRules: verify.DefaultRules(verify.DefaultTypes),
+	},
+	"all rules": {
+		reader: strings.NewReader(`{
+			"types": ["feat", "fix"], "scopes": ["make"],
+			"title-min": 10, "title-max": 72, "issue": "[A-Z]+-[0-9]+",
+			"issue-mode": "optional", "signed-off-by": "forbidden",
+			"breaking-footer": true
+		}`),
+		expectRules: &verify.Rules{
+			Types: []string{"feat", "fix"}, Scopes: []string{"make"},
+			TitleMin: 10, TitleMax: 72, Issue: "[A-Z]+-[0-9]+",
+			IssueMode:   verify.IssueOptional,
+			SignedOffBy: verify.SignedForbidden, BreakingFooter: true,
+		},
+	},
+	"unknown rule": {
+		reader: strings.NewReader(`{"unknown": true}`),
+		expectError: verify.NewErrRules("rules",
+			func() error {
+				decoder := json.NewDecoder(strings.NewReader(`{"unknown": true}`))
+				decoder.DisallowUnknownFields()
+				return decoder.Decode(&verify.Rules{})
+			}()),
+	},
+	"read failure": {
+		reader:      iotest.ErrReader(assert.AnError),
+		expectError: verify.NewErrRules("rules", assert.AnError),
+	},
+}
+
+func TestReadRules(t *testing.T) {
+	test.Map(t, readRulesTestCases).
+		Run(func(t test.Test, param ReadRulesParams) {
+			// Given
+			deflt := verify.DefaultRules(verify.DefaultTypes)
+
+			// When
+			rules, err := verify.ReadRules("rules", param.reader, deflt)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectRules, rules)
+			assert.Equal(t, verify.DefaultRules(verify.DefaultTypes), deflt)
+		})
+}
+
+type NewVerifierParams struct {
+	rules       *verify.Rules
+	expectError error
+}
+
+var newVerifierTestCases = map[string]NewVerifierParams{
+	"default rules": {
+		rules: verify.DefaultRules(verify.DefaultTypes),
+	},
+	"types missing": {
+		rules:       verify.DefaultRules(""),
+		expectError: verify.NewErrRuleValue("types", "[]"),
+	},
+	"title min negative": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.TitleMin = -1
+		}),
+		expectError: verify.NewErrRuleValue("title-min", "-1"),
+	},
+	"title max negative": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.TitleMax = -1
+		}),
+		expectError: verify.NewErrRuleValue("title-max", "-1"),
+	},
+	"title max below min": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.TitleMin, rules.TitleMax = 20, 10
+		}),
+		expectError: verify.NewErrRuleValue("title-max", "10"),
+	},
+	"signed-off-by invalid": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.SignedOffBy = "sometimes"
+		}),
+		expectError: verify.NewErrRuleValue("signed-off-by", "sometimes"),
+	},
+	"issue missing": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Issue = ""
+		}),
+		expectError: verify.NewErrRuleValue("issue", ""),
+	},
+	"issue invalid": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Issue = "[A-Z"
+		}),
+		expectError: verify.NewErrRuleValue("issue", "[A-Z"),
+	},
+	"issue mode invalid": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.IssueMode = "sometimes"
+		}),
+		expectError: verify.NewErrRuleValue("issue-mode", "sometimes"),
+	},
+	"issue disabled without pattern": {
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Issue, rules.IssueMode = "", verify.IssueDisabled
+		}),
+	},
+}
+
+func TestNewVerifier(t *testing.T) {
+	test.Map(t, newVerifierTestCases).
+		Run(func(t test.Test, param NewVerifierParams) {
+			// When
+			verifier, err := verify.NewVerifier(param.rules, "")
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectError == nil, verifier != nil)
+		})
+}
@@ -0,0 +1,477 @@
+// Package verify provides a verifier for the commit conventions of commit
+// messages, i.e. the conventional commit type, the issue references, and the
+// `Signed-off-by` trailer, that reports precise diagnostics with line and
+// column of the commit message or git log input.
+package verify
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultTypes provides the default conventional commit types as defined by
+// `COMMIT_CONVENTION` in the `Makefile.base`.
+const DefaultTypes = "feat deprecate remove docs fix style refactor " +
+	"perf test build ci chore"
+
+// Available verification rules.
+const (
+	// RuleTypeMissing reports a title without conventional commit type.
+	RuleTypeMissing = "type-missing"
+	// RuleTypeInvalid reports a title with unknown conventional commit type.
+	RuleTypeInvalid = "type-invalid"
+	// RuleIssueMissing reports a title without issue references.
+	RuleIssueMissing = "issue-missing"
+	// RuleIssueInvalid reports a title with misplaced issue references.
+	RuleIssueInvalid = "issue-invalid"
+	// RuleScopeInvalid reports a title with unknown conventional commit scope.
+	RuleScopeInvalid = "scope-invalid"
+	// RuleTitleShort reports a title shorter than the minimum length.
+	RuleTitleShort = "title-too-short"
+	// RuleTitleLong reports a title longer than the maximum length.
+	RuleTitleLong = "title-too-long"
+	// RuleBreakingMissing reports a breaking change without footer.
+	RuleBreakingMissing = "breaking-change-missing"
+	// RuleSignedMissing reports a message without final `Signed-off-by`.
+	RuleSignedMissing = "signed-off-by-missing"
+	// RuleSignedMultiple reports a message with multiple `Signed-off-by`.
+	RuleSignedMultiple = "signed-off-by-multiple"
+	// RuleSignedAuthor reports a `Signed-off-by` not matching the author.
+	RuleSignedAuthor = "signed-off-by-author"
+	// RuleSignedForbidden reports a forbidden `Signed-off-by`.
+	RuleSignedForbidden = "signed-off-by-forbidden"
+)
+
+// signedOffBy provides the prefix of the `Signed-off-by` trailer.
+const signedOffBy = "Signed-off-by:"
+
+// breakingChange provides the prefixes of the breaking change footer.
+var breakingChange = []string{"BREAKING CHANGE: ", "BREAKING-CHANGE: "}
+
+var (
+	// regexType matches the conventional commit type prefix of a title with
+	// optional scope and breaking change marker.
+	regexType = regexp.MustCompile(`^([a-z]+)(?:[\[(]([a-z0-9-]+)[\])])?(!)?: `)
+	// regexSigned matches a complete `Signed-off-by` trailer line.
+	regexSigned = regexp.MustCompile(`^` + signedOffBy + ` +(\S.*)$`)
+)
+
+// ErrParse represents a commit input parse failure.
+var ErrParse = errors.New("parse failed")
+
+// NewErrParse wraps the error of a failed commit input parse operation for
+// the input with given name.
+func NewErrParse(name string, err error) error {
+	return fmt.Errorf("%w [name=%s]: %w", ErrParse, name, err)
+}
+
+// Line represents a line of a commit message with its position in the input.
+type Line struct {
+	// Text provides the text of the message line.
+	Text string
+	// Line provides the line number of the message line in the input.
+	Line int
+	// Column provides the column offset of the message line in the input.
+	Column int
+}
+
+// Commit represents a commit message to be verified.
+type Commit struct {
+	// Name provides the name of the input of the commit message.
+	Name string
+	// Hash provides the hash of the commit, if known.
+	Hash string
+	// Lines provides the lines of the commit message.
+	Lines []*Line
+	// end provides the input line following the commit message, or the
+	// input line of the empty commit message.
+	end int
+}
+
+// Diagnostic represents a violation of the commit conventions at a specific
+// line and column of the input.
+type Diagnostic struct {
+	// Name provides the name of the input.
+	Name string `json:"name"`
+	// Commit provides the hash of the commit, if known.
+	Commit string `json:"commit,omitempty"`
+	// Line provides the line number of the violation in the input.
+	Line int `json:"line"`
+	// Column provides the column of the violation in the input.
+	Column int `json:"column"`
+	// Rule provides the violated rule.
+	Rule string `json:"rule"`
+	// Message provides the message describing the violation.
+	Message string `json:"message"`
+	// Context provides the violating title or trailer.
+	Context string `json:"context"`
+}
+
+// Error returns the diagnostic in the common `name:line:column: message`
+// format followed by the commit and context.
+func (d *Diagnostic) Error() string {
+	if d.Commit != "" {
+		return fmt.Sprintf("%s:%d:%d: %s [commit=%s, %s]", d.Name,
+			d.Line, d.Column, d.Message, d.Commit, d.Context)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s [%s]", d.Name,
+		d.Line, d.Column, d.Message, d.Context)
+}
+
+// ParseMessage parses a single commit message as provided to the git
+// `commit-msg` hook by the given reader. Trailing comment and empty lines as
+// added by the editor are ignored.
+func ParseMessage(name string, reader io.Reader) (*Commit, error) {
+	commit := &Commit{Name: name, end: 1}
+	scanner := bufio.NewScanner(reader)
+	for number := 1; scanner.Scan(); number++ {
+		commit.add(scanner.Text(), number, 0)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewErrParse(name, err)
+	}
+
+	for index := len(commit.Lines) - 1; index >= 0; index-- {
+		if text := commit.Lines[index].Text; text != "" && text[0] != '#' {
+			break
+		}
+		commit.Lines = commit.Lines[:index]
+	}
+	return commit.finish(), nil
+}
+
+// ParseLog parses the commit messages of a `git log --format=raw` output as
+// provided by the given reader. The commit messages consist of the lines
+// indented by four spaces, while any other non-empty line ends the message.
+// Trailing empty lines are ignored.
+func ParseLog(name string, reader io.Reader) ([]*Commit, error) {
+	commits := []*Commit{}
+	var hash string
+	var commit *Commit
+
+	scanner := bufio.NewScanner(reader)
+	for number := 1; scanner.Scan(); number++ {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "    "):
+			if commit == nil {
+				commit = &Commit{Name: name, Hash: hash, end: number}
+				commits = append(commits, commit)
+			}
+			commit.add(line[4:], number, 4) //nolint:mnd // indentation.
+
+		case strings.TrimSpace(line) == "" && commit != nil:
+			commit.add("", number, 0)
+
+		default:
+			if commit != nil {
+				commit.finish()
+				commit, hash = nil, ""
+			}
+			if value, ok := strings.CutPrefix(line, "commit "); ok {
+				hash, _, _ = strings.Cut(value, " ")
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewErrParse(name, err)
+	}
+	if commit != nil {
+		commit.finish()
+	}
+	return commits, nil
+}
+
+// add adds the given message line with given input line number and column
+// offset to the commit.
+func (c *Commit) add(text string, line, column int) {
+	c.Lines = append(c.Lines, &Line{Text: text, Line: line, Column: column})
+}
+
+// finish removes the trailing empty lines of the commit message and sets up
+// the input line following the commit message, if the message is not empty.
+func (c *Commit) finish() *Commit {
+	for len(c.Lines) > 0 &&
+		strings.TrimSpace(c.Lines[len(c.Lines)-1].Text) == "" {
+		c.Lines = c.Lines[:len(c.Lines)-1]
+	}
+	if len(c.Lines) > 0 {
+		c.end = c.Lines[len(c.Lines)-1].Line + 1
+	}
+	return c
+}
+
+// Change represents the conventional commit change of a commit message.
+type Change struct {
+	// Commit provides the hash of the commit, if known.
+	Commit string `json:"commit,omitempty"`
+	// Type provides the conventional commit type, if any.
+	Type string `json:"type,omitempty"`
+	// Scope provides the conventional commit scope, if any.
+	Scope string `json:"scope,omitempty"`
+	// Subject provides the title without commit type and issue references.
+	Subject string `json:"subject"`
+	// Issues provides the issue references of the title.
+	Issues []string `json:"issues,omitempty"`
+	// Breaking provides the breaking change note, if the change is breaking.
+	Breaking string `json:"breaking,omitempty"`
+}
+
+// Verifier provides the verification of commit messages.
+type Verifier struct {
+	// rules provides the commit rules to verify.
+	rules *Rules
+	// issues provides the regular expression matching the issue references,
+	// or nil if the issue references are disabled.
+	issues *regexp.Regexp
+	// issue provides the regular expression matching a single issue
+	// reference.
+	issue *regexp.Regexp
+	// author provides the expected `Signed-off-by` author, if checked.
+	author string
+}
+
+// NewVerifier creates a new verifier for the given commit rules. If the given
+// author is not empty, the `Signed-off-by` trailer must name the author. An
+// error is returned, if the commit rules are invalid.
+func NewVerifier(rules *Rules, author string) (*Verifier, error) {
+	issues, err := rules.compile()
+	if err != nil {
+		return nil, err
+	}
+	verifier := &Verifier{rules: rules, issues: issues, author: author}
+	if issues != nil {
+		verifier.issue = regexp.MustCompile(rules.Issue)
+	}
+	return verifier, nil
+}
+
+// Verify verifies the given commits and returns the diagnostics of all
+// violations of the commit conventions in order of the commits.
+func (v *Verifier) Verify(commits ...*Commit) []*Diagnostic {
+	diagnostics := []*Diagnostic{}
+	for _, commit := range commits {
+		diagnostics = append(diagnostics, v.verifyTitle(commit)...)
+		if diagnostic := v.verifyBreaking(commit); diagnostic != nil {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+		if diagnostic := v.verifySigned(commit); diagnostic != nil {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+	return diagnostics
+}
+
+// Types returns the conventional commit types of the commit rules.
+func (v *Verifier) Types() []string {
+	return v.rules.Types
+}
+
+// Change returns the conventional commit change of the given commit, i.e. the
+// commit type, the scope, the subject, the issue references, and the breaking
+// change note. If the title has no conventional commit type, the commit type
+// is empty and the subject provides the title.
+func (v *Verifier) Change(commit *Commit) *Change {
+	title := commit.Title()
+	change := &Change{Commit: commit.Hash, Subject: title}
+	if v.issues != nil {
+		if loc := v.issues.FindStringIndex(title); loc != nil {
+			change.Issues = v.issue.FindAllString(title[loc[0]:], -1)
+			change.Subject = strings.TrimSpace(title[:loc[0]])
+		}
+	}
+
+	match := regexType.FindStringSubmatchIndex(change.Subject)
+	if match == nil {
+		return change
+	}
+	change.Type = change.Subject[match[2]:match[3]]
+	if match[4] >= 0 {
+		change.Scope = change.Subject[match[4]:match[5]]
+	}
+	change.Subject = change.Subject[match[1]:]
+
+	if note := commit.breaking(); note != "" {
+		change.Breaking = note
+	} else if match[6] >= 0 {
+		change.Breaking = change.Subject
+	}
+	return change
+}
+
+// breaking returns the note of the breaking change footer of the commit
+// including its continuation lines, or an empty string if the commit has no
+// breaking change footer.
+func (c *Commit) breaking() string {
+	for index, line := range c.Lines[min(1, len(c.Lines)):] {
+		for _, prefix := range breakingChange {
+			if note, ok := strings.CutPrefix(line.Text, prefix); ok {
+				notes := []string{strings.TrimSpace(note)}
+				for _, line := range c.Lines[index+2:] {
+					text := strings.TrimSpace(line.Text)
+					if text == "" || strings.HasPrefix(text, signedOffBy) {
+						break
+					}
+					notes = append(notes, text)
+				}
+				return strings.Join(notes, " ")
+			}
+		}
+	}
+	return ""
+}
+
+// verifyTitle verifies the conventional commit type and scope, the length,
+// and the issue references of the title of the given commit.
+func (v *Verifier) verifyTitle(commit *Commit) []*Diagnostic {
+	title := commit.titleLine()
+	diagnostics := []*Diagnostic{}
+	context := "title=" + title.Text
+	prefix := 0
+	if match := regexType.FindStringSubmatchIndex(title.Text); match == nil {
+		diagnostics = append(diagnostics, commit.diagnostic(title, 0,
+			RuleTypeMissing, "commit type missing", context))
+	} else {
+		prefix = match[1]
+		if !slices.Contains(v.rules.Types, title.Text[match[2]:match[3]]) {
+			diagnostics = append(diagnostics, commit.diagnostic(title, 0,
+				RuleTypeInvalid, "commit type invalid", context))
+		}
+		if match[4] >= 0 && len(v.rules.Scopes) != 0 && !slices.Contains(
+			v.rules.Scopes, title.Text[match[4]:match[5]]) {
+			diagnostics = append(diagnostics, commit.diagnostic(title,
+				match[4], RuleScopeInvalid, "commit scope invalid", context))
+		}
+	}
+
+	if diagnostic := v.verifyLength(commit, title); diagnostic != nil {
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	if v.issues == nil {
+		return diagnostics
+	}
+	if loc := v.issues.FindStringIndex(title.Text); loc == nil {
+		if v.rules.IssueMode == IssueRequired {
+			diagnostics = append(diagnostics, commit.diagnostic(title,
+				len(title.Text), RuleIssueMissing, "issue missing", context))
+		}
+	} else if index := strings.IndexAny(
+		title.Text[prefix:loc[0]], "()"); index >= 0 {
+		diagnostics = append(diagnostics, commit.diagnostic(title,
+			prefix+index, RuleIssueInvalid, "issue invalid", context))
+	}
+	return diagnostics
+}
+
+// verifyLength verifies that the number of characters of the given title of
+// the given commit is within the configured limits.
+func (v *Verifier) verifyLength(commit *Commit, title *Line) *Diagnostic {
+	length := utf8.RuneCountInString(title.Text)
+	switch {
+	case length < v.rules.TitleMin:
+		return commit.diagnostic(title, len(title.Text), RuleTitleShort,
+			"title too short", "title="+title.Text+
+				", min="+strconv.Itoa(v.rules.TitleMin))
+	case v.rules.TitleMax > 0 && length > v.rules.TitleMax:
+		index := 0
+		for count := 0; count < v.rules.TitleMax; count++ {
+			_, size := utf8.DecodeRuneInString(title.Text[index:])
+			index += size
+		}
+		return commit.diagnostic(title, index, RuleTitleLong,
+			"title too long", "title="+title.Text+
+				", max="+strconv.Itoa(v.rules.TitleMax))
+	}
+	return nil
+}
+
+// verifyBreaking verifies that a title with breaking change marker is
+// followed by a breaking change footer, if required.
+func (v *Verifier) verifyBreaking(commit *Commit) *Diagnostic {
+	title := commit.titleLine()
+	match := regexType.FindStringSubmatchIndex(title.Text)
+	if !v.rules.BreakingFooter || match == nil || match[6] < 0 {
+		return nil
+	}
+
+	for _, line := range commit.Lines[1:] {
+		for _, prefix := range breakingChange {
+			if strings.HasPrefix(line.Text, prefix) {
+				return nil
+			}
+		}
+	}
+	return commit.diagnostic(&Line{Line: commit.end}, 0, RuleBreakingMissing,
+		"breaking change footer missing", "title="+title.Text)
+}
+
+// verifySigned verifies that the commit message ends with a single
+// `Signed-off-by` trailer naming the author, if required. Depending on the
+// trailer mode, a missing trailer is accepted or any trailer is reported.
+func (v *Verifier) verifySigned(commit *Commit) *Diagnostic {
+	first := slices.IndexFunc(commit.Lines, func(line *Line) bool {
+		return strings.HasPrefix(line.Text, signedOffBy)
+	})
+	last := len(commit.Lines) - 1
+
+	if first < 0 && v.rules.SignedOffBy != SignedRequired {
+		return nil
+	} else if v.rules.SignedOffBy == SignedForbidden {
+		return commit.diagnostic(commit.Lines[first], 0, RuleSignedForbidden,
+			"signed-off-by forbidden", "sign="+commit.Lines[first].Text)
+	}
+
+	switch {
+	case first < 0 || !regexSigned.MatchString(commit.Lines[last].Text):
+		end := &Line{Line: commit.end}
+		return commit.diagnostic(end, 0, RuleSignedMissing,
+			"signed-off-by missing", "msg="+commit.Title())
+	case first != last:
+		return commit.diagnostic(commit.Lines[first+1], 0,
+			RuleSignedMultiple, "signed-off-by too many",
+			"sign="+commit.Lines[first+1].Text)
+	}
+
+	sign := commit.Lines[last]
+	match := regexSigned.FindStringSubmatchIndex(sign.Text)
+	if v.author != "" && sign.Text[match[2]:] != v.author {
+		return commit.diagnostic(sign, match[2], RuleSignedAuthor,
+			"signed-off-by not the author",
+			"sign="+sign.Text+", author="+v.author)
+	}
+	return nil
+}
+
+// Title returns the title of the commit.
+func (c *Commit) Title() string {
+	return c.titleLine().Text
+}
+
+// titleLine returns the title line of the commit, or an empty line at the
+// end of an empty commit message.
+func (c *Commit) titleLine() *Line {
+	if len(c.Lines) > 0 {
+		return c.Lines[0]
+	}
+	return &Line{Line: c.end, Column: 0}
+}
+
+// diagnostic creates a diagnostic for the given rule at the given character
+// index of the given message line.
+func (c *Commit) diagnostic(
+	line *Line, index int, rule, message, context string,
+) *Diagnostic {
+	return &Diagnostic{
+		Name: c.Name, Commit: c.Hash,
+		Line: line.Line, Column: line.Column + index + 1,
+		Rule: rule, Message: message, Context: context,
+	}
+}
@@ -0,0 +1,652 @@
+package verify_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-make/internal/verify"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// author contains the author of the test commit messages.
+	author = "John Doe <john.doe@zalando.de>"
+	// signed contains the `Signed-off-by` trailer of the test author.
+	signed = "Signed-off-by: " + author
+)
+
+// Open opens the fixture file with given name.
+func Open(name string) io.Reader {
+	file, err := os.Open("fixtures/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+// Diagnostic creates a diagnostic for the input with given name.
+func Diagnostic( //revive:disable-line:argument-limit // kiss.
+	name string, line, column int, rule, message, context string,
+) *verify.Diagnostic {
+	return &verify.Diagnostic{
+		Name: name, Line: line, Column: column,
+		Rule: rule, Message: message, Context: context,
+	}
+}
+
+// Rules creates the default rules updated by the given function.
+func Rules(update func(rules *verify.Rules)) *verify.Rules {
+	rules := verify.DefaultRules(verify.DefaultTypes)
+	update(rules)
+	return rules
+}
+
+type ParseMessageParams struct {
+	reader       io.Reader
+	expectCommit *verify.Commit
+	expectError  error
+}
+
+var parseMessageTestCases = map[string]ParseMessageParams{
+	"empty": {
+		reader:       strings.NewReader(""),
+		expectCommit: &verify.Commit{Name: "msg"},
+	},
+	"message with comments": {
+		reader: strings.NewReader("feat: title (#1)\n\n# comment\n" +
+			signed + "\n\n# comment\n#\n\n"),
+		expectCommit: &verify.Commit{Name: "msg", Lines: []*verify.Line{
+			{Text: "feat: title (#1)", Line: 1},
+			{Text: "", Line: 2},
+			{Text: "# comment", Line: 3},
+			{Text: signed, Line: 4},
+		}},
+	},
+	"read failure": {
+		reader:      iotest.ErrReader(assert.AnError),
+		expectError: verify.NewErrParse("msg", assert.AnError),
+	},
+}
+
+func TestParseMessage(t *testing.T) {
+	test.Map(t, parseMessageTestCases).
+		Run(func(t test.Test, param ParseMessageParams) {
+			// When
+			commit, err := verify.ParseMessage("msg", param.reader)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectCommit != nil {
+				assert.Equal(t, param.expectCommit.Lines, commit.Lines)
+				assert.Equal(t, param.expectCommit.Name, commit.Name)
+			}
+		})
+}
+
+type ParseLogParams struct {
+	reader        io.Reader
+	expectCommits []*verify.Commit
+	expectError   error
+}
+
+var parseLogTestCases = map[string]ParseLogParams{
+	"empty": {
+		reader:        strings.NewReader(""),
+		expectCommits: []*verify.Commit{},
+	},
+	"raw log": {
+		reader: strings.NewReader("commit 1234abcd\n" +
+			"tree 5678efab\nauthor John Doe\n\n" +
+			"    feat: title (#1)\n    \n    " + signed + "\n\n" +
+			"commit 9876fedc (HEAD -> main)\n\n    fix: title (#2)\n\n\n" +
+			"commit 5432dcba\n\n    \n"),
+		expectCommits: []*verify.Commit{{
+			Name: "log", Hash: "1234abcd", Lines: []*verify.Line{
+				{Text: "feat: title (#1)", Line: 5, Column: 4},
+				{Text: "", Line: 6, Column: 4},
+				{Text: signed, Line: 7, Column: 4},
+			},
+		}, {
+			Name: "log", Hash: "9876fedc", Lines: []*verify.Line{
+				{Text: "fix: title (#2)", Line: 11, Column: 4},
+			},
+		}, {
+			Name: "log", Hash: "5432dcba", Lines: []*verify.Line{},
+		}},
+	},
+	"read failure": {
+		reader:      iotest.ErrReader(assert.AnError),
+		expectError: verify.NewErrParse("log", assert.AnError),
+	},
+}
+
+func TestParseLog(t *testing.T) {
+	test.Map(t, parseLogTestCases).
+		Run(func(t test.Test, param ParseLogParams) {
+			// When
+			commits, err := verify.ParseLog("log", param.reader)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if assert.Len(t, commits, len(param.expectCommits)) {
+				for index, commit := range commits {
+					expect := param.expectCommits[index]
+					assert.Equal(t, expect.Name, commit.Name)
+					assert.Equal(t, expect.Hash, commit.Hash)
+					assert.Equal(t, expect.Lines, commit.Lines)
+				}
+			}
+		})
+}
+
+type VerifyParams struct {
+	message           string
+	rules             *verify.Rules
+	author            string
+	expectDiagnostics []*verify.Diagnostic
+}
+
+var verifyTestCases = map[string]VerifyParams{
+	"valid": {
+		message:           "feat: title (#1)\n\n" + signed + "\n",
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"valid with scope and breaking change": {
+		message:           "feat(scope)!: title (#1)\n\n" + signed + "\n",
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"valid with multiple issues": {
+		message: "fix[scope]: title (org/repo#7,#8) (#9)(#10)\n\n" +
+			signed + "\n",
+		author:            author,
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"empty": {
+		message: "",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 1, verify.RuleTypeMissing,
+				"commit type missing", "title="),
+			Diagnostic("msg", 1, 1, verify.RuleIssueMissing,
+				"issue missing", "title="),
+			Diagnostic("msg", 1, 1, verify.RuleSignedMissing,
+				"signed-off-by missing", "msg="),
+		},
+	},
+	"type missing": {
+		message: "title (#1)\n\n" + signed + "\n",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 1, verify.RuleTypeMissing,
+				"commit type missing", "title=title (#1)"),
+		},
+	},
+	"type invalid": {
+		message: "feature: title (#1)\n\n" + signed + "\n",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 1, verify.RuleTypeInvalid,
+				"commit type invalid", "title=feature: title (#1)"),
+		},
+	},
+	"issue missing": {
+		message: "feat: title\n\n" + signed + "\n",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 12, verify.RuleIssueMissing,
+				"issue missing", "title=feat: title"),
+		},
+	},
+	"issue invalid": {
+		message: "feat: title (draft) (#1)\n\n" + signed + "\n",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 13, verify.RuleIssueInvalid,
+				"issue invalid", "title=feat: title (draft) (#1)"),
+		},
+	},
+	"signed-off-by missing": {
+		message: "feat: title (#1)\n",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 2, 1, verify.RuleSignedMissing,
+				"signed-off-by missing", "msg=feat: title (#1)"),
+		},
+	},
+	"signed-off-by not last": {
+		message: "feat: title (#1)\n\n" + signed + "\ntext\n",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 5, 1, verify.RuleSignedMissing,
+				"signed-off-by missing", "msg=feat: title (#1)"),
+		},
+	},
+	"signed-off-by too many": {
+		message: "feat: title (#1)\n\n" + signed + "\n" + signed + "\n",
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 4, 1, verify.RuleSignedMultiple,
+				"signed-off-by too many", "sign="+signed),
+		},
+	},
+	"signed-off-by not the author": {
+		message: "feat: title (#1)\n\n" +
+			"Signed-off-by: Alice Doe <alice.doe@zalando.de>\n",
+		author: author,
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 3, 16, verify.RuleSignedAuthor,
+				"signed-off-by not the author",
+				"sign=Signed-off-by: Alice Doe <alice.doe@zalando.de>, "+
+					"author="+author),
+		},
+	},
+
+	"scope valid": {
+		message: "feat(make): title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Scopes = []string{"make", "go-make"}
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"scope invalid": {
+		message: "feat(build): title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Scopes = []string{"make", "go-make"}
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 6, verify.RuleScopeInvalid,
+				"commit scope invalid", "title=feat(build): title (#1)"),
+		},
+	},
+	"scope invalid type invalid": {
+		message: "feature[build]: title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Scopes = []string{"make"}
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 1, verify.RuleTypeInvalid,
+				"commit type invalid", "title=feature[build]: title (#1)"),
+			Diagnostic("msg", 1, 9, verify.RuleScopeInvalid,
+				"commit scope invalid", "title=feature[build]: title (#1)"),
+		},
+	},
+	"title too short": {
+		message: "feat: x (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.TitleMin = 16
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 13, verify.RuleTitleShort,
+				"title too short", "title=feat: x (#1), min=16"),
+		},
+	},
+	"title too long": {
+		message: "feat: größer (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.TitleMax = 10
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 13, verify.RuleTitleLong,
+				"title too long", "title=feat: größer (#1), max=10"),
+		},
+	},
+	"title within limits": {
+		message: "feat: title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.TitleMin, rules.TitleMax = 16, 16
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"issue jira valid": {
+		message: "feat: title (PROJ-12) (OPS-3)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Issue = `[A-Z][A-Z0-9]+-[0-9]+`
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"issue jira missing": {
+		message: "feat: title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Issue = `[A-Z][A-Z0-9]+-[0-9]+`
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 17, verify.RuleIssueMissing,
+				"issue missing", "title=feat: title (#1)"),
+		},
+	},
+	"issue optional missing": {
+		message: "feat: title\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.IssueMode = verify.IssueOptional
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"issue optional invalid": {
+		message: "feat: title (x) (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.IssueMode = verify.IssueOptional
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 1, 13, verify.RuleIssueInvalid,
+				"issue invalid", "title=feat: title (x) (#1)"),
+		},
+	},
+	"issue disabled": {
+		message: "feat: title (x)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.IssueMode = verify.IssueDisabled
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"signed-off-by optional missing": {
+		message: "feat: title (#1)\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.SignedOffBy = verify.SignedOptional
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"signed-off-by optional too many": {
+		message: "feat: title (#1)\n\n" + signed + "\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.SignedOffBy = verify.SignedOptional
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 4, 1, verify.RuleSignedMultiple,
+				"signed-off-by too many", "sign="+signed),
+		},
+	},
+	"signed-off-by forbidden missing": {
+		message: "feat: title (#1)\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.SignedOffBy = verify.SignedForbidden
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"signed-off-by forbidden": {
+		message: "feat: title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.SignedOffBy = verify.SignedForbidden
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 3, 1, verify.RuleSignedForbidden,
+				"signed-off-by forbidden", "sign="+signed),
+		},
+	},
+	"breaking footer": {
+		message: "feat!: title (#1)\n\nBREAKING CHANGE: removed x\n" +
+			signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.BreakingFooter = true
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"breaking footer alternative": {
+		message: "feat(make)!: title (#1)\n\nBREAKING-CHANGE: removed x\n" +
+			signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.BreakingFooter = true
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+	"breaking footer missing": {
+		message: "feat!: title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.BreakingFooter = true
+		}),
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg", 4, 1, verify.RuleBreakingMissing,
+				"breaking change footer missing", "title=feat!: title (#1)"),
+		},
+	},
+	"breaking footer not required": {
+		message: "feat: title (#1)\n\n" + signed + "\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.BreakingFooter = true
+		}),
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+}
+
+func TestVerify(t *testing.T) {
+	test.Map(t, verifyTestCases).
+		Run(func(t test.Test, param VerifyParams) {
+			// Given
+			commit, err := verify.ParseMessage("msg",
+				strings.NewReader(param.message))
+			assert.NoError(t, err)
+			rules := param.rules
+			if rules == nil {
+				rules = verify.DefaultRules(verify.DefaultTypes)
+			}
+			verifier, err := verify.NewVerifier(rules, param.author)
+			assert.NoError(t, err)
+
+			// When
+			diagnostics := verifier.Verify(commit)
+
+			// Then
+			assert.Equal(t, param.expectDiagnostics, diagnostics)
+		})
+}
+
+type VerifyFixtureParams struct {
+	name              string
+	parse             func(name string, reader io.Reader) []*verify.Commit
+	author            string
+	expectDiagnostics []*verify.Diagnostic
+}
+
+// ParseMessage parses the commit message fixture with given name.
+func ParseMessage(name string, reader io.Reader) []*verify.Commit {
+	commit, err := verify.ParseMessage(name, reader)
+	if err != nil {
+		panic(err)
+	}
+	return []*verify.Commit{commit}
+}
+
+// ParseLog parses the git log fixture with given name.
+func ParseLog(name string, reader io.Reader) []*verify.Commit {
+	commits, err := verify.ParseLog(name, reader)
+	if err != nil {
+		panic(err)
+	}
+	return commits
+}
+
+var verifyFixtureTestCases = map[string]VerifyFixtureParams{
+	"log all": {
+		name:  "log-all.in",
+		parse: ParseLog,
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("log-all.in", 2, 5, verify.RuleTypeMissing,
+				"commit type missing",
+				"title=commit type is missing (#0)"),
+			Diagnostic("log-all.in", 6, 5, verify.RuleTypeInvalid,
+				"commit type invalid",
+				"title=feature: commit type is invalid (#1)"),
+			Diagnostic("log-all.in", 18, 27, verify.RuleIssueMissing,
+				"issue missing", "title=feat: issue is missing"),
+			Diagnostic("log-all.in", 22, 33, verify.RuleIssueMissing,
+				"issue missing", "title=feat: issue is invalid (#4a)"),
+			Diagnostic("log-all.in", 26, 35, verify.RuleIssueMissing,
+				"issue missing", "title=feat: issue is invalid (org#5)"),
+			Diagnostic("log-all.in", 39, 1, verify.RuleSignedMissing,
+				"signed-off-by missing",
+				"msg=feat[service]!: initial commit (#11)"),
+			Diagnostic("log-all.in", 43, 5, verify.RuleSignedMultiple,
+				"signed-off-by too many", "sign="+signed),
+		},
+	},
+	"message failed": {
+		name:   "msg-failed.in",
+		parse:  ParseMessage,
+		author: author,
+		expectDiagnostics: []*verify.Diagnostic{
+			Diagnostic("msg-failed.in", 1, 1, verify.RuleTypeMissing,
+				"commit type missing",
+				"title=feat{wrong}: all is somehow wrong (org#1)"),
+			Diagnostic("msg-failed.in", 1, 42, verify.RuleIssueMissing,
+				"issue missing",
+				"title=feat{wrong}: all is somehow wrong (org#1)"),
+			Diagnostic("msg-failed.in", 3, 16, verify.RuleSignedAuthor,
+				"signed-off-by not the author",
+				"sign=Signed-off-by: Alice Doe <alice.doe@zalando.de>, "+
+					"author="+author),
+		},
+	},
+	"message okay": {
+		name:              "msg-okay.in",
+		parse:             ParseMessage,
+		author:            author,
+		expectDiagnostics: []*verify.Diagnostic{},
+	},
+}
+
+func TestVerifyFixtures(t *testing.T) {
+	test.Map(t, verifyFixtureTestCases).
+		Run(func(t test.Test, param VerifyFixtureParams) {
+			// Given
+			commits := param.parse(param.name, Open(param.name))
+			verifier, err := verify.NewVerifier(
+				verify.DefaultRules(verify.DefaultTypes), param.author)
+			assert.NoError(t, err)
+
+			// When
+			diagnostics := verifier.Verify(commits...)
+
+			// Then
+			assert.Equal(t, param.expectDiagnostics, diagnostics)
+		})
+}
+
+type ChangeParams struct {
+	message      string
+	rules        *verify.Rules
+	expectChange *verify.Change
+}
+
+var changeTestCases = map[string]ChangeParams{
+	"empty": {
+		message:      "",
+		expectChange: &verify.Change{},
+	},
+	"no convention": {
+		message:      "add feature\n\n" + signed + "\n",
+		expectChange: &verify.Change{Subject: "add feature"},
+	},
+	"no convention with issue": {
+		message: "add feature (#1)\n",
+		expectChange: &verify.Change{
+			Subject: "add feature", Issues: []string{"#1"},
+		},
+	},
+	"type only": {
+		message: "fix: repair feature\n",
+		expectChange: &verify.Change{
+			Type: "fix", Subject: "repair feature",
+		},
+	},
+	"type with scope and issues": {
+		message: "feat(make): add feature (#1,org/repo#2)(#3)\n",
+		expectChange: &verify.Change{
+			Type: "feat", Scope: "make", Subject: "add feature",
+			Issues: []string{"#1", "org/repo#2", "#3"},
+		},
+	},
+	"breaking marker": {
+		message: "feat[make]!: change feature (#1)\n\n" + signed + "\n",
+		expectChange: &verify.Change{
+			Type: "feat", Scope: "make", Subject: "change feature",
+			Issues: []string{"#1"}, Breaking: "change feature",
+		},
+	},
+	"breaking footer": {
+		message: "feat: change feature (#1)\n\nbody\n\n" +
+			"BREAKING CHANGE: option removed\n  use other option\n" +
+			signed + "\n",
+		expectChange: &verify.Change{
+			Type: "feat", Subject: "change feature",
+			Issues:   []string{"#1"},
+			Breaking: "option removed use other option",
+		},
+	},
+	"breaking footer after marker": {
+		message: "remove!: drop feature (#1)\n\n" +
+			"BREAKING-CHANGE: feature dropped\n\n" + signed + "\n",
+		expectChange: &verify.Change{
+			Type: "remove", Subject: "drop feature",
+			Issues: []string{"#1"}, Breaking: "feature dropped",
+		},
+	},
+	"issues disabled": {
+		message: "fix: repair feature (#1)\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.IssueMode = verify.IssueDisabled
+		}),
+		expectChange: &verify.Change{
+			Type: "fix", Subject: "repair feature (#1)",
+		},
+	},
+	"jira issues": {
+		message: "fix: repair feature (ABC-1,XYZ-2)\n",
+		rules: Rules(func(rules *verify.Rules) {
+			rules.Issue = `[A-Z]+-[0-9]+`
+		}),
+		expectChange: &verify.Change{
+			Type: "fix", Subject: "repair feature",
+			Issues: []string{"ABC-1", "XYZ-2"},
+		},
+	},
+}
+
+func TestChange(t *testing.T) {
+	test.Map(t, changeTestCases).
+		Run(func(t test.Test, param ChangeParams) {
+			// Given
+			commit, err := verify.ParseMessage("msg",
+				strings.NewReader(param.message))
+			assert.NoError(t, err)
+			rules := param.rules
+			if rules == nil {
+				rules = verify.DefaultRules(verify.DefaultTypes)
+			}
+			verifier, err := verify.NewVerifier(rules, "")
+			assert.NoError(t, err)
+
+			// When
+			change := verifier.Change(commit)
+
+			// Then
+			assert.Equal(t, param.expectChange, change)
+		})
+}
+
+type DiagnosticParams struct {
+	diagnostic  *verify.Diagnostic
+	expectError string
+}
+
+var diagnosticTestCases = map[string]DiagnosticParams{
+	"without commit": {
+		diagnostic: Diagnostic("msg", 1, 1, verify.RuleTypeMissing,
+			"commit type missing", "title=title"),
+		expectError: "msg:1:1: commit type missing [title=title]",
+	},
+	"with commit": {
+		diagnostic: &verify.Diagnostic{
+			Name: "branch", Commit: "1234abcd", Line: 5, Column: 5,
+			Message: "commit type missing", Context: "title=title",
+		},
+		expectError: "branch:5:5: commit type missing " +
+			"[commit=1234abcd, title=title]",
+	},
+}
+
+func TestDiagnostic(t *testing.T) {
+	test.Map(t, diagnosticTestCases).
+		Run(func(t test.Test, param DiagnosticParams) {
+			// When
+			err := param.diagnostic.Error()
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+		})
+}